@@ -8,6 +8,7 @@
 // schema/registry-gcr.yaml
 // schema/registry-server.yaml
 // schema/task-build.yaml
+// schema/task-host.yaml
 // schema/task-plan.yaml
 // schema/task-push.yaml
 // schema/task-remove.yaml
@@ -90,8 +91,18 @@ properties:
     properties:
       force-sequential:
         type: boolean
+      fail-fast:
+        type: boolean
+      jobs:
+        type: integer
+        minimum: 0
       healthcheck-interval:
         type: string
+      runtime:
+        type: string
+        enum:
+          - docker
+          - podman
       ssh-identities:
         oneOf:
           - type: string
@@ -119,6 +130,18 @@ properties:
     type: object
   metaplans:
     type: object
+  caches:
+    type: object
+    additionalProperties:
+      type: object
+      properties:
+        path:
+          type: string
+        key:
+          type: string
+      required:
+        - path
+      additionalProperties: false
 additionalProperties: false
 `)
 
@@ -132,7 +155,7 @@ func schemaConfigYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "schema/config.yaml", size: 1371, mode: os.FileMode(420), modTime: time.Unix(1546566187, 0)}
+	info := bindataFileInfo{name: "schema/config.yaml", size: 1779, mode: os.FileMode(420), modTime: time.Unix(1792321126, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -192,12 +215,26 @@ properties:
         $ref: '#/definitions/stringOrList'
       force-sequential:
         type: boolean
+      fail-fast:
+        type: boolean
+      jobs:
+        type: integer
+        minimum: 0
       healthcheck-interval:
         type: string
       path-substitutions:
         type: object
         additionalProperties:
           type: string
+      runtime:
+        type: string
+        enum:
+          - docker
+          - podman
+      allowed-mounts:
+        $ref: '#/definitions/stringOrList'
+      allow-privileged:
+        type: boolean
     additionalProperties: false
   registries:
     type: array
@@ -224,7 +261,7 @@ func schemaOverrideYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "schema/override.yaml", size: 1130, mode: os.FileMode(420), modTime: time.Unix(1546907405, 0)}
+	info := bindataFileInfo{name: "schema/override.yaml", size: 1421, mode: os.FileMode(420), modTime: time.Unix(1792323411, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -245,6 +282,8 @@ definitions:
         type: string
       disabled:
         type: string
+      if:
+        type: string
       before-stage:
         type: string
       after-stage:
@@ -261,11 +300,58 @@ definitions:
           - on-failure
       parallel:
         type: boolean
+      max-parallel:
+        type: integer
+        minimum: 0
+      fail-fast:
+        type: boolean
+      timeout:
+        type: string
       environment:
         $ref: '#/definitions/stringOrList'
     additionalProperties: false
     required:
       - name
+  matrix:
+    type: object
+    properties:
+      exclude:
+        type: array
+        items:
+          type: object
+          additionalProperties:
+            type: string
+    additionalProperties:
+      $ref: '#/definitions/stringOrList'
+    minProperties: 1
+  parameter:
+    type: object
+    properties:
+      name:
+        type: string
+      type:
+        type: string
+        enum:
+          - string
+          - int
+          - bool
+          - enum
+      values:
+        type: array
+        items:
+          type: string
+      default:
+        type:
+          - string
+          - integer
+          - boolean
+      description:
+        type: string
+      required:
+        type: boolean
+    additionalProperties: false
+    required:
+      - name
   stageTask:
     oneOf:
       - type: string
@@ -275,8 +361,30 @@ definitions:
             type: string
           disabled:
             type: string
+          if:
+            type: string
           environment:
             $ref: '#/definitions/stringOrList'
+          needs:
+            $ref: '#/definitions/stringOrList'
+          matrix:
+            $ref: '#/definitions/matrix'
+          allow-failure:
+            type: boolean
+          retries:
+            type: integer
+            minimum: 0
+          retry-delay:
+            type: string
+          retry-backoff:
+            type: number
+            minimum: 1
+          retry-max-delay:
+            type: string
+          retry-on-exit-codes:
+            type: array
+            items:
+              type: integer
         additionalProperties: false
         required:
           - name
@@ -287,12 +395,20 @@ properties:
     type: string
   disabled:
     type: string
+  if:
+    type: string
+  timeout:
+    type: string
   stages:
     type: array
     items:
       $ref: '#/definitions/stage'
   environment:
     $ref: '#/definitions/stringOrList'
+  parameters:
+    type: array
+    items:
+      $ref: '#/definitions/parameter'
 additionalProperties: false
 `)
 
@@ -306,7 +422,7 @@ func schemaPlanYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "schema/plan.yaml", size: 1231, mode: os.FileMode(420), modTime: time.Unix(1546566187, 0)}
+	info := bindataFileInfo{name: "schema/plan.yaml", size: 2837, mode: os.FileMode(420), modTime: time.Unix(1792324190, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -442,6 +558,28 @@ properties:
     type: array
     items:
       type: string
+  timeout:
+    type: string
+  allow-failure:
+    type: boolean
+  inputs:
+    $ref: '#/definitions/stringOrList'
+  outputs:
+    $ref: '#/definitions/stringOrList'
+  retries:
+    type: integer
+    minimum: 0
+  retry-delay:
+    type: string
+  retry-backoff:
+    type: number
+    minimum: 1
+  retry-max-delay:
+    type: string
+  retry-on-exit-codes:
+    type: array
+    items:
+      type: integer
   dockerfile:
     type: string
   target:
@@ -463,7 +601,82 @@ func schemaTaskBuildYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "schema/task-build.yaml", size: 533, mode: os.FileMode(420), modTime: time.Unix(1546566188, 0)}
+	info := bindataFileInfo{name: "schema/task-build.yaml", size: 926, mode: os.FileMode(420), modTime: time.Unix(1792324190, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _schemaTaskHostYaml = []byte(`---
+
+definitions:
+  stringOrList:
+    oneOf:
+      - type: string
+      - type: array
+        items:
+          type: string
+
+type: object
+properties:
+  type:
+    type: string
+    enum:
+      - host
+  extends:
+    type: string
+  environment:
+    $ref: '#/definitions/stringOrList'
+  required-environment:
+    type: array
+    items:
+      type: string
+  timeout:
+    type: string
+  allow-failure:
+    type: boolean
+  inputs:
+    $ref: '#/definitions/stringOrList'
+  outputs:
+    $ref: '#/definitions/stringOrList'
+  retries:
+    type: integer
+    minimum: 0
+  retry-delay:
+    type: string
+  retry-backoff:
+    type: number
+    minimum: 1
+  retry-max-delay:
+    type: string
+  retry-on-exit-codes:
+    type: array
+    items:
+      type: integer
+  command:
+    type: string
+  shell:
+    type: string
+  script:
+    type: string
+  workdir:
+    type: string
+    enum:
+      - project
+      - workspace
+additionalProperties: false
+`)
+
+func schemaTaskHostYamlBytes() ([]byte, error) {
+	return _schemaTaskHostYaml, nil
+}
+
+func schemaTaskHostYaml() (*asset, error) {
+	bytes, err := schemaTaskHostYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "schema/task-host.yaml", size: 924, mode: os.FileMode(420), modTime: time.Unix(1792324190, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -492,6 +705,10 @@ properties:
     type: array
     items:
       type: string
+  timeout:
+    type: string
+  allow-failure:
+    type: boolean
   name:
     type: string
 additionalProperties: false
@@ -507,7 +724,7 @@ func schemaTaskPlanYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "schema/task-plan.yaml", size: 403, mode: os.FileMode(420), modTime: time.Unix(1546566188, 0)}
+	info := bindataFileInfo{name: "schema/task-plan.yaml", size: 466, mode: os.FileMode(420), modTime: time.Unix(1792317046, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -536,6 +753,24 @@ properties:
     type: array
     items:
       type: string
+  timeout:
+    type: string
+  allow-failure:
+    type: boolean
+  retries:
+    type: integer
+    minimum: 0
+  retry-delay:
+    type: string
+  retry-backoff:
+    type: number
+    minimum: 1
+  retry-max-delay:
+    type: string
+  retry-on-exit-codes:
+    type: array
+    items:
+      type: integer
   images:
     $ref: '#/definitions/stringOrList'
   include-built:
@@ -553,7 +788,7 @@ func schemaTaskPushYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "schema/task-push.yaml", size: 462, mode: os.FileMode(420), modTime: time.Unix(1550198041, 0)}
+	info := bindataFileInfo{name: "schema/task-push.yaml", size: 756, mode: os.FileMode(420), modTime: time.Unix(1792324190, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -582,6 +817,24 @@ properties:
     type: array
     items:
       type: string
+  timeout:
+    type: string
+  allow-failure:
+    type: boolean
+  retries:
+    type: integer
+    minimum: 0
+  retry-delay:
+    type: string
+  retry-backoff:
+    type: number
+    minimum: 1
+  retry-max-delay:
+    type: string
+  retry-on-exit-codes:
+    type: array
+    items:
+      type: integer
   images:
     $ref: '#/definitions/stringOrList'
   include-built:
@@ -599,7 +852,7 @@ func schemaTaskRemoveYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "schema/task-remove.yaml", size: 464, mode: os.FileMode(420), modTime: time.Unix(1550198039, 0)}
+	info := bindataFileInfo{name: "schema/task-remove.yaml", size: 758, mode: os.FileMode(420), modTime: time.Unix(1792324190, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -626,6 +879,64 @@ definitions:
         type: string
       timeout:
         type: string
+      max-wait:
+        type: string
+    additionalProperties: false
+  readiness:
+    type: object
+    properties:
+      tcp-port:
+        type: integer
+        minimum: 1
+        maximum: 65535
+      http:
+        type: object
+        properties:
+          port:
+            type: integer
+            minimum: 1
+            maximum: 65535
+          path:
+            type: string
+          status:
+            type: integer
+            minimum: 100
+            maximum: 599
+        required:
+          - port
+        additionalProperties: false
+      log-pattern:
+        type: string
+      timeout:
+        type: string
+      interval:
+        type: string
+    additionalProperties: false
+  mount:
+    type: object
+    properties:
+      type:
+        type: string
+        enum:
+          - bind
+          - volume
+          - tmpfs
+      source:
+        type: string
+      target:
+        type: string
+      read-only:
+        type: boolean
+    required:
+      - target
+    additionalProperties: false
+  cache:
+    type: object
+    properties:
+      inputs:
+        $ref: '#/definitions/stringOrList'
+      outputs:
+        $ref: '#/definitions/stringOrList'
     additionalProperties: false
 
 type: object
@@ -642,6 +953,28 @@ properties:
     type: array
     items:
       type: string
+  timeout:
+    type: string
+  allow-failure:
+    type: boolean
+  inputs:
+    $ref: '#/definitions/stringOrList'
+  outputs:
+    $ref: '#/definitions/stringOrList'
+  retries:
+    type: integer
+    minimum: 0
+  retry-delay:
+    type: string
+  retry-backoff:
+    type: number
+    minimum: 1
+  retry-max-delay:
+    type: string
+  retry-on-exit-codes:
+    type: array
+    items:
+      type: integer
   image:
     type: string
   command:
@@ -662,8 +995,38 @@ properties:
     type: boolean
   healthcheck:
     $ref: '#/definitions/healthcheck'
+  readiness:
+    $ref: '#/definitions/readiness'
   export-environment-file:
     $ref: '#/definitions/stringOrList'
+  cache:
+    $ref: '#/definitions/cache'
+  cpu-shares:
+    type: string
+  memory:
+    type: string
+  shm-size:
+    type: string
+  ulimits:
+    $ref: '#/definitions/stringOrList'
+  privileged:
+    type: boolean
+  cap-add:
+    $ref: '#/definitions/stringOrList'
+  tmpfs:
+    $ref: '#/definitions/stringOrList'
+  devices:
+    $ref: '#/definitions/stringOrList'
+  platform:
+    type: string
+  mounts:
+    type: array
+    items:
+      $ref: '#/definitions/mount'
+  ports:
+    $ref: '#/definitions/stringOrList'
+  caches:
+    $ref: '#/definitions/stringOrList'
 additionalProperties: false
 `)
 
@@ -677,7 +1040,7 @@ func schemaTaskRunYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "schema/task-run.yaml", size: 1013, mode: os.FileMode(420), modTime: time.Unix(1546566188, 0)}
+	info := bindataFileInfo{name: "schema/task-run.yaml", size: 3171, mode: os.FileMode(420), modTime: time.Unix(1792324190, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -742,6 +1105,7 @@ var _bindata = map[string]func() (*asset, error){
 	"schema/registry-gcr.yaml":    schemaRegistryGcrYaml,
 	"schema/registry-server.yaml": schemaRegistryServerYaml,
 	"schema/task-build.yaml":      schemaTaskBuildYaml,
+	"schema/task-host.yaml":       schemaTaskHostYaml,
 	"schema/task-plan.yaml":       schemaTaskPlanYaml,
 	"schema/task-push.yaml":       schemaTaskPushYaml,
 	"schema/task-remove.yaml":     schemaTaskRemoveYaml,
@@ -798,6 +1162,7 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"registry-gcr.yaml":    &bintree{schemaRegistryGcrYaml, map[string]*bintree{}},
 		"registry-server.yaml": &bintree{schemaRegistryServerYaml, map[string]*bintree{}},
 		"task-build.yaml":      &bintree{schemaTaskBuildYaml, map[string]*bintree{}},
+		"task-host.yaml":       &bintree{schemaTaskHostYaml, map[string]*bintree{}},
 		"task-plan.yaml":       &bintree{schemaTaskPlanYaml, map[string]*bintree{}},
 		"task-push.yaml":       &bintree{schemaTaskPushYaml, map[string]*bintree{}},
 		"task-remove.yaml":     &bintree{schemaTaskRemoveYaml, map[string]*bintree{}},
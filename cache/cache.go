@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ij-build/ij/paths"
+	"github.com/ij-build/ij/util"
+)
+
+// Cache is a content-addressed store of task outputs on the host.
+type Cache struct {
+	root string
+}
+
+const DefaultDir = ".ij/cache"
+
+func NewCache(root string) *Cache {
+	return &Cache{
+		root: root,
+	}
+}
+
+// DefaultRoot returns the cache directory within the home directory of
+// the current user.
+func DefaultRoot() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, DefaultDir), nil
+}
+
+// Key hashes the given definition strings along with the content and
+// path (relative to root) of each of the given files.
+func Key(root string, files []string, definition ...string) (string, error) {
+	hash := sha256.New()
+
+	for _, value := range definition {
+		fmt.Fprintf(hash, "%d:%s\n", len(value), value)
+	}
+
+	for _, path := range files {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(hash, "%d:%s\n", len(rel), filepath.ToSlash(rel))
+
+		if err := hashFile(hash, path); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Restore copies the files stored under the given key into the target
+// directory. Returns false if there is no entry for the key.
+func (c *Cache) Restore(key, target string) (bool, error) {
+	entry := c.entryPath(key)
+
+	exists, err := paths.DirExists(entry)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	files, err := listFiles(entry)
+	if err != nil {
+		return false, err
+	}
+
+	if err := copyFiles(entry, target, files); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Store copies the given files, which must be within the source directory,
+// into an entry for the given key. The entry is populated in a temporary
+// directory first so that a partial entry is never visible.
+func (c *Cache) Store(key, source string, files []string) error {
+	id, err := util.MakeID()
+	if err != nil {
+		return err
+	}
+
+	temp := c.entryPath(fmt.Sprintf(".%s-%s", key, id))
+
+	if err := paths.EnsureDirExists(temp, os.ModePerm); err != nil {
+		return err
+	}
+
+	if err := copyFiles(source, temp, files); err != nil {
+		os.RemoveAll(temp)
+		return err
+	}
+
+	if err := os.Rename(temp, c.entryPath(key)); err != nil {
+		os.RemoveAll(temp)
+
+		// Another run stored the same outputs first
+		if exists, _ := paths.DirExists(c.entryPath(key)); exists {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *Cache) entryPath(key string) string {
+	return filepath.Join(c.root, key)
+}
+
+//
+// Helpers
+
+func hashFile(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return err
+}
+
+func listFiles(root string) ([]string, error) {
+	files := []string{}
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+
+	return files, err
+}
+
+func copyFiles(source, target string, files []string) error {
+	for _, path := range files {
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+
+		if err := copyFile(path, filepath.Join(target, rel)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyFile(src, dest string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer srcFile.Close()
+
+	if err := paths.EnsureParentExists(dest, os.ModePerm); err != nil {
+		return err
+	}
+
+	destFile, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	defer destFile.Close()
+
+	_, err = io.Copy(destFile, srcFile)
+	return err
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type CacheSuite struct{}
+
+func (s *CacheSuite) TestKey(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	var (
+		a = filepath.Join(name, "a.txt")
+		b = filepath.Join(name, "b.txt")
+	)
+
+	ioutil.WriteFile(a, []byte("foo"), 0644)
+	ioutil.WriteFile(b, []byte("bar"), 0644)
+
+	key1, err := Key(name, []string{a, b}, "def")
+	Expect(err).To(BeNil())
+	Expect(key1).To(HaveLen(64))
+
+	key2, _ := Key(name, []string{a, b}, "def")
+	Expect(key2).To(Equal(key1))
+
+	key3, _ := Key(name, []string{a, b}, "other")
+	Expect(key3).NotTo(Equal(key1))
+
+	key4, _ := Key(name, []string{a}, "def")
+	Expect(key4).NotTo(Equal(key1))
+
+	ioutil.WriteFile(b, []byte("baz"), 0644)
+	key5, _ := Key(name, []string{a, b}, "def")
+	Expect(key5).NotTo(Equal(key1))
+}
+
+func (s *CacheSuite) TestStoreAndRestore(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	var (
+		cache  = NewCache(filepath.Join(name, "cache"))
+		source = filepath.Join(name, "source")
+		target = filepath.Join(name, "target")
+		file   = filepath.Join(source, "x", "a.txt")
+	)
+
+	os.MkdirAll(filepath.Dir(file), os.ModePerm)
+	ioutil.WriteFile(file, []byte("foo"), 0755)
+	ioutil.WriteFile(filepath.Join(source, "b.txt"), []byte("bar"), 0644)
+
+	Expect(cache.Store("abc", source, []string{file})).To(BeNil())
+
+	hit, err := cache.Restore("abc", target)
+	Expect(err).To(BeNil())
+	Expect(hit).To(BeTrue())
+
+	content, err := ioutil.ReadFile(filepath.Join(target, "x", "a.txt"))
+	Expect(err).To(BeNil())
+	Expect(string(content)).To(Equal("foo"))
+
+	info, err := os.Stat(filepath.Join(target, "x", "a.txt"))
+	Expect(err).To(BeNil())
+	Expect(info.Mode().Perm()).To(Equal(os.FileMode(0755)))
+
+	_, err = os.Stat(filepath.Join(target, "b.txt"))
+	Expect(os.IsNotExist(err)).To(BeTrue())
+
+	entries, err := ioutil.ReadDir(filepath.Join(name, "cache"))
+	Expect(err).To(BeNil())
+	Expect(entries).To(HaveLen(1))
+}
+
+func (s *CacheSuite) TestStoreExisting(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	var (
+		cache = NewCache(filepath.Join(name, "cache"))
+		file  = filepath.Join(name, "a.txt")
+	)
+
+	ioutil.WriteFile(file, []byte("foo"), 0644)
+	Expect(cache.Store("abc", name, []string{file})).To(BeNil())
+	Expect(cache.Store("abc", name, []string{file})).To(BeNil())
+
+	entries, err := ioutil.ReadDir(filepath.Join(name, "cache"))
+	Expect(err).To(BeNil())
+	Expect(entries).To(HaveLen(1))
+}
+
+func (s *CacheSuite) TestRestoreMissing(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	hit, err := NewCache(name).Restore("abc", filepath.Join(name, "target"))
+	Expect(err).To(BeNil())
+	Expect(hit).To(BeFalse())
+}
@@ -20,6 +20,8 @@ type (
 
 	runner struct {
 		logger  logging.Logger
+		dir     string
+		env     []string
 		testing bool
 	}
 )
@@ -38,6 +40,16 @@ func NewRunner(logger logging.Logger) Runner {
 	return newRunner(logger, false)
 }
 
+// NewHostRunner creates a runner that invokes commands from the given
+// directory with the given environment.
+func NewHostRunner(logger logging.Logger, dir string, env []string) Runner {
+	return &runner{
+		logger: logger,
+		dir:    dir,
+		env:    env,
+	}
+}
+
 func newRunner(logger logging.Logger, testing bool) *runner {
 	return &runner{
 		logger:  logger,
@@ -90,7 +102,7 @@ func (r *runner) run(
 		r.logger.Debug(
 			nil,
 			"Running command: %s",
-			strings.Join(maskSecrets(args), " "),
+			strings.Join(MaskSecrets(args), " "),
 		)
 	}
 
@@ -105,6 +117,9 @@ func (r *runner) run(
 		command.Stdin = stdin
 	}
 
+	command.Dir = r.dir
+	command.Env = r.env
+
 	if r.testing {
 		command.Env = []string{
 			fmt.Sprintf("%s=1", TestEnvFlag),
@@ -136,6 +151,17 @@ func (r *runner) run(
 	return nil
 }
 
+// ExitCode returns the exit status of the process (or container) which
+// produced the given error, or -1 if the error did not come from a process
+// that exited normally.
+func ExitCode(err error) int {
+	if exitErr, ok := err.(interface{ ExitCode() int }); ok {
+		return exitErr.ExitCode()
+	}
+
+	return -1
+}
+
 func processOutput(r io.Reader, p outputProcessor) {
 	scanner := bufio.NewScanner(r)
 
@@ -144,7 +170,9 @@ func processOutput(r io.Reader, p outputProcessor) {
 	}
 }
 
-func maskSecrets(args []string) []string {
+// MaskSecrets replaces the value of each argument of the form NAME=VALUE
+// whose name appears to refer to a secret.
+func MaskSecrets(args []string) []string {
 	masked := []string{}
 	for _, arg := range args {
 		masked = append(masked, maskSecret(arg))
@@ -3,24 +3,38 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/stevenle/topsort"
+
+	"github.com/ij-build/ij/expression"
 )
 
 type (
 	Config struct {
-		Extends          []string            `json:"extends,omitempty"`
-		Options          *Options            `json:"options,omitempty"`
-		Registries       []Registry          `json:"registries,omitempty"`
-		Workspace        string              `json:"workspace,omitempty"`
-		Environment      []string            `json:"environment,omitempty"`
-		EnvironmentFiles []string            `json:"env-file,omitempty"`
-		Import           *ImportFileList     `json:"import,omitempty"`
-		Export           *ExportFileList     `json:"export,omitempty"`
-		Tasks            map[string]Task     `json:"tasks,omitempty"`
-		Plans            map[string]*Plan    `json:"plans,omitempty"`
-		Metaplans        map[string][]string `json:"metaplans,omitempty"`
+		Extends          []string                    `json:"extends,omitempty"`
+		Options          *Options                    `json:"options,omitempty"`
+		Registries       []Registry                  `json:"registries,omitempty"`
+		Workspace        string                      `json:"workspace,omitempty"`
+		Environment      []string                    `json:"environment,omitempty"`
+		EnvironmentFiles []string                    `json:"env-file,omitempty"`
+		Import           *ImportFileList             `json:"import,omitempty"`
+		Export           *ExportFileList             `json:"export,omitempty"`
+		Tasks            map[string]Task             `json:"tasks,omitempty"`
+		Plans            map[string]*Plan            `json:"plans,omitempty"`
+		Metaplans        map[string][]string         `json:"metaplans,omitempty"`
+		Caches           map[string]*DependencyCache `json:"caches,omitempty"`
+	}
+
+	// DependencyCache is a directory of run task containers which persists
+	// across runs of the project. Tasks which opt into the cache share the
+	// same content for each distinct expansion of the key.
+	DependencyCache struct {
+		Name string `json:"-"`
+		Path string `json:"path"`
+		Key  string `json:"key,omitempty"`
 	}
 
 	// Note: Options must serialize itself manually due to the time.Duration field.
@@ -28,8 +42,13 @@ type (
 	Options struct {
 		SSHIdentities       []string
 		ForceSequential     bool
+		FailFast            bool
+		Jobs                int
 		HealthcheckInterval time.Duration
 		PathSubstitutions   map[string]string
+		Runtime             string
+		AllowedMounts       []string
+		AllowPrivileged     bool
 	}
 
 	ImportFileList struct {
@@ -54,6 +73,8 @@ type (
 	}
 )
 
+var cacheNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
 func (c *Config) Merge(child *Config) error {
 	c.Options.Merge(child.Options)
 	c.Registries = append(c.Registries, child.Registries...)
@@ -88,6 +109,10 @@ func (c *Config) Merge(child *Config) error {
 		c.Metaplans[name] = plans
 	}
 
+	for name, cache := range child.Caches {
+		c.Caches[name] = cache
+	}
+
 	return nil
 }
 
@@ -122,7 +147,12 @@ func (o *Options) Merge(child *Options) {
 	}
 
 	o.ForceSequential = extendBool(child.ForceSequential, o.ForceSequential)
+	o.FailFast = extendBool(child.FailFast, o.FailFast)
+	o.Jobs = extendInt(child.Jobs, o.Jobs)
 	o.HealthcheckInterval = extendDuration(child.HealthcheckInterval, o.HealthcheckInterval)
+	o.Runtime = extendString(child.Runtime, o.Runtime)
+	o.AllowedMounts = append(o.AllowedMounts, child.AllowedMounts...)
+	o.AllowPrivileged = extendBool(child.AllowPrivileged, o.AllowPrivileged)
 }
 
 func (f *ImportFileList) Merge(child *ImportFileList) {
@@ -162,6 +192,14 @@ func (c *Config) Validate() error {
 	validators := []func() error{
 		c.validateTaskNames,
 		c.validatePlanNames,
+		c.validatePlanGraphs,
+		c.validateConditions,
+		c.validateMatrices,
+		c.validateParameters,
+		c.validateArtifacts,
+		c.validateMounts,
+		c.validateCaches,
+		c.validateReadiness,
 	}
 
 	for _, validator := range validators {
@@ -249,15 +287,338 @@ func (c *Config) validatePlanNames() error {
 	return nil
 }
 
+func (c *Config) validatePlanGraphs() error {
+	for _, plan := range c.Plans {
+		if !plan.HasNeeds() {
+			continue
+		}
+
+		if _, err := plan.Graph(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateConditions() error {
+	for _, plan := range c.Plans {
+		if err := validateCondition(plan.If, plan.Name); err != nil {
+			return err
+		}
+
+		for _, stage := range plan.Stages {
+			if err := validateCondition(stage.If, plan.Name, stage.Name); err != nil {
+				return err
+			}
+
+			for _, stageTask := range stage.Tasks {
+				if err := validateCondition(stageTask.If, plan.Name, stage.Name, stageTask.Name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateMatrices() error {
+	for _, plan := range c.Plans {
+		for _, stage := range plan.Stages {
+			for _, stageTask := range stage.Tasks {
+				if len(stageTask.Instances()) == 0 {
+					return fmt.Errorf(
+						"matrix of %s/%s/%s excludes every combination",
+						plan.Name,
+						stage.Name,
+						stageTask.Name,
+					)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateParameters() error {
+	for _, plan := range c.Plans {
+		names := map[string]struct{}{}
+
+		for _, parameter := range plan.Parameters {
+			if _, ok := names[parameter.Name]; ok {
+				return fmt.Errorf(
+					"parameter %s is declared twice in plan %s",
+					parameter.Name,
+					plan.Name,
+				)
+			}
+
+			names[parameter.Name] = struct{}{}
+
+			if err := parameter.validate(); err != nil {
+				return fmt.Errorf("plan %s: %s", plan.Name, err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateArtifacts() error {
+	for name, task := range c.Tasks {
+		for _, input := range task.GetInputs() {
+			producer, _, ok := SplitArtifact(input)
+			if !ok {
+				return fmt.Errorf(
+					"malformed input %s in task %s (expected task:path)",
+					input,
+					name,
+				)
+			}
+
+			other, ok := c.Tasks[producer]
+			if !ok {
+				return fmt.Errorf(
+					"task %s consumes artifacts of undeclared task %s",
+					name,
+					producer,
+				)
+			}
+
+			if len(other.GetOutputs()) == 0 {
+				return fmt.Errorf(
+					"task %s consumes artifacts of task %s, which declares no outputs",
+					name,
+					producer,
+				)
+			}
+		}
+	}
+
+	for _, plan := range c.Plans {
+		if err := c.validatePlanArtifacts(plan); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validatePlanArtifacts ensures that a task of the plan which declares
+// outputs runs as a single instance, and that a task consuming artifacts
+// of another task of the plan runs after it. A producer absent from the
+// plan may be run by an earlier plan of the same invocation.
+func (c *Config) validatePlanArtifacts(plan *Plan) error {
+	nodes, err := plan.Graph()
+	if err != nil {
+		return err
+	}
+
+	indices := map[string][]int{}
+	for i, node := range nodes {
+		indices[node.StageTask.Name] = append(indices[node.StageTask.Name], i)
+	}
+
+	for _, node := range nodes {
+		name := node.StageTask.Name
+
+		task, ok := c.Tasks[name]
+		if !ok || len(task.GetOutputs()) == 0 {
+			continue
+		}
+
+		if node.StageTask.Matrix != nil {
+			return fmt.Errorf(
+				"task %s declares outputs but is run by a matrix in %s/%s",
+				name,
+				plan.Name,
+				node.Stage.Name,
+			)
+		}
+
+		if len(indices[name]) > 1 {
+			return fmt.Errorf(
+				"task %s declares outputs but runs more than once in plan %s",
+				name,
+				plan.Name,
+			)
+		}
+	}
+
+	for i, node := range nodes {
+		task, ok := c.Tasks[node.StageTask.Name]
+		if !ok {
+			continue
+		}
+
+		for _, input := range task.GetInputs() {
+			producer, _, _ := SplitArtifact(input)
+
+			matches := indices[producer]
+			if len(matches) == 0 || dependsOn(nodes, i, matches[0]) {
+				continue
+			}
+
+			return fmt.Errorf(
+				"task %s consumes artifacts of task %s, which does not run before it in %s/%s",
+				node.StageTask.Name,
+				producer,
+				plan.Name,
+				node.Stage.Name,
+			)
+		}
+	}
+
+	return nil
+}
+
+// dependsOn determines if the node at the given index transitively needs
+// the node at the target index.
+func dependsOn(nodes []*PlanNode, index, target int) bool {
+	visited := map[int]struct{}{}
+	queue := append([]int{}, nodes[index].Needs...)
+
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		if next == target {
+			return true
+		}
+
+		if _, ok := visited[next]; ok {
+			continue
+		}
+
+		visited[next] = struct{}{}
+		queue = append(queue, nodes[next].Needs...)
+	}
+
+	return false
+}
+
+func (c *Config) validateMounts() error {
+	for name, task := range c.Tasks {
+		runTask, ok := task.(*RunTask)
+		if !ok {
+			continue
+		}
+
+		for _, mount := range runTask.Mounts {
+			switch mount.Type {
+			case MountTypeBind, MountTypeVolume:
+				if mount.Source == "" {
+					return fmt.Errorf(
+						"%s mount of %s in task %s has no source",
+						mount.Type,
+						mount.Target,
+						name,
+					)
+				}
+
+			case MountTypeTmpfs:
+				if mount.Source != "" {
+					return fmt.Errorf(
+						"tmpfs mount of %s in task %s has a source",
+						mount.Target,
+						name,
+					)
+				}
+
+			default:
+				return fmt.Errorf(
+					"unknown mount type %s in task %s",
+					mount.Type,
+					name,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateCaches() error {
+	for name := range c.Caches {
+		if !cacheNamePattern.MatchString(name) {
+			return fmt.Errorf(
+				"illegal cache name %s (expected letters, digits, and the characters _.-)",
+				name,
+			)
+		}
+	}
+
+	for name, task := range c.Tasks {
+		runTask, ok := task.(*RunTask)
+		if !ok {
+			continue
+		}
+
+		for _, cache := range runTask.Caches {
+			if _, ok := c.Caches[cache]; !ok {
+				return fmt.Errorf(
+					"unknown cache name %s referenced in task %s",
+					cache,
+					name,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateReadiness() error {
+	for name, task := range c.Tasks {
+		runTask, ok := task.(*RunTask)
+		if !ok {
+			continue
+		}
+
+		if runTask.Readiness != nil && !runTask.Detach {
+			return fmt.Errorf(
+				"readiness probes of task %s require a detached task",
+				name,
+			)
+		}
+	}
+
+	return nil
+}
+
+func validateCondition(condition string, path ...string) error {
+	if condition == "" {
+		return nil
+	}
+
+	if _, err := expression.Parse(condition); err != nil {
+		return fmt.Errorf(
+			"invalid condition in %s: %s",
+			strings.Join(path, "/"),
+			err.Error(),
+		)
+	}
+
+	return nil
+}
+
 func (o *Options) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
 		SSHIdentities       []string `json:"ssh-identities,omitempty"`
 		ForceSequential     bool     `json:"force-sequential,omitempty"`
+		FailFast            bool     `json:"fail-fast,omitempty"`
+		Jobs                int      `json:"jobs,omitempty"`
 		HealthcheckInterval string   `json:"healthcheck-interval,omitempty"`
+		Runtime             string   `json:"runtime,omitempty"`
 	}{
 		SSHIdentities:       o.SSHIdentities,
 		ForceSequential:     o.ForceSequential,
+		FailFast:            o.FailFast,
+		Jobs:                o.Jobs,
 		HealthcheckInterval: durationString(o.HealthcheckInterval),
+		Runtime:             o.Runtime,
 	})
 }
 
@@ -38,12 +38,18 @@ func (s *ConfigSuite) TestMerge(t sweet.T) {
 			"mp1": []string{"p1"},
 			"mp2": []string{"p1", "p2"},
 		},
+		Caches: map[string]*DependencyCache{
+			"c1": &DependencyCache{Name: "c1", Path: "/a"},
+			"c2": &DependencyCache{Name: "c2", Path: "/b"},
+		},
 	}
 
 	child := &Config{
 		Options: &Options{
 			SSHIdentities:       []string{"child-ssh2", "child-ssh3"},
 			ForceSequential:     true,
+			FailFast:            true,
+			Jobs:                4,
 			HealthcheckInterval: time.Second * 10,
 		},
 		Registries:       []Registry{&ServerRegistry{Server: "child.io"}},
@@ -70,11 +76,16 @@ func (s *ConfigSuite) TestMerge(t sweet.T) {
 			"mp2": []string{"p1", "p3"},
 			"mp3": []string{"p2"},
 		},
+		Caches: map[string]*DependencyCache{
+			"c2": &DependencyCache{Name: "c2", Path: "/c", Key: "k"},
+		},
 	}
 
 	Expect(parent.Merge(child)).To(BeNil())
 	Expect(parent.Options.SSHIdentities).To(ConsistOf("child-ssh2", "child-ssh3"))
 	Expect(parent.Options.ForceSequential).To(BeTrue())
+	Expect(parent.Options.FailFast).To(BeTrue())
+	Expect(parent.Options.Jobs).To(Equal(4))
 	Expect(parent.Options.HealthcheckInterval).To(Equal(time.Second * 10))
 	Expect(parent.Registries).To(ConsistOf(
 		&ServerRegistry{Server: "parent.io"},
@@ -102,6 +113,10 @@ func (s *ConfigSuite) TestMerge(t sweet.T) {
 	Expect(parent.Metaplans["mp1"]).To(Equal([]string{"p1"}))
 	Expect(parent.Metaplans["mp2"]).To(Equal([]string{"p1", "p3"}))
 	Expect(parent.Metaplans["mp3"]).To(Equal([]string{"p2"}))
+
+	Expect(parent.Caches).To(HaveLen(2))
+	Expect(parent.Caches["c1"].Path).To(Equal("/a"))
+	Expect(parent.Caches["c2"].Path).To(Equal("/c"))
 }
 
 func (s *ConfigSuite) TestMergeNoOverride(t sweet.T) {
@@ -142,7 +157,9 @@ func (s *ConfigSuite) TestApplyOverride(t sweet.T) {
 		Options: &Options{
 			SSHIdentities:       []string{"override-ssh"},
 			ForceSequential:     true,
+			Jobs:                2,
 			HealthcheckInterval: time.Second * 10,
+			Runtime:             "podman",
 		},
 		Registries:     []Registry{&ECRRegistry{AccountID: "override-ecr"}},
 		Environment:    []string{"X=3", "Z=2"},
@@ -153,7 +170,9 @@ func (s *ConfigSuite) TestApplyOverride(t sweet.T) {
 	config.ApplyOverride(override)
 	Expect(config.Options.SSHIdentities).To(Equal([]string{"override-ssh"}))
 	Expect(config.Options.ForceSequential).To(BeTrue())
+	Expect(config.Options.Jobs).To(Equal(2))
 	Expect(config.Options.HealthcheckInterval).To(Equal(time.Second * 10))
+	Expect(config.Options.Runtime).To(Equal("podman"))
 	Expect(config.Registries).To(Equal([]Registry{
 		&GCRRegistry{KeyFile: "config-gcr"},
 		&ECRRegistry{AccountID: "override-ecr"},
@@ -220,6 +239,82 @@ func (s *ConfigSuite) TestValidateUnknownExtends(t sweet.T) {
 	Expect(config.Validate()).To(MatchError("unknown task name unknown referenced in task foo"))
 }
 
+func (s *ConfigSuite) TestValidateMounts(t sweet.T) {
+	validate := func(mount *Mount) error {
+		config := &Config{
+			Tasks: map[string]Task{
+				"foo": &RunTask{TaskMeta: TaskMeta{Name: "foo"}, Mounts: []*Mount{mount}},
+			},
+		}
+
+		return config.Validate()
+	}
+
+	Expect(validate(&Mount{Type: "bind", Source: "~/.m2", Target: "/root/.m2"})).To(BeNil())
+	Expect(validate(&Mount{Type: "volume", Source: "gradle", Target: "/cache"})).To(BeNil())
+	Expect(validate(&Mount{Type: "tmpfs", Target: "/tmp"})).To(BeNil())
+	Expect(validate(&Mount{Type: "bind", Target: "/root/.m2"})).To(MatchError("bind mount of /root/.m2 in task foo has no source"))
+	Expect(validate(&Mount{Type: "tmpfs", Source: "x", Target: "/tmp"})).To(MatchError("tmpfs mount of /tmp in task foo has a source"))
+	Expect(validate(&Mount{Type: "nfs", Target: "/tmp"})).To(MatchError("unknown mount type nfs in task foo"))
+}
+
+func (s *ConfigSuite) TestValidateCaches(t sweet.T) {
+	validate := func(name string, caches ...string) error {
+		config := &Config{
+			Tasks: map[string]Task{
+				"foo": &RunTask{TaskMeta: TaskMeta{Name: "foo"}, Caches: caches},
+			},
+			Caches: map[string]*DependencyCache{
+				name: &DependencyCache{Name: name, Path: "/go/pkg/mod"},
+			},
+		}
+
+		return config.validateCaches()
+	}
+
+	Expect(validate("gomod")).To(BeNil())
+	Expect(validate("gomod", "gomod")).To(BeNil())
+	Expect(validate("go.mod_1-x", "go.mod_1-x")).To(BeNil())
+	Expect(validate("go/mod")).To(MatchError("illegal cache name go/mod (expected letters, digits, and the characters _.-)"))
+	Expect(validate("gomod", "npm")).To(MatchError("unknown cache name npm referenced in task foo"))
+}
+
+func (s *ConfigSuite) TestValidateMatrixExcludesAll(t sweet.T) {
+	config := &Config{
+		Tasks: map[string]Task{
+			"t1": &RunTask{TaskMeta: TaskMeta{Name: "t1"}},
+		},
+		Plans: map[string]*Plan{
+			"foo": &Plan{Name: "foo", Stages: []*Stage{
+				&Stage{Name: "bar", Tasks: []*StageTask{
+					&StageTask{Name: "t1", Matrix: &Matrix{
+						Variables: map[string][]string{"GO": []string{"1.11"}},
+						Exclude:   []map[string]string{map[string]string{"GO": "1.11"}},
+					}},
+				}},
+			}},
+		},
+	}
+
+	Expect(config.Validate()).To(MatchError("matrix of foo/bar/t1 excludes every combination"))
+}
+
+func (s *ConfigSuite) TestValidateReadinessNotDetached(t sweet.T) {
+	config := &Config{
+		Tasks: map[string]Task{
+			"t1": &RunTask{
+				TaskMeta:  TaskMeta{Name: "t1"},
+				Readiness: &Readiness{TCPPort: 5432},
+			},
+		},
+	}
+
+	Expect(config.Validate()).To(MatchError("readiness probes of task t1 require a detached task"))
+
+	config.Tasks["t1"].(*RunTask).Detach = true
+	Expect(config.Validate()).To(BeNil())
+}
+
 func (s *ConfigSuite) TestValidateUnknownTask(t sweet.T) {
 	config := &Config{
 		Plans: map[string]*Plan{
@@ -242,6 +337,164 @@ func (s *ConfigSuite) TestValidateUnknownTask(t sweet.T) {
 	Expect(config.Validate()).To(MatchError("unknown task name unknown referenced in foo/bar"))
 }
 
+func (s *ConfigSuite) TestValidateCyclicNeeds(t sweet.T) {
+	config := &Config{
+		Tasks: map[string]Task{
+			"t1": &RunTask{TaskMeta: TaskMeta{Name: "t1"}},
+			"t2": &RunTask{TaskMeta: TaskMeta{Name: "t2"}},
+		},
+		Plans: map[string]*Plan{
+			"foo": &Plan{
+				Name: "foo",
+				Stages: []*Stage{
+					&Stage{
+						Name: "bar",
+						Tasks: []*StageTask{
+							&StageTask{Name: "t1", Needs: []string{"t2"}},
+							&StageTask{Name: "t2", Needs: []string{"t1"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	Expect(config.Validate()).To(MatchError("failed to schedule cyclic task dependencies in plan foo (bar/t1 -> bar/t2 -> bar/t1)"))
+}
+
+func (s *ConfigSuite) TestValidateInvalidCondition(t sweet.T) {
+	config := &Config{
+		Tasks: map[string]Task{
+			"t1": &RunTask{TaskMeta: TaskMeta{Name: "t1"}},
+		},
+		Plans: map[string]*Plan{
+			"foo": &Plan{
+				Name: "foo",
+				If:   `BRANCH == "main"`,
+				Stages: []*Stage{
+					&Stage{
+						Name: "bar",
+						Tasks: []*StageTask{
+							&StageTask{Name: "t1", If: `BRANCH = "main"`},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	Expect(config.Validate()).To(MatchError("invalid condition in foo/bar/t1: failed to parse expression `BRANCH = \"main\"`: unexpected character '=' at position 8"))
+}
+
+func (s *ConfigSuite) TestValidateInvalidParameters(t sweet.T) {
+	config := &Config{
+		Plans: map[string]*Plan{
+			"foo": &Plan{
+				Name: "foo",
+				Parameters: []*Parameter{
+					&Parameter{Name: "replicas", Type: "int", Default: "many"},
+				},
+			},
+		},
+	}
+
+	Expect(config.Validate()).To(MatchError("plan foo: invalid default for parameter replicas: many is not an integer"))
+
+	config.Plans["foo"].Parameters = []*Parameter{
+		&Parameter{Name: "target", Type: "enum"},
+	}
+
+	Expect(config.Validate()).To(MatchError("plan foo: enum parameter target declares no values"))
+
+	config.Plans["foo"].Parameters = []*Parameter{
+		&Parameter{Name: "target"},
+		&Parameter{Name: "target"},
+	}
+
+	Expect(config.Validate()).To(MatchError("parameter target is declared twice in plan foo"))
+}
+
+func (s *ConfigSuite) TestValidateInvalidArtifacts(t sweet.T) {
+	config := &Config{
+		Tasks: map[string]Task{
+			"build": &RunTask{TaskMeta: TaskMeta{Name: "build", Outputs: []string{"bin/*"}}},
+			"lint":  &RunTask{TaskMeta: TaskMeta{Name: "lint"}},
+			"test":  &RunTask{TaskMeta: TaskMeta{Name: "test", Inputs: []string{"build:bin/app"}}},
+		},
+	}
+
+	Expect(config.Validate()).To(BeNil())
+
+	config.Tasks["test"].(*RunTask).Inputs = []string{"bin/app"}
+	Expect(config.Validate()).To(MatchError("malformed input bin/app in task test (expected task:path)"))
+
+	config.Tasks["test"].(*RunTask).Inputs = []string{"package:bin/app"}
+	Expect(config.Validate()).To(MatchError("task test consumes artifacts of undeclared task package"))
+
+	config.Tasks["test"].(*RunTask).Inputs = []string{"lint:report.txt"}
+	Expect(config.Validate()).To(MatchError("task test consumes artifacts of task lint, which declares no outputs"))
+}
+
+func (s *ConfigSuite) TestValidateArtifactOrder(t sweet.T) {
+	config := &Config{
+		Tasks: map[string]Task{
+			"build": &RunTask{TaskMeta: TaskMeta{Name: "build", Outputs: []string{"bin/*"}}},
+			"lint":  &RunTask{TaskMeta: TaskMeta{Name: "lint"}},
+			"test":  &RunTask{TaskMeta: TaskMeta{Name: "test", Inputs: []string{"build:bin/app"}}},
+		},
+		Plans: map[string]*Plan{
+			"ci": &Plan{Name: "ci", Stages: []*Stage{
+				&Stage{Name: "build", Tasks: []*StageTask{
+					&StageTask{Name: "build"},
+				}},
+				&Stage{Name: "test", Tasks: []*StageTask{
+					&StageTask{Name: "lint"},
+					&StageTask{Name: "test", Needs: []string{"lint"}},
+				}},
+			}},
+		},
+	}
+
+	Expect(config.Validate()).To(BeNil())
+
+	// Runs in parallel with the producer
+	config.Plans["ci"].Stages[0].Tasks = []*StageTask{
+		&StageTask{Name: "build"},
+		&StageTask{Name: "test"},
+	}
+
+	Expect(config.Validate()).To(MatchError("task test consumes artifacts of task build, which does not run before it in ci/build"))
+
+	config.Plans["ci"].Stages[0].Tasks[1].Needs = []string{"build"}
+	Expect(config.Validate()).To(BeNil())
+}
+
+func (s *ConfigSuite) TestValidateArtifactInstances(t sweet.T) {
+	config := &Config{
+		Tasks: map[string]Task{
+			"build": &RunTask{TaskMeta: TaskMeta{Name: "build", Outputs: []string{"bin/*"}}},
+		},
+		Plans: map[string]*Plan{
+			"ci": &Plan{Name: "ci", Stages: []*Stage{
+				&Stage{Name: "build", Tasks: []*StageTask{
+					&StageTask{Name: "build", Matrix: &Matrix{
+						Variables: map[string][]string{"GOOS": []string{"linux", "darwin"}},
+					}},
+				}},
+			}},
+		},
+	}
+
+	Expect(config.Validate()).To(MatchError("task build declares outputs but is run by a matrix in ci/build"))
+
+	config.Plans["ci"].Stages[0].Tasks = []*StageTask{
+		&StageTask{Name: "build"},
+		&StageTask{Name: "build"},
+	}
+
+	Expect(config.Validate()).To(MatchError("task build declares outputs but runs more than once in plan ci"))
+}
+
 func (s *ConfigSuite) TestValidatePlanDefinedTwice(t sweet.T) {
 	config := &Config{
 		Plans: map[string]*Plan{
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type HostTask struct {
+	TaskMeta
+	Command string `json:"command,omitempty"`
+	Shell   string `json:"shell,omitempty"`
+	Script  string `json:"script,omitempty"`
+	Workdir string `json:"workdir,omitempty"`
+}
+
+const (
+	HostWorkdirProject   = "project"
+	HostWorkdirWorkspace = "workspace"
+)
+
+func (t *HostTask) GetType() string {
+	return "host"
+}
+
+func (t *HostTask) Extend(task Task) error {
+	parent, ok := task.(*HostTask)
+	if !ok {
+		return fmt.Errorf(
+			"task %s extends %s, but they have different types",
+			t.Name,
+			task.GetName(),
+		)
+	}
+
+	t.extendMeta(parent.TaskMeta)
+	t.Command = extendString(t.Command, parent.Command)
+	t.Shell = extendString(t.Shell, parent.Shell)
+	t.Script = extendString(t.Script, parent.Script)
+	t.Workdir = extendString(t.Workdir, parent.Workdir)
+	return nil
+}
+
+func (t *HostTask) MarshalJSON() ([]byte, error) {
+	type Alias HostTask
+
+	return json.Marshal(&struct {
+		*Alias
+		Type          string `json:"type"`
+		RetryDelay    string `json:"retry-delay,omitempty"`
+		RetryMaxDelay string `json:"retry-max-delay,omitempty"`
+		Timeout       string `json:"timeout,omitempty"`
+	}{
+		Alias:         (*Alias)(t),
+		Type:          t.GetType(),
+		RetryDelay:    durationString(t.RetryDelay),
+		RetryMaxDelay: durationString(t.RetryMaxDelay),
+		Timeout:       durationString(t.Timeout),
+	})
+}
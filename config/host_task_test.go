@@ -0,0 +1,41 @@
+package config
+
+import (
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type HostTaskSuite struct{}
+
+func (s *HostTaskSuite) TestExtend(t sweet.T) {
+	parent := &HostTask{
+		TaskMeta: TaskMeta{
+			Name:        "parent",
+			Environment: []string{"parent-env1"},
+		},
+		Command: "git tag v1",
+		Shell:   "/bin/bash",
+		Workdir: "workspace",
+	}
+
+	child := &HostTask{
+		TaskMeta: TaskMeta{
+			Name:        "child",
+			Extends:     "parent",
+			Environment: []string{"child-env1"},
+		},
+		Command: "git tag v2",
+	}
+
+	Expect(child.Extend(parent)).To(BeNil())
+	Expect(child.Environment).To(Equal([]string{"parent-env1", "child-env1"}))
+	Expect(child.Command).To(Equal("git tag v2"))
+	Expect(child.Shell).To(Equal("/bin/bash"))
+	Expect(child.Workdir).To(Equal("workspace"))
+}
+
+func (s *HostTaskSuite) TestExtendWrongType(t sweet.T) {
+	parent := &RunTask{TaskMeta: TaskMeta{Name: "parent"}}
+	child := &HostTask{TaskMeta: TaskMeta{Name: "child", Extends: "parent"}}
+	Expect(child.Extend(parent)).NotTo(BeNil())
+}
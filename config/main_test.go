@@ -16,6 +16,9 @@ func TestMain(m *testing.M) {
 
 		s.AddSuite(&BuildTaskSuite{})
 		s.AddSuite(&ConfigSuite{})
+		s.AddSuite(&HostTaskSuite{})
+		s.AddSuite(&MatrixSuite{})
+		s.AddSuite(&ParameterSuite{})
 		s.AddSuite(&PlanSuite{})
 		s.AddSuite(&PlanTaskSuite{})
 		s.AddSuite(&PushTaskSuite{})
@@ -23,6 +26,7 @@ func TestMain(m *testing.M) {
 		s.AddSuite(&ResolverSuite{})
 		s.AddSuite(&RunTaskSuite{})
 		s.AddSuite(&StageSuite{})
+		s.AddSuite(&TaskSuite{})
 		s.AddSuite(&UtilSuite{})
 	})
 }
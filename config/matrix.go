@@ -0,0 +1,123 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type (
+	Matrix struct {
+		Variables map[string][]string
+		Exclude   []map[string]string
+	}
+
+	// MatrixInstance maps each variable of a matrix to a single value.
+	MatrixInstance map[string]string
+)
+
+// Instances returns one instance for each combination of variable values
+// which is not excluded. Instances are ordered by the variable names, then
+// by the order in which each value was declared.
+func (m *Matrix) Instances() []MatrixInstance {
+	instances := []MatrixInstance{MatrixInstance{}}
+	for _, name := range m.names() {
+		expanded := []MatrixInstance{}
+		for _, instance := range instances {
+			for _, value := range m.Variables[name] {
+				expanded = append(expanded, instance.with(name, value))
+			}
+		}
+
+		instances = expanded
+	}
+
+	included := []MatrixInstance{}
+	for _, instance := range instances {
+		if !m.excludes(instance) {
+			included = append(included, instance)
+		}
+	}
+
+	return included
+}
+
+func (m *Matrix) names() []string {
+	names := []string{}
+	for name := range m.Variables {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func (m *Matrix) excludes(instance MatrixInstance) bool {
+	for _, exclude := range m.Exclude {
+		if instance.matches(exclude) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Label returns the variable values of the instance in the form
+// `name=value,name=value`, ordered by name.
+func (i MatrixInstance) Label() string {
+	return strings.Join(i.Environment(), ",")
+}
+
+// Environment returns the variable values of the instance as environment
+// lines, ordered by name.
+func (i MatrixInstance) Environment() []string {
+	lines := []string{}
+	for _, name := range i.names() {
+		lines = append(lines, fmt.Sprintf("%s=%s", name, i[name]))
+	}
+
+	return lines
+}
+
+func (i MatrixInstance) names() []string {
+	names := []string{}
+	for name := range i {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func (i MatrixInstance) with(name, value string) MatrixInstance {
+	instance := MatrixInstance{name: value}
+	for k, v := range i {
+		instance[k] = v
+	}
+
+	return instance
+}
+
+func (i MatrixInstance) matches(values map[string]string) bool {
+	for name, value := range values {
+		if i[name] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *Matrix) MarshalJSON() ([]byte, error) {
+	fields := map[string]interface{}{}
+	for name, values := range m.Variables {
+		fields[name] = values
+	}
+
+	if len(m.Exclude) > 0 {
+		fields["exclude"] = m.Exclude
+	}
+
+	return json.Marshal(fields)
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type MatrixSuite struct{}
+
+func (s *MatrixSuite) TestInstances(t sweet.T) {
+	matrix := &Matrix{
+		Variables: map[string][]string{
+			"go": []string{"1.21", "1.20"},
+			"db": []string{"pg13", "pg14"},
+		},
+		Exclude: []map[string]string{
+			map[string]string{"go": "1.20", "db": "pg14"},
+		},
+	}
+
+	Expect(matrix.Instances()).To(Equal([]MatrixInstance{
+		MatrixInstance{"db": "pg13", "go": "1.21"},
+		MatrixInstance{"db": "pg13", "go": "1.20"},
+		MatrixInstance{"db": "pg14", "go": "1.21"},
+	}))
+}
+
+func (s *MatrixSuite) TestInstancesPartialExclude(t sweet.T) {
+	matrix := &Matrix{
+		Variables: map[string][]string{
+			"go": []string{"1.20", "1.21"},
+			"db": []string{"pg13", "pg14"},
+		},
+		Exclude: []map[string]string{
+			map[string]string{"go": "1.20"},
+		},
+	}
+
+	Expect(matrix.Instances()).To(Equal([]MatrixInstance{
+		MatrixInstance{"db": "pg13", "go": "1.21"},
+		MatrixInstance{"db": "pg14", "go": "1.21"},
+	}))
+}
+
+func (s *MatrixSuite) TestLabel(t sweet.T) {
+	instance := MatrixInstance{"go": "1.21", "db": "pg14"}
+	Expect(instance.Label()).To(Equal("db=pg14,go=1.21"))
+	Expect(instance.Environment()).To(Equal([]string{"db=pg14", "go=1.21"}))
+	Expect(MatrixInstance{}.Label()).To(Equal(""))
+}
+
+func (s *MatrixSuite) TestStageTaskInstances(t sweet.T) {
+	Expect((&StageTask{Name: "t"}).Instances()).To(Equal([]MatrixInstance{
+		MatrixInstance{},
+	}))
+}
+
+func (s *MatrixSuite) TestMarshalJSON(t sweet.T) {
+	matrix := &Matrix{
+		Variables: map[string][]string{"go": []string{"1.20"}},
+		Exclude:   []map[string]string{map[string]string{"go": "1.20"}},
+	}
+
+	data, err := json.Marshal(matrix)
+	Expect(err).To(BeNil())
+	Expect(data).To(MatchJSON(`{"go": ["1.20"], "exclude": [{"go": "1.20"}]}`))
+}
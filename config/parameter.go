@@ -0,0 +1,216 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Parameter struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type,omitempty"`
+	Values      []string `json:"values,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+}
+
+const (
+	ParameterTypeString = "string"
+	ParameterTypeInt    = "int"
+	ParameterTypeBool   = "bool"
+	ParameterTypeEnum   = "enum"
+)
+
+// Normalize validates the given value against the type of the parameter
+// and returns its canonical form.
+func (p *Parameter) Normalize(value string) (string, error) {
+	switch p.Type {
+	case "", ParameterTypeString:
+		return value, nil
+
+	case ParameterTypeInt:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("%s is not an integer", value)
+		}
+
+		return strconv.Itoa(i), nil
+
+	case ParameterTypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s is not a boolean", value)
+		}
+
+		return strconv.FormatBool(b), nil
+
+	case ParameterTypeEnum:
+		for _, allowed := range p.Values {
+			if value == allowed {
+				return value, nil
+			}
+		}
+
+		return "", fmt.Errorf(
+			"%s is not one of %s",
+			value,
+			strings.Join(p.Values, ", "),
+		)
+	}
+
+	return "", fmt.Errorf("unknown parameter type %s", p.Type)
+}
+
+// Resolve returns the value of the parameter given the arguments supplied
+// for the run, falling back to the default value of the parameter. The
+// second return value is false if the parameter has no value.
+func (p *Parameter) Resolve(arguments map[string]string) (string, bool, error) {
+	value, ok := arguments[p.Name]
+	if !ok {
+		if p.Required {
+			return "", false, fmt.Errorf("missing required parameter %s", p.Name)
+		}
+
+		if p.Default == "" {
+			return "", false, nil
+		}
+
+		value = p.Default
+	}
+
+	normalized, err := p.Normalize(value)
+	if err != nil {
+		return "", false, fmt.Errorf(
+			"invalid value for parameter %s: %s",
+			p.Name,
+			err.Error(),
+		)
+	}
+
+	return normalized, true, nil
+}
+
+func (p *Parameter) validate() error {
+	switch p.Type {
+	case "", ParameterTypeString, ParameterTypeInt, ParameterTypeBool, ParameterTypeEnum:
+	default:
+		return fmt.Errorf("unknown type %s for parameter %s", p.Type, p.Name)
+	}
+
+	if p.Type == ParameterTypeEnum && len(p.Values) == 0 {
+		return fmt.Errorf("enum parameter %s declares no values", p.Name)
+	}
+
+	if p.Type != ParameterTypeEnum && len(p.Values) > 0 {
+		return fmt.Errorf("parameter %s declares values but is not an enum", p.Name)
+	}
+
+	if p.Default == "" {
+		return nil
+	}
+
+	if _, err := p.Normalize(p.Default); err != nil {
+		return fmt.Errorf(
+			"invalid default for parameter %s: %s",
+			p.Name,
+			err.Error(),
+		)
+	}
+
+	return nil
+}
+
+// ApplyParameters resolves the parameters declared by the given plans and
+// the plans that they invoke. The resolved values are added to the
+// environment of the plan that declares them. It is an error to supply an
+// argument that is not declared by any of these plans.
+func (c *Config) ApplyParameters(names []string, arguments map[string]string) error {
+	var (
+		plans    = c.reachablePlans(names)
+		declared = map[string]struct{}{}
+	)
+
+	for _, name := range plans {
+		plan := c.Plans[name]
+
+		for _, parameter := range plan.Parameters {
+			declared[parameter.Name] = struct{}{}
+
+			value, ok, err := parameter.Resolve(arguments)
+			if err != nil {
+				return fmt.Errorf("plan %s: %s", plan.Name, err.Error())
+			}
+
+			if ok {
+				plan.Environment = append(plan.Environment, fmt.Sprintf("%s=%s", parameter.Name, value))
+			}
+		}
+	}
+
+	unknown := []string{}
+	for name := range arguments {
+		if _, ok := declared[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+
+		return fmt.Errorf(
+			"unknown parameter %s",
+			strings.Join(unknown, ", "),
+		)
+	}
+
+	return nil
+}
+
+// reachablePlans returns the names of the plans that may run as part of
+// the given plans or metaplans, including plans invoked by plan tasks.
+func (c *Config) reachablePlans(names []string) []string {
+	var (
+		visited = map[string]struct{}{}
+		plans   = []string{}
+		visit   func(name string)
+	)
+
+	visit = func(name string) {
+		if _, ok := visited[name]; ok {
+			return
+		}
+
+		visited[name] = struct{}{}
+
+		if metaplan, ok := c.Metaplans[name]; ok {
+			for _, plan := range metaplan {
+				visit(plan)
+			}
+
+			return
+		}
+
+		plan, ok := c.Plans[name]
+		if !ok {
+			return
+		}
+
+		plans = append(plans, name)
+
+		for _, stage := range plan.Stages {
+			for _, stageTask := range stage.Tasks {
+				if task, ok := c.Tasks[stageTask.Name].(*PlanTask); ok {
+					visit(task.Name)
+				}
+			}
+		}
+	}
+
+	for _, name := range names {
+		visit(name)
+	}
+
+	return plans
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type ParameterSuite struct{}
+
+func (s *ParameterSuite) TestNormalize(t sweet.T) {
+	value, err := (&Parameter{Name: "p"}).Normalize("foo")
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal("foo"))
+
+	value, err = (&Parameter{Name: "p", Type: "int"}).Normalize("0012")
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal("12"))
+
+	value, err = (&Parameter{Name: "p", Type: "bool"}).Normalize("1")
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal("true"))
+
+	value, err = (&Parameter{Name: "p", Type: "enum", Values: []string{"a", "b"}}).Normalize("b")
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal("b"))
+}
+
+func (s *ParameterSuite) TestNormalizeInvalid(t sweet.T) {
+	_, err := (&Parameter{Name: "p", Type: "int"}).Normalize("foo")
+	Expect(err).To(MatchError("foo is not an integer"))
+
+	_, err = (&Parameter{Name: "p", Type: "bool"}).Normalize("foo")
+	Expect(err).To(MatchError("foo is not a boolean"))
+
+	_, err = (&Parameter{Name: "p", Type: "enum", Values: []string{"a", "b"}}).Normalize("c")
+	Expect(err).To(MatchError("c is not one of a, b"))
+}
+
+func (s *ParameterSuite) TestResolve(t sweet.T) {
+	parameter := &Parameter{Name: "p", Type: "int", Default: "3"}
+
+	value, ok, err := parameter.Resolve(map[string]string{"p": "5"})
+	Expect(err).To(BeNil())
+	Expect(ok).To(BeTrue())
+	Expect(value).To(Equal("5"))
+
+	value, ok, err = parameter.Resolve(map[string]string{})
+	Expect(err).To(BeNil())
+	Expect(ok).To(BeTrue())
+	Expect(value).To(Equal("3"))
+
+	_, ok, err = (&Parameter{Name: "p"}).Resolve(map[string]string{})
+	Expect(err).To(BeNil())
+	Expect(ok).To(BeFalse())
+}
+
+func (s *ParameterSuite) TestResolveRequired(t sweet.T) {
+	_, _, err := (&Parameter{Name: "p", Required: true}).Resolve(map[string]string{})
+	Expect(err).To(MatchError("missing required parameter p"))
+}
+
+func (s *ParameterSuite) TestApplyParameters(t sweet.T) {
+	config := &Config{
+		Tasks: map[string]Task{
+			"t": &PlanTask{TaskMeta: TaskMeta{Name: "t"}, Name: "p2"},
+		},
+		Plans: map[string]*Plan{
+			"p1": &Plan{
+				Name:        "p1",
+				Stages:      []*Stage{&Stage{Name: "s", Tasks: []*StageTask{&StageTask{Name: "t"}}}},
+				Environment: []string{"X=1"},
+				Parameters: []*Parameter{
+					&Parameter{Name: "target", Type: "enum", Values: []string{"staging", "production"}, Required: true},
+				},
+			},
+			"p2": &Plan{
+				Name: "p2",
+				Parameters: []*Parameter{
+					&Parameter{Name: "replicas", Type: "int", Default: "2"},
+					&Parameter{Name: "verbose", Type: "bool"},
+				},
+			},
+			"p3": &Plan{
+				Name: "p3",
+				Parameters: []*Parameter{
+					&Parameter{Name: "other", Required: true},
+				},
+			},
+		},
+		Metaplans: map[string][]string{
+			"m": []string{"p1"},
+		},
+	}
+
+	Expect(config.ApplyParameters([]string{"m"}, map[string]string{"target": "staging"})).To(BeNil())
+	Expect(config.Plans["p1"].Environment).To(Equal([]string{"X=1", "target=staging"}))
+	Expect(config.Plans["p2"].Environment).To(Equal([]string{"replicas=2"}))
+	Expect(config.Plans["p3"].Environment).To(BeEmpty())
+}
+
+func (s *ParameterSuite) TestApplyParametersInvalid(t sweet.T) {
+	config := &Config{
+		Plans: map[string]*Plan{
+			"p": &Plan{
+				Name: "p",
+				Parameters: []*Parameter{
+					&Parameter{Name: "target", Type: "enum", Values: []string{"staging", "production"}},
+				},
+			},
+		},
+	}
+
+	Expect(config.ApplyParameters([]string{"p"}, map[string]string{"target": "dev"})).To(MatchError(
+		"plan p: invalid value for parameter target: dev is not one of staging, production",
+	))
+}
+
+func (s *ParameterSuite) TestApplyParametersUnknown(t sweet.T) {
+	config := &Config{
+		Plans: map[string]*Plan{
+			"p": &Plan{Name: "p"},
+		},
+	}
+
+	Expect(config.ApplyParameters([]string{"p"}, map[string]string{"b": "1", "a": "2"})).To(MatchError(
+		"unknown parameter a, b",
+	))
+}
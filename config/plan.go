@@ -1,14 +1,38 @@
 package config
 
-import "fmt"
-
-type Plan struct {
-	Name        string   `json:"-"`
-	Disabled    string   `json:"disabled,omitempty"`
-	Extends     string   `json:"extends,omitempty"`
-	Stages      []*Stage `json:"stages,omitempty"`
-	Environment []string `json:"environment,omitempty"`
-}
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stevenle/topsort"
+)
+
+type (
+	// Note: Plan must serialize itself manually due to the time.Duration field.
+
+	Plan struct {
+		Name        string        `json:"-"`
+		Disabled    string        `json:"disabled,omitempty"`
+		If          string        `json:"if,omitempty"`
+		Extends     string        `json:"extends,omitempty"`
+		Timeout     time.Duration `json:"-"`
+		Stages      []*Stage      `json:"stages,omitempty"`
+		Environment []string      `json:"environment,omitempty"`
+		Parameters  []*Parameter  `json:"parameters,omitempty"`
+	}
+
+	// PlanNode is a single stage task of a plan scheduled as a graph. The
+	// index is the position of the stage task within its stage, and needs
+	// holds the positions of the nodes that must complete before this one.
+	PlanNode struct {
+		Stage     *Stage
+		StageTask *StageTask
+		Index     int
+		Needs     []int
+	}
+)
 
 func (p *Plan) Clone() *Plan {
 	stages := []*Stage{}
@@ -21,12 +45,20 @@ func (p *Plan) Clone() *Plan {
 		environment = append(environment, value)
 	}
 
+	parameters := []*Parameter{}
+	for _, parameter := range p.Parameters {
+		parameters = append(parameters, parameter)
+	}
+
 	return &Plan{
 		Name:        p.Name,
 		Disabled:    p.Disabled,
+		If:          p.If,
 		Extends:     p.Extends,
+		Timeout:     p.Timeout,
 		Stages:      stages,
 		Environment: environment,
+		Parameters:  parameters,
 	}
 }
 
@@ -38,10 +70,30 @@ func (p *Plan) Merge(child *Plan) error {
 	}
 
 	p.Disabled = extendString(child.Disabled, p.Disabled)
+	p.If = extendString(child.If, p.If)
+	p.Timeout = extendDuration(child.Timeout, p.Timeout)
 	p.Environment = append(p.Environment, child.Environment...)
+
+	for _, parameter := range child.Parameters {
+		p.AddParameter(parameter)
+	}
+
 	return nil
 }
 
+// AddParameter declares a parameter of the plan. A parameter with the same
+// name as one declared in the parent config replaces it.
+func (p *Plan) AddParameter(parameter *Parameter) {
+	for i, existing := range p.Parameters {
+		if existing.Name == parameter.Name {
+			p.Parameters[i] = parameter
+			return
+		}
+	}
+
+	p.Parameters = append(p.Parameters, parameter)
+}
+
 func (p *Plan) AddStage(stage *Stage) error {
 	if stage.BeforeStage != "" && stage.AfterStage != "" {
 		return fmt.Errorf(
@@ -110,3 +162,173 @@ func (p *Plan) InsertStage(stage *Stage, index int) {
 	copy(p.Stages[index+1:], p.Stages[index:])
 	p.Stages[index] = stage
 }
+
+// HasNeeds determines if any stage task of the plan declares an explicit
+// dependency, in which case the plan is scheduled as a graph rather than
+// stage by stage.
+func (p *Plan) HasNeeds() bool {
+	for _, stage := range p.Stages {
+		for _, stageTask := range stage.Tasks {
+			if len(stageTask.Needs) > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Graph returns the stage tasks of the plan along with their dependencies.
+// A stage task that declares needs depends only on the named tasks. Any
+// other stage task depends on every task of the stages preceding it.
+func (p *Plan) Graph() ([]*PlanNode, error) {
+	var (
+		nodes   = []*PlanNode{}
+		indices = map[string][]int{}
+		stages  = [][]int{}
+	)
+
+	for _, stage := range p.Stages {
+		stageIndices := []int{}
+		for i, stageTask := range stage.Tasks {
+			indices[stageTask.Name] = append(indices[stageTask.Name], len(nodes))
+			stageIndices = append(stageIndices, len(nodes))
+
+			nodes = append(nodes, &PlanNode{
+				Stage:     stage,
+				StageTask: stageTask,
+				Index:     i,
+			})
+		}
+
+		stages = append(stages, stageIndices)
+	}
+
+	previous := []int{}
+	for s, stage := range p.Stages {
+		for _, index := range stages[s] {
+			node := nodes[index]
+
+			if len(node.StageTask.Needs) == 0 {
+				node.Needs = append([]int{}, previous...)
+				continue
+			}
+
+			for _, name := range node.StageTask.Needs {
+				matches := indices[name]
+
+				if len(matches) == 0 {
+					return nil, fmt.Errorf(
+						"unknown task name %s needed by %s/%s/%s",
+						name,
+						p.Name,
+						stage.Name,
+						node.StageTask.Name,
+					)
+				}
+
+				if len(matches) > 1 {
+					return nil, fmt.Errorf(
+						"ambiguous task name %s needed by %s/%s/%s",
+						name,
+						p.Name,
+						stage.Name,
+						node.StageTask.Name,
+					)
+				}
+
+				node.Needs = append(node.Needs, matches[0])
+			}
+		}
+
+		previous = append(previous, stages[s]...)
+	}
+
+	if err := checkPlanGraph(p, nodes, stages); err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+func (p *Plan) MarshalJSON() ([]byte, error) {
+	type Alias Plan
+
+	return json.Marshal(&struct {
+		*Alias
+		Timeout string `json:"timeout,omitempty"`
+	}{
+		Alias:   (*Alias)(p),
+		Timeout: durationString(p.Timeout),
+	})
+}
+
+//
+// Helpers
+
+// checkPlanGraph returns an error if the dependencies of the plan nodes
+// form a cycle. Implicit dependencies on preceding stages are expressed
+// through one barrier node per stage rather than an edge to every task of
+// every preceding stage, as the sort visits each path of the graph.
+func checkPlanGraph(p *Plan, nodes []*PlanNode, stages [][]int) error {
+	var (
+		graph  = topsort.NewGraph()
+		labels = map[string]string{}
+	)
+
+	for index, node := range nodes {
+		name := fmt.Sprintf("%d", index)
+		labels[name] = fmt.Sprintf("%s/%s", node.Stage.Name, node.StageTask.Name)
+		graph.AddNode(name)
+	}
+
+	for s := range stages {
+		graph.AddNode(stageBarrierName(s))
+	}
+
+	for s, indices := range stages {
+		implied := false
+		for _, index := range indices {
+			graph.AddEdge(stageBarrierName(s), fmt.Sprintf("%d", index))
+
+			if len(nodes[index].StageTask.Needs) > 0 {
+				for _, need := range nodes[index].Needs {
+					graph.AddEdge(fmt.Sprintf("%d", index), fmt.Sprintf("%d", need))
+				}
+			} else if s > 0 {
+				graph.AddEdge(fmt.Sprintf("%d", index), stageBarrierName(s-1))
+				implied = true
+			}
+		}
+
+		// The barrier of the preceding stage is already reachable
+		// through any task of this stage that depends on it
+		if s > 0 && !implied {
+			graph.AddEdge(stageBarrierName(s), stageBarrierName(s-1))
+		}
+	}
+
+	for index := range nodes {
+		if _, err := graph.TopSort(fmt.Sprintf("%d", index)); err != nil {
+			// Error messages starts with "Cycle error: "
+			names := []string{}
+			for _, name := range strings.Split(err.Error()[13:], " -> ") {
+				if label, ok := labels[name]; ok {
+					names = append(names, label)
+				}
+			}
+
+			return fmt.Errorf(
+				"failed to schedule cyclic task dependencies in plan %s (%s)",
+				p.Name,
+				strings.Join(names, " -> "),
+			)
+		}
+	}
+
+	return nil
+}
+
+func stageBarrierName(index int) string {
+	return fmt.Sprintf("stage-%d", index)
+}
@@ -34,9 +34,15 @@ func (t *PlanTask) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(&struct {
 		*Alias
-		Type string `json:"type"`
+		Type          string `json:"type"`
+		RetryDelay    string `json:"retry-delay,omitempty"`
+		RetryMaxDelay string `json:"retry-max-delay,omitempty"`
+		Timeout       string `json:"timeout,omitempty"`
 	}{
-		Alias: (*Alias)(t),
-		Type:  t.GetType(),
+		Alias:         (*Alias)(t),
+		Type:          t.GetType(),
+		RetryDelay:    durationString(t.RetryDelay),
+		RetryMaxDelay: durationString(t.RetryMaxDelay),
+		Timeout:       durationString(t.Timeout),
 	})
 }
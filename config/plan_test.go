@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/aphistic/sweet"
 	. "github.com/onsi/gomega"
 )
@@ -10,6 +13,7 @@ type PlanSuite struct{}
 func (s *PlanSuite) TestMerge(t sweet.T) {
 	parent := &Plan{
 		Disabled: "${PARENT_DISABLED}",
+		Timeout:  time.Hour,
 		Stages: []*Stage{
 			&Stage{Name: "a"},
 			&Stage{Name: "b"},
@@ -19,10 +23,15 @@ func (s *PlanSuite) TestMerge(t sweet.T) {
 			"X=1",
 			"Y=2",
 		},
+		Parameters: []*Parameter{
+			&Parameter{Name: "p1", Default: "a"},
+			&Parameter{Name: "p2"},
+		},
 	}
 
 	child := &Plan{
 		Disabled: "${CHILD_DISABLED}",
+		Timeout:  time.Minute,
 		Stages: []*Stage{
 			&Stage{Name: "a"},
 			&Stage{Name: "d", BeforeStage: "a"},
@@ -32,10 +41,15 @@ func (s *PlanSuite) TestMerge(t sweet.T) {
 			"X=4",
 			"Z=3",
 		},
+		Parameters: []*Parameter{
+			&Parameter{Name: "p1", Default: "b"},
+			&Parameter{Name: "p3"},
+		},
 	}
 
 	Expect(parent.Merge(child)).To(BeNil())
 	Expect(parent.Disabled).To(Equal("${CHILD_DISABLED}"))
+	Expect(parent.Timeout).To(Equal(time.Minute))
 	Expect(parent.Stages).To(HaveLen(5))
 	Expect(parent.Stages[0].Name).To(Equal("d"))
 	Expect(parent.Stages[1].Name).To(Equal("a"))
@@ -43,6 +57,11 @@ func (s *PlanSuite) TestMerge(t sweet.T) {
 	Expect(parent.Stages[3].Name).To(Equal("c"))
 	Expect(parent.Stages[4].Name).To(Equal("e"))
 	Expect(parent.Environment).To(Equal([]string{"X=1", "Y=2", "X=4", "Z=3"}))
+	Expect(parent.Parameters).To(Equal([]*Parameter{
+		&Parameter{Name: "p1", Default: "b"},
+		&Parameter{Name: "p2"},
+		&Parameter{Name: "p3"},
+	}))
 }
 
 func (s *PlanSuite) TestAddStageOverwrite(t sweet.T) {
@@ -184,3 +203,121 @@ func (s *PlanSuite) TestInsertStage(t sweet.T) {
 	Expect(plan.Stages[3].Name).To(Equal("a"))
 	Expect(plan.Stages[4].Name).To(Equal("e"))
 }
+
+func (s *PlanSuite) TestGraph(t sweet.T) {
+	plan := &Plan{
+		Stages: []*Stage{
+			&Stage{Name: "a", Tasks: []*StageTask{
+				&StageTask{Name: "lint"},
+				&StageTask{Name: "build"},
+			}},
+			&Stage{Name: "b", Tasks: []*StageTask{
+				&StageTask{Name: "test", Needs: []string{"lint"}},
+				&StageTask{Name: "package", Needs: []string{"build"}},
+			}},
+			&Stage{Name: "c", Tasks: []*StageTask{
+				&StageTask{Name: "notify"},
+			}},
+		},
+	}
+
+	Expect(plan.HasNeeds()).To(BeTrue())
+
+	nodes, err := plan.Graph()
+	Expect(err).To(BeNil())
+	Expect(nodes).To(HaveLen(5))
+	Expect(nodes[0].Needs).To(BeEmpty())
+	Expect(nodes[1].Needs).To(BeEmpty())
+	Expect(nodes[2].Needs).To(Equal([]int{0}))
+	Expect(nodes[3].Needs).To(Equal([]int{1}))
+	Expect(nodes[4].Needs).To(Equal([]int{0, 1, 2, 3}))
+	Expect(nodes[3].Stage.Name).To(Equal("b"))
+	Expect(nodes[3].Index).To(Equal(1))
+}
+
+func (s *PlanSuite) TestGraphUnknownNeed(t sweet.T) {
+	plan := &Plan{
+		Name: "p",
+		Stages: []*Stage{
+			&Stage{Name: "a", Tasks: []*StageTask{
+				&StageTask{Name: "test", Needs: []string{"lint"}},
+			}},
+		},
+	}
+
+	_, err := plan.Graph()
+	Expect(err).To(MatchError("unknown task name lint needed by p/a/test"))
+}
+
+func (s *PlanSuite) TestGraphAmbiguousNeed(t sweet.T) {
+	plan := &Plan{
+		Name: "p",
+		Stages: []*Stage{
+			&Stage{Name: "a", Tasks: []*StageTask{
+				&StageTask{Name: "lint"},
+				&StageTask{Name: "lint"},
+			}},
+			&Stage{Name: "b", Tasks: []*StageTask{
+				&StageTask{Name: "test", Needs: []string{"lint"}},
+			}},
+		},
+	}
+
+	_, err := plan.Graph()
+	Expect(err).To(MatchError("ambiguous task name lint needed by p/b/test"))
+}
+
+func (s *PlanSuite) TestGraphCycle(t sweet.T) {
+	plan := &Plan{
+		Name: "p",
+		Stages: []*Stage{
+			&Stage{Name: "a", Tasks: []*StageTask{
+				&StageTask{Name: "lint", Needs: []string{"test"}},
+			}},
+			&Stage{Name: "b", Tasks: []*StageTask{
+				&StageTask{Name: "test"},
+			}},
+		},
+	}
+
+	_, err := plan.Graph()
+	Expect(err).To(MatchError("failed to schedule cyclic task dependencies in plan p (a/lint -> b/test -> a/lint)"))
+}
+
+func (s *PlanSuite) TestGraphCycleThroughEarlierStage(t sweet.T) {
+	plan := &Plan{
+		Name: "p",
+		Stages: []*Stage{
+			&Stage{Name: "a", Tasks: []*StageTask{
+				&StageTask{Name: "lint", Needs: []string{"package"}},
+			}},
+			&Stage{Name: "b", Tasks: []*StageTask{
+				&StageTask{Name: "test", Needs: []string{"lint"}},
+				&StageTask{Name: "build", Needs: []string{"lint"}},
+			}},
+			&Stage{Name: "c", Tasks: []*StageTask{
+				&StageTask{Name: "package"},
+			}},
+		},
+	}
+
+	_, err := plan.Graph()
+	Expect(err).To(MatchError(MatchRegexp(`^failed to schedule cyclic task dependencies in plan p \(a/lint -> c/package( -> b/(test|build))? -> a/lint\)$`)))
+}
+
+func (s *PlanSuite) TestGraphManyStages(t sweet.T) {
+	plan := &Plan{Name: "p"}
+	for i := 0; i < 40; i++ {
+		plan.Stages = append(plan.Stages, &Stage{
+			Name: fmt.Sprintf("s%d", i),
+			Tasks: []*StageTask{
+				&StageTask{Name: fmt.Sprintf("t%d", i)},
+			},
+		})
+	}
+
+	nodes, err := plan.Graph()
+	Expect(err).To(BeNil())
+	Expect(nodes).To(HaveLen(40))
+	Expect(nodes[39].Needs).To(HaveLen(39))
+}
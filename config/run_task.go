@@ -19,7 +19,36 @@ type (
 		Hostname               string       `json:"hostname,omitempty"`
 		Detach                 bool         `json:"detach,omitempty"`
 		Healthcheck            *Healthcheck `json:"healthcheck,omitempty"`
+		Readiness              *Readiness   `json:"readiness,omitempty"`
 		ExportEnvironmentFiles []string     `json:"export-environment-files,omitempty"`
+		Cache                  *Cache       `json:"cache,omitempty"`
+		CPUShares              string       `json:"cpu-shares,omitempty"`
+		Memory                 string       `json:"memory,omitempty"`
+		ShmSize                string       `json:"shm-size,omitempty"`
+		Ulimits                []string     `json:"ulimits,omitempty"`
+		Privileged             bool         `json:"privileged,omitempty"`
+		CapAdd                 []string     `json:"cap-add,omitempty"`
+		Tmpfs                  []string     `json:"tmpfs,omitempty"`
+		Devices                []string     `json:"devices,omitempty"`
+		Platform               string       `json:"platform,omitempty"`
+		Mounts                 []*Mount     `json:"mounts,omitempty"`
+		Ports                  []string     `json:"ports,omitempty"`
+		Caches                 []string     `json:"caches,omitempty"`
+	}
+
+	// Mount is an additional mount of a container. The source of a bind
+	// mount is a path on the host, and the source of a volume mount is the
+	// name of a named volume. A tmpfs mount has no source.
+	Mount struct {
+		Type     string `json:"type,omitempty"`
+		Source   string `json:"source,omitempty"`
+		Target   string `json:"target,omitempty"`
+		ReadOnly bool   `json:"read-only,omitempty"`
+	}
+
+	Cache struct {
+		Inputs  []string `json:"inputs,omitempty"`
+		Outputs []string `json:"outputs,omitempty"`
 	}
 
 	// Note: Healthcheck must serialize itself manually due to the time.Duration fields.
@@ -30,9 +59,32 @@ type (
 		Retries     int
 		StartPeriod time.Duration
 		Timeout     time.Duration
+		MaxWait     time.Duration
+	}
+
+	// Note: Readiness must serialize itself manually due to the time.Duration fields.
+
+	Readiness struct {
+		TCPPort    int
+		HTTP       *HTTPProbe
+		LogPattern string
+		Timeout    time.Duration
+		Interval   time.Duration
+	}
+
+	HTTPProbe struct {
+		Port   int    `json:"port,omitempty"`
+		Path   string `json:"path,omitempty"`
+		Status int    `json:"status,omitempty"`
 	}
 )
 
+const (
+	MountTypeBind   = "bind"
+	MountTypeVolume = "volume"
+	MountTypeTmpfs  = "tmpfs"
+)
+
 func (t *RunTask) GetType() string {
 	return "run"
 }
@@ -58,7 +110,21 @@ func (t *RunTask) Extend(task Task) error {
 	t.Hostname = extendString(t.Hostname, parent.Hostname)
 	t.Detach = extendBool(t.Detach, parent.Detach)
 	t.Healthcheck.Extend(parent.Healthcheck)
+	t.Readiness = t.Readiness.Extend(parent.Readiness)
 	t.ExportEnvironmentFiles = append(parent.ExportEnvironmentFiles, t.ExportEnvironmentFiles...)
+	t.Cache = t.Cache.Extend(parent.Cache)
+	t.CPUShares = extendString(t.CPUShares, parent.CPUShares)
+	t.Memory = extendString(t.Memory, parent.Memory)
+	t.ShmSize = extendString(t.ShmSize, parent.ShmSize)
+	t.Ulimits = append(parent.Ulimits, t.Ulimits...)
+	t.Privileged = extendBool(t.Privileged, parent.Privileged)
+	t.CapAdd = append(parent.CapAdd, t.CapAdd...)
+	t.Tmpfs = append(parent.Tmpfs, t.Tmpfs...)
+	t.Devices = append(parent.Devices, t.Devices...)
+	t.Platform = extendString(t.Platform, parent.Platform)
+	t.Mounts = append(parent.Mounts, t.Mounts...)
+	t.Ports = append(parent.Ports, t.Ports...)
+	t.Caches = append(parent.Caches, t.Caches...)
 	return nil
 }
 
@@ -68,18 +134,78 @@ func (h *Healthcheck) Extend(parent *Healthcheck) error {
 	h.Retries = extendInt(h.Retries, parent.Retries)
 	h.StartPeriod = extendDuration(h.StartPeriod, parent.StartPeriod)
 	h.Timeout = extendDuration(h.Timeout, parent.Timeout)
+	h.MaxWait = extendDuration(h.MaxWait, parent.MaxWait)
 	return nil
 }
 
+// Extend returns the readiness probes of a task merged with the readiness
+// probes of the task it extends. Either value may be nil.
+func (r *Readiness) Extend(parent *Readiness) *Readiness {
+	if parent == nil {
+		return r
+	}
+
+	if r == nil {
+		return parent
+	}
+
+	return &Readiness{
+		TCPPort:    extendInt(r.TCPPort, parent.TCPPort),
+		HTTP:       r.HTTP.Extend(parent.HTTP),
+		LogPattern: extendString(r.LogPattern, parent.LogPattern),
+		Timeout:    extendDuration(r.Timeout, parent.Timeout),
+		Interval:   extendDuration(r.Interval, parent.Interval),
+	}
+}
+
+func (p *HTTPProbe) Extend(parent *HTTPProbe) *HTTPProbe {
+	if parent == nil {
+		return p
+	}
+
+	if p == nil {
+		return parent
+	}
+
+	return &HTTPProbe{
+		Port:   extendInt(p.Port, parent.Port),
+		Path:   extendString(p.Path, parent.Path),
+		Status: extendInt(p.Status, parent.Status),
+	}
+}
+
+// Extend returns the cache settings of a task merged with the cache
+// settings of the task it extends. Either value may be nil.
+func (c *Cache) Extend(parent *Cache) *Cache {
+	if parent == nil {
+		return c
+	}
+
+	if c == nil {
+		return parent
+	}
+
+	return &Cache{
+		Inputs:  append(parent.Inputs, c.Inputs...),
+		Outputs: append(parent.Outputs, c.Outputs...),
+	}
+}
+
 func (t *RunTask) MarshalJSON() ([]byte, error) {
 	type Alias RunTask
 
 	return json.Marshal(&struct {
 		*Alias
-		Type string `json:"type"`
+		Type          string `json:"type"`
+		RetryDelay    string `json:"retry-delay,omitempty"`
+		RetryMaxDelay string `json:"retry-max-delay,omitempty"`
+		Timeout       string `json:"timeout,omitempty"`
 	}{
-		Alias: (*Alias)(t),
-		Type:  t.GetType(),
+		Alias:         (*Alias)(t),
+		Type:          t.GetType(),
+		RetryDelay:    durationString(t.RetryDelay),
+		RetryMaxDelay: durationString(t.RetryMaxDelay),
+		Timeout:       durationString(t.Timeout),
 	})
 }
 
@@ -90,11 +216,29 @@ func (h *Healthcheck) MarshalJSON() ([]byte, error) {
 		Retries     int    `json:"retries,omitempty"`
 		StartPeriod string `json:"start-period,omitempty"`
 		Timeout     string `json:"timeout,omitempty"`
+		MaxWait     string `json:"max-wait,omitempty"`
 	}{
 		Command:     h.Command,
 		Interval:    durationString(h.Interval),
 		Retries:     h.Retries,
 		StartPeriod: durationString(h.StartPeriod),
 		Timeout:     durationString(h.Timeout),
+		MaxWait:     durationString(h.MaxWait),
+	})
+}
+
+func (r *Readiness) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		TCPPort    int        `json:"tcp-port,omitempty"`
+		HTTP       *HTTPProbe `json:"http,omitempty"`
+		LogPattern string     `json:"log-pattern,omitempty"`
+		Timeout    string     `json:"timeout,omitempty"`
+		Interval   string     `json:"interval,omitempty"`
+	}{
+		TCPPort:    r.TCPPort,
+		HTTP:       r.HTTP,
+		LogPattern: r.LogPattern,
+		Timeout:    durationString(r.Timeout),
+		Interval:   durationString(r.Interval),
 	})
 }
@@ -16,6 +16,7 @@ func (s *RunTaskSuite) TestExtend(t sweet.T) {
 		Retries:     5,
 		StartPeriod: time.Minute,
 		Timeout:     time.Minute,
+		MaxWait:     time.Minute,
 	}
 
 	childHealthcheck := &Healthcheck{
@@ -24,6 +25,7 @@ func (s *RunTaskSuite) TestExtend(t sweet.T) {
 		Retries:     10,
 		StartPeriod: time.Second,
 		Timeout:     time.Second,
+		MaxWait:     time.Second,
 	}
 
 	parent := &RunTask{
@@ -82,6 +84,7 @@ func (s *RunTaskSuite) TestExtend(t sweet.T) {
 	Expect(child.Healthcheck.Retries).To(Equal(10))
 	Expect(child.Healthcheck.StartPeriod).To(Equal(time.Second))
 	Expect(child.Healthcheck.Timeout).To(Equal(time.Second))
+	Expect(child.Healthcheck.MaxWait).To(Equal(time.Second))
 	Expect(child.ExportEnvironmentFiles).To(ConsistOf("parent-exp1", "child-exp1"))
 }
 
@@ -92,6 +95,7 @@ func (s *RunTaskSuite) TestExtendNoOverride(t sweet.T) {
 		Retries:     5,
 		StartPeriod: time.Minute,
 		Timeout:     time.Minute,
+		MaxWait:     time.Minute,
 	}
 
 	parent := &RunTask{
@@ -128,6 +132,7 @@ func (s *RunTaskSuite) TestExtendNoOverride(t sweet.T) {
 	Expect(child.Healthcheck.Retries).To(Equal(5))
 	Expect(child.Healthcheck.StartPeriod).To(Equal(time.Minute))
 	Expect(child.Healthcheck.Timeout).To(Equal(time.Minute))
+	Expect(child.Healthcheck.MaxWait).To(Equal(time.Minute))
 }
 
 func (s *RunTaskSuite) TestExtendWrongType(t sweet.T) {
@@ -136,3 +141,149 @@ func (s *RunTaskSuite) TestExtendWrongType(t sweet.T) {
 
 	Expect(child.Extend(parent)).NotTo(BeNil())
 }
+
+func (s *RunTaskSuite) TestExtendCache(t sweet.T) {
+	parent := &RunTask{
+		TaskMeta:    TaskMeta{Name: "parent"},
+		Healthcheck: &Healthcheck{},
+		Cache: &Cache{
+			Inputs:  []string{"parent-input"},
+			Outputs: []string{"parent-output"},
+		},
+	}
+
+	child := &RunTask{
+		TaskMeta:    TaskMeta{Name: "child", Extends: "parent"},
+		Healthcheck: &Healthcheck{},
+		Cache: &Cache{
+			Inputs: []string{"child-input"},
+		},
+	}
+
+	Expect(child.Extend(parent)).To(BeNil())
+	Expect(child.Cache.Inputs).To(Equal([]string{"parent-input", "child-input"}))
+	Expect(child.Cache.Outputs).To(Equal([]string{"parent-output"}))
+}
+
+func (s *RunTaskSuite) TestExtendNoCache(t sweet.T) {
+	parent := &RunTask{
+		TaskMeta:    TaskMeta{Name: "parent"},
+		Healthcheck: &Healthcheck{},
+		Cache:       &Cache{Outputs: []string{"parent-output"}},
+	}
+
+	child := &RunTask{
+		TaskMeta:    TaskMeta{Name: "child", Extends: "parent"},
+		Healthcheck: &Healthcheck{},
+	}
+
+	Expect(child.Extend(parent)).To(BeNil())
+	Expect(child.Cache).To(Equal(&Cache{Outputs: []string{"parent-output"}}))
+}
+
+func (s *RunTaskSuite) TestExtendLimits(t sweet.T) {
+	parent := &RunTask{
+		TaskMeta:    TaskMeta{Name: "parent"},
+		Healthcheck: &Healthcheck{},
+		CPUShares:   "512",
+		Memory:      "1g",
+		ShmSize:     "256m",
+		Ulimits:     []string{"nofile=1024:2048"},
+		Privileged:  true,
+		CapAdd:      []string{"SYS_PTRACE"},
+		Tmpfs:       []string{"/run"},
+		Platform:    "linux/amd64",
+	}
+
+	child := &RunTask{
+		TaskMeta:    TaskMeta{Name: "child", Extends: "parent"},
+		Healthcheck: &Healthcheck{},
+		Memory:      "4g",
+		CapAdd:      []string{"NET_ADMIN"},
+		Devices:     []string{"/dev/fuse"},
+	}
+
+	Expect(child.Extend(parent)).To(BeNil())
+	Expect(child.CPUShares).To(Equal("512"))
+	Expect(child.Memory).To(Equal("4g"))
+	Expect(child.ShmSize).To(Equal("256m"))
+	Expect(child.Ulimits).To(Equal([]string{"nofile=1024:2048"}))
+	Expect(child.Privileged).To(BeTrue())
+	Expect(child.CapAdd).To(Equal([]string{"SYS_PTRACE", "NET_ADMIN"}))
+	Expect(child.Tmpfs).To(Equal([]string{"/run"}))
+	Expect(child.Devices).To(Equal([]string{"/dev/fuse"}))
+	Expect(child.Platform).To(Equal("linux/amd64"))
+}
+
+func (s *RunTaskSuite) TestExtendMounts(t sweet.T) {
+	parent := &RunTask{
+		TaskMeta:    TaskMeta{Name: "parent"},
+		Healthcheck: &Healthcheck{},
+		Mounts:      []*Mount{&Mount{Type: "bind", Source: "~/.m2", Target: "/root/.m2", ReadOnly: true}},
+		Ports:       []string{"8080:80"},
+		Caches:      []string{"gomod"},
+	}
+
+	child := &RunTask{
+		TaskMeta:    TaskMeta{Name: "child", Extends: "parent"},
+		Healthcheck: &Healthcheck{},
+		Mounts:      []*Mount{&Mount{Type: "tmpfs", Target: "/tmp"}},
+		Ports:       []string{"9090"},
+		Caches:      []string{"npm"},
+	}
+
+	Expect(child.Extend(parent)).To(BeNil())
+	Expect(child.Mounts).To(Equal([]*Mount{
+		&Mount{Type: "bind", Source: "~/.m2", Target: "/root/.m2", ReadOnly: true},
+		&Mount{Type: "tmpfs", Target: "/tmp"},
+	}))
+	Expect(child.Ports).To(Equal([]string{"8080:80", "9090"}))
+	Expect(child.Caches).To(Equal([]string{"gomod", "npm"}))
+}
+
+func (s *RunTaskSuite) TestExtendReadiness(t sweet.T) {
+	parent := &RunTask{
+		TaskMeta:    TaskMeta{Name: "parent"},
+		Healthcheck: &Healthcheck{},
+		Readiness: &Readiness{
+			TCPPort:    5432,
+			HTTP:       &HTTPProbe{Port: 8080, Path: "/health"},
+			LogPattern: "parent-pattern",
+			Timeout:    time.Minute,
+		},
+	}
+
+	child := &RunTask{
+		TaskMeta:    TaskMeta{Name: "child", Extends: "parent"},
+		Healthcheck: &Healthcheck{},
+		Readiness: &Readiness{
+			HTTP:     &HTTPProbe{Status: 204},
+			Interval: time.Second,
+		},
+	}
+
+	Expect(child.Extend(parent)).To(BeNil())
+	Expect(child.Readiness).To(Equal(&Readiness{
+		TCPPort:    5432,
+		HTTP:       &HTTPProbe{Port: 8080, Path: "/health", Status: 204},
+		LogPattern: "parent-pattern",
+		Timeout:    time.Minute,
+		Interval:   time.Second,
+	}))
+}
+
+func (s *RunTaskSuite) TestExtendNoReadiness(t sweet.T) {
+	parent := &RunTask{
+		TaskMeta:    TaskMeta{Name: "parent"},
+		Healthcheck: &Healthcheck{},
+		Readiness:   &Readiness{TCPPort: 5432},
+	}
+
+	child := &RunTask{
+		TaskMeta:    TaskMeta{Name: "child", Extends: "parent"},
+		Healthcheck: &Healthcheck{},
+	}
+
+	Expect(child.Extend(parent)).To(BeNil())
+	Expect(child.Readiness).To(Equal(&Readiness{TCPPort: 5432}))
+}
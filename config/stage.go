@@ -1,23 +1,44 @@
 package config
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 type (
+	// Note: Stage must serialize itself manually due to the time.Duration field.
+
 	Stage struct {
-		Name        string       `json:"name,omitempty"`
-		Disabled    string       `json:"disabled,omitempty"`
-		BeforeStage string       `json:"before-stage,omitempty"`
-		AfterStage  string       `json:"after-stage,omitempty"`
-		RunMode     RunMode      `json:"run-mode,omitempty"`
-		Parallel    bool         `json:"parallel,omitempty"`
-		Environment []string     `json:"environment,omitempty"`
-		Tasks       []*StageTask `json:"tasks,omitempty"`
+		Name        string        `json:"name,omitempty"`
+		Disabled    string        `json:"disabled,omitempty"`
+		If          string        `json:"if,omitempty"`
+		BeforeStage string        `json:"before-stage,omitempty"`
+		AfterStage  string        `json:"after-stage,omitempty"`
+		RunMode     RunMode       `json:"run-mode,omitempty"`
+		Parallel    bool          `json:"parallel,omitempty"`
+		MaxParallel int           `json:"max-parallel,omitempty"`
+		FailFast    bool          `json:"fail-fast,omitempty"`
+		Timeout     time.Duration `json:"-"`
+		Environment []string      `json:"environment,omitempty"`
+		Tasks       []*StageTask  `json:"tasks,omitempty"`
 	}
 
+	// Note: StageTask must serialize itself manually due to the time.Duration field.
+
 	StageTask struct {
-		Name        string   `json:"name,omitempty"`
-		Disabled    string   `json:"disabled,omitempty"`
-		Environment []string `json:"environment,omitempty"`
+		Name             string        `json:"name,omitempty"`
+		Disabled         string        `json:"disabled,omitempty"`
+		If               string        `json:"if,omitempty"`
+		Environment      []string      `json:"environment,omitempty"`
+		Needs            []string      `json:"needs,omitempty"`
+		Matrix           *Matrix       `json:"matrix,omitempty"`
+		AllowFailure     bool          `json:"allow-failure,omitempty"`
+		Retries          *int          `json:"retries,omitempty"`
+		RetryDelay       time.Duration `json:"-"`
+		RetryBackoff     float64       `json:"retry-backoff,omitempty"`
+		RetryMaxDelay    time.Duration `json:"-"`
+		RetryOnExitCodes []int         `json:"retry-on-exit-codes,omitempty"`
 	}
 
 	RunMode int
@@ -43,6 +64,69 @@ func (s *Stage) ShouldRun(failure bool) bool {
 	return false
 }
 
+// RetryPolicy returns the retry policy of the given task with any values
+// supplied by this stage task taking precedence. The number of retries is
+// a pointer so that a stage task can disable the retries of its task.
+func (t *StageTask) RetryPolicy(task Task) *RetryPolicy {
+	policy := task.GetRetryPolicy()
+	policy.RetryDelay = extendDuration(t.RetryDelay, policy.RetryDelay)
+	policy.RetryBackoff = extendFloat(t.RetryBackoff, policy.RetryBackoff)
+	policy.RetryMaxDelay = extendDuration(t.RetryMaxDelay, policy.RetryMaxDelay)
+
+	if t.Retries != nil {
+		policy.Retries = *t.Retries
+	}
+
+	if len(t.RetryOnExitCodes) > 0 {
+		policy.RetryOnExitCodes = t.RetryOnExitCodes
+	}
+
+	return policy
+}
+
+// Instances returns an instance for each combination of the matrix of
+// this stage task. A stage task without a matrix has a single, empty
+// instance.
+func (t *StageTask) Instances() []MatrixInstance {
+	if t.Matrix == nil {
+		return []MatrixInstance{MatrixInstance{}}
+	}
+
+	return t.Matrix.Instances()
+}
+
+// AllowsFailure determines if a failure of the given task should not cause
+// the enclosing stage to fail.
+func (t *StageTask) AllowsFailure(task Task) bool {
+	return t.AllowFailure || task.GetAllowFailure()
+}
+
+func (s *Stage) MarshalJSON() ([]byte, error) {
+	type Alias Stage
+
+	return json.Marshal(&struct {
+		*Alias
+		Timeout string `json:"timeout,omitempty"`
+	}{
+		Alias:   (*Alias)(s),
+		Timeout: durationString(s.Timeout),
+	})
+}
+
+func (t *StageTask) MarshalJSON() ([]byte, error) {
+	type Alias StageTask
+
+	return json.Marshal(&struct {
+		*Alias
+		RetryDelay    string `json:"retry-delay,omitempty"`
+		RetryMaxDelay string `json:"retry-max-delay,omitempty"`
+	}{
+		Alias:         (*Alias)(t),
+		RetryDelay:    durationString(t.RetryDelay),
+		RetryMaxDelay: durationString(t.RetryMaxDelay),
+	})
+}
+
 func (m RunMode) MarshalJSON() ([]byte, error) {
 	switch m {
 	case RunModeOnSuccess:
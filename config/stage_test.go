@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/aphistic/sweet"
 	. "github.com/onsi/gomega"
 )
@@ -21,3 +23,55 @@ func (s *StageSuite) TestShouldRun(t sweet.T) {
 	Expect(s3.ShouldRun(true)).To(BeTrue())
 	Expect(s3.ShouldRun(false)).To(BeFalse())
 }
+
+func (s *StageSuite) TestRetryPolicy(t sweet.T) {
+	task := &RunTask{
+		TaskMeta: TaskMeta{
+			Name:             "t",
+			Retries:          3,
+			RetryDelay:       time.Second,
+			RetryOnExitCodes: []int{1},
+		},
+	}
+
+	Expect((&StageTask{Name: "t"}).RetryPolicy(task)).To(Equal(&RetryPolicy{
+		Retries:          3,
+		RetryDelay:       time.Second,
+		RetryOnExitCodes: []int{1},
+	}))
+
+	var (
+		five = 5
+		zero = 0
+	)
+
+	stageTask := &StageTask{
+		Name:             "t",
+		Retries:          &five,
+		RetryDelay:       time.Minute,
+		RetryBackoff:     2,
+		RetryMaxDelay:    time.Hour,
+		RetryOnExitCodes: []int{2, 3},
+	}
+
+	Expect(stageTask.RetryPolicy(task)).To(Equal(&RetryPolicy{
+		Retries:          5,
+		RetryDelay:       time.Minute,
+		RetryBackoff:     2,
+		RetryMaxDelay:    time.Hour,
+		RetryOnExitCodes: []int{2, 3},
+	}))
+
+	Expect((&StageTask{Name: "t", Retries: &zero}).RetryPolicy(task).Retries).To(Equal(0))
+}
+
+func (s *StageSuite) TestAllowsFailure(t sweet.T) {
+	var (
+		task        = &RunTask{TaskMeta: TaskMeta{Name: "t"}}
+		allowedTask = &RunTask{TaskMeta: TaskMeta{Name: "t", AllowFailure: true}}
+	)
+
+	Expect((&StageTask{Name: "t"}).AllowsFailure(task)).To(BeFalse())
+	Expect((&StageTask{Name: "t"}).AllowsFailure(allowedTask)).To(BeTrue())
+	Expect((&StageTask{Name: "t", AllowFailure: true}).AllowsFailure(task)).To(BeTrue())
+}
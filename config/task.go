@@ -1,5 +1,11 @@
 package config
 
+import (
+	"math"
+	"strings"
+	"time"
+)
+
 type (
 	Task interface {
 		GetName() string
@@ -7,14 +13,40 @@ type (
 		GetExtends() string
 		GetEnvironment() []string
 		GetRequiredEnvironment() []string
+		GetRetryPolicy() *RetryPolicy
+		GetTimeout() time.Duration
+		GetAllowFailure() bool
+		GetInputs() []string
+		GetOutputs() []string
 		Extend(parent Task) error
 	}
 
+	// Note: TaskMeta's RetryDelay, RetryMaxDelay, and Timeout fields are
+	// serialized by the MarshalJSON method of each task type due to the
+	// time.Duration type.
+
 	TaskMeta struct {
-		Name                string   `json:"-"`
-		Extends             string   `json:"extends,omitempty"`
-		Environment         []string `json:"environment,omitempty"`
-		RequiredEnvironment []string `json:"required-environment,omitempty"`
+		Name                string        `json:"-"`
+		Extends             string        `json:"extends,omitempty"`
+		Environment         []string      `json:"environment,omitempty"`
+		RequiredEnvironment []string      `json:"required-environment,omitempty"`
+		Retries             int           `json:"retries,omitempty"`
+		RetryDelay          time.Duration `json:"-"`
+		RetryBackoff        float64       `json:"retry-backoff,omitempty"`
+		RetryMaxDelay       time.Duration `json:"-"`
+		RetryOnExitCodes    []int         `json:"retry-on-exit-codes,omitempty"`
+		Timeout             time.Duration `json:"-"`
+		AllowFailure        bool          `json:"allow-failure,omitempty"`
+		Inputs              []string      `json:"inputs,omitempty"`
+		Outputs             []string      `json:"outputs,omitempty"`
+	}
+
+	RetryPolicy struct {
+		Retries          int
+		RetryDelay       time.Duration
+		RetryBackoff     float64
+		RetryMaxDelay    time.Duration
+		RetryOnExitCodes []int
 	}
 )
 
@@ -22,8 +54,85 @@ func (t *TaskMeta) GetName() string                  { return t.Name }
 func (t *TaskMeta) GetExtends() string               { return t.Extends }
 func (t *TaskMeta) GetEnvironment() []string         { return t.Environment }
 func (t *TaskMeta) GetRequiredEnvironment() []string { return t.RequiredEnvironment }
+func (t *TaskMeta) GetTimeout() time.Duration        { return t.Timeout }
+func (t *TaskMeta) GetAllowFailure() bool            { return t.AllowFailure }
+func (t *TaskMeta) GetInputs() []string              { return t.Inputs }
+func (t *TaskMeta) GetOutputs() []string             { return t.Outputs }
+
+func (t *TaskMeta) GetRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		Retries:          t.Retries,
+		RetryDelay:       t.RetryDelay,
+		RetryBackoff:     t.RetryBackoff,
+		RetryMaxDelay:    t.RetryMaxDelay,
+		RetryOnExitCodes: t.RetryOnExitCodes,
+	}
+}
 
 func (t *TaskMeta) extendMeta(parent TaskMeta) {
 	t.Environment = append(parent.Environment, t.Environment...)
 	t.RequiredEnvironment = append(parent.RequiredEnvironment, t.RequiredEnvironment...)
+	t.Retries = extendInt(t.Retries, parent.Retries)
+	t.RetryDelay = extendDuration(t.RetryDelay, parent.RetryDelay)
+	t.RetryBackoff = extendFloat(t.RetryBackoff, parent.RetryBackoff)
+	t.RetryMaxDelay = extendDuration(t.RetryMaxDelay, parent.RetryMaxDelay)
+	t.RetryOnExitCodes = append(parent.RetryOnExitCodes, t.RetryOnExitCodes...)
+	t.Timeout = extendDuration(t.Timeout, parent.Timeout)
+	t.AllowFailure = extendBool(t.AllowFailure, parent.AllowFailure)
+	t.Inputs = append(parent.Inputs, t.Inputs...)
+	t.Outputs = append(parent.Outputs, t.Outputs...)
+}
+
+// SplitArtifact splits an input of the form `task:path` into the name of
+// the task that produced the artifact and its path relative to the outputs
+// of that task.
+func SplitArtifact(input string) (string, string, bool) {
+	parts := strings.SplitN(input, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+// Attempts returns the maximum number of times a task should be invoked.
+func (p *RetryPolicy) Attempts() int {
+	return p.Retries + 1
+}
+
+// Delay returns the duration to wait after the given failed attempt. The
+// retry delay is multiplied by the backoff factor after each attempt, and
+// is capped by the maximum delay if one is set.
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	delay := float64(p.RetryDelay)
+	if p.RetryBackoff > 1 {
+		delay *= math.Pow(p.RetryBackoff, float64(attempt-1))
+	}
+
+	if p.RetryMaxDelay > 0 && delay > float64(p.RetryMaxDelay) {
+		return p.RetryMaxDelay
+	}
+
+	return time.Duration(delay)
+}
+
+// ShouldRetry determines if a command that exited with the given status
+// should be attempted again. A status code of -1 indicates that the command
+// did not exit normally (e.g. it could not be started).
+func (p *RetryPolicy) ShouldRetry(attempt, exitCode int) bool {
+	if attempt >= p.Attempts() {
+		return false
+	}
+
+	if len(p.RetryOnExitCodes) == 0 {
+		return true
+	}
+
+	for _, code := range p.RetryOnExitCodes {
+		if code == exitCode {
+			return true
+		}
+	}
+
+	return false
 }
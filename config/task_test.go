@@ -0,0 +1,134 @@
+package config
+
+import (
+	"time"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type TaskSuite struct{}
+
+func (s *TaskSuite) TestExtendRetryPolicy(t sweet.T) {
+	parent := &PushTask{
+		TaskMeta: TaskMeta{
+			Name:             "parent",
+			Retries:          3,
+			RetryDelay:       time.Second,
+			RetryBackoff:     2,
+			RetryMaxDelay:    time.Minute,
+			RetryOnExitCodes: []int{1},
+		},
+	}
+
+	child := &PushTask{
+		TaskMeta: TaskMeta{
+			Name:             "child",
+			Extends:          "parent",
+			Retries:          5,
+			RetryOnExitCodes: []int{2},
+		},
+	}
+
+	Expect(child.Extend(parent)).To(BeNil())
+	Expect(child.GetRetryPolicy()).To(Equal(&RetryPolicy{
+		Retries:          5,
+		RetryDelay:       time.Second,
+		RetryBackoff:     2,
+		RetryMaxDelay:    time.Minute,
+		RetryOnExitCodes: []int{1, 2},
+	}))
+}
+
+func (s *TaskSuite) TestShouldRetry(t sweet.T) {
+	policy := &RetryPolicy{Retries: 2}
+	Expect(policy.Attempts()).To(Equal(3))
+	Expect(policy.ShouldRetry(1, 1)).To(BeTrue())
+	Expect(policy.ShouldRetry(2, -1)).To(BeTrue())
+	Expect(policy.ShouldRetry(3, 1)).To(BeFalse())
+}
+
+func (s *TaskSuite) TestShouldRetryExitCodes(t sweet.T) {
+	policy := &RetryPolicy{Retries: 2, RetryOnExitCodes: []int{125, 137}}
+	Expect(policy.ShouldRetry(1, 125)).To(BeTrue())
+	Expect(policy.ShouldRetry(1, 137)).To(BeTrue())
+	Expect(policy.ShouldRetry(1, 1)).To(BeFalse())
+	Expect(policy.ShouldRetry(1, -1)).To(BeFalse())
+	Expect(policy.ShouldRetry(3, 125)).To(BeFalse())
+}
+
+func (s *TaskSuite) TestDelay(t sweet.T) {
+	policy := &RetryPolicy{RetryDelay: time.Second}
+	Expect(policy.Delay(1)).To(Equal(time.Second))
+	Expect(policy.Delay(4)).To(Equal(time.Second))
+
+	policy = &RetryPolicy{RetryDelay: time.Second, RetryBackoff: 2, RetryMaxDelay: time.Second * 5}
+	Expect(policy.Delay(1)).To(Equal(time.Second))
+	Expect(policy.Delay(2)).To(Equal(time.Second * 2))
+	Expect(policy.Delay(3)).To(Equal(time.Second * 4))
+	Expect(policy.Delay(4)).To(Equal(time.Second * 5))
+	Expect(policy.Delay(10)).To(Equal(time.Second * 5))
+}
+
+func (s *TaskSuite) TestShouldRetryNoRetries(t sweet.T) {
+	policy := &RetryPolicy{}
+	Expect(policy.Attempts()).To(Equal(1))
+	Expect(policy.ShouldRetry(1, 1)).To(BeFalse())
+}
+
+func (s *TaskSuite) TestExtendTimeout(t sweet.T) {
+	parent := &BuildTask{TaskMeta: TaskMeta{Name: "parent", Timeout: time.Minute}}
+	child1 := &BuildTask{TaskMeta: TaskMeta{Name: "child1", Extends: "parent"}}
+	child2 := &BuildTask{TaskMeta: TaskMeta{Name: "child2", Extends: "parent", Timeout: time.Hour}}
+
+	Expect(child1.Extend(parent)).To(BeNil())
+	Expect(child2.Extend(parent)).To(BeNil())
+	Expect(child1.GetTimeout()).To(Equal(time.Minute))
+	Expect(child2.GetTimeout()).To(Equal(time.Hour))
+}
+
+func (s *TaskSuite) TestExtendAllowFailure(t sweet.T) {
+	parent := &PushTask{TaskMeta: TaskMeta{Name: "parent", AllowFailure: true}}
+	child := &PushTask{TaskMeta: TaskMeta{Name: "child", Extends: "parent"}}
+
+	Expect(child.Extend(parent)).To(BeNil())
+	Expect(child.GetAllowFailure()).To(BeTrue())
+}
+
+func (s *TaskSuite) TestExtendArtifacts(t sweet.T) {
+	parent := &HostTask{
+		TaskMeta: TaskMeta{
+			Name:    "parent",
+			Inputs:  []string{"build:bin/app"},
+			Outputs: []string{"dist"},
+		},
+	}
+
+	child := &HostTask{
+		TaskMeta: TaskMeta{
+			Name:    "child",
+			Extends: "parent",
+			Outputs: []string{"report.xml"},
+		},
+	}
+
+	Expect(child.Extend(parent)).To(BeNil())
+	Expect(child.GetInputs()).To(Equal([]string{"build:bin/app"}))
+	Expect(child.GetOutputs()).To(Equal([]string{"dist", "report.xml"}))
+}
+
+func (s *TaskSuite) TestSplitArtifact(t sweet.T) {
+	task, path, ok := SplitArtifact("build:bin/app")
+	Expect(ok).To(BeTrue())
+	Expect(task).To(Equal("build"))
+	Expect(path).To(Equal("bin/app"))
+
+	_, _, ok = SplitArtifact("bin/app")
+	Expect(ok).To(BeFalse())
+
+	_, _, ok = SplitArtifact(":bin/app")
+	Expect(ok).To(BeFalse())
+
+	_, _, ok = SplitArtifact("build:")
+	Expect(ok).To(BeFalse())
+}
@@ -22,6 +22,14 @@ func extendInt(child, parent int) int {
 	return parent
 }
 
+func extendFloat(child, parent float64) float64 {
+	if child > 0 {
+		return child
+	}
+
+	return parent
+}
+
 func extendDuration(child, parent time.Duration) time.Duration {
 	if child > 0 {
 		return child
@@ -0,0 +1,208 @@
+package expression
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ij-build/ij/environment"
+)
+
+type (
+	node interface {
+		eval(s *scope) (value, error)
+	}
+
+	value struct {
+		str       string
+		boolean   bool
+		isBoolean bool
+	}
+
+	scope struct {
+		env     environment.Environment
+		failure bool
+	}
+
+	function struct {
+		arity int
+		call  func(s *scope, args []node) (value, error)
+	}
+
+	literalNode struct {
+		value    value
+		template bool
+	}
+
+	variableNode struct {
+		name string
+	}
+
+	notNode struct {
+		operand node
+	}
+
+	binaryNode struct {
+		op    tokenKind
+		left  node
+		right node
+	}
+
+	callNode struct {
+		name string
+		fn   *function
+		args []node
+	}
+)
+
+var functions = map[string]*function{
+	// defined(NAME) is true if the variable is set, even to an empty value
+	"defined": &function{
+		arity: 1,
+		call: func(s *scope, args []node) (value, error) {
+			name, err := variableName(s, args[0])
+			if err != nil {
+				return value{}, err
+			}
+
+			_, ok := s.env[name]
+			return boolValue(ok), nil
+		},
+	},
+
+	// failed() is true if a task preceding this point of the run has failed
+	"failed": &function{
+		arity: 0,
+		call: func(s *scope, args []node) (value, error) {
+			return boolValue(s.failure), nil
+		},
+	},
+}
+
+func stringValue(str string) value {
+	return value{str: str}
+}
+
+func boolValue(boolean bool) value {
+	return value{boolean: boolean, isBoolean: true}
+}
+
+// truthy determines if the value satisfies a condition. As with the
+// disabled property, any non-empty string is true.
+func (v value) truthy() bool {
+	if v.isBoolean {
+		return v.boolean
+	}
+
+	return v.str != ""
+}
+
+func (v value) String() string {
+	if v.isBoolean {
+		return fmt.Sprintf("%v", v.boolean)
+	}
+
+	return v.str
+}
+
+func (n *literalNode) eval(s *scope) (value, error) {
+	if !n.template {
+		return n.value, nil
+	}
+
+	str, err := s.env.ExpandString(n.value.str)
+	if err != nil {
+		return value{}, err
+	}
+
+	return stringValue(str), nil
+}
+
+func (n *variableNode) eval(s *scope) (value, error) {
+	if _, ok := s.env[n.name]; !ok {
+		return stringValue(""), nil
+	}
+
+	str, err := s.env.ExpandString(fmt.Sprintf("${%s}", n.name))
+	if err != nil {
+		return value{}, err
+	}
+
+	return stringValue(str), nil
+}
+
+func (n *notNode) eval(s *scope) (value, error) {
+	operand, err := n.operand.eval(s)
+	if err != nil {
+		return value{}, err
+	}
+
+	return boolValue(!operand.truthy()), nil
+}
+
+func (n *binaryNode) eval(s *scope) (value, error) {
+	left, err := n.left.eval(s)
+	if err != nil {
+		return value{}, err
+	}
+
+	// Short-circuit the logical operators
+	switch n.op {
+	case tokenAnd:
+		if !left.truthy() {
+			return boolValue(false), nil
+		}
+	case tokenOr:
+		if left.truthy() {
+			return boolValue(true), nil
+		}
+	}
+
+	right, err := n.right.eval(s)
+	if err != nil {
+		return value{}, err
+	}
+
+	switch n.op {
+	case tokenAnd, tokenOr:
+		return boolValue(right.truthy()), nil
+	case tokenEq:
+		return boolValue(left.String() == right.String()), nil
+	case tokenNe:
+		return boolValue(left.String() != right.String()), nil
+	}
+
+	pattern, err := regexp.Compile(right.String())
+	if err != nil {
+		return value{}, fmt.Errorf("invalid regular expression `%s`: %s", right, err.Error())
+	}
+
+	matched := pattern.MatchString(left.String())
+
+	if n.op == tokenNotMatch {
+		return boolValue(!matched), nil
+	}
+
+	return boolValue(matched), nil
+}
+
+func (n *callNode) eval(s *scope) (value, error) {
+	return n.fn.call(s, n.args)
+}
+
+//
+// Helpers
+
+// variableName returns the name referenced by a function argument. Both
+// `defined(NAME)` and `defined("NAME")` refer to the same variable.
+func variableName(s *scope, arg node) (string, error) {
+	if variable, ok := arg.(*variableNode); ok {
+		return variable.name, nil
+	}
+
+	name, err := arg.eval(s)
+	if err != nil {
+		return "", err
+	}
+
+	return name.String(), nil
+}
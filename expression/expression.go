@@ -0,0 +1,55 @@
+package expression
+
+import (
+	"fmt"
+
+	"github.com/ij-build/ij/environment"
+)
+
+// Expression is a parsed condition such as `BRANCH == "main" && !failed()`.
+type Expression struct {
+	source string
+	root   node
+}
+
+// Parse parses the given source into an expression.
+func Parse(source string) (*Expression, error) {
+	root, err := parse(source)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to parse expression `%s`: %s",
+			source,
+			err.Error(),
+		)
+	}
+
+	return &Expression{
+		source: source,
+		root:   root,
+	}, nil
+}
+
+// Evaluate determines if the expression holds in the given environment.
+// The failure flag is the result of the `failed()` function.
+func (e *Expression) Evaluate(env environment.Environment, failure bool) (bool, error) {
+	value, err := e.root.eval(&scope{env: env, failure: failure})
+	if err != nil {
+		return false, fmt.Errorf(
+			"failed to evaluate expression `%s`: %s",
+			e.source,
+			err.Error(),
+		)
+	}
+
+	return value.truthy(), nil
+}
+
+// Evaluate parses and evaluates the given source.
+func Evaluate(source string, env environment.Environment, failure bool) (bool, error) {
+	expression, err := Parse(source)
+	if err != nil {
+		return false, err
+	}
+
+	return expression.Evaluate(env, failure)
+}
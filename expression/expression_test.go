@@ -0,0 +1,94 @@
+package expression
+
+import (
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/environment"
+	. "github.com/onsi/gomega"
+)
+
+type ExpressionSuite struct{}
+
+var testEnv = environment.New([]string{
+	"BRANCH=main",
+	"TAG=v1.2.3",
+	"EMPTY=",
+	"REF=${BRANCH}",
+})
+
+func (s *ExpressionSuite) TestEvaluate(t sweet.T) {
+	cases := map[string]bool{
+		`BRANCH == "main"`:                       true,
+		`BRANCH == 'main'`:                       true,
+		`$BRANCH == "main"`:                      true,
+		`${BRANCH} != "main"`:                    false,
+		`REF == "main"`:                          true,
+		`"${BRANCH}-x" == "main-x"`:              true,
+		`BRANCH`:                                 true,
+		`EMPTY`:                                  false,
+		`MISSING`:                                false,
+		`MISSING == ""`:                          true,
+		`!EMPTY`:                                 true,
+		`!!BRANCH`:                               true,
+		`true && false`:                          false,
+		`true || false`:                          true,
+		`false || BRANCH == "dev" || true`:       true,
+		`!(BRANCH == "main" && TAG == "x")`:      true,
+		`TAG =~ "^v[0-9]+\\.[0-9]+"`:             true,
+		`TAG !~ "^v"`:                            false,
+		`defined(EMPTY)`:                         true,
+		`defined("BRANCH")`:                      true,
+		`defined(MISSING)`:                       false,
+		`failed()`:                               false,
+		`!failed() && BRANCH == "main"`:          true,
+		`TAG == "v1.2.3" && BRANCH != "release"`: true,
+	}
+
+	for source, expected := range cases {
+		ok, err := Evaluate(source, testEnv, false)
+		Expect(err).To(BeNil(), source)
+		Expect(ok).To(Equal(expected), source)
+	}
+}
+
+func (s *ExpressionSuite) TestEvaluateFailure(t sweet.T) {
+	ok, err := Evaluate(`failed()`, testEnv, true)
+	Expect(err).To(BeNil())
+	Expect(ok).To(BeTrue())
+}
+
+func (s *ExpressionSuite) TestShortCircuit(t sweet.T) {
+	ok, err := Evaluate(`false && TAG =~ "("`, testEnv, false)
+	Expect(err).To(BeNil())
+	Expect(ok).To(BeFalse())
+}
+
+func (s *ExpressionSuite) TestParseErrors(t sweet.T) {
+	cases := map[string]string{
+		`BRANCH ==`:        "unexpected end of expression",
+		`BRANCH = "main"`:  `unexpected character '=' at position 8`,
+		`(BRANCH`:          "unexpected end of expression",
+		`BRANCH "main"`:    `unexpected "\"main\"" at position 8`,
+		`"main`:            "unterminated string at position 1",
+		`${BRANCH`:         "unterminated variable at position 1",
+		`unknown()`:        `unknown function "unknown" at position 1`,
+		`defined()`:        "function defined expects 1 argument(s), got 0",
+		`failed(BRANCH)`:   "function failed expects 0 argument(s), got 1",
+		`BRANCH == == "x"`: `unexpected "==" at position 11`,
+		`a == "b" == "c"`:  `unexpected "==" at position 10`,
+		`&& BRANCH`:        `unexpected "&&" at position 1`,
+		`BRANCH == "x" &&`: "unexpected end of expression",
+		`BRANCH == "x" ) `: `unexpected ")" at position 15`,
+	}
+
+	for source, message := range cases {
+		_, err := Parse(source)
+		Expect(err).NotTo(BeNil(), source)
+		Expect(err.Error()).To(HaveSuffix(message), source)
+	}
+}
+
+func (s *ExpressionSuite) TestEvaluateInvalidRegex(t sweet.T) {
+	_, err := Evaluate(`TAG =~ "("`, testEnv, false)
+	Expect(err).NotTo(BeNil())
+	Expect(err.Error()).To(ContainSubstring("invalid regular expression `(`"))
+}
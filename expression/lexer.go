@@ -0,0 +1,219 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type (
+	token struct {
+		kind  tokenKind
+		text  string
+		value string
+		pos   int
+	}
+
+	tokenKind int
+)
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenVariable
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenComma
+	tokenNot
+	tokenAnd
+	tokenOr
+	tokenEq
+	tokenNe
+	tokenMatch
+	tokenNotMatch
+)
+
+var operators = []struct {
+	text string
+	kind tokenKind
+}{
+	// Two-character operators must precede their one-character prefixes
+	{"&&", tokenAnd},
+	{"||", tokenOr},
+	{"==", tokenEq},
+	{"!=", tokenNe},
+	{"=~", tokenMatch},
+	{"!~", tokenNotMatch},
+	{"!", tokenNot},
+	{"(", tokenLParen},
+	{")", tokenRParen},
+	{",", tokenComma},
+}
+
+func lex(source string) ([]token, error) {
+	var (
+		tokens = []token{}
+		runes  = []rune(source)
+		pos    = 0
+	)
+
+	for pos < len(runes) {
+		r := runes[pos]
+
+		if unicode.IsSpace(r) {
+			pos++
+			continue
+		}
+
+		if t, ok := lexOperator(runes, pos); ok {
+			tokens = append(tokens, t)
+			pos += len(t.text)
+			continue
+		}
+
+		var (
+			t   token
+			err error
+		)
+
+		switch {
+		case r == '"' || r == '\'':
+			t, err = lexString(runes, pos)
+		case r == '$':
+			t, err = lexVariable(runes, pos)
+		case unicode.IsDigit(r):
+			t = lexNumber(runes, pos)
+		case isIdentStart(r):
+			t = lexIdent(runes, pos)
+		default:
+			err = fmt.Errorf("unexpected character %q at position %d", r, pos+1)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, t)
+		pos += len([]rune(t.text))
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+func lexOperator(runes []rune, pos int) (token, bool) {
+	rest := string(runes[pos:])
+
+	for _, operator := range operators {
+		if strings.HasPrefix(rest, operator.text) {
+			return token{kind: operator.kind, text: operator.text, pos: pos}, true
+		}
+	}
+
+	return token{}, false
+}
+
+func lexString(runes []rune, pos int) (token, error) {
+	var (
+		quote = runes[pos]
+		value = []rune{}
+		end   = pos + 1
+	)
+
+	for ; end < len(runes); end++ {
+		r := runes[end]
+
+		if r == quote {
+			return token{
+				kind:  tokenString,
+				text:  string(runes[pos : end+1]),
+				value: string(value),
+				pos:   pos,
+			}, nil
+		}
+
+		if r == '\\' && end+1 < len(runes) {
+			end++
+			r = runes[end]
+		}
+
+		value = append(value, r)
+	}
+
+	return token{}, fmt.Errorf("unterminated string at position %d", pos+1)
+}
+
+func lexVariable(runes []rune, pos int) (token, error) {
+	if pos+1 < len(runes) && runes[pos+1] == '{' {
+		for end := pos + 2; end < len(runes); end++ {
+			if runes[end] == '}' {
+				return token{
+					kind:  tokenVariable,
+					text:  string(runes[pos : end+1]),
+					value: string(runes[pos+2 : end]),
+					pos:   pos,
+				}, nil
+			}
+		}
+
+		return token{}, fmt.Errorf("unterminated variable at position %d", pos+1)
+	}
+
+	if pos+1 < len(runes) && isIdentStart(runes[pos+1]) {
+		ident := lexIdent(runes, pos+1)
+
+		return token{
+			kind:  tokenVariable,
+			text:  "$" + ident.text,
+			value: ident.text,
+			pos:   pos,
+		}, nil
+	}
+
+	return token{}, fmt.Errorf("unexpected character '$' at position %d", pos+1)
+}
+
+func lexIdent(runes []rune, pos int) token {
+	end := pos
+	for end < len(runes) && isIdentPart(runes[end]) {
+		end++
+	}
+
+	return token{
+		kind:  tokenIdent,
+		text:  string(runes[pos:end]),
+		value: string(runes[pos:end]),
+		pos:   pos,
+	}
+}
+
+// lexNumber reads a bare number, which is compared as a string.
+func lexNumber(runes []rune, pos int) token {
+	end := pos
+	for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+		end++
+	}
+
+	return token{
+		kind:  tokenString,
+		text:  string(runes[pos:end]),
+		value: string(runes[pos:end]),
+		pos:   pos,
+	}
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r)
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+
+	return fmt.Sprintf("%q at position %d", t.text, t.pos+1)
+}
@@ -0,0 +1,206 @@
+package expression
+
+import (
+	"fmt"
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// The grammar of an expression, from lowest to highest precedence:
+//
+//   or         := and ('||' and)*
+//   and        := unary ('&&' unary)*
+//   unary      := '!' unary | comparison
+//   comparison := primary (('==' | '!=' | '=~' | '!~') primary)?
+//   primary    := '(' or ')' | string | variable | call | 'true' | 'false'
+//   call       := identifier '(' (or (',' or)*)? ')'
+
+func parse(source string) (node, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s", t)
+	}
+
+	return root, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseBinary(p.parseAnd, tokenOr)
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseBinary(p.parseUnary, tokenAnd)
+}
+
+func (p *parser) parseBinary(operand func() (node, error), kind tokenKind) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == kind {
+		p.next()
+
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{op: kind, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &notNode{operand: operand}, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind := p.peek().kind; kind {
+	case tokenEq, tokenNe, tokenMatch, tokenNotMatch:
+		p.next()
+
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &binaryNode{op: kind, left: left, right: right}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(tokenRParen); err != nil {
+			return nil, err
+		}
+
+		return inner, nil
+
+	case tokenString:
+		return &literalNode{value: stringValue(t.value), template: true}, nil
+
+	case tokenVariable:
+		return &variableNode{name: t.value}, nil
+
+	case tokenIdent:
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(t)
+		}
+
+		switch t.value {
+		case "true":
+			return &literalNode{value: boolValue(true)}, nil
+		case "false":
+			return &literalNode{value: boolValue(false)}, nil
+		}
+
+		return &variableNode{name: t.value}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected %s", t)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.value]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+
+	p.next()
+
+	args := []node{}
+	if p.peek().kind != tokenRParen {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+
+			args = append(args, arg)
+
+			if p.peek().kind != tokenComma {
+				break
+			}
+
+			p.next()
+		}
+	}
+
+	if err := p.expect(tokenRParen); err != nil {
+		return nil, err
+	}
+
+	if len(args) != fn.arity {
+		return nil, fmt.Errorf(
+			"function %s expects %d argument(s), got %d",
+			name.value,
+			fn.arity,
+			len(args),
+		)
+	}
+
+	return &callNode{name: name.value, fn: fn, args: args}, nil
+}
+
+func (p *parser) expect(kind tokenKind) error {
+	if t := p.next(); t.kind != kind {
+		return fmt.Errorf("unexpected %s", t)
+	}
+
+	return nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
@@ -11,6 +11,15 @@ type BuildTask struct {
 	Extends             string          `json:"extends"`
 	Environment         json.RawMessage `json:"environment"`
 	RequiredEnvironment []string        `json:"required-environment"`
+	Retries             int             `json:"retries"`
+	RetryDelay          util.Duration   `json:"retry-delay"`
+	RetryBackoff        float64         `json:"retry-backoff"`
+	RetryMaxDelay       util.Duration   `json:"retry-max-delay"`
+	RetryOnExitCodes    []int           `json:"retry-on-exit-codes"`
+	Timeout             util.Duration   `json:"timeout"`
+	AllowFailure        bool            `json:"allow-failure"`
+	Inputs              json.RawMessage `json:"inputs"`
+	Outputs             json.RawMessage `json:"outputs"`
 	Dockerfile          string          `json:"dockerfile"`
 	Target              string          `json:"target"`
 	Tags                json.RawMessage `json:"tags"`
@@ -33,11 +42,30 @@ func (t *BuildTask) Translate(name string) (config.Task, error) {
 		return nil, err
 	}
 
+	inputs, err := util.UnmarshalStringList(t.Inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := util.UnmarshalStringList(t.Outputs)
+	if err != nil {
+		return nil, err
+	}
+
 	meta := config.TaskMeta{
 		Name:                name,
 		Extends:             t.Extends,
 		Environment:         environment,
 		RequiredEnvironment: t.RequiredEnvironment,
+		Retries:             t.Retries,
+		RetryDelay:          t.RetryDelay.Duration,
+		RetryBackoff:        t.RetryBackoff,
+		RetryMaxDelay:       t.RetryMaxDelay.Duration,
+		RetryOnExitCodes:    t.RetryOnExitCodes,
+		Timeout:             t.Timeout.Duration,
+		AllowFailure:        t.AllowFailure,
+		Inputs:              inputs,
+		Outputs:             outputs,
 	}
 
 	if t.Dockerfile == "" {
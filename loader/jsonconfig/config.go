@@ -9,24 +9,35 @@ import (
 
 type (
 	Config struct {
-		Extends          json.RawMessage            `json:"extends"`
-		Options          *Options                   `json:"options"`
-		Registries       []json.RawMessage          `json:"registries"`
-		Workspace        string                     `json:"workspace"`
-		Environment      json.RawMessage            `json:"environment"`
-		EnvironmentFiles json.RawMessage            `json:"env-file"`
-		Import           *ImportFileList            `json:"import"`
-		Export           *ExportFileList            `json:"export"`
-		Tasks            map[string]json.RawMessage `json:"tasks"`
-		Plans            map[string]*Plan           `json:"plans"`
-		Metaplans        map[string][]string        `json:"metaplans"`
+		Extends          json.RawMessage             `json:"extends"`
+		Options          *Options                    `json:"options"`
+		Registries       []json.RawMessage           `json:"registries"`
+		Workspace        string                      `json:"workspace"`
+		Environment      json.RawMessage             `json:"environment"`
+		EnvironmentFiles json.RawMessage             `json:"env-file"`
+		Import           *ImportFileList             `json:"import"`
+		Export           *ExportFileList             `json:"export"`
+		Tasks            map[string]json.RawMessage  `json:"tasks"`
+		Plans            map[string]*Plan            `json:"plans"`
+		Metaplans        map[string][]string         `json:"metaplans"`
+		Caches           map[string]*DependencyCache `json:"caches"`
+	}
+
+	DependencyCache struct {
+		Path string `json:"path"`
+		Key  string `json:"key"`
 	}
 
 	Options struct {
 		SSHIdentities       json.RawMessage   `json:"ssh-identities"`
 		ForceSequential     bool              `json:"force-sequential"`
+		FailFast            bool              `json:"fail-fast"`
+		Jobs                int               `json:"jobs"`
 		HealthcheckInterval util.Duration     `json:"healthcheck-interval"`
 		PathSubstitutions   map[string]string `json:"path-substitutions"`
+		Runtime             string            `json:"runtime"`
+		AllowedMounts       json.RawMessage   `json:"allowed-mounts"`
+		AllowPrivileged     bool              `json:"allow-privileged"`
 	}
 
 	ImportFileList struct {
@@ -102,6 +113,11 @@ func (c *Config) Translate(parent *config.Config) (*config.Config, error) {
 		plans[name] = translated
 	}
 
+	caches := map[string]*config.DependencyCache{}
+	for name, cache := range c.Caches {
+		caches[name] = cache.Translate(name)
+	}
+
 	return &config.Config{
 		Extends:          extends,
 		Options:          options,
@@ -114,20 +130,39 @@ func (c *Config) Translate(parent *config.Config) (*config.Config, error) {
 		Tasks:            tasks,
 		Plans:            plans,
 		Metaplans:        c.Metaplans,
+		Caches:           caches,
 	}, nil
 }
 
+func (c *DependencyCache) Translate(name string) *config.DependencyCache {
+	return &config.DependencyCache{
+		Name: name,
+		Path: c.Path,
+		Key:  c.Key,
+	}
+}
+
 func (c *Options) Translate() (*config.Options, error) {
 	sshIdentities, err := util.UnmarshalStringList(c.SSHIdentities)
 	if err != nil {
 		return nil, err
 	}
 
+	allowedMounts, err := util.UnmarshalStringList(c.AllowedMounts)
+	if err != nil {
+		return nil, err
+	}
+
 	return &config.Options{
 		SSHIdentities:       sshIdentities,
 		ForceSequential:     c.ForceSequential,
+		FailFast:            c.FailFast,
+		Jobs:                c.Jobs,
 		HealthcheckInterval: c.HealthcheckInterval.Duration,
 		PathSubstitutions:   c.PathSubstitutions,
+		Runtime:             c.Runtime,
+		AllowedMounts:       allowedMounts,
+		AllowPrivileged:     c.AllowPrivileged,
 	}, nil
 }
 
@@ -41,6 +41,9 @@ func (s *ConfigSuite) TestTranslate(t sweet.T) {
 		Metaplans: map[string][]string{
 			"default": []string{"a", "b"},
 		},
+		Caches: map[string]*DependencyCache{
+			"gomod": &DependencyCache{Path: "/go/pkg/mod", Key: "go-${GIT_BRANCH}"},
+		},
 	}
 
 	translated, err := jsonConfig.Translate(nil)
@@ -102,6 +105,9 @@ func (s *ConfigSuite) TestTranslate(t sweet.T) {
 		Metaplans: map[string][]string{
 			"default": []string{"a", "b"},
 		},
+		Caches: map[string]*config.DependencyCache{
+			"gomod": &config.DependencyCache{Name: "gomod", Path: "/go/pkg/mod", Key: "go-${GIT_BRANCH}"},
+		},
 	}))
 }
 
@@ -143,5 +149,6 @@ func (s *ConfigSuite) TestTranslateStringLists(t sweet.T) {
 		Tasks:     map[string]config.Task{},
 		Plans:     map[string]*config.Plan{},
 		Metaplans: map[string][]string{},
+		Caches:    map[string]*config.DependencyCache{},
 	}))
 }
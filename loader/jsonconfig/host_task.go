@@ -0,0 +1,68 @@
+package jsonconfig
+
+import (
+	"encoding/json"
+
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/util"
+)
+
+type HostTask struct {
+	Extends             string          `json:"extends"`
+	Environment         json.RawMessage `json:"environment"`
+	RequiredEnvironment []string        `json:"required-environment"`
+	Retries             int             `json:"retries"`
+	RetryDelay          util.Duration   `json:"retry-delay"`
+	RetryBackoff        float64         `json:"retry-backoff"`
+	RetryMaxDelay       util.Duration   `json:"retry-max-delay"`
+	RetryOnExitCodes    []int           `json:"retry-on-exit-codes"`
+	Timeout             util.Duration   `json:"timeout"`
+	AllowFailure        bool            `json:"allow-failure"`
+	Inputs              json.RawMessage `json:"inputs"`
+	Outputs             json.RawMessage `json:"outputs"`
+	Command             string          `json:"command"`
+	Shell               string          `json:"shell"`
+	Script              string          `json:"script"`
+	Workdir             string          `json:"workdir"`
+}
+
+func (t *HostTask) Translate(name string) (config.Task, error) {
+	environment, err := util.UnmarshalStringList(t.Environment)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, err := util.UnmarshalStringList(t.Inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := util.UnmarshalStringList(t.Outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := config.TaskMeta{
+		Name:                name,
+		Extends:             t.Extends,
+		Environment:         environment,
+		RequiredEnvironment: t.RequiredEnvironment,
+		Retries:             t.Retries,
+		RetryDelay:          t.RetryDelay.Duration,
+		RetryBackoff:        t.RetryBackoff,
+		RetryMaxDelay:       t.RetryMaxDelay.Duration,
+		RetryOnExitCodes:    t.RetryOnExitCodes,
+		Timeout:             t.Timeout.Duration,
+		AllowFailure:        t.AllowFailure,
+		Inputs:              inputs,
+		Outputs:             outputs,
+	}
+
+	return &config.HostTask{
+		TaskMeta: meta,
+		Command:  t.Command,
+		Shell:    t.Shell,
+		Script:   t.Script,
+		Workdir:  t.Workdir,
+	}, nil
+}
@@ -0,0 +1,58 @@
+package jsonconfig
+
+import (
+	"encoding/json"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/config"
+	. "github.com/onsi/gomega"
+)
+
+type HostTaskSuite struct{}
+
+func (s *HostTaskSuite) TestTranslate(t sweet.T) {
+	task := &HostTask{
+		Extends:             "parent",
+		Environment:         json.RawMessage(`["X=1", "Y=2"]`),
+		RequiredEnvironment: []string{"X"},
+		Script:              "git tag v${X}",
+		Shell:               "/bin/bash",
+		Workdir:             "workspace",
+	}
+
+	translated, err := task.Translate("tag")
+	Expect(err).To(BeNil())
+	Expect(translated).To(Equal(&config.HostTask{
+		TaskMeta: config.TaskMeta{
+			Name:                "tag",
+			Extends:             "parent",
+			Environment:         []string{"X=1", "Y=2"},
+			RequiredEnvironment: []string{"X"},
+		},
+		Script:  "git tag v${X}",
+		Shell:   "/bin/bash",
+		Workdir: "workspace",
+	}))
+}
+
+func (s *HostTaskSuite) TestTranslateTask(t sweet.T) {
+	task, err := translateTask(nil, "tag", json.RawMessage(`{
+		"type": "host",
+		"command": "git tag v1",
+		"workdir": "project"
+	}`))
+
+	Expect(err).To(BeNil())
+	Expect(task.GetType()).To(Equal("host"))
+	Expect(task.(*config.HostTask).Command).To(Equal("git tag v1"))
+}
+
+func (s *HostTaskSuite) TestTranslateTaskInvalidWorkdir(t sweet.T) {
+	_, err := translateTask(nil, "tag", json.RawMessage(`{
+		"type": "host",
+		"command": "git tag v1",
+		"workdir": "home"
+	}`))
+
+	Expect(err).NotTo(BeNil())
+}
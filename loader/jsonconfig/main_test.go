@@ -16,6 +16,7 @@ func TestMain(m *testing.M) {
 
 		s.AddSuite(&BuildTaskSuite{})
 		s.AddSuite(&ConfigSuite{})
+		s.AddSuite(&HostTaskSuite{})
 		s.AddSuite(&OverrideSuite{})
 		s.AddSuite(&PlanSuite{})
 		s.AddSuite(&PlanTaskSuite{})
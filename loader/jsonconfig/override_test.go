@@ -16,7 +16,11 @@ func (s *OverrideSuite) TestTranslate(t sweet.T) {
 	jsonOverride := &Override{
 		Options: &Options{
 			SSHIdentities:       json.RawMessage(`"*"`),
+			Jobs:                4,
 			HealthcheckInterval: util.Duration{time.Second * 10},
+			Runtime:             "podman",
+			AllowedMounts:       json.RawMessage(`"~/.m2"`),
+			AllowPrivileged:     true,
 		},
 		Registries: []json.RawMessage{
 			json.RawMessage(`{"server": "docker.io"}`),
@@ -32,7 +36,11 @@ func (s *OverrideSuite) TestTranslate(t sweet.T) {
 	Expect(translated).To(Equal(&config.Override{
 		Options: &config.Options{
 			SSHIdentities:       []string{"*"},
+			Jobs:                4,
 			HealthcheckInterval: time.Second * 10,
+			Runtime:             "podman",
+			AllowedMounts:       []string{"~/.m2"},
+			AllowPrivileged:     true,
 		},
 		Registries: []config.Registry{
 			&config.ServerRegistry{Server: "docker.io"},
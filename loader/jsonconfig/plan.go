@@ -2,17 +2,32 @@ package jsonconfig
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/util"
 )
 
-type Plan struct {
-	Extends     string          `json:"extends"`
-	Disabled    string          `json:"disabled"`
-	Stages      []*Stage        `json:"stages"`
-	Environment json.RawMessage `json:"environment"`
-}
+type (
+	Plan struct {
+		Extends     string          `json:"extends"`
+		Disabled    string          `json:"disabled"`
+		If          string          `json:"if"`
+		Timeout     util.Duration   `json:"timeout"`
+		Stages      []*Stage        `json:"stages"`
+		Environment json.RawMessage `json:"environment"`
+		Parameters  []*Parameter    `json:"parameters"`
+	}
+
+	Parameter struct {
+		Name        string      `json:"name"`
+		Type        string      `json:"type"`
+		Values      []string    `json:"values"`
+		Default     interface{} `json:"default"`
+		Description string      `json:"description"`
+		Required    bool        `json:"required"`
+	}
+)
 
 func (p *Plan) Translate(name string) (*config.Plan, error) {
 	stages := []*config.Stage{}
@@ -30,11 +45,36 @@ func (p *Plan) Translate(name string) (*config.Plan, error) {
 		return nil, err
 	}
 
+	var parameters []*config.Parameter
+	for _, parameter := range p.Parameters {
+		parameters = append(parameters, parameter.Translate())
+	}
+
 	return &config.Plan{
 		Name:        name,
 		Extends:     p.Extends,
 		Disabled:    p.Disabled,
+		If:          p.If,
+		Timeout:     p.Timeout.Duration,
 		Stages:      stages,
 		Environment: environment,
+		Parameters:  parameters,
 	}, nil
 }
+
+func (p *Parameter) Translate() *config.Parameter {
+	defaultValue := ""
+	if p.Default != nil {
+		// Defaults may be written as YAML integers or booleans
+		defaultValue = fmt.Sprintf("%v", p.Default)
+	}
+
+	return &config.Parameter{
+		Name:        p.Name,
+		Type:        p.Type,
+		Values:      p.Values,
+		Default:     defaultValue,
+		Description: p.Description,
+		Required:    p.Required,
+	}
+}
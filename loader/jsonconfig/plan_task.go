@@ -11,6 +11,8 @@ type PlanTask struct {
 	Extends             string          `json:"extends"`
 	Environment         json.RawMessage `json:"environment"`
 	RequiredEnvironment []string        `json:"required-environment"`
+	Timeout             util.Duration   `json:"timeout"`
+	AllowFailure        bool            `json:"allow-failure"`
 	Name                string          `json:"name"`
 }
 
@@ -25,6 +27,8 @@ func (t *PlanTask) Translate(name string) (config.Task, error) {
 		Extends:             t.Extends,
 		Environment:         environment,
 		RequiredEnvironment: t.RequiredEnvironment,
+		Timeout:             t.Timeout.Duration,
+		AllowFailure:        t.AllowFailure,
 	}
 
 	return &config.PlanTask{
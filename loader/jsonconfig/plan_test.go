@@ -2,9 +2,11 @@ package jsonconfig
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/aphistic/sweet"
 	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/util"
 	. "github.com/onsi/gomega"
 )
 
@@ -14,10 +16,12 @@ func (s *PlanSuite) TestTranslate(t sweet.T) {
 	plan := &Plan{
 		Extends:  "foo",
 		Disabled: "${DISABLED}",
+		Timeout:  util.Duration{Duration: time.Hour},
 		Stages: []*Stage{
 			&Stage{
 				Name:     "bar",
 				Disabled: "${STAGE_DISABLED}",
+				Timeout:  util.Duration{Duration: time.Minute},
 				Tasks: []json.RawMessage{
 					json.RawMessage(`"t1"`),
 					json.RawMessage(`{
@@ -45,10 +49,12 @@ func (s *PlanSuite) TestTranslate(t sweet.T) {
 		Name:     "foo",
 		Extends:  "foo",
 		Disabled: "${DISABLED}",
+		Timeout:  time.Hour,
 		Stages: []*config.Stage{
 			&config.Stage{
 				Name:     "bar",
 				Disabled: "${STAGE_DISABLED}",
+				Timeout:  time.Minute,
 				Tasks: []*config.StageTask{
 					&config.StageTask{Name: "t1"},
 					&config.StageTask{
@@ -86,3 +92,24 @@ func (s *PlanSuite) TestTranslateStringLists(t sweet.T) {
 		Environment: []string{"X=1"},
 	}))
 }
+
+func (s *PlanSuite) TestTranslateParameters(t sweet.T) {
+	jsonPlan := &Plan{}
+	err := json.Unmarshal([]byte(`{
+		"parameters": [
+			{"name": "target", "type": "enum", "values": ["staging", "production"], "required": true},
+			{"name": "replicas", "type": "int", "default": 3},
+			{"name": "verbose", "type": "bool", "default": false, "description": "Output debug logs."}
+		]
+	}`), jsonPlan)
+
+	Expect(err).To(BeNil())
+
+	translated, err := jsonPlan.Translate("foo")
+	Expect(err).To(BeNil())
+	Expect(translated.Parameters).To(Equal([]*config.Parameter{
+		&config.Parameter{Name: "target", Type: "enum", Values: []string{"staging", "production"}, Required: true},
+		&config.Parameter{Name: "replicas", Type: "int", Default: "3"},
+		&config.Parameter{Name: "verbose", Type: "bool", Default: "false", Description: "Output debug logs."},
+	}))
+}
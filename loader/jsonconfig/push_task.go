@@ -11,6 +11,13 @@ type PushTask struct {
 	Extends             string          `json:"extends"`
 	Environment         json.RawMessage `json:"environment"`
 	RequiredEnvironment []string        `json:"required-environment"`
+	Retries             int             `json:"retries"`
+	RetryDelay          util.Duration   `json:"retry-delay"`
+	RetryBackoff        float64         `json:"retry-backoff"`
+	RetryMaxDelay       util.Duration   `json:"retry-max-delay"`
+	RetryOnExitCodes    []int           `json:"retry-on-exit-codes"`
+	Timeout             util.Duration   `json:"timeout"`
+	AllowFailure        bool            `json:"allow-failure"`
 	Images              json.RawMessage `json:"images"`
 	IncludeBuilt        bool            `json:"include-built"`
 }
@@ -31,6 +38,13 @@ func (t *PushTask) Translate(name string) (config.Task, error) {
 		Extends:             t.Extends,
 		Environment:         environment,
 		RequiredEnvironment: t.RequiredEnvironment,
+		Retries:             t.Retries,
+		RetryDelay:          t.RetryDelay.Duration,
+		RetryBackoff:        t.RetryBackoff,
+		RetryMaxDelay:       t.RetryMaxDelay.Duration,
+		RetryOnExitCodes:    t.RetryOnExitCodes,
+		Timeout:             t.Timeout.Duration,
+		AllowFailure:        t.AllowFailure,
 	}
 
 	return &config.PushTask{
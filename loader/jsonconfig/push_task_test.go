@@ -2,9 +2,11 @@ package jsonconfig
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/aphistic/sweet"
 	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/util"
 	. "github.com/onsi/gomega"
 )
 
@@ -53,3 +55,26 @@ func (s *PushTaskSuite) TestTranslateStringLists(t sweet.T) {
 		IncludeBuilt: true,
 	}))
 }
+
+func (s *PushTaskSuite) TestTranslateRetryPolicy(t sweet.T) {
+	task := &PushTask{
+		Retries:          3,
+		RetryDelay:       util.Duration{Duration: time.Second},
+		RetryBackoff:     2,
+		RetryMaxDelay:    util.Duration{Duration: time.Minute},
+		RetryOnExitCodes: []int{1, 125},
+	}
+
+	translated, err := task.Translate("push")
+	Expect(err).To(BeNil())
+	Expect(translated).To(Equal(&config.PushTask{
+		TaskMeta: config.TaskMeta{
+			Name:             "push",
+			Retries:          3,
+			RetryDelay:       time.Second,
+			RetryBackoff:     2,
+			RetryMaxDelay:    time.Minute,
+			RetryOnExitCodes: []int{1, 125},
+		},
+	}))
+}
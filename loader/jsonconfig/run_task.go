@@ -12,6 +12,15 @@ type (
 		Extends                string          `json:"extends"`
 		Environment            json.RawMessage `json:"environment"`
 		RequiredEnvironment    []string        `json:"required-environment"`
+		Retries                int             `json:"retries"`
+		RetryDelay             util.Duration   `json:"retry-delay"`
+		RetryBackoff           float64         `json:"retry-backoff"`
+		RetryMaxDelay          util.Duration   `json:"retry-max-delay"`
+		RetryOnExitCodes       []int           `json:"retry-on-exit-codes"`
+		Timeout                util.Duration   `json:"timeout"`
+		AllowFailure           bool            `json:"allow-failure"`
+		Inputs                 json.RawMessage `json:"inputs"`
+		Outputs                json.RawMessage `json:"outputs"`
 		Image                  string          `json:"image"`
 		Command                string          `json:"command"`
 		Shell                  string          `json:"shell"`
@@ -22,7 +31,28 @@ type (
 		Hostname               string          `json:"hostname"`
 		Detach                 bool            `json:"detach"`
 		Healthcheck            *Healthcheck    `json:"healthcheck"`
+		Readiness              *Readiness      `json:"readiness"`
 		ExportEnvironmentFiles json.RawMessage `json:"export-environment-file"`
+		Cache                  *Cache          `json:"cache"`
+		CPUShares              string          `json:"cpu-shares"`
+		Memory                 string          `json:"memory"`
+		ShmSize                string          `json:"shm-size"`
+		Ulimits                json.RawMessage `json:"ulimits"`
+		Privileged             bool            `json:"privileged"`
+		CapAdd                 json.RawMessage `json:"cap-add"`
+		Tmpfs                  json.RawMessage `json:"tmpfs"`
+		Devices                json.RawMessage `json:"devices"`
+		Platform               string          `json:"platform"`
+		Mounts                 []*Mount        `json:"mounts"`
+		Ports                  json.RawMessage `json:"ports"`
+		Caches                 json.RawMessage `json:"caches"`
+	}
+
+	Mount struct {
+		Type     string `json:"type"`
+		Source   string `json:"source"`
+		Target   string `json:"target"`
+		ReadOnly bool   `json:"read-only"`
 	}
 
 	Healthcheck struct {
@@ -31,6 +61,26 @@ type (
 		Retries     int           `json:"retries"`
 		StartPeriod util.Duration `json:"start-period"`
 		Timeout     util.Duration `json:"timeout"`
+		MaxWait     util.Duration `json:"max-wait"`
+	}
+
+	Readiness struct {
+		TCPPort    int           `json:"tcp-port"`
+		HTTP       *HTTPProbe    `json:"http"`
+		LogPattern string        `json:"log-pattern"`
+		Timeout    util.Duration `json:"timeout"`
+		Interval   util.Duration `json:"interval"`
+	}
+
+	HTTPProbe struct {
+		Port   int    `json:"port"`
+		Path   string `json:"path"`
+		Status int    `json:"status"`
+	}
+
+	Cache struct {
+		Inputs  json.RawMessage `json:"inputs"`
+		Outputs json.RawMessage `json:"outputs"`
 	}
 )
 
@@ -40,21 +90,85 @@ func (t *RunTask) Translate(name string) (config.Task, error) {
 		return nil, err
 	}
 
+	readiness, err := t.Readiness.Translate()
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := t.Cache.Translate()
+	if err != nil {
+		return nil, err
+	}
+
 	environment, err := util.UnmarshalStringList(t.Environment)
 	if err != nil {
 		return nil, err
 	}
 
+	inputs, err := util.UnmarshalStringList(t.Inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := util.UnmarshalStringList(t.Outputs)
+	if err != nil {
+		return nil, err
+	}
+
 	exportedEnvironmentFiles, err := util.UnmarshalStringList(t.ExportEnvironmentFiles)
 	if err != nil {
 		return nil, err
 	}
 
+	ulimits, err := util.UnmarshalStringList(t.Ulimits)
+	if err != nil {
+		return nil, err
+	}
+
+	capAdd, err := util.UnmarshalStringList(t.CapAdd)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpfs, err := util.UnmarshalStringList(t.Tmpfs)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := util.UnmarshalStringList(t.Devices)
+	if err != nil {
+		return nil, err
+	}
+
+	ports, err := util.UnmarshalStringList(t.Ports)
+	if err != nil {
+		return nil, err
+	}
+
+	caches, err := util.UnmarshalStringList(t.Caches)
+	if err != nil {
+		return nil, err
+	}
+
+	var mounts []*config.Mount
+	for _, mount := range t.Mounts {
+		mounts = append(mounts, mount.Translate())
+	}
+
 	meta := config.TaskMeta{
 		Name:                name,
 		Extends:             t.Extends,
 		Environment:         environment,
 		RequiredEnvironment: t.RequiredEnvironment,
+		Retries:             t.Retries,
+		RetryDelay:          t.RetryDelay.Duration,
+		RetryBackoff:        t.RetryBackoff,
+		RetryMaxDelay:       t.RetryMaxDelay.Duration,
+		RetryOnExitCodes:    t.RetryOnExitCodes,
+		Timeout:             t.Timeout.Duration,
+		AllowFailure:        t.AllowFailure,
+		Inputs:              inputs,
+		Outputs:             outputs,
 	}
 
 	return &config.RunTask{
@@ -69,10 +183,38 @@ func (t *RunTask) Translate(name string) (config.Task, error) {
 		Hostname:               t.Hostname,
 		Detach:                 t.Detach,
 		Healthcheck:            healthcheck,
+		Readiness:              readiness,
 		ExportEnvironmentFiles: exportedEnvironmentFiles,
+		Cache:                  cache,
+		CPUShares:              t.CPUShares,
+		Memory:                 t.Memory,
+		ShmSize:                t.ShmSize,
+		Ulimits:                ulimits,
+		Privileged:             t.Privileged,
+		CapAdd:                 capAdd,
+		Tmpfs:                  tmpfs,
+		Devices:                devices,
+		Platform:               t.Platform,
+		Mounts:                 mounts,
+		Ports:                  ports,
+		Caches:                 caches,
 	}, nil
 }
 
+func (m *Mount) Translate() *config.Mount {
+	mountType := m.Type
+	if mountType == "" {
+		mountType = config.MountTypeBind
+	}
+
+	return &config.Mount{
+		Type:     mountType,
+		Source:   m.Source,
+		Target:   m.Target,
+		ReadOnly: m.ReadOnly,
+	}
+}
+
 func (h *Healthcheck) Translate() (*config.Healthcheck, error) {
 	if h == nil {
 		return &config.Healthcheck{}, nil
@@ -84,5 +226,50 @@ func (h *Healthcheck) Translate() (*config.Healthcheck, error) {
 		Retries:     h.Retries,
 		StartPeriod: h.StartPeriod.Duration,
 		Timeout:     h.Timeout.Duration,
+		MaxWait:     h.MaxWait.Duration,
+	}, nil
+}
+
+func (r *Readiness) Translate() (*config.Readiness, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	var http *config.HTTPProbe
+	if r.HTTP != nil {
+		http = &config.HTTPProbe{
+			Port:   r.HTTP.Port,
+			Path:   r.HTTP.Path,
+			Status: r.HTTP.Status,
+		}
+	}
+
+	return &config.Readiness{
+		TCPPort:    r.TCPPort,
+		HTTP:       http,
+		LogPattern: r.LogPattern,
+		Timeout:    r.Timeout.Duration,
+		Interval:   r.Interval.Duration,
+	}, nil
+}
+
+func (c *Cache) Translate() (*config.Cache, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	inputs, err := util.UnmarshalStringList(c.Inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := util.UnmarshalStringList(c.Outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config.Cache{
+		Inputs:  inputs,
+		Outputs: outputs,
 	}, nil
 }
@@ -83,6 +83,7 @@ func (s *RunTaskSuite) TestTranslateHealthcheck(t sweet.T) {
 			Retries:     10,
 			StartPeriod: util.Duration{time.Second},
 			Timeout:     util.Duration{time.Second},
+			MaxWait:     util.Duration{Duration: time.Minute},
 		},
 	}
 
@@ -99,6 +100,123 @@ func (s *RunTaskSuite) TestTranslateHealthcheck(t sweet.T) {
 			Retries:     10,
 			StartPeriod: time.Second,
 			Timeout:     time.Second,
+			MaxWait:     time.Minute,
 		},
 	}))
 }
+
+func (s *RunTaskSuite) TestTranslateCache(t sweet.T) {
+	task := &RunTask{
+		Cache: &Cache{
+			Inputs:  json.RawMessage(`"go.sum"`),
+			Outputs: json.RawMessage(`["vendor", "bin/*"]`),
+		},
+	}
+
+	translated, err := task.Translate("run")
+	Expect(err).To(BeNil())
+	Expect(translated).To(Equal(&config.RunTask{
+		TaskMeta:    config.TaskMeta{Name: "run"},
+		Healthcheck: &config.Healthcheck{},
+		Cache: &config.Cache{
+			Inputs:  []string{"go.sum"},
+			Outputs: []string{"vendor", "bin/*"},
+		},
+	}))
+}
+
+func (s *RunTaskSuite) TestTranslateLimits(t sweet.T) {
+	task := &RunTask{
+		Memory:     "2g",
+		ShmSize:    "1g",
+		Ulimits:    json.RawMessage(`"nofile=1024"`),
+		Privileged: true,
+		CapAdd:     json.RawMessage(`["SYS_PTRACE", "NET_ADMIN"]`),
+		Tmpfs:      json.RawMessage(`"/run:size=64m"`),
+		Devices:    json.RawMessage(`["/dev/fuse"]`),
+		Platform:   "linux/arm64",
+	}
+
+	translated, err := task.Translate("run")
+	Expect(err).To(BeNil())
+	Expect(translated).To(Equal(&config.RunTask{
+		TaskMeta:    config.TaskMeta{Name: "run"},
+		Healthcheck: &config.Healthcheck{},
+		Memory:      "2g",
+		ShmSize:     "1g",
+		Ulimits:     []string{"nofile=1024"},
+		Privileged:  true,
+		CapAdd:      []string{"SYS_PTRACE", "NET_ADMIN"},
+		Tmpfs:       []string{"/run:size=64m"},
+		Devices:     []string{"/dev/fuse"},
+		Platform:    "linux/arm64",
+	}))
+}
+
+func (s *RunTaskSuite) TestTranslateMounts(t sweet.T) {
+	task := &RunTask{
+		Mounts: []*Mount{
+			&Mount{Source: "~/.m2", Target: "/root/.m2", ReadOnly: true},
+			&Mount{Type: "volume", Source: "gradle", Target: "/cache"},
+		},
+		Ports:  json.RawMessage(`"8080:80"`),
+		Caches: json.RawMessage(`["gomod", "npm"]`),
+	}
+
+	translated, err := task.Translate("run")
+	Expect(err).To(BeNil())
+	Expect(translated).To(Equal(&config.RunTask{
+		TaskMeta:    config.TaskMeta{Name: "run"},
+		Healthcheck: &config.Healthcheck{},
+		Mounts: []*config.Mount{
+			&config.Mount{Type: "bind", Source: "~/.m2", Target: "/root/.m2", ReadOnly: true},
+			&config.Mount{Type: "volume", Source: "gradle", Target: "/cache"},
+		},
+		Ports:  []string{"8080:80"},
+		Caches: []string{"gomod", "npm"},
+	}))
+}
+
+func (s *RunTaskSuite) TestTranslateReadiness(t sweet.T) {
+	task := &RunTask{
+		Readiness: &Readiness{
+			TCPPort:    5432,
+			HTTP:       &HTTPProbe{Port: 8080, Path: "/health", Status: 204},
+			LogPattern: "ready to accept connections",
+			Timeout:    util.Duration{Duration: time.Minute},
+			Interval:   util.Duration{Duration: time.Second},
+		},
+	}
+
+	translated, err := task.Translate("run")
+	Expect(err).To(BeNil())
+	Expect(translated).To(Equal(&config.RunTask{
+		TaskMeta:    config.TaskMeta{Name: "run"},
+		Healthcheck: &config.Healthcheck{},
+		Readiness: &config.Readiness{
+			TCPPort:    5432,
+			HTTP:       &config.HTTPProbe{Port: 8080, Path: "/health", Status: 204},
+			LogPattern: "ready to accept connections",
+			Timeout:    time.Minute,
+			Interval:   time.Second,
+		},
+	}))
+}
+
+func (s *RunTaskSuite) TestTranslateArtifacts(t sweet.T) {
+	task := &RunTask{
+		Inputs:  json.RawMessage(`"build:bin/app"`),
+		Outputs: json.RawMessage(`["dist", "report.xml"]`),
+	}
+
+	translated, err := task.Translate("run")
+	Expect(err).To(BeNil())
+	Expect(translated).To(Equal(&config.RunTask{
+		TaskMeta: config.TaskMeta{
+			Name:    "run",
+			Inputs:  []string{"build:bin/app"},
+			Outputs: []string{"dist", "report.xml"},
+		},
+		Healthcheck: &config.Healthcheck{},
+	}))
+}
@@ -12,18 +12,31 @@ type (
 	Stage struct {
 		Name        string            `json:"name"`
 		Disabled    string            `json:"disabled"`
+		If          string            `json:"if"`
 		BeforeStage string            `json:"before-stage"`
 		AfterStage  string            `json:"after-stage"`
 		Tasks       []json.RawMessage `json:"tasks"`
 		RunMode     string            `json:"run-mode"`
 		Parallel    bool              `json:"parallel"`
+		MaxParallel int               `json:"max-parallel"`
+		FailFast    bool              `json:"fail-fast"`
+		Timeout     util.Duration     `json:"timeout"`
 		Environment json.RawMessage   `json:"environment"`
 	}
 
 	StageTask struct {
-		Name        string          `json:"name"`
-		Disabled    string          `json:"disabled"`
-		Environment json.RawMessage `json:"environment"`
+		Name             string          `json:"name"`
+		Disabled         string          `json:"disabled"`
+		If               string          `json:"if"`
+		Environment      json.RawMessage `json:"environment"`
+		Needs            json.RawMessage `json:"needs"`
+		Matrix           json.RawMessage `json:"matrix"`
+		AllowFailure     bool            `json:"allow-failure"`
+		Retries          *int            `json:"retries"`
+		RetryDelay       util.Duration   `json:"retry-delay"`
+		RetryBackoff     float64         `json:"retry-backoff"`
+		RetryMaxDelay    util.Duration   `json:"retry-max-delay"`
+		RetryOnExitCodes []int           `json:"retry-on-exit-codes"`
 	}
 )
 
@@ -51,11 +64,15 @@ func (s *Stage) Translate() (*config.Stage, error) {
 	return &config.Stage{
 		Name:        s.Name,
 		Disabled:    s.Disabled,
+		If:          s.If,
 		BeforeStage: s.BeforeStage,
 		AfterStage:  s.AfterStage,
 		Tasks:       stageTasks,
 		RunMode:     runMode,
 		Parallel:    s.Parallel,
+		MaxParallel: s.MaxParallel,
+		FailFast:    s.FailFast,
+		Timeout:     s.Timeout.Duration,
 		Environment: environment,
 	}, nil
 }
@@ -91,9 +108,74 @@ func unmarshalStageTask(raw json.RawMessage) (*config.StageTask, error) {
 		return nil, err
 	}
 
+	needs, err := util.UnmarshalStringList(stageTask.Needs)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix, err := translateMatrix(stageTask.Matrix)
+	if err != nil {
+		return nil, err
+	}
+
 	return &config.StageTask{
-		Name:        stageTask.Name,
-		Disabled:    stageTask.Disabled,
-		Environment: environment,
+		Name:             stageTask.Name,
+		Disabled:         stageTask.Disabled,
+		If:               stageTask.If,
+		Environment:      environment,
+		Needs:            needs,
+		Matrix:           matrix,
+		AllowFailure:     stageTask.AllowFailure,
+		Retries:          stageTask.Retries,
+		RetryDelay:       stageTask.RetryDelay.Duration,
+		RetryBackoff:     stageTask.RetryBackoff,
+		RetryMaxDelay:    stageTask.RetryMaxDelay.Duration,
+		RetryOnExitCodes: stageTask.RetryOnExitCodes,
 	}, nil
 }
+
+func translateMatrix(raw json.RawMessage) (*config.Matrix, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	matrix := &config.Matrix{
+		Variables: map[string][]string{},
+	}
+
+	for name, value := range fields {
+		if name == "exclude" {
+			if err := json.Unmarshal(value, &matrix.Exclude); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		values, err := util.UnmarshalStringList(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(values) == 0 {
+			return nil, fmt.Errorf("matrix variable %s has no values", name)
+		}
+
+		matrix.Variables[name] = values
+	}
+
+	for _, exclude := range matrix.Exclude {
+		for name := range exclude {
+			if _, ok := matrix.Variables[name]; !ok {
+				return nil, fmt.Errorf("matrix exclude refers to unknown variable %s", name)
+			}
+		}
+	}
+
+	return matrix, nil
+}
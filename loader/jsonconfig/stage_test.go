@@ -2,6 +2,7 @@ package jsonconfig
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/aphistic/sweet"
 	"github.com/ij-build/ij/config"
@@ -21,6 +22,8 @@ func (s *StageSuite) TestTranslate(t sweet.T) {
 			json.RawMessage(`{"name": "t3", "environment": ["X=4", "Y=5"]}`),
 		},
 		Parallel:    true,
+		MaxParallel: 2,
+		FailFast:    true,
 		Environment: json.RawMessage(`["X=1", "Y=2", "Z=3"]`),
 	}
 
@@ -37,6 +40,8 @@ func (s *StageSuite) TestTranslate(t sweet.T) {
 		},
 		RunMode:     config.RunModeOnSuccess,
 		Parallel:    true,
+		MaxParallel: 2,
+		FailFast:    true,
 		Environment: []string{"X=1", "Y=2", "Z=3"},
 	}))
 }
@@ -58,7 +63,7 @@ func (s *StageSuite) TestTranslateStringLists(t sweet.T) {
 func (s *StageSuite) TestTranslateNestedStringLists(t sweet.T) {
 	stage := &Stage{
 		Tasks: []json.RawMessage{
-			json.RawMessage(`{"name": "t1", "environment": "X=1"}`),
+			json.RawMessage(`{"name": "t1", "environment": "X=1", "needs": "t0", "allow-failure": true}`),
 		},
 	}
 
@@ -66,7 +71,12 @@ func (s *StageSuite) TestTranslateNestedStringLists(t sweet.T) {
 	Expect(err).To(BeNil())
 	Expect(translated).To(Equal(&config.Stage{
 		Tasks: []*config.StageTask{
-			&config.StageTask{Name: "t1", Environment: []string{"X=1"}},
+			&config.StageTask{
+				Name:         "t1",
+				Environment:  []string{"X=1"},
+				Needs:        []string{"t0"},
+				AllowFailure: true,
+			},
 		},
 		RunMode: config.RunModeOnSuccess,
 	}))
@@ -94,3 +104,87 @@ func (s *StageSuite) TestTranslateRunMode(t sweet.T) {
 		}))
 	}
 }
+
+func (s *StageSuite) TestTranslateRetryPolicy(t sweet.T) {
+	stage := &Stage{
+		Tasks: []json.RawMessage{
+			json.RawMessage(`{"name": "t1", "retries": 3, "retry-delay": "5s", "retry-backoff": 1.5, "retry-max-delay": "1m", "retry-on-exit-codes": [1]}`),
+			json.RawMessage(`{"name": "t2", "retries": 0}`),
+		},
+	}
+
+	var (
+		three = 3
+		zero  = 0
+	)
+
+	translated, err := stage.Translate()
+	Expect(err).To(BeNil())
+	Expect(translated).To(Equal(&config.Stage{
+		Tasks: []*config.StageTask{
+			&config.StageTask{
+				Name:             "t1",
+				Retries:          &three,
+				RetryDelay:       time.Second * 5,
+				RetryBackoff:     1.5,
+				RetryMaxDelay:    time.Minute,
+				RetryOnExitCodes: []int{1},
+			},
+			&config.StageTask{
+				Name:    "t2",
+				Retries: &zero,
+			},
+		},
+		RunMode: config.RunModeOnSuccess,
+	}))
+}
+
+func (s *StageSuite) TestTranslateMatrix(t sweet.T) {
+	stage := &Stage{
+		Tasks: []json.RawMessage{
+			json.RawMessage(`{"name": "t1", "matrix": {"go": ["1.20", "1.21"], "db": "pg14", "exclude": [{"go": "1.20"}]}}`),
+		},
+	}
+
+	translated, err := stage.Translate()
+	Expect(err).To(BeNil())
+	Expect(translated).To(Equal(&config.Stage{
+		Tasks: []*config.StageTask{
+			&config.StageTask{
+				Name: "t1",
+				Matrix: &config.Matrix{
+					Variables: map[string][]string{
+						"go": []string{"1.20", "1.21"},
+						"db": []string{"pg14"},
+					},
+					Exclude: []map[string]string{
+						map[string]string{"go": "1.20"},
+					},
+				},
+			},
+		},
+		RunMode: config.RunModeOnSuccess,
+	}))
+}
+
+func (s *StageSuite) TestTranslateMatrixUnknownExclude(t sweet.T) {
+	stage := &Stage{
+		Tasks: []json.RawMessage{
+			json.RawMessage(`{"name": "t1", "matrix": {"go": ["1.20", "1.21"], "exclude": [{"db": "pg14"}]}}`),
+		},
+	}
+
+	_, err := stage.Translate()
+	Expect(err).To(MatchError("matrix exclude refers to unknown variable db"))
+}
+
+func (s *StageSuite) TestTranslateMatrixNoValues(t sweet.T) {
+	stage := &Stage{
+		Tasks: []json.RawMessage{
+			json.RawMessage(`{"name": "t1", "matrix": {"go": []}}`),
+		},
+	}
+
+	_, err := stage.Translate()
+	Expect(err).To(MatchError("matrix variable go has no values"))
+}
@@ -46,6 +46,7 @@ func translateTask(
 
 	structMap := map[string]Task{
 		"build":  &BuildTask{},
+		"host":   &HostTask{},
 		"plan":   &PlanTask{},
 		"push":   &PushTask{},
 		"remove": &RemoveTask{},
@@ -58,6 +58,7 @@ func (s *LoaderSuite) TestLoad(t sweet.T) {
 		Metaplans: map[string][]string{
 			"default": []string{"a", "b"},
 		},
+		Caches: map[string]*config.DependencyCache{},
 	}))
 }
 
@@ -81,6 +82,7 @@ func (s *LoaderSuite) TestLoadFromURL(t sweet.T) {
 		Tasks:       map[string]config.Task{},
 		Plans:       map[string]*config.Plan{},
 		Metaplans:   map[string][]string{},
+		Caches:      map[string]*config.DependencyCache{},
 	}))
 }
 
@@ -96,6 +98,7 @@ func (s *LoaderSuite) TestLoadExtends(t sweet.T) {
 		Tasks:       map[string]config.Task{},
 		Plans:       map[string]*config.Plan{},
 		Metaplans:   map[string][]string{},
+		Caches:      map[string]*config.DependencyCache{},
 	}))
 }
 
@@ -26,3 +26,20 @@ func (f *LoggerFactory) Logger(prefix string, writePrefix bool) (Logger, error)
 
 	return f.logProcessor.Logger(outFile, errFile, writePrefix), nil
 }
+
+// FileLogger creates a logger which writes to the log files of the given
+// prefix without echoing to the console.
+func (f *LoggerFactory) FileLogger(prefix string, writePrefix bool) (Logger, error) {
+	outFile, errFile, err := f.fileFactory(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.logProcessor.FileLogger(outFile, errFile, writePrefix), nil
+}
+
+// Processor returns the processor which writes the messages of the loggers
+// created by this factory.
+func (f *LoggerFactory) Processor() Processor {
+	return f.logProcessor
+}
@@ -16,6 +16,7 @@ type (
 		writePrefix bool
 		stream      io.Writer
 		file        io.Writer
+		control     func()
 	}
 
 	LogLevel int
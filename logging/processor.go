@@ -16,6 +16,9 @@ type (
 		Start()
 		Shutdown()
 		Logger(outFile, errFile io.WriteCloser, writePrefix bool) Logger
+		FileLogger(outFile, errFile io.WriteCloser, writePrefix bool) Logger
+		Pause()
+		Resume()
 	}
 
 	processor struct {
@@ -26,11 +29,18 @@ type (
 		errStream   io.Writer
 		colorPicker ColorPicker
 		queue       chan *message
+		paused      bool
+		held        []heldText
 		handles     []io.Closer
 		mutex       sync.Mutex
 		once        sync.Once
 		wg          sync.WaitGroup
 	}
+
+	heldText struct {
+		stream io.Writer
+		text   string
+	}
 )
 
 const (
@@ -103,14 +113,50 @@ func (p *processor) Logger(outFile, errFile io.WriteCloser, writePrefix bool) Lo
 	)
 }
 
+// FileLogger creates a logger which writes only to the given files and
+// not to the output streams of the processor.
+func (p *processor) FileLogger(outFile, errFile io.WriteCloser, writePrefix bool) Logger {
+	p.mutex.Lock()
+	p.handles = append(p.handles, outFile, errFile)
+	p.mutex.Unlock()
+
+	return newLogger(
+		p,
+		NilWriter,
+		outFile,
+		NilWriter,
+		errFile,
+		writePrefix,
+	)
+}
+
+// Pause holds back the output of messages to the output streams until
+// Resume is called. Messages are still written to files while paused. All
+// messages logged before the call are output before it returns.
+func (p *processor) Pause() {
+	p.queue <- &message{control: func() { p.paused = true }}
+}
+
+// Resume outputs the messages held back since the call to Pause and
+// stops holding back further messages.
+func (p *processor) Resume() {
+	p.queue <- &message{control: p.flush}
+}
+
 func (p *processor) enqueue(message *message) {
 	p.queue <- message
 }
 
 func (p *processor) process() {
 	defer p.wg.Done()
+	defer p.flush()
 
 	for message := range p.queue {
+		if message.control != nil {
+			message.control()
+			continue
+		}
+
 		text := message.Text()
 
 		if p.colorize {
@@ -136,8 +182,10 @@ func (p *processor) process() {
 			message.Text(),
 		)
 
-		if _, err := io.Copy(message.stream, bytes.NewReader([]byte(streamText))); err != nil {
-			EmergencyLog("error: failed to write log: %s", err.Error())
+		if p.paused {
+			p.held = append(p.held, heldText{message.stream, streamText})
+		} else {
+			writeText(message.stream, streamText)
 		}
 
 		if _, err := io.Copy(message.file, bytes.NewReader([]byte(fileText))); err != nil {
@@ -146,6 +194,15 @@ func (p *processor) process() {
 	}
 }
 
+func (p *processor) flush() {
+	for _, held := range p.held {
+		writeText(held.stream, held.text)
+	}
+
+	p.paused = false
+	p.held = nil
+}
+
 func (p *processor) buildPrefix(prefix *Prefix) string {
 	if prefix == nil {
 		return ""
@@ -168,6 +225,12 @@ func (p *processor) buildPrefixForFile(prefix *Prefix, writePrefix bool) string
 //
 // Helpers
 
+func writeText(stream io.Writer, text string) {
+	if _, err := io.Copy(stream, bytes.NewReader([]byte(text))); err != nil {
+		EmergencyLog("error: failed to write log: %s", err.Error())
+	}
+}
+
 func getOutStream(quiet bool) io.Writer {
 	if quiet {
 		return &nilWriter{}
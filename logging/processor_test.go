@@ -113,9 +113,76 @@ func (s *ProcessorSuite) TestNonVerbose(t sweet.T) {
 	Expect(errFile.String()).To(BeEmpty())
 }
 
+func (s *ProcessorSuite) TestFileLogger(t sweet.T) {
+	var (
+		clock     = glock.NewMockClock()
+		outStream = &bytes.Buffer{}
+		errStream = &bytes.Buffer{}
+	)
+
+	processor := newProcessor(false, false, clock, outStream, errStream)
+	processor.Start()
+
+	clock.SetCurrent(time.Unix(1535293743, 123000000))
+
+	var (
+		outFile = &logWriter{}
+		errFile = &logWriter{}
+		logger  = processor.FileLogger(outFile, errFile, false)
+	)
+
+	logger.Info(NewPrefix("x"), "> %s", "a")
+	logger.Error(NewPrefix("x"), "> %s", "b")
+	processor.Shutdown()
+
+	Expect(outFile.String()).To(Equal("2018-08-26 14:29:03.123 | > a\n"))
+	Expect(errFile.String()).To(Equal("2018-08-26 14:29:03.123 | > b\n"))
+	Expect(outStream.String()).To(BeEmpty())
+	Expect(errStream.String()).To(BeEmpty())
+}
+
 //
 // Helpers
 
+func (s *ProcessorSuite) TestPause(t sweet.T) {
+	var (
+		clock     = glock.NewMockClock()
+		outStream = &bytes.Buffer{}
+		errStream = &bytes.Buffer{}
+	)
+
+	processor := newProcessor(false, false, clock, outStream, errStream)
+	processor.Start()
+
+	clock.SetCurrent(time.Unix(1535293743, 123000000))
+
+	var (
+		outFile = &logWriter{}
+		errFile = &logWriter{}
+		logger  = processor.Logger(outFile, errFile, false)
+	)
+
+	logger.Info(nil, "> %s", "a")
+	processor.Pause()
+	Expect(outStream.String()).To(Equal("14:29:03 > a\n"))
+
+	logger.Info(nil, "> %s", "b")
+	logger.Error(nil, "> %s", "c")
+
+	// Pausing again waits for the preceding messages
+	processor.Pause()
+	Expect(outStream.String()).To(Equal("14:29:03 > a\n"))
+	Expect(errStream.String()).To(BeEmpty())
+	Expect(outFile.String()).To(ContainSubstring("> b"))
+
+	processor.Resume()
+	logger.Info(nil, "> %s", "d")
+	processor.Shutdown()
+
+	Expect(outStream.String()).To(Equal("14:29:03 > a\n14:29:03 > b\n14:29:03 > d\n"))
+	Expect(errStream.String()).To(Equal("14:29:03 > c\n"))
+}
+
 type logWriter struct {
 	bytes.Buffer
 }
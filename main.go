@@ -37,14 +37,33 @@ func newRunOptions(cmd *kingpin.CmdClause) *options.RunOptions {
 
 	cmd.Arg("plans", "The name of the plans to execute.").Default("default").StringsVar(&opts.Plans)
 	cmd.Flag("cpu-shares", "The amount of cpu shares to give to each container.").Short('c').StringVar(&opts.CPUShares)
+	cmd.Flag("debug-on-failure", "Open a shell in a snapshot of the container of a failed task.").Default("false").BoolVar(&opts.DebugOnFailure)
+	cmd.Flag("dry-run", "Print the commands each task would run without running them.").Default("false").BoolVar(&opts.DryRun)
+	cmd.Flag("fail-fast", "Cancel the remaining tasks of a parallel stage once one fails.").Default("false").BoolVar(&opts.FailFast)
 	cmd.Flag("force-sequential", "Disable parallel execution.").Default("false").BoolVar(&opts.ForceSequential)
+	cmd.Flag("from-stage", "Skip the stages of each plan preceding the given stage.").PlaceHolder("STAGE").StringVar(&opts.FromStage)
+	cmd.Flag("jobs", "The maximum number of tasks to run concurrently. 0 for no limit.").Short('j').Default("0").IntVar(&opts.Jobs)
 	cmd.Flag("healthcheck-interval", "The interval between service container healthchecks.").Default("5s").DurationVar(&opts.HealthcheckInterval)
 	cmd.Flag("keep-workspace", "Do not delete the workspace").Short('k').Default("false").BoolVar(&opts.KeepWorkspace)
 	cmd.Flag("login", "Login to docker registries before running.").Default("false").BoolVar(&opts.Login)
 	cmd.Flag("memory", "The amount of memory to give each container.").Short('m').StringVar(&opts.Memory)
+	cmd.Flag("no-cache", "Run tasks without restoring or storing cached outputs.").Default("false").BoolVar(&opts.NoCache)
+	cmd.Flag("only", "Run only the tasks matching the given pattern.").PlaceHolder("PATTERN").StringsVar(&opts.Only)
+	cmd.Flag("param", "Plan parameter values.").PlaceHolder("NAME=VALUE").StringMapVar(&opts.Params)
+	cmd.Flag("resume", "Resume a failed run, skipping the tasks that succeeded.").PlaceHolder("RUN-ID").StringVar(&opts.Resume)
+	cmd.Flag("runtime", "The container runtime (docker, podman).").EnumVar(&opts.Runtime, "docker", "podman")
+	cmd.Flag("service-logs", "When to echo the output of detached containers (never, always, on-failure).").Default("never").EnumVar(&opts.ServiceLogs, "never", "always", "on-failure")
+	cmd.Flag("skip", "Skip the tasks matching the given pattern.").PlaceHolder("PATTERN").StringsVar(&opts.Skip)
 	cmd.Flag("timeout", "Maximum amount of time a plan can run. 0 to disable.").Default("15m").DurationVar(&opts.PlanTimeout)
 	cmd.Flag("ssh-identity", "Enable ssh-agent for the given identities.").StringsVar(&opts.SSHIdentities)
 	cmd.Flag("ssh-agent-container", "Start an ssh-agent inside of a container.").BoolVar(&opts.EnableContainerSSHAgent)
+	cmd.Flag("workspace-mode", "How the workspace is shared with containers (bind, volume).").Default("bind").EnumVar(&opts.WorkspaceMode, "bind", "volume")
+	return opts
+}
+
+func newCacheOptions(cmd *kingpin.CmdClause) *options.CacheOptions {
+	opts := &options.CacheOptions{}
+	cmd.Arg("caches", "The names of the caches to prune. Defaults to all caches.").StringsVar(&opts.Caches)
 	return opts
 }
 
@@ -66,12 +85,16 @@ func main() {
 
 func runMain() error {
 	app := kingpin.New("ij", "IJ is a build tool using Docker containers.").Version(consts.Version)
+	cache := app.Command("cache", "Manage the volumes backing dependency caches.")
+	_ = cache.Command("list", "List the cache volumes of the project.")
+	cachePrune := cache.Command("prune", "Remove the cache volumes of the project.")
 	clean := app.Command("clean", "Remove exported files.")
 	_ = app.Command("login", "Login to docker registries.")
 	_ = app.Command("logout", "Logout of docker registries.")
 	_ = app.Command("rotate-logs", "Trim old run logs the .ij directory.")
 	run := app.Command("run", "Run a plan or metaplan.").Default()
 	_ = app.Command("show-config", "Show the effective config after resolving parents.")
+	_ = app.Command("show-params", "Show the parameters declared by each plan.")
 
 	projectDir, err := os.Getwd()
 	if err != nil {
@@ -79,6 +102,7 @@ func runMain() error {
 	}
 
 	appOptions := newSharedOptions(app, projectDir)
+	cacheOptions := newCacheOptions(cachePrune)
 	cleanOptions := newCleanOptions(clean)
 	runOptions := newRunOptions(run)
 
@@ -96,7 +120,10 @@ func runMain() error {
 		Options: &config.Options{
 			SSHIdentities:       runOptions.SSHIdentities,
 			ForceSequential:     runOptions.ForceSequential,
+			FailFast:            runOptions.FailFast,
+			Jobs:                runOptions.Jobs,
 			HealthcheckInterval: runOptions.HealthcheckInterval,
+			Runtime:             runOptions.Runtime,
 		},
 		EnvironmentFiles: appOptions.EnvFiles,
 	}
@@ -110,6 +137,7 @@ func runMain() error {
 		command,
 		config,
 		appOptions,
+		cacheOptions,
 		cleanOptions,
 		runOptions,
 	)
@@ -3,63 +3,37 @@ package network
 import (
 	"context"
 
-	"github.com/ij-build/ij/command"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 )
 
 type Network struct {
-	ctx    context.Context
-	runID  string
-	logger logging.Logger
-	runner command.Runner
+	ctx     context.Context
+	runID   string
+	runtime runtime.ContainerRuntime
+	logger  logging.Logger
 }
 
 func NewNetwork(
 	ctx context.Context,
 	runID string,
+	runtime runtime.ContainerRuntime,
 	logger logging.Logger,
-) (*Network, error) {
-	return newNetwork(
-		ctx,
-		runID,
-		logger,
-		command.NewRunner(logger),
-	)
-}
-
-func newNetwork(
-	ctx context.Context,
-	runID string,
-	logger logging.Logger,
-	runner command.Runner,
 ) (*Network, error) {
 	logger.Info(
 		nil,
 		"Creating network",
 	)
 
-	args := []string{
-		"docker",
-		"network",
-		"create",
-		runID,
-	}
-
-	_, _, err := runner.RunForOutput(
-		ctx,
-		args,
-		nil,
-	)
-
-	if err != nil {
+	if err := runtime.CreateNetwork(ctx, runID); err != nil {
 		return nil, err
 	}
 
 	return &Network{
-		ctx:    ctx,
-		runID:  runID,
-		logger: logger,
-		runner: runner,
+		ctx:     ctx,
+		runID:   runID,
+		runtime: runtime,
+		logger:  logger,
 	}, nil
 }
 
@@ -69,20 +43,7 @@ func (n *Network) Teardown() {
 		"Removing network",
 	)
 
-	args := []string{
-		"docker",
-		"network",
-		"rm",
-		n.runID,
-	}
-
-	_, _, err := n.runner.RunForOutput(
-		context.Background(),
-		args,
-		nil,
-	)
-
-	if err != nil {
+	if err := n.runtime.RemoveNetwork(context.Background(), n.runID); err != nil {
 		n.logger.Error(
 			nil,
 			"Failed to remove network: %s",
@@ -1,52 +1,43 @@
 package network
 
-//go:generate go-mockgen -f github.com/ij-build/ij/command -i Runner -o mock_runner_test.go
-
 import (
 	"context"
 	"fmt"
-	"io"
 
 	"github.com/aphistic/sweet"
-	. "github.com/efritz/go-mockgen/matchers"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 	. "github.com/onsi/gomega"
 )
 
 type NetworkSuite struct{}
 
 func (s *NetworkSuite) TestSetupTeardown(t sweet.T) {
-	runner := NewMockRunner()
+	runtime := runtime.NewFakeRuntime()
 
-	network, err := newNetwork(
+	network, err := NewNetwork(
 		context.Background(),
 		"abcdef0",
+		runtime,
 		logging.NilLogger,
-		runner,
 	)
 
 	Expect(err).To(BeNil())
-	Expect(runner.RunForOutputFunc).To(BeCalledOnce())
-	Expect(runner.RunForOutputFunc).To(BeCalledWith(BeAnything(), []string{
-		"docker", "network", "create", "abcdef0",
-	}, BeAnything()))
+	Expect(runtime.Networks).To(HaveKey("abcdef0"))
 
 	network.Teardown()
-	Expect(runner.RunForOutputFunc).To(BeCalledN(2))
-	Expect(runner.RunForOutputFunc).To(BeCalledWith(BeAnything(), []string{
-		"docker", "network", "rm", "abcdef0",
-	}, BeAnything()))
+	Expect(runtime.Networks).To(BeEmpty())
 }
 
 func (s *NetworkSuite) TestSetupError(t sweet.T) {
-	runner := NewMockRunner()
-	runner.RunForOutputFunc.SetDefaultReturn("", "", fmt.Errorf("utoh"))
+	runtime := runtime.NewFakeRuntime()
+	runtime.Errors["CreateNetwork"] = fmt.Errorf("utoh")
 
-	_, err := newNetwork(
+	_, err := NewNetwork(
 		context.Background(),
 		"abcdef0",
+		runtime,
 		logging.NilLogger,
-		runner,
 	)
 
 	Expect(err).To(MatchError("utoh"))
@@ -56,22 +47,11 @@ func (s *NetworkSuite) TestCancelSetup(t sweet.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	runner := NewMockRunner()
-	runner.RunForOutputFunc.SetDefaultHook(func(ctx context.Context, args []string, _ io.ReadCloser) (string, string, error) {
-		select {
-		case <-ctx.Done():
-			return "", "", fmt.Errorf("context canceled")
-		default:
-		}
-
-		return "", "", nil
-	})
-
-	network, err := newNetwork(
+	network, err := NewNetwork(
 		ctx,
 		"abcdef0",
+		runtime.NewFakeRuntime(),
 		logging.NilLogger,
-		runner,
 	)
 
 	Expect(err).To(MatchError("context canceled"))
@@ -80,24 +60,18 @@ func (s *NetworkSuite) TestCancelSetup(t sweet.T) {
 
 func (s *NetworkSuite) TestCancelDuringTeardown(t sweet.T) {
 	ctx, cancel := context.WithCancel(context.Background())
-	runner := NewMockRunner()
+	runtime := runtime.NewFakeRuntime()
 
-	network, err := newNetwork(
+	network, err := NewNetwork(
 		ctx,
 		"abcdef0",
+		runtime,
 		logging.NilLogger,
-		runner,
 	)
 
 	Expect(err).To(BeNil())
 
 	cancel()
 	network.Teardown()
-	Expect(runner.RunForOutputFunc).To(BeCalledN(2))
-
-	select {
-	case <-runner.RunForOutputFunc.History()[1].Arg0.Done():
-		t.Fail()
-	default:
-	}
+	Expect(runtime.Networks).To(BeEmpty())
 }
@@ -0,0 +1,5 @@
+package options
+
+type CacheOptions struct {
+	Caches []string
+}
@@ -8,12 +8,25 @@ import (
 type RunOptions struct {
 	Plans                   []string
 	CPUShares               string
+	DebugOnFailure          bool
+	DryRun                  bool
+	FailFast                bool
+	FromStage               string
 	ForceSequential         bool
 	HealthcheckInterval     time.Duration
+	Jobs                    int
 	KeepWorkspace           bool
 	Login                   bool
 	Memory                  string
+	NoCache                 bool
+	Only                    []string
+	Params                  map[string]string
 	PlanTimeout             time.Duration
+	Resume                  string
+	Runtime                 string
+	ServiceLogs             string
+	WorkspaceMode           string
+	Skip                    []string
 	SSHIdentities           []string
 	EnableContainerSSHAgent bool
 	Context                 context.Context
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/ij-build/ij/logging"
@@ -15,6 +16,44 @@ type FilePair struct {
 	Dest string
 }
 
+// Match returns the sorted paths of all files within the root directory
+// that match one of the given patterns. Directories that match a pattern
+// contribute each file beneath them.
+func Match(root string, patterns []string) ([]string, error) {
+	files := map[string]struct{}{}
+
+	err := runOnPatterns(patterns, root, logging.NilLogger, func(pair FilePair) error {
+		path, err := sanitize(pair.Src, root)
+		if err != nil {
+			return err
+		}
+
+		return filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.Mode().IsRegular() {
+				files[path] = struct{}{}
+			}
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []string{}
+	for path := range files {
+		matches = append(matches, path)
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
 func runOnPatterns(
 	patterns []string,
 	root string,
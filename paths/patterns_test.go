@@ -160,3 +160,44 @@ func (s *PatternsSuite) TestSplitPathExplicitDestination(t sweet.T) {
 	Expect(src).To(Equal("a/b/c"))
 	Expect(dest).To(Equal("x/y/z"))
 }
+
+func (s *PatternsSuite) TestMatch(t sweet.T) {
+	name := buildTempDir(buildEmptyFiles([]string{
+		"a.txt",
+		"b.txt",
+		"x/a.txt",
+		"x/y/b.txt",
+		"z/c.txt",
+	}))
+
+	defer os.RemoveAll(name)
+
+	matches, err := Match(name, []string{
+		"x",
+		"*.txt",
+		"a.txt",
+	})
+
+	Expect(err).To(BeNil())
+	Expect(matches).To(Equal([]string{
+		filepath.Join(name, "a.txt"),
+		filepath.Join(name, "b.txt"),
+		filepath.Join(name, "x", "a.txt"),
+		filepath.Join(name, "x", "y", "b.txt"),
+	}))
+}
+
+func (s *PatternsSuite) TestMatchOutsideRoot(t sweet.T) {
+	name := buildTempDir(buildEmptyFiles([]string{
+		"x/a.txt",
+		"y.txt",
+	}))
+
+	defer os.RemoveAll(name)
+
+	_, err := Match(filepath.Join(name, "x"), []string{
+		"../y.txt",
+	})
+
+	Expect(err).NotTo(BeNil())
+}
@@ -57,6 +57,41 @@ func (t *Transferer) Export(patterns, blacklistPatterns []string) error {
 	})
 }
 
+// ListImports returns the paths (relative to the project and workspace
+// directories) that Import would copy, without copying any files.
+func (t *Transferer) ListImports(patterns, blacklistPatterns []string) ([]FilePair, error) {
+	blacklist, err := constructBlacklist(t.project, blacklistPatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := []FilePair{}
+	err = runOnPatterns(patterns, t.project, t.logger, func(pair FilePair) error {
+		if _, ok := blacklist[pair.Src]; ok {
+			return nil
+		}
+
+		src, err := filepath.Rel(t.project, pair.Src)
+		if err != nil {
+			return err
+		}
+
+		dest, err := filepath.Rel(t.project, pair.Dest)
+		if err != nil {
+			return err
+		}
+
+		pairs = append(pairs, FilePair{Src: src, Dest: dest})
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pairs, nil
+}
+
 func (t *Transferer) transferPath(
 	rawSrc string,
 	rawDest string,
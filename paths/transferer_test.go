@@ -76,6 +76,51 @@ func (s *TransfererSuite) TestImport(t sweet.T) {
 	Expect(os.IsNotExist(err)).To(BeTrue())
 }
 
+func (s *TransfererSuite) TestListImports(t sweet.T) {
+	srcRoot := buildTempDir(buildEmptyFiles([]string{
+		"bar/b.txt",
+		"baz/c.txt",
+		"foo/a.txt",
+		"foo/junit.xml",
+		"quux/unique.txt",
+	}))
+
+	defer os.RemoveAll(srcRoot)
+
+	destRoot := buildTempDir(buildEmptyFiles([]string{}))
+	defer os.RemoveAll(destRoot)
+
+	transferer := NewTransferer(
+		srcRoot,
+		destRoot,
+		filepath.Join(destRoot, "workspace"),
+		logging.NilLogger,
+	)
+
+	patterns := []string{
+		"foo/*",
+		"b*",
+		"quux/unique.txt:moved.txt",
+	}
+
+	blacklistPatterns := []string{
+		"baz",
+		"**/junit*.xml",
+	}
+
+	pairs, err := transferer.ListImports(patterns, blacklistPatterns)
+	Expect(err).To(BeNil())
+	Expect(pairs).To(ConsistOf(
+		FilePair{Src: "foo/a.txt", Dest: "foo/a.txt"},
+		FilePair{Src: "bar", Dest: "bar"},
+		FilePair{Src: "quux/unique.txt", Dest: "moved.txt"},
+	))
+
+	// Nothing moved
+	_, err = os.Stat(filepath.Join(destRoot, "workspace"))
+	Expect(os.IsNotExist(err)).To(BeTrue())
+}
+
 func (s *TransfererSuite) TestImportOutsideProjectPath(t sweet.T) {
 	srcRoot := buildTempDir(nil)
 	defer os.RemoveAll(srcRoot)
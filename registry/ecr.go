@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/ij-build/ij/command"
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/environment"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 )
 
 type (
@@ -17,7 +17,7 @@ type (
 		logger   logging.Logger
 		env      environment.Environment
 		registry *config.ECRRegistry
-		runner   command.Runner
+		runtime  runtime.ContainerRuntime
 	}
 
 	awsCredentials struct {
@@ -39,29 +39,14 @@ func NewECRLogin(
 	logger logging.Logger,
 	env environment.Environment,
 	registry *config.ECRRegistry,
-) Login {
-	return newECRLogin(
-		ctx,
-		logger,
-		env,
-		registry,
-		command.NewRunner(logger),
-	)
-}
-
-func newECRLogin(
-	ctx context.Context,
-	logger logging.Logger,
-	env environment.Environment,
-	registry *config.ECRRegistry,
-	runner command.Runner,
+	runtime runtime.ContainerRuntime,
 ) Login {
 	return &ecrLogin{
 		ctx:      ctx,
 		logger:   logger,
 		env:      env,
 		registry: registry,
-		runner:   runner,
+		runtime:  runtime,
 	}
 }
 
@@ -87,7 +72,7 @@ func (l *ecrLogin) Login() error {
 
 	token, err := getAWSToken(
 		l.ctx,
-		l.runner,
+		l.runtime,
 		credentials,
 	)
 
@@ -95,9 +80,8 @@ func (l *ecrLogin) Login() error {
 		return err
 	}
 
-	return login(
+	return l.runtime.Login(
 		l.ctx,
-		l.runner,
 		l.getServer(credentials),
 		"AWS",
 		token,
@@ -155,23 +139,14 @@ func getAWSCredentials(
 
 func getAWSToken(
 	ctx context.Context,
-	runner command.Runner,
+	containerRuntime runtime.ContainerRuntime,
 	credentials *awsCredentials,
 ) (string, error) {
-	args := []string{
-		"docker",
-		"run",
-		"--rm",
-	}
-
-	args = append(args, credentials.Env()...)
-	args = append(args, ECRTokenImage)
-
-	token, stderr, err := runner.RunForOutput(
-		ctx,
-		args,
-		nil,
-	)
+	token, stderr, err := containerRuntime.RunForOutput(ctx, &runtime.RunOptions{
+		Image:  ECRTokenImage,
+		Env:    credentials.Env(),
+		Remove: true,
+	})
 
 	if err != nil || token == "" {
 		return "", fmt.Errorf("failed to generate AWS token: %s", strings.TrimSpace(stderr))
@@ -182,10 +157,10 @@ func getAWSToken(
 
 func (c *awsCredentials) Env() []string {
 	return []string{
-		"-e", fmt.Sprintf("AWS_ACCESS_KEY_ID=%s", c.AccessKeyID),
-		"-e", fmt.Sprintf("AWS_SECRET_ACCESS_KEY=%s", c.SecretAccessKey),
-		"-e", fmt.Sprintf("AWS_ACCOUNT_ID=%s", c.AccountID),
-		"-e", fmt.Sprintf("AWS_REGION=%s", c.Region),
-		"-e", fmt.Sprintf("AWS_ROLE=%s", c.Role),
+		fmt.Sprintf("AWS_ACCESS_KEY_ID=%s", c.AccessKeyID),
+		fmt.Sprintf("AWS_SECRET_ACCESS_KEY=%s", c.SecretAccessKey),
+		fmt.Sprintf("AWS_ACCOUNT_ID=%s", c.AccountID),
+		fmt.Sprintf("AWS_REGION=%s", c.Region),
+		fmt.Sprintf("AWS_ROLE=%s", c.Role),
 	}
 }
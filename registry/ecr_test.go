@@ -2,20 +2,20 @@ package registry
 
 import (
 	"context"
-	"io/ioutil"
 
 	"github.com/aphistic/sweet"
-	. "github.com/efritz/go-mockgen/matchers"
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/environment"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 	. "github.com/onsi/gomega"
 )
 
 type ECRSuite struct{}
 
 func (s *ECRSuite) TestLogin(t sweet.T) {
-	runner := NewMockRunner()
+	containerRuntime := runtime.NewFakeRuntime()
+	containerRuntime.Outputs[ECRTokenImage] = "somehugetoken"
 	registry := &config.ECRRegistry{
 		AccessKeyID:     "testAccessKeyID",
 		SecretAccessKey: "testSecretAccessKey",
@@ -24,14 +24,12 @@ func (s *ECRSuite) TestLogin(t sweet.T) {
 		Role:            "testRole",
 	}
 
-	runner.RunForOutputFunc.SetDefaultReturn("somehugetoken", "", nil)
-
-	login := newECRLogin(
+	login := NewECRLogin(
 		context.Background(),
 		logging.NilLogger,
 		environment.New(nil),
 		registry,
-		runner,
+		containerRuntime,
 	)
 
 	server, err := login.GetServer()
@@ -39,36 +37,27 @@ func (s *ECRSuite) TestLogin(t sweet.T) {
 	Expect(server).To(Equal("https://testAccountID.dkr.ecr.testRegion.amazonaws.com"))
 	Expect(login.Login()).To(BeNil())
 
-	Expect(runner.RunForOutputFunc).To(BeCalledOnce())
-	Expect(runner.RunForOutputFunc).To(BeCalledWith(BeAnything(), []string{
-		"docker",
-		"run",
-		"--rm",
-		"-e", "AWS_ACCESS_KEY_ID=testAccessKeyID",
-		"-e", "AWS_SECRET_ACCESS_KEY=testSecretAccessKey",
-		"-e", "AWS_ACCOUNT_ID=testAccountID",
-		"-e", "AWS_REGION=testRegion",
-		"-e", "AWS_ROLE=testRole",
-		"efritz/ij-ecr-token:latest",
-	}, BeAnything()))
-
-	Expect(runner.RunFunc).To(BeCalledOnce())
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{
-		"docker",
-		"login",
-		"-u",
-		"AWS",
-		"--password-stdin",
-		"https://testAccountID.dkr.ecr.testRegion.amazonaws.com",
-	}, BeAnything(), BeAnything()))
-
-	stdin := runner.RunFunc.History()[0].Arg2
-	content, _ := ioutil.ReadAll(stdin)
-	Expect(string(content)).To(Equal("somehugetoken"))
+	Expect(containerRuntime.Runs).To(Equal([]*runtime.RunOptions{
+		&runtime.RunOptions{
+			Image: "efritz/ij-ecr-token:latest",
+			Env: []string{
+				"AWS_ACCESS_KEY_ID=testAccessKeyID",
+				"AWS_SECRET_ACCESS_KEY=testSecretAccessKey",
+				"AWS_ACCOUNT_ID=testAccountID",
+				"AWS_REGION=testRegion",
+				"AWS_ROLE=testRole",
+			},
+			Remove: true,
+		},
+	}))
+
+	Expect(containerRuntime.Logins).To(HaveLen(1))
+	Expect(containerRuntime.Logins["https://testAccountID.dkr.ecr.testRegion.amazonaws.com"]).To(Equal("AWS:somehugetoken"))
 }
 
 func (s *ECRSuite) TestLoginMappedEnvironment(t sweet.T) {
-	runner := NewMockRunner()
+	containerRuntime := runtime.NewFakeRuntime()
+	containerRuntime.Outputs[ECRTokenImage] = "somehugetoken"
 	registry := &config.ECRRegistry{
 		AccessKeyID:     "${AWS_ACCESS_KEY_ID}",
 		SecretAccessKey: "${AWS_SECRET_ACCESS_KEY}",
@@ -85,14 +74,12 @@ func (s *ECRSuite) TestLoginMappedEnvironment(t sweet.T) {
 		"AWS_ROLE=testRole",
 	}
 
-	runner.RunForOutputFunc.SetDefaultReturn("somehugetoken", "", nil)
-
-	login := newECRLogin(
+	login := NewECRLogin(
 		context.Background(),
 		logging.NilLogger,
 		environment.New(env),
 		registry,
-		runner,
+		containerRuntime,
 	)
 
 	server, err := login.GetServer()
@@ -100,30 +87,41 @@ func (s *ECRSuite) TestLoginMappedEnvironment(t sweet.T) {
 	Expect(server).To(Equal("https://testAccountID.dkr.ecr.testRegion.amazonaws.com"))
 	Expect(login.Login()).To(BeNil())
 
-	Expect(runner.RunForOutputFunc).To(BeCalledOnce())
-	Expect(runner.RunForOutputFunc).To(BeCalledWith(BeAnything(), []string{
-		"docker",
-		"run",
-		"--rm",
-		"-e", "AWS_ACCESS_KEY_ID=testAccessKeyID",
-		"-e", "AWS_SECRET_ACCESS_KEY=testSecretAccessKey",
-		"-e", "AWS_ACCOUNT_ID=testAccountID",
-		"-e", "AWS_REGION=testRegion",
-		"-e", "AWS_ROLE=testRole",
-		"efritz/ij-ecr-token:latest",
-	}, BeAnything()))
-
-	Expect(runner.RunFunc).To(BeCalledOnce())
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{
-		"docker",
-		"login",
-		"-u",
-		"AWS",
-		"--password-stdin",
-		"https://testAccountID.dkr.ecr.testRegion.amazonaws.com",
-	}, BeAnything(), BeAnything()))
-
-	stdin := runner.RunFunc.History()[0].Arg2
-	content, _ := ioutil.ReadAll(stdin)
-	Expect(string(content)).To(Equal("somehugetoken"))
+	Expect(containerRuntime.Runs).To(Equal([]*runtime.RunOptions{
+		&runtime.RunOptions{
+			Image: "efritz/ij-ecr-token:latest",
+			Env: []string{
+				"AWS_ACCESS_KEY_ID=testAccessKeyID",
+				"AWS_SECRET_ACCESS_KEY=testSecretAccessKey",
+				"AWS_ACCOUNT_ID=testAccountID",
+				"AWS_REGION=testRegion",
+				"AWS_ROLE=testRole",
+			},
+			Remove: true,
+		},
+	}))
+
+	Expect(containerRuntime.Logins).To(HaveLen(1))
+	Expect(containerRuntime.Logins["https://testAccountID.dkr.ecr.testRegion.amazonaws.com"]).To(Equal("AWS:somehugetoken"))
+}
+
+func (s *ECRSuite) TestLoginTokenError(t sweet.T) {
+	containerRuntime := runtime.NewFakeRuntime()
+	registry := &config.ECRRegistry{
+		AccessKeyID:     "testAccessKeyID",
+		SecretAccessKey: "testSecretAccessKey",
+		AccountID:       "testAccountID",
+		Region:          "testRegion",
+	}
+
+	login := NewECRLogin(
+		context.Background(),
+		logging.NilLogger,
+		environment.New(nil),
+		registry,
+		containerRuntime,
+	)
+
+	Expect(login.Login()).To(MatchError("failed to generate AWS token: "))
+	Expect(containerRuntime.Logins).To(BeEmpty())
 }
@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"io/ioutil"
 
-	"github.com/ij-build/ij/command"
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/environment"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 )
 
 type gcrLogin struct {
@@ -16,7 +16,7 @@ type gcrLogin struct {
 	logger   logging.Logger
 	env      environment.Environment
 	registry *config.GCRRegistry
-	runner   command.Runner
+	runtime  runtime.ContainerRuntime
 }
 
 func NewGCRLogin(
@@ -24,29 +24,14 @@ func NewGCRLogin(
 	logger logging.Logger,
 	env environment.Environment,
 	registry *config.GCRRegistry,
-) Login {
-	return newGCRLogin(
-		ctx,
-		logger,
-		env,
-		registry,
-		command.NewRunner(logger),
-	)
-}
-
-func newGCRLogin(
-	ctx context.Context,
-	logger logging.Logger,
-	env environment.Environment,
-	registry *config.GCRRegistry,
-	runner command.Runner,
+	runtime runtime.ContainerRuntime,
 ) Login {
 	return &gcrLogin{
 		ctx:      ctx,
 		logger:   logger,
 		env:      env,
 		registry: registry,
-		runner:   runner,
+		runtime:  runtime,
 	}
 }
 
@@ -65,9 +50,8 @@ func (l *gcrLogin) Login() error {
 		return err
 	}
 
-	return login(
+	return l.runtime.Login(
 		l.ctx,
-		l.runner,
 		server,
 		"_json_key",
 		string(password),
@@ -2,32 +2,31 @@ package registry
 
 import (
 	"context"
-	"io/ioutil"
 	"strings"
 
 	"github.com/aphistic/sweet"
-	. "github.com/efritz/go-mockgen/matchers"
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/environment"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 	. "github.com/onsi/gomega"
 )
 
 type GCRSuite struct{}
 
 func (s *GCRSuite) TestLoginKey(t sweet.T) {
-	runner := NewMockRunner()
+	runtime := runtime.NewFakeRuntime()
 	registry := &config.GCRRegistry{
 		Hostname: "gcr.io",
 		Key:      `{"some": "json", "blob": "here"}`,
 	}
 
-	login := newGCRLogin(
+	login := NewGCRLogin(
 		context.Background(),
 		logging.NilLogger,
 		environment.New(nil),
 		registry,
-		runner,
+		runtime,
 	)
 
 	server, err := login.GetServer()
@@ -35,23 +34,12 @@ func (s *GCRSuite) TestLoginKey(t sweet.T) {
 	Expect(server).To(Equal("https://gcr.io"))
 	Expect(login.Login()).To(BeNil())
 
-	Expect(runner.RunFunc).To(BeCalledOnce())
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{
-		"docker",
-		"login",
-		"-u",
-		"_json_key",
-		"--password-stdin",
-		"https://gcr.io",
-	}, BeAnything(), BeAnything()))
-
-	stdin := runner.RunFunc.History()[0].Arg2
-	content, _ := ioutil.ReadAll(stdin)
-	Expect(strings.TrimSpace(string(content))).To(Equal(`{"some": "json", "blob": "here"}`))
+	Expect(runtime.Logins).To(HaveLen(1))
+	Expect(strings.TrimSpace(runtime.Logins["https://gcr.io"])).To(Equal(`_json_key:{"some": "json", "blob": "here"}`))
 }
 
 func (s *GCRSuite) TestLoginKeyMappedEnvironment(t sweet.T) {
-	runner := NewMockRunner()
+	runtime := runtime.NewFakeRuntime()
 	registry := &config.GCRRegistry{
 		Hostname: "eu.gcr.io",
 		Key:      `${KEY}`,
@@ -61,12 +49,12 @@ func (s *GCRSuite) TestLoginKeyMappedEnvironment(t sweet.T) {
 		`KEY={"some": "json", "blob": "here"}`,
 	}
 
-	login := newGCRLogin(
+	login := NewGCRLogin(
 		context.Background(),
 		logging.NilLogger,
 		environment.New(env),
 		registry,
-		runner,
+		runtime,
 	)
 
 	server, err := login.GetServer()
@@ -74,34 +62,23 @@ func (s *GCRSuite) TestLoginKeyMappedEnvironment(t sweet.T) {
 	Expect(server).To(Equal("https://eu.gcr.io"))
 	Expect(login.Login()).To(BeNil())
 
-	Expect(runner.RunFunc).To(BeCalledOnce())
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{
-		"docker",
-		"login",
-		"-u",
-		"_json_key",
-		"--password-stdin",
-		"https://eu.gcr.io",
-	}, BeAnything(), BeAnything()))
-
-	stdin := runner.RunFunc.History()[0].Arg2
-	content, _ := ioutil.ReadAll(stdin)
-	Expect(strings.TrimSpace(string(content))).To(Equal(`{"some": "json", "blob": "here"}`))
+	Expect(runtime.Logins).To(HaveLen(1))
+	Expect(strings.TrimSpace(runtime.Logins["https://eu.gcr.io"])).To(Equal(`_json_key:{"some": "json", "blob": "here"}`))
 }
 
 func (s *GCRSuite) TestLoginKeyFile(t sweet.T) {
-	runner := NewMockRunner()
+	runtime := runtime.NewFakeRuntime()
 	registry := &config.GCRRegistry{
 		Hostname: "gcr.io",
 		KeyFile:  "./test-files/gcr.key",
 	}
 
-	login := newGCRLogin(
+	login := NewGCRLogin(
 		context.Background(),
 		logging.NilLogger,
 		environment.New(nil),
 		registry,
-		runner,
+		runtime,
 	)
 
 	server, err := login.GetServer()
@@ -109,23 +86,12 @@ func (s *GCRSuite) TestLoginKeyFile(t sweet.T) {
 	Expect(server).To(Equal("https://gcr.io"))
 	Expect(login.Login()).To(BeNil())
 
-	Expect(runner.RunFunc).To(BeCalledOnce())
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{
-		"docker",
-		"login",
-		"-u",
-		"_json_key",
-		"--password-stdin",
-		"https://gcr.io",
-	}, BeAnything(), BeAnything()))
-
-	stdin := runner.RunFunc.History()[0].Arg2
-	content, _ := ioutil.ReadAll(stdin)
-	Expect(strings.TrimSpace(string(content))).To(Equal(`{"some": "json", "blob": "here"}`))
+	Expect(runtime.Logins).To(HaveLen(1))
+	Expect(strings.TrimSpace(runtime.Logins["https://gcr.io"])).To(Equal(`_json_key:{"some": "json", "blob": "here"}`))
 }
 
 func (s *GCRSuite) TestLoginKeyFileMappedEnvironment(t sweet.T) {
-	runner := NewMockRunner()
+	runtime := runtime.NewFakeRuntime()
 	registry := &config.GCRRegistry{
 		Hostname: "eu.gcr.io",
 		KeyFile:  "${KEY_FILE}",
@@ -135,12 +101,12 @@ func (s *GCRSuite) TestLoginKeyFileMappedEnvironment(t sweet.T) {
 		"KEY_FILE=./test-files/gcr.key",
 	}
 
-	login := newGCRLogin(
+	login := NewGCRLogin(
 		context.Background(),
 		logging.NilLogger,
 		environment.New(env),
 		registry,
-		runner,
+		runtime,
 	)
 
 	server, err := login.GetServer()
@@ -148,17 +114,6 @@ func (s *GCRSuite) TestLoginKeyFileMappedEnvironment(t sweet.T) {
 	Expect(server).To(Equal("https://eu.gcr.io"))
 	Expect(login.Login()).To(BeNil())
 
-	Expect(runner.RunFunc).To(BeCalledOnce())
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{
-		"docker",
-		"login",
-		"-u",
-		"_json_key",
-		"--password-stdin",
-		"https://eu.gcr.io",
-	}, BeAnything(), BeAnything()))
-
-	stdin := runner.RunFunc.History()[0].Arg2
-	content, _ := ioutil.ReadAll(stdin)
-	Expect(strings.TrimSpace(string(content))).To(Equal(`{"some": "json", "blob": "here"}`))
+	Expect(runtime.Logins).To(HaveLen(1))
+	Expect(strings.TrimSpace(runtime.Logins["https://eu.gcr.io"])).To(Equal(`_json_key:{"some": "json", "blob": "here"}`))
 }
@@ -13,16 +13,17 @@ import (
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/environment"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 )
 
 type RegistrySetSuite struct{}
 
 func (s *RegistrySetSuite) TestLoginLogout(t sweet.T) {
 	var (
-		login   = NewMockLogin()
-		runner  = NewMockRunner()
-		args    = []config.Registry{}
-		servers = make(chan string, 3)
+		login            = NewMockLogin()
+		containerRuntime = runtime.NewFakeRuntime()
+		args             = []config.Registry{}
+		servers          = make(chan string, 3)
 	)
 
 	servers <- "x"
@@ -41,6 +42,7 @@ func (s *RegistrySetSuite) TestLoginLogout(t sweet.T) {
 		_ logging.Logger,
 		_ environment.Environment,
 		arg config.Registry,
+		_ runtime.ContainerRuntime,
 	) Login {
 		args = append(args, arg)
 		return login
@@ -56,7 +58,7 @@ func (s *RegistrySetSuite) TestLoginLogout(t sweet.T) {
 		environment.New(nil),
 		registries,
 		factory,
-		runner,
+		containerRuntime,
 	)
 
 	Expect(err).To(BeNil())
@@ -65,17 +67,14 @@ func (s *RegistrySetSuite) TestLoginLogout(t sweet.T) {
 	Expect(login.LoginFunc).To(BeCalledN(3))
 
 	registrySet.Logout()
-	Expect(runner.RunFunc).To(BeCalledN(3))
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{"docker", "logout", "x"}, BeAnything(), BeAnything()))
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{"docker", "logout", "y"}, BeAnything(), BeAnything()))
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{"docker", "logout", "z"}, BeAnything(), BeAnything()))
+	Expect(containerRuntime.LoggedOut).To(ConsistOf("x", "y", "z"))
 }
 
 func (s *RegistrySetSuite) TestSetupError(t sweet.T) {
 	var (
-		login  = NewMockLogin()
-		runner = NewMockRunner()
-		errors = make(chan error, 3)
+		login            = NewMockLogin()
+		containerRuntime = runtime.NewFakeRuntime()
+		errors           = make(chan error, 3)
 	)
 
 	errors <- nil
@@ -94,6 +93,7 @@ func (s *RegistrySetSuite) TestSetupError(t sweet.T) {
 		_ logging.Logger,
 		_ environment.Environment,
 		arg config.Registry,
+		_ runtime.ContainerRuntime,
 	) Login {
 		return login
 	}
@@ -108,7 +108,7 @@ func (s *RegistrySetSuite) TestSetupError(t sweet.T) {
 		environment.New(nil),
 		registries,
 		factory,
-		runner,
+		containerRuntime,
 	)
 
 	Expect(err).To(MatchError("utoh"))
@@ -116,11 +116,11 @@ func (s *RegistrySetSuite) TestSetupError(t sweet.T) {
 
 func (s *RegistrySetSuite) TestLoginError(t sweet.T) {
 	var (
-		login   = NewMockLogin()
-		runner  = NewMockRunner()
-		args    = []config.Registry{}
-		servers = make(chan string, 3)
-		errors  = make(chan error, 3)
+		login            = NewMockLogin()
+		containerRuntime = runtime.NewFakeRuntime()
+		args             = []config.Registry{}
+		servers          = make(chan string, 3)
+		errors           = make(chan error, 3)
 	)
 
 	servers <- "x"
@@ -144,6 +144,7 @@ func (s *RegistrySetSuite) TestLoginError(t sweet.T) {
 		_ logging.Logger,
 		_ environment.Environment,
 		arg config.Registry,
+		_ runtime.ContainerRuntime,
 	) Login {
 		args = append(args, arg)
 		return login
@@ -163,14 +164,12 @@ func (s *RegistrySetSuite) TestLoginError(t sweet.T) {
 		environment.New(nil),
 		registries,
 		factory,
-		runner,
+		containerRuntime,
 	)
 
 	Expect(err).To(BeNil())
 	Expect(registrySet.Login()).To(MatchError("utoh"))
-	Expect(runner.RunFunc).To(BeCalledN(2))
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{"docker", "logout", "x"}, BeAnything(), BeAnything()))
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{"docker", "logout", "y"}, BeAnything(), BeAnything()))
+	Expect(containerRuntime.LoggedOut).To(ConsistOf("x", "y"))
 }
 
 func (s *RegistrySetSuite) TestCancelSetup(t sweet.T) {
@@ -189,7 +188,7 @@ func (s *RegistrySetSuite) TestCancelSetup(t sweet.T) {
 		environment.New(nil),
 		registries,
 		testLoginFactory,
-		NewMockRunner(),
+		runtime.NewFakeRuntime(),
 	)
 
 	Expect(err).To(BeNil())
@@ -198,7 +197,7 @@ func (s *RegistrySetSuite) TestCancelSetup(t sweet.T) {
 
 func (s *RegistrySetSuite) TestCancelDuringTeardown(t sweet.T) {
 	ctx, cancel := context.WithCancel(context.Background())
-	runner := NewMockRunner()
+	containerRuntime := runtime.NewFakeRuntime()
 
 	registries := []config.Registry{
 		&config.GCRRegistry{KeyFile: "a"},
@@ -212,21 +211,16 @@ func (s *RegistrySetSuite) TestCancelDuringTeardown(t sweet.T) {
 		environment.New(nil),
 		registries,
 		testLoginFactory,
-		runner,
+		containerRuntime,
 	)
 
 	Expect(err).To(BeNil())
 	Expect(registrySet.Login()).To(BeNil())
 
+	// Logout is not bound to the canceled context
 	cancel()
 	registrySet.Logout()
-	Expect(runner.RunFunc).To(BeCalledN(3))
-
-	select {
-	case <-runner.RunFunc.History()[1].Arg0.Done():
-		t.Fail()
-	default:
-	}
+	Expect(containerRuntime.LoggedOut).To(HaveLen(3))
 }
 
 //
@@ -237,6 +231,7 @@ func testLoginFactory(
 	_ logging.Logger,
 	_ environment.Environment,
 	arg config.Registry,
+	_ runtime.ContainerRuntime,
 ) Login {
 	login := NewMockLogin()
 	login.LoginFunc.SetDefaultHook(func() error {
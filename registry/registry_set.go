@@ -3,10 +3,10 @@ package registry
 import (
 	"context"
 
-	"github.com/ij-build/ij/command"
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/environment"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 )
 
 type (
@@ -14,7 +14,7 @@ type (
 		ctx         context.Context
 		logger      logging.Logger
 		env         environment.Environment
-		runner      command.Runner
+		runtime     runtime.ContainerRuntime
 		namedLogins []*namedLogin
 	}
 
@@ -28,6 +28,7 @@ type (
 		logging.Logger,
 		environment.Environment,
 		config.Registry,
+		runtime.ContainerRuntime,
 	) Login
 )
 
@@ -36,6 +37,7 @@ func NewRegistrySet(
 	logger logging.Logger,
 	env environment.Environment,
 	registries []config.Registry,
+	runtime runtime.ContainerRuntime,
 ) (*RegistrySet, error) {
 	return newRegistrySet(
 		ctx,
@@ -43,7 +45,7 @@ func NewRegistrySet(
 		env,
 		registries,
 		defaultLoginFactory,
-		command.NewRunner(logger),
+		runtime,
 	)
 }
 
@@ -53,13 +55,13 @@ func newRegistrySet(
 	env environment.Environment,
 	registries []config.Registry,
 	factory loginFactory,
-	runner command.Runner,
+	runtime runtime.ContainerRuntime,
 ) (*RegistrySet, error) {
 	set := &RegistrySet{
 		ctx:         ctx,
 		logger:      logger,
 		env:         env,
-		runner:      runner,
+		runtime:     runtime,
 		namedLogins: []*namedLogin{},
 	}
 
@@ -85,7 +87,7 @@ func (s *RegistrySet) Login() error {
 		)
 
 		if err := namedLogin.login.Login(); err != nil {
-			logoutRegistries(s.logger, s.runner, servers)
+			logoutRegistries(s.logger, s.runtime, servers)
 			return err
 		}
 
@@ -110,7 +112,7 @@ func (s *RegistrySet) Logout() {
 		servers = append(servers, namedLogin.name)
 	}
 
-	logoutRegistries(s.logger, s.runner, servers)
+	logoutRegistries(s.logger, s.runtime, servers)
 }
 
 func (s *RegistrySet) populateLoginMap(
@@ -118,7 +120,7 @@ func (s *RegistrySet) populateLoginMap(
 	factory loginFactory,
 ) error {
 	for _, registry := range registries {
-		login := factory(s.ctx, s.logger, s.env, registry)
+		login := factory(s.ctx, s.logger, s.env, registry, s.runtime)
 
 		server, err := login.GetServer()
 		if err != nil {
@@ -139,7 +141,7 @@ func (s *RegistrySet) populateLoginMap(
 
 func logoutRegistries(
 	logger logging.Logger,
-	runner command.Runner,
+	runtime runtime.ContainerRuntime,
 	servers []string,
 ) {
 	for _, server := range servers {
@@ -149,20 +151,7 @@ func logoutRegistries(
 			server,
 		)
 
-		args := []string{
-			"docker",
-			"logout",
-			server,
-		}
-
-		err := runner.Run(
-			context.Background(),
-			args,
-			nil,
-			nil,
-		)
-
-		if err != nil {
+		if err := runtime.Logout(context.Background(), server); err != nil {
 			logger.Error(
 				nil,
 				"Failed to log out of registry: %s",
@@ -177,14 +166,15 @@ func defaultLoginFactory(
 	logger logging.Logger,
 	env environment.Environment,
 	reg config.Registry,
+	runtime runtime.ContainerRuntime,
 ) Login {
 	switch r := reg.(type) {
 	case *config.ECRRegistry:
-		return NewECRLogin(ctx, logger, env, r)
+		return NewECRLogin(ctx, logger, env, r, runtime)
 	case *config.GCRRegistry:
-		return NewGCRLogin(ctx, logger, env, r)
+		return NewGCRLogin(ctx, logger, env, r, runtime)
 	case *config.ServerRegistry:
-		return NewServerLogin(ctx, logger, env, r)
+		return NewServerLogin(ctx, logger, env, r, runtime)
 	}
 
 	panic("unexpected registry type")
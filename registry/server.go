@@ -4,10 +4,10 @@ import (
 	"context"
 	"io/ioutil"
 
-	"github.com/ij-build/ij/command"
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/environment"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 )
 
 type serverLogin struct {
@@ -15,7 +15,7 @@ type serverLogin struct {
 	logger   logging.Logger
 	env      environment.Environment
 	registry *config.ServerRegistry
-	runner   command.Runner
+	runtime  runtime.ContainerRuntime
 }
 
 func NewServerLogin(
@@ -23,29 +23,14 @@ func NewServerLogin(
 	logger logging.Logger,
 	env environment.Environment,
 	registry *config.ServerRegistry,
-) Login {
-	return newServerLogin(
-		ctx,
-		logger,
-		env,
-		registry,
-		command.NewRunner(logger),
-	)
-}
-
-func newServerLogin(
-	ctx context.Context,
-	logger logging.Logger,
-	env environment.Environment,
-	registry *config.ServerRegistry,
-	runner command.Runner,
+	runtime runtime.ContainerRuntime,
 ) Login {
 	return &serverLogin{
 		ctx:      ctx,
 		logger:   logger,
 		env:      env,
 		registry: registry,
-		runner:   runner,
+		runtime:  runtime,
 	}
 }
 
@@ -69,9 +54,8 @@ func (l *serverLogin) Login() error {
 		return err
 	}
 
-	return login(
+	return l.runtime.Login(
 		l.ctx,
-		l.runner,
 		server,
 		username,
 		password,
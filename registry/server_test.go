@@ -1,37 +1,34 @@
 package registry
 
-//go:generate go-mockgen -f github.com/ij-build/ij/command -i Runner -o mock_runner_test.go
-
 import (
 	"context"
-	"io/ioutil"
 	"strings"
 
 	"github.com/aphistic/sweet"
 	. "github.com/onsi/gomega"
 
-	. "github.com/efritz/go-mockgen/matchers"
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/environment"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 )
 
 type ServerSuite struct{}
 
 func (s *ServerSuite) TestLogin(t sweet.T) {
-	runner := NewMockRunner()
+	runtime := runtime.NewFakeRuntime()
 	registry := &config.ServerRegistry{
 		Server:   "docker.io",
 		Username: "admin",
 		Password: "secret",
 	}
 
-	login := newServerLogin(
+	login := NewServerLogin(
 		context.Background(),
 		logging.NilLogger,
 		environment.New(nil),
 		registry,
-		runner,
+		runtime,
 	)
 
 	server, err := login.GetServer()
@@ -39,35 +36,24 @@ func (s *ServerSuite) TestLogin(t sweet.T) {
 	Expect(server).To(Equal("docker.io"))
 	Expect(login.Login()).To(BeNil())
 
-	Expect(runner.RunFunc).To(BeCalledOnce())
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{
-		"docker",
-		"login",
-		"-u",
-		"admin",
-		"--password-stdin",
-		"docker.io",
-	}, BeAnything(), BeAnything()))
-
-	stdin := runner.RunFunc.History()[0].Arg2
-	content, _ := ioutil.ReadAll(stdin)
-	Expect(string(content)).To(Equal("secret"))
+	Expect(runtime.Logins).To(HaveLen(1))
+	Expect(runtime.Logins["docker.io"]).To(Equal("admin:secret"))
 }
 
 func (s *ServerSuite) TestLoginPasswordFile(t sweet.T) {
-	runner := NewMockRunner()
+	runtime := runtime.NewFakeRuntime()
 	registry := &config.ServerRegistry{
 		Server:       "docker.io",
 		Username:     "admin",
 		PasswordFile: "./test-files/secret.key",
 	}
 
-	login := newServerLogin(
+	login := NewServerLogin(
 		context.Background(),
 		logging.NilLogger,
 		environment.New(nil),
 		registry,
-		runner,
+		runtime,
 	)
 
 	server, err := login.GetServer()
@@ -75,23 +61,12 @@ func (s *ServerSuite) TestLoginPasswordFile(t sweet.T) {
 	Expect(server).To(Equal("docker.io"))
 	Expect(login.Login()).To(BeNil())
 
-	Expect(runner.RunFunc).To(BeCalledOnce())
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{
-		"docker",
-		"login",
-		"-u",
-		"admin",
-		"--password-stdin",
-		"docker.io",
-	}, BeAnything(), BeAnything()))
-
-	stdin := runner.RunFunc.History()[0].Arg2
-	content, _ := ioutil.ReadAll(stdin)
-	Expect(strings.TrimSpace(string(content))).To(Equal("super secret file"))
+	Expect(runtime.Logins).To(HaveLen(1))
+	Expect(strings.TrimSpace(runtime.Logins["docker.io"])).To(Equal("admin:super secret file"))
 }
 
 func (s *ServerSuite) TestLoginMappedEnvironment(t sweet.T) {
-	runner := NewMockRunner()
+	runtime := runtime.NewFakeRuntime()
 	registry := &config.ServerRegistry{
 		Server:   "${DOCKER_HOST}",
 		Username: "${DOCKER_USERNAME}",
@@ -104,12 +79,12 @@ func (s *ServerSuite) TestLoginMappedEnvironment(t sweet.T) {
 		"DOCKER_PASSWORD=secret",
 	}
 
-	login := newServerLogin(
+	login := NewServerLogin(
 		context.Background(),
 		logging.NilLogger,
 		environment.New(env),
 		registry,
-		runner,
+		runtime,
 	)
 
 	server, err := login.GetServer()
@@ -117,17 +92,6 @@ func (s *ServerSuite) TestLoginMappedEnvironment(t sweet.T) {
 	Expect(server).To(Equal("docker.io"))
 	Expect(login.Login()).To(BeNil())
 
-	Expect(runner.RunFunc).To(BeCalledOnce())
-	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{
-		"docker",
-		"login",
-		"-u",
-		"admin",
-		"--password-stdin",
-		"docker.io",
-	}, BeAnything(), BeAnything()))
-
-	stdin := runner.RunFunc.History()[0].Arg2
-	content, _ := ioutil.ReadAll(stdin)
-	Expect(string(content)).To(Equal("secret"))
+	Expect(runtime.Logins).To(HaveLen(1))
+	Expect(runtime.Logins["docker.io"]).To(Equal("admin:secret"))
 }
@@ -0,0 +1,374 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/paths"
+	"github.com/ij-build/ij/scratch"
+	"github.com/ij-build/ij/volume"
+)
+
+type (
+	artifactTaskRunner struct {
+		ctx     context.Context
+		runner  TaskRunner
+		scratch *scratch.ScratchSpace
+		volume  *volume.Volume
+		copier  *paths.Copier
+		locks   *artifactLocks
+		logger  logging.Logger
+		task    config.Task
+		env     environment.Environment
+		prefix  *logging.Prefix
+	}
+
+	// artifactLocks tracks the paths of the workspace held by running tasks
+	// which stage inputs or declare outputs. A task waits until no other task
+	// holds a path overlapping its own, so that the outputs of a task cannot
+	// be overwritten by another task before they are collected.
+	artifactLocks struct {
+		held    map[string]int
+		changed chan struct{}
+		mutex   sync.Mutex
+	}
+)
+
+// artifactTaskRunnerFactory wraps the given factory so that the artifacts
+// consumed by a task are copied into the workspace before it runs, and the
+// outputs it declares are copied out of the workspace once it succeeds.
+// Tasks whose inputs or outputs share a path of the workspace do not run
+// concurrently.
+func artifactTaskRunnerFactory(
+	factory TaskRunnerFactory,
+	scratch *scratch.ScratchSpace,
+	volume *volume.Volume,
+	logger logging.Logger,
+) TaskRunnerFactory {
+	locks := newArtifactLocks()
+
+	return func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		runner := factory(ctx, context, task, retryPolicy, prefix, env)
+
+		if len(task.GetInputs()) == 0 && len(task.GetOutputs()) == 0 {
+			return runner
+		}
+
+		return &artifactTaskRunner{
+			ctx:     ctx,
+			runner:  runner,
+			scratch: scratch,
+			volume:  volume,
+			copier:  paths.NewCopier(logger, scratch.Runpath()),
+			locks:   locks,
+			logger:  logger,
+			task:    task,
+			env:     env,
+			prefix:  prefix,
+		}
+	}
+}
+
+func (r *artifactTaskRunner) Run(context *RunContext) bool {
+	roots, err := r.workspaceRoots()
+	if err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to stage artifacts: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	if !r.locks.acquire(r.ctx, roots) {
+		return false
+	}
+
+	defer r.locks.release(roots)
+
+	staged, err := r.stageInputs()
+	if err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to stage artifacts: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	if !pushWorkspacePaths(r.ctx, r.volume, r.scratch, r.logger, r.prefix, staged) {
+		return false
+	}
+
+	if !r.runner.Run(context) {
+		return false
+	}
+
+	if !r.pullOutputs() {
+		return false
+	}
+
+	if err := r.collectOutputs(); err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to collect artifacts: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	return true
+}
+
+// workspaceRoots returns the paths of the workspace under which the inputs
+// of the task are staged and its outputs are produced.
+func (r *artifactTaskRunner) workspaceRoots() ([]string, error) {
+	patterns := []string{}
+	for _, input := range r.task.GetInputs() {
+		if _, pattern, ok := config.SplitArtifact(input); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	patterns, err := r.env.ExpandSlice(append(patterns, r.task.GetOutputs()...))
+	if err != nil {
+		return nil, err
+	}
+
+	roots := []string{}
+	for _, pattern := range patterns {
+		roots = append(roots, patternRoot(pattern))
+	}
+
+	return roots, nil
+}
+
+// pullOutputs copies the paths of the workspace volume which may hold the
+// outputs of the task into the host workspace.
+func (r *artifactTaskRunner) pullOutputs() bool {
+	patterns, err := r.env.ExpandSlice(r.task.GetOutputs())
+	if err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to collect artifacts: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	return pullWorkspacePatterns(
+		r.ctx,
+		r.volume,
+		r.scratch,
+		r.logger,
+		r.prefix,
+		patterns,
+	)
+}
+
+// stageInputs copies each artifact consumed by the task from the outputs
+// of the task that produced it into the same path of the workspace. The
+// staged paths are returned relative to the workspace.
+func (r *artifactTaskRunner) stageInputs() ([]string, error) {
+	staged := []string{}
+	for _, input := range r.task.GetInputs() {
+		producer, pattern, ok := config.SplitArtifact(input)
+		if !ok {
+			return nil, fmt.Errorf("malformed input %s", input)
+		}
+
+		pattern, err := r.env.ExpandString(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		copied, err := r.copyMatching(
+			r.scratch.Artifacts(producer),
+			r.scratch.Workspace(),
+			pattern,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(copied) == 0 {
+			return nil, fmt.Errorf("task %s did not produce an artifact matching %s", producer, pattern)
+		}
+
+		r.logger.Info(
+			r.prefix,
+			"Staged %d files of artifact %s",
+			len(copied),
+			input,
+		)
+
+		staged = append(staged, copied...)
+	}
+
+	return staged, nil
+}
+
+// collectOutputs copies the files matching each output pattern of the task
+// from the workspace into the artifacts directory of the task.
+func (r *artifactTaskRunner) collectOutputs() error {
+	for _, pattern := range r.task.GetOutputs() {
+		pattern, err := r.env.ExpandString(pattern)
+		if err != nil {
+			return err
+		}
+
+		copied, err := r.copyMatching(
+			r.scratch.Workspace(),
+			r.scratch.Artifacts(r.task.GetName()),
+			pattern,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		if len(copied) == 0 {
+			return fmt.Errorf("declared output %s was not produced", pattern)
+		}
+
+		r.logger.Info(
+			r.prefix,
+			"Collected %d files of output %s",
+			len(copied),
+			pattern,
+		)
+	}
+
+	return nil
+}
+
+// copyMatching copies the files within the source directory that match the
+// given pattern to the same relative path within the destination directory.
+// The copied paths are returned relative to both directories.
+func (r *artifactTaskRunner) copyMatching(src, dest, pattern string) ([]string, error) {
+	matches, err := paths.Match(src, []string{pattern})
+	if err != nil {
+		return nil, err
+	}
+
+	copied := []string{}
+	for _, path := range matches {
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := r.copier.Copy(path, filepath.Join(dest, rel), nil); err != nil {
+			return nil, err
+		}
+
+		copied = append(copied, rel)
+	}
+
+	return copied, nil
+}
+
+func newArtifactLocks() *artifactLocks {
+	return &artifactLocks{
+		held:    map[string]int{},
+		changed: make(chan struct{}),
+	}
+}
+
+// acquire blocks until none of the given paths overlaps a path held by
+// another task, then holds them. False is returned if the context is
+// canceled first.
+func (l *artifactLocks) acquire(ctx context.Context, roots []string) bool {
+	for {
+		l.mutex.Lock()
+
+		if !l.overlaps(roots) {
+			for _, root := range roots {
+				l.held[root]++
+			}
+
+			l.mutex.Unlock()
+			return true
+		}
+
+		changed := l.changed
+		l.mutex.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (l *artifactLocks) release(roots []string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for _, root := range roots {
+		if l.held[root]--; l.held[root] == 0 {
+			delete(l.held, root)
+		}
+	}
+
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+func (l *artifactLocks) overlaps(roots []string) bool {
+	for _, root := range roots {
+		for held := range l.held {
+			if pathsOverlap(root, held) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+//
+// Helpers
+
+// patternRoot returns the deepest path of the workspace which contains every
+// path matched by the given pattern. An empty path denotes the workspace.
+func patternRoot(pattern string) string {
+	parts := strings.Split(filepath.ToSlash(pattern), "/")
+	for i, part := range parts {
+		if strings.ContainsAny(part, "*?[{") {
+			parts = parts[:i]
+			break
+		}
+	}
+
+	root := path.Join(parts...)
+	if root == "." {
+		return ""
+	}
+
+	return root
+}
+
+func pathsOverlap(a, b string) bool {
+	return a == "" || b == "" || a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
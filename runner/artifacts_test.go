@@ -0,0 +1,186 @@
+package runner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/scratch"
+	. "github.com/onsi/gomega"
+)
+
+type ArtifactsSuite struct{}
+
+func (s *ArtifactsSuite) TestPassArtifacts(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	scratch := scratch.NewScratchSpace("abcdef0", name, name, false)
+	Expect(scratch.Setup()).To(BeNil())
+
+	var (
+		workspace = scratch.Workspace()
+		staged    = ""
+	)
+
+	factory := artifactTaskRunnerFactory(func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return testTaskRunner(func(context *RunContext) bool {
+			if task.GetName() == "build" {
+				os.MkdirAll(filepath.Join(workspace, "bin"), os.ModePerm)
+				ioutil.WriteFile(filepath.Join(workspace, "bin", "app"), []byte("build"), os.ModePerm)
+				ioutil.WriteFile(filepath.Join(workspace, "bin", "app.debug"), []byte("debug"), os.ModePerm)
+				return true
+			}
+
+			data, _ := ioutil.ReadFile(filepath.Join(workspace, "bin", "app"))
+			staged = string(data)
+			return true
+		})
+	}, scratch, nil, logging.NilLogger)
+
+	build := &config.RunTask{TaskMeta: config.TaskMeta{Name: "build", Outputs: []string{"bin/${NAME}"}}}
+	test := &config.RunTask{TaskMeta: config.TaskMeta{Name: "test", Inputs: []string{"build:bin/app"}}}
+	env := environment.New([]string{"NAME=app"})
+
+	Expect(factory(context.Background(), nil, build, nil, nil, env).Run(nil)).To(BeTrue())
+
+	data, err := ioutil.ReadFile(filepath.Join(scratch.Artifacts("build"), "bin", "app"))
+	Expect(err).To(BeNil())
+	Expect(string(data)).To(Equal("build"))
+
+	_, err = os.Stat(filepath.Join(scratch.Artifacts("build"), "bin", "app.debug"))
+	Expect(os.IsNotExist(err)).To(BeTrue())
+
+	// Clobbered by another task sharing the workspace
+	Expect(ioutil.WriteFile(filepath.Join(workspace, "bin", "app"), []byte("other"), os.ModePerm)).To(BeNil())
+
+	Expect(factory(context.Background(), nil, test, nil, nil, env).Run(nil)).To(BeTrue())
+	Expect(staged).To(Equal("build"))
+}
+
+func (s *ArtifactsSuite) TestMissingArtifacts(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	scratch := scratch.NewScratchSpace("abcdef0", name, name, false)
+	Expect(scratch.Setup()).To(BeNil())
+
+	ran := false
+	factory := artifactTaskRunnerFactory(func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return testTaskRunner(func(context *RunContext) bool {
+			ran = true
+			return true
+		})
+	}, scratch, nil, logging.NilLogger)
+
+	// Declared output is missing
+	build := &config.RunTask{TaskMeta: config.TaskMeta{Name: "build", Outputs: []string{"bin/app"}}}
+	Expect(factory(context.Background(), nil, build, nil, nil, environment.New(nil)).Run(nil)).To(BeFalse())
+	Expect(ran).To(BeTrue())
+
+	// Consumed artifact is missing
+	ran = false
+	test := &config.RunTask{TaskMeta: config.TaskMeta{Name: "test", Inputs: []string{"build:bin/app"}}}
+	Expect(factory(context.Background(), nil, test, nil, nil, environment.New(nil)).Run(nil)).To(BeFalse())
+	Expect(ran).To(BeFalse())
+}
+
+func (s *ArtifactsSuite) TestConcurrentProducers(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	scratch := scratch.NewScratchSpace("abcdef0", name, name, false)
+	Expect(scratch.Setup()).To(BeNil())
+
+	workspace := scratch.Workspace()
+
+	factory := artifactTaskRunnerFactory(func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return testTaskRunner(func(context *RunContext) bool {
+			os.MkdirAll(filepath.Join(workspace, "bin"), os.ModePerm)
+			ioutil.WriteFile(filepath.Join(workspace, "bin", "app"), []byte(task.GetName()), os.ModePerm)
+
+			// Give the other producer a chance to overwrite the output
+			time.Sleep(time.Millisecond * 50)
+			return true
+		})
+	}, scratch, nil, logging.NilLogger)
+
+	var (
+		wg      sync.WaitGroup
+		results = make(chan bool, 2)
+	)
+
+	for _, name := range []string{"linux", "darwin"} {
+		wg.Add(1)
+
+		go func(name string) {
+			defer wg.Done()
+			task := &config.RunTask{TaskMeta: config.TaskMeta{Name: name, Outputs: []string{"bin/*"}}}
+			results <- factory(context.Background(), nil, task, nil, nil, environment.New(nil)).Run(nil)
+		}(name)
+	}
+
+	wg.Wait()
+	Expect(<-results).To(BeTrue())
+	Expect(<-results).To(BeTrue())
+
+	for _, name := range []string{"linux", "darwin"} {
+		data, err := ioutil.ReadFile(filepath.Join(scratch.Artifacts(name), "bin", "app"))
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal(name))
+	}
+}
+
+func (s *ArtifactsSuite) TestArtifactLocks(t sweet.T) {
+	locks := newArtifactLocks()
+	Expect(locks.acquire(context.Background(), []string{"bin"})).To(BeTrue())
+	Expect(locks.acquire(context.Background(), []string{"docs"})).To(BeTrue())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	Expect(locks.acquire(ctx, []string{"bin/app"})).To(BeFalse())
+
+	acquired := make(chan bool)
+	go func() { acquired <- locks.acquire(context.Background(), []string{"bin/app"}) }()
+	Consistently(acquired).ShouldNot(Receive())
+
+	locks.release([]string{"bin"})
+	Eventually(acquired).Should(Receive(BeTrue()))
+}
+
+func (s *ArtifactsSuite) TestPatternRoot(t sweet.T) {
+	Expect(patternRoot("bin/app")).To(Equal("bin/app"))
+	Expect(patternRoot("./dist/**/*.js")).To(Equal("dist"))
+	Expect(patternRoot("*.jar")).To(Equal(""))
+	Expect(pathsOverlap("bin", "bin/app")).To(BeTrue())
+	Expect(pathsOverlap("bin", "binaries")).To(BeFalse())
+	Expect(pathsOverlap("", "docs")).To(BeTrue())
+}
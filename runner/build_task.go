@@ -3,22 +3,21 @@ package runner
 import (
 	"context"
 
-	"github.com/ij-build/ij/command"
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/environment"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 )
 
 type (
 	BuildTaskRunnerFactory func(
 		*config.BuildTask,
+		*config.RetryPolicy,
 		environment.Environment,
 		*logging.Prefix,
 	) TaskRunner
 
-	buildTaskCommandBuilderState struct {
-		ctx       context.Context
-		logger    logging.Logger
+	buildTaskOptionsState struct {
 		workspace string
 		env       environment.Environment
 		task      *config.BuildTask
@@ -27,121 +26,133 @@ type (
 
 func NewBuildTaskRunnerFactory(
 	ctx context.Context,
+	runtime runtime.ContainerRuntime,
 	workspace string,
 	logger logging.Logger,
 ) BuildTaskRunnerFactory {
 	return func(
 		task *config.BuildTask,
+		retryPolicy *config.RetryPolicy,
 		env environment.Environment,
 		prefix *logging.Prefix,
 	) TaskRunner {
-		factory := buildTaskCommandFactory(
-			workspace,
-			task,
-			env,
-		)
-
 		runner := NewBaseRunner(
 			ctx,
-			NewMultiFactory(factory),
+			buildTaskOperationFactory(runtime, workspace, task, env),
+			retryPolicy,
 			logger,
 			prefix,
 		)
 
-		runner.RegisterOnSuccess(func(context *RunContext) error {
-			tags := []string{}
-			for _, tag := range task.Tags {
-				expanded, err := env.ExpandString(tag)
-				if err != nil {
-					return err
-				}
+		runner.RegisterOnSuccess(buildTaskTagHook(task, env))
+		return runner
+	}
+}
 
-				tags = append(tags, expanded)
+// buildTaskTagHook returns a hook which makes the tags of a successfully
+// built image available to subsequent tasks.
+func buildTaskTagHook(task *config.BuildTask, env environment.Environment) HookFunc {
+	return func(context *RunContext) error {
+		tags := []string{}
+		for _, tag := range task.Tags {
+			expanded, err := env.ExpandString(tag)
+			if err != nil {
+				return err
 			}
 
-			context.AddTags(tags)
-			return nil
-		})
+			tags = append(tags, expanded)
+		}
 
-		return runner
+		context.AddTags(tags)
+		return nil
 	}
 }
 
-func buildTaskCommandFactory(
+func buildTaskOperationFactory(
+	runtime runtime.ContainerRuntime,
 	workspace string,
 	task *config.BuildTask,
 	env environment.Environment,
-) BuilderFactory {
-	return func() (*command.Builder, error) {
-		s := &buildTaskCommandBuilderState{
-			workspace: workspace,
-			task:      task,
-			env:       env,
+) OperationSetFactory {
+	return func() ([]Operation, error) {
+		options, err := buildTaskOptionsFactory(workspace, task, env)
+		if err != nil {
+			return nil, err
 		}
 
-		return command.NewBuilder(
-			[]string{
-				"docker",
-				"build",
-			},
-			[]command.BuildFunc{
-				s.addWorkspaceArg,
-				s.addDockerfileOptions,
-				s.addTargetOptions,
-				s.addTagOptions,
-				s.addLabelOptions,
-			},
-		), nil
+		operation := func(ctx context.Context, logger logging.Logger, prefix *logging.Prefix) error {
+			return runtime.Build(ctx, options, logger, prefix)
+		}
+
+		return []Operation{operation}, nil
 	}
 }
 
-func (s *buildTaskCommandBuilderState) addWorkspaceArg(cb *command.Builder) error {
-	cb.AddArgs(s.workspace)
-	return nil
+func buildTaskOptionsFactory(
+	workspace string,
+	task *config.BuildTask,
+	env environment.Environment,
+) (*runtime.BuildOptions, error) {
+	s := &buildTaskOptionsState{
+		workspace: workspace,
+		task:      task,
+		env:       env,
+	}
+
+	options := &runtime.BuildOptions{
+		ContextDir: workspace,
+	}
+
+	for _, f := range []func(*runtime.BuildOptions) error{
+		s.addDockerfileOptions,
+		s.addTargetOptions,
+		s.addTagOptions,
+		s.addLabelOptions,
+	} {
+		if err := f(options); err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
 }
 
-func (s *buildTaskCommandBuilderState) addDockerfileOptions(cb *command.Builder) error {
+func (s *buildTaskOptionsState) addDockerfileOptions(options *runtime.BuildOptions) error {
 	dockerfile, err := s.env.ExpandString(s.task.Dockerfile)
 	if err != nil {
 		return err
 	}
 
-	cb.AddFlagValue("-f", dockerfile)
+	options.Dockerfile = dockerfile
 	return nil
 }
 
-func (s *buildTaskCommandBuilderState) addTargetOptions(cb *command.Builder) error {
+func (s *buildTaskOptionsState) addTargetOptions(options *runtime.BuildOptions) error {
 	target, err := s.env.ExpandString(s.task.Target)
 	if err != nil {
 		return err
 	}
 
-	cb.AddFlagValue("--target", target)
+	options.Target = target
 	return nil
 }
 
-func (s *buildTaskCommandBuilderState) addTagOptions(cb *command.Builder) error {
-	for _, tag := range s.task.Tags {
-		expanded, err := s.env.ExpandString(tag)
-		if err != nil {
-			return err
-		}
-
-		cb.AddFlagValue("-t", expanded)
+func (s *buildTaskOptionsState) addTagOptions(options *runtime.BuildOptions) error {
+	tags, err := s.env.ExpandSlice(s.task.Tags)
+	if err != nil {
+		return err
 	}
 
+	options.Tags = tags
 	return nil
 }
 
-func (s *buildTaskCommandBuilderState) addLabelOptions(cb *command.Builder) error {
-	for _, label := range s.task.Labels {
-		expanded, err := s.env.ExpandString(label)
-		if err != nil {
-			return err
-		}
-
-		cb.AddFlagValue("--label", expanded)
+func (s *buildTaskOptionsState) addLabelOptions(options *runtime.BuildOptions) error {
+	labels, err := s.env.ExpandSlice(s.task.Labels)
+	if err != nil {
+		return err
 	}
 
+	options.Labels = labels
 	return nil
 }
@@ -4,8 +4,8 @@ import (
 	"context"
 	"sync"
 
-	"github.com/ij-build/ij/command"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 	"github.com/ij-build/ij/util"
 )
 
@@ -49,6 +49,13 @@ func (l *ContainerList) Remove(containerName string) {
 	l.mutex.Unlock()
 }
 
+// ExecuteOne removes the given container from the list and applies the
+// target function to it immediately.
+func (l *ContainerList) ExecuteOne(containerName string) {
+	l.Remove(containerName)
+	l.target(containerName)
+}
+
 func (l *ContainerList) Execute() {
 	l.mutex.RLock()
 	defer l.mutex.RUnlock()
@@ -66,42 +73,9 @@ func (l *ContainerList) Execute() {
 	util.RunParallelArgs(l.target, names...)
 }
 
-func NewContainerStopper(logger logging.Logger) *ContainerList {
+func NewContainerStopper(runtime runtime.ContainerRuntime, logger logging.Logger) *ContainerList {
 	stopper := func(containerName string) {
-		logger.Info(
-			nil,
-			"Stopping container %s",
-			containerName,
-		)
-
-		args := []string{
-			"docker",
-			"kill",
-			containerName,
-		}
-
-		_, _, err := command.NewRunner(logger).RunForOutput(
-			context.Background(),
-			args,
-			nil,
-		)
-
-		if err != nil {
-			logger.Error(
-				nil,
-				"Failed to stop container %s: %s",
-				containerName,
-				err.Error(),
-			)
-
-			return
-		}
-
-		logger.Info(
-			nil,
-			"Stopped container %s",
-			containerName,
-		)
+		stopContainer(runtime, containerName, logger, nil)
 	}
 
 	return NewContainerList(
@@ -111,7 +85,11 @@ func NewContainerStopper(logger logging.Logger) *ContainerList {
 	)
 }
 
-func NewNetworkDisconnector(runID string, logger logging.Logger) *ContainerList {
+func NewNetworkDisconnector(
+	runID string,
+	runtime runtime.ContainerRuntime,
+	logger logging.Logger,
+) *ContainerList {
 	disconnect := func(containerName string) {
 		logger.Info(
 			nil,
@@ -119,19 +97,10 @@ func NewNetworkDisconnector(runID string, logger logging.Logger) *ContainerList
 			containerName,
 		)
 
-		args := []string{
-			"docker",
-			"network",
-			"disconnect",
-			"--force",
+		err := runtime.DisconnectNetwork(
+			context.Background(),
 			runID,
 			containerName,
-		}
-
-		_, _, err := command.NewRunner(logger).RunForOutput(
-			context.Background(),
-			args,
-			nil,
 		)
 
 		if err != nil {
@@ -158,3 +127,35 @@ func NewNetworkDisconnector(runID string, logger logging.Logger) *ContainerList
 		logger,
 	)
 }
+
+func stopContainer(
+	runtime runtime.ContainerRuntime,
+	containerName string,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+) {
+	logger.Info(
+		prefix,
+		"Stopping container %s",
+		containerName,
+	)
+
+	// Detached containers are not run with --rm so that their state
+	// remains available if they exit early
+	if err := runtime.Remove(context.Background(), containerName); err != nil {
+		logger.Error(
+			prefix,
+			"Failed to stop container %s: %s",
+			containerName,
+			err.Error(),
+		)
+
+		return
+	}
+
+	logger.Info(
+		prefix,
+		"Stopped container %s",
+		containerName,
+	)
+}
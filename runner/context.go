@@ -16,6 +16,10 @@ type RunContext struct {
 	tagsMutex        sync.RWMutex
 	exportedEnv      []string
 	exportedEnvMutex sync.RWMutex
+	completed        map[string]struct{}
+	resumed          map[string]struct{}
+	failedAllowed    map[string]struct{}
+	completedMutex   sync.RWMutex
 }
 
 func NewRunContext(parent *RunContext) *RunContext {
@@ -91,3 +95,129 @@ func (c *RunContext) GetExportedEnv() []string {
 
 	return env
 }
+
+// MarkCompleted records that the task with the given key has completed
+// successfully during this run.
+func (c *RunContext) MarkCompleted(key string) {
+	if c.parent != nil {
+		c.parent.MarkCompleted(key)
+		return
+	}
+
+	c.completedMutex.Lock()
+	defer c.completedMutex.Unlock()
+
+	if c.completed == nil {
+		c.completed = map[string]struct{}{}
+	}
+
+	c.completed[key] = struct{}{}
+}
+
+// MarkFailedAllowed records that the task with the given key has failed
+// during this run but allows failure, so the failure is not propagated.
+func (c *RunContext) MarkFailedAllowed(key string) {
+	if c.parent != nil {
+		c.parent.MarkFailedAllowed(key)
+		return
+	}
+
+	c.completedMutex.Lock()
+	defer c.completedMutex.Unlock()
+
+	if c.failedAllowed == nil {
+		c.failedAllowed = map[string]struct{}{}
+	}
+
+	c.failedAllowed[key] = struct{}{}
+}
+
+// GetFailedAllowed returns the sorted keys of the tasks which have failed
+// during this run but allow failure.
+func (c *RunContext) GetFailedAllowed() []string {
+	if c.parent != nil {
+		return c.parent.GetFailedAllowed()
+	}
+
+	c.completedMutex.RLock()
+	defer c.completedMutex.RUnlock()
+
+	keys := []string{}
+	for key := range c.failedAllowed {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// CompletedPreviously determines if the task with the given key completed
+// successfully in the run being resumed.
+func (c *RunContext) CompletedPreviously(key string) bool {
+	if c.parent != nil {
+		return c.parent.CompletedPreviously(key)
+	}
+
+	c.completedMutex.RLock()
+	defer c.completedMutex.RUnlock()
+
+	_, ok := c.resumed[key]
+	return ok
+}
+
+// Restore populates the context from the state of a previous run.
+func (c *RunContext) Restore(state *RunState) {
+	if c.parent != nil {
+		c.parent.Restore(state)
+		return
+	}
+
+	c.completedMutex.Lock()
+	c.resumed = map[string]struct{}{}
+	for _, key := range state.Completed {
+		c.resumed[key] = struct{}{}
+	}
+	c.completedMutex.Unlock()
+
+	c.exportedEnvMutex.Lock()
+	c.exportedEnv = append(c.exportedEnv, state.ExportedEnv...)
+	c.exportedEnvMutex.Unlock()
+
+	if len(state.Tags) > 0 {
+		c.AddTags(state.Tags)
+	}
+}
+
+// State returns the portion of the context that is persisted so that the
+// run can be resumed. Tasks that completed in a resumed run are included.
+// Tasks whose failure was allowed are recorded but run again on resume.
+func (c *RunContext) State() *RunState {
+	if c.parent != nil {
+		return c.parent.State()
+	}
+
+	c.completedMutex.RLock()
+	keys := map[string]struct{}{}
+	for key := range c.resumed {
+		keys[key] = struct{}{}
+	}
+
+	for key := range c.completed {
+		keys[key] = struct{}{}
+	}
+	c.completedMutex.RUnlock()
+
+	completed := []string{}
+	for key := range keys {
+		completed = append(completed, key)
+	}
+
+	sort.Strings(completed)
+
+	return &RunState{
+		Completed:     completed,
+		FailedAllowed: c.GetFailedAllowed(),
+		Tags:          c.GetTags(),
+		ExportedEnv:   c.GetExportedEnv(),
+	}
+}
@@ -20,3 +20,40 @@ func (s *ContextSuite) TestExportEnv(t sweet.T) {
 	Expect(b.GetExportedEnv()).To(Equal([]string{"X=1", "Y=2", "Z=3"}))
 	Expect(c.GetExportedEnv()).To(Equal([]string{"X=1", "Y=2", "Z=3"}))
 }
+
+func (s *ContextSuite) TestState(t sweet.T) {
+	a := NewRunContext(nil)
+	b := NewRunContext(a)
+
+	b.MarkCompleted("p/s/x")
+	b.MarkFailedAllowed("p/s/y")
+	b.ExportEnv("X=1")
+	b.AddTags([]string{"app:latest"})
+
+	state := a.State()
+	Expect(state.Completed).To(Equal([]string{"p/s/x"}))
+	Expect(state.FailedAllowed).To(Equal([]string{"p/s/y"}))
+	Expect(state.ExportedEnv).To(Equal([]string{"X=1"}))
+	Expect(state.Tags).To(Equal([]string{"app:latest"}))
+	Expect(b.CompletedPreviously("p/s/x")).To(BeFalse())
+}
+
+func (s *ContextSuite) TestRestore(t sweet.T) {
+	a := NewRunContext(nil)
+	b := NewRunContext(a)
+
+	b.Restore(&RunState{
+		Completed:   []string{"p/s/x", "p/s/y"},
+		Tags:        []string{"app:latest"},
+		ExportedEnv: []string{"X=1"},
+	})
+
+	b.MarkCompleted("p/s/z")
+
+	Expect(b.CompletedPreviously("p/s/x")).To(BeTrue())
+	Expect(b.CompletedPreviously("p/s/z")).To(BeFalse())
+	Expect(b.GetExportedEnv()).To(Equal([]string{"X=1"}))
+	Expect(b.GetTags()).To(Equal([]string{"app:latest"}))
+	Expect(a.Environment["IJ_IMAGE_TAGS"]).To(Equal("app:latest"))
+	Expect(a.State().Completed).To(Equal([]string{"p/s/x", "p/s/y", "p/s/z"}))
+}
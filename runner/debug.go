@@ -0,0 +1,316 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
+	"github.com/ij-build/ij/scratch"
+)
+
+type (
+	// Debugger opens an interactive shell in a snapshot of the container
+	// of a failed run task. Only one shell is open at a time, and the user
+	// is asked whether to resume or abort the run once the shell exits.
+	// While a shell is attached, log output is held back, no new task is
+	// started, and the clock of the global timeout is stopped.
+	Debugger struct {
+		runtime      runtime.ContainerRuntime
+		logger       logging.Logger
+		processor    logging.Processor
+		cancel       func()
+		pauseTimeout PauseFunc
+		mutex        sync.Mutex
+		attached     sync.RWMutex
+	}
+
+	debugGatedTaskRunner struct {
+		debugger *Debugger
+		runner   TaskRunner
+	}
+)
+
+func NewDebugger(
+	runtime runtime.ContainerRuntime,
+	logger logging.Logger,
+	processor logging.Processor,
+	cancel func(),
+	pauseTimeout PauseFunc,
+) *Debugger {
+	return &Debugger{
+		runtime:      runtime,
+		logger:       logger,
+		processor:    processor,
+		cancel:       cancel,
+		pauseTimeout: pauseTimeout,
+	}
+}
+
+// Wait blocks while a debug shell is attached to the terminal.
+func (d *Debugger) Wait() {
+	d.attached.RLock()
+	d.attached.RUnlock()
+}
+
+// Debug commits the given container and invokes the command created by the
+// given function with the resulting image. The image is removed once the
+// shell exits.
+func (d *Debugger) Debug(
+	containerName string,
+	buildCommand func(image string) ([]string, error),
+	prefix *logging.Prefix,
+) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !isTerminal(os.Stdin) {
+		d.logger.Warn(
+			prefix,
+			"Cannot debug failed task without an interactive terminal",
+		)
+
+		return
+	}
+
+	image, err := commitContainer(d.runtime, containerName, d.logger, prefix)
+	if err != nil {
+		d.logger.Error(
+			prefix,
+			"Failed to commit container %s: %s",
+			containerName,
+			err.Error(),
+		)
+
+		return
+	}
+
+	defer removeImage(d.runtime, image, d.logger, prefix)
+
+	args, err := buildCommand(image)
+	if err != nil {
+		d.logger.Error(
+			prefix,
+			"Failed to build debug command args: %s",
+			err.Error(),
+		)
+
+		return
+	}
+
+	d.logger.Warn(
+		prefix,
+		"Opening debug shell in snapshot of container %s",
+		containerName,
+	)
+
+	// The shell is not bound to the task context so that the task
+	// timeout does not terminate the session
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	d.attached.Lock()
+	d.processor.Pause()
+	resumeTimeout := d.pauseTimeout()
+
+	err = cmd.Run()
+	fmt.Fprintf(os.Stderr, "Debug shell exited with status %d\n", command.ExitCode(err))
+	resume := promptResume(os.Stdin, os.Stderr)
+
+	resumeTimeout()
+	d.processor.Resume()
+	d.attached.Unlock()
+
+	if !resume {
+		d.logger.Warn(
+			prefix,
+			"Aborting run after debug shell",
+		)
+
+		d.cancel()
+		return
+	}
+
+	d.logger.Info(
+		prefix,
+		"Resuming run after debug shell",
+	)
+}
+
+// debugGateTaskRunnerFactory wraps the given factory so that the runners
+// it creates do not start while a debug shell is attached.
+func debugGateTaskRunnerFactory(factory TaskRunnerFactory, debugger *Debugger) TaskRunnerFactory {
+	if debugger == nil {
+		return factory
+	}
+
+	return func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return &debugGatedTaskRunner{
+			debugger: debugger,
+			runner:   factory(ctx, context, task, retryPolicy, prefix, env),
+		}
+	}
+}
+
+func (r *debugGatedTaskRunner) Run(context *RunContext) bool {
+	r.debugger.Wait()
+	return r.runner.Run(context)
+}
+
+// runTaskDebugOptionsFactory creates the options of an interactive container
+// which runs the shell of the given task in the given image.
+func runTaskDebugOptionsFactory(
+	runID string,
+	config *config.Config,
+	containerOptions *containerOptions,
+	scratch *scratch.ScratchSpace,
+	writeScript ScriptWriter,
+	task *config.RunTask,
+	containerName string,
+	image string,
+	env environment.Environment,
+) (*runtime.RunOptions, error) {
+	s := &runTaskOptionsState{
+		runID:            runID,
+		config:           config,
+		containerOptions: containerOptions,
+		scratch:          scratch,
+		writeScript:      writeScript,
+		task:             task,
+		containerName:    containerName,
+		env:              env,
+	}
+
+	options := &runtime.RunOptions{
+		Image:       image,
+		Remove:      true,
+		Interactive: true,
+	}
+
+	addShell := func(options *runtime.RunOptions) error {
+		shell, err := s.shell()
+		if err != nil {
+			return err
+		}
+
+		options.Entrypoint = shell
+		return nil
+	}
+
+	return options, s.apply(
+		options,
+		addShell,
+		s.addScriptMount,
+		s.addCacheOptions,
+		s.addContainerName,
+		s.addEnvironmentOptions,
+		s.addLimitOptions,
+		s.addMountOptions,
+		s.addNetworkOptions,
+		s.addSSHOptions,
+		s.addUserOptions,
+		s.addWorkspaceOptions,
+	)
+}
+
+//
+// Helpers
+
+// promptResume asks whether the run should resume once a debug shell has
+// exited. Anything but an explicit answer is asked again, and the run is
+// aborted if the input is closed.
+func promptResume(in io.Reader, out io.Writer) bool {
+	reader := bufio.NewReader(in)
+
+	for {
+		fmt.Fprint(out, "Resume the run? [y/n] ")
+
+		line, err := reader.ReadString('\n')
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+
+		if err != nil {
+			fmt.Fprintln(out)
+			return false
+		}
+	}
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func commitContainer(
+	runtime runtime.ContainerRuntime,
+	containerName string,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+) (string, error) {
+	logger.Info(
+		prefix,
+		"Committing container %s",
+		containerName,
+	)
+
+	return runtime.Commit(context.Background(), containerName)
+}
+
+func removeContainer(
+	runtime runtime.ContainerRuntime,
+	containerName string,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+) {
+	if err := runtime.Remove(context.Background(), containerName); err != nil {
+		logger.Error(
+			prefix,
+			"Failed to remove container %s: %s",
+			containerName,
+			err.Error(),
+		)
+	}
+}
+
+func removeImage(
+	runtime runtime.ContainerRuntime,
+	image string,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+) {
+	if err := runtime.RemoveImage(context.Background(), image); err != nil {
+		logger.Error(
+			prefix,
+			"Failed to remove image %s: %s",
+			image,
+			err.Error(),
+		)
+	}
+}
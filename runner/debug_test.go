@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
+	"github.com/ij-build/ij/scratch"
+	. "github.com/onsi/gomega"
+)
+
+type DebugSuite struct{}
+
+func (s *DebugSuite) TestDebugCommand(t sweet.T) {
+	var (
+		task = &config.RunTask{
+			Image:  "golang:${GO}",
+			Script: "go test ./...",
+			Shell:  "/bin/bash",
+		}
+
+		writeScript = func(script string) (string, error) {
+			return "/scripts/1", nil
+		}
+	)
+
+	options, err := runTaskDebugOptionsFactory(
+		"run",
+		&config.Config{},
+		&containerOptions{Memory: "1g"},
+		scratch.NewScratchSpace("run", "/project", "/project", false),
+		writeScript,
+		task,
+		"debug",
+		"sha256:abcd",
+		environment.New([]string{"GO=1.21"}),
+	)
+
+	Expect(err).To(BeNil())
+
+	args := runtime.NewFakeRuntime().RunArgs(options)
+	Expect(args[:4]).To(Equal([]string{"docker", "run", "--rm", "-it"}))
+	Expect(args[len(args)-1]).To(Equal("sha256:abcd"))
+	Expect(args).NotTo(ContainElement("golang:1.21"))
+	Expect(args).NotTo(ContainElement(ScriptPath))
+	Expect(hasFlagValue(args, "--entrypoint", "/bin/bash")).To(BeTrue())
+	Expect(hasFlagValue(args, "-v", "/scripts/1:"+ScriptPath)).To(BeTrue())
+	Expect(hasFlagValue(args, "--name", "debug")).To(BeTrue())
+	Expect(hasFlagValue(args, "--network", "run")).To(BeTrue())
+	Expect(hasFlagValue(args, "--memory", "1g")).To(BeTrue())
+	Expect(hasFlagValue(args, "-e", "GO=1.21")).To(BeTrue())
+	Expect(hasFlagValue(args, "-w", DefaultWorkspacePath)).To(BeTrue())
+}
+
+func (s *DebugSuite) TestKeepContainer(t sweet.T) {
+	build := func(containerOptions *containerOptions) *runtime.RunOptions {
+		options, err := testRunOptions(
+			&config.Config{},
+			containerOptions,
+			&config.RunTask{Image: "alpine", Healthcheck: &config.Healthcheck{}},
+			environment.New(nil),
+		)
+
+		Expect(err).To(BeNil())
+		return options
+	}
+
+	Expect(build(&containerOptions{}).Remove).To(BeTrue())
+	Expect(build(&containerOptions{KeepContainer: true}).Remove).To(BeFalse())
+}
+
+func (s *DebugSuite) TestDebugGate(t sweet.T) {
+	var (
+		debugger = NewDebugger(runtime.NewFakeRuntime(), logging.NilLogger, nil, nil, nil)
+		ran      = make(chan struct{})
+	)
+
+	factory := debugGateTaskRunnerFactory(func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return testTaskRunner(func(context *RunContext) bool {
+			close(ran)
+			return true
+		})
+	}, debugger)
+
+	runner := factory(context.Background(), NewRunContext(nil), &config.RunTask{}, nil, logging.NewPrefix(), nil)
+
+	// Simulate an attached shell
+	debugger.attached.Lock()
+	go runner.Run(NewRunContext(nil))
+	Consistently(ran).ShouldNot(BeClosed())
+
+	debugger.attached.Unlock()
+	Eventually(ran).Should(BeClosed())
+}
+
+func (s *DebugSuite) TestPromptResume(t sweet.T) {
+	var (
+		out    = &bytes.Buffer{}
+		prompt = "Resume the run? [y/n] "
+	)
+
+	Expect(promptResume(strings.NewReader("y\n"), out)).To(BeTrue())
+	Expect(promptResume(strings.NewReader("maybe\nYES\n"), out)).To(BeTrue())
+	Expect(promptResume(strings.NewReader("n\n"), out)).To(BeFalse())
+	Expect(promptResume(strings.NewReader(""), out)).To(BeFalse())
+	Expect(strings.Count(out.String(), prompt)).To(Equal(5))
+}
+
+func hasFlagValue(args []string, flag, value string) bool {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag && args[i+1] == value {
+			return true
+		}
+	}
+
+	return false
+}
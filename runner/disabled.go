@@ -0,0 +1,37 @@
+package runner
+
+import (
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/expression"
+)
+
+// isDisabled determines if a plan, stage, or stage task should be skipped.
+// It is skipped if its disabled property expands to a non-empty string or
+// if its condition does not hold. The failure flag determines the result
+// of the `failed()` function within the condition.
+func isDisabled(
+	disabled string,
+	condition string,
+	env environment.Environment,
+	failure bool,
+) (bool, error) {
+	val, err := env.ExpandString(disabled)
+	if err != nil {
+		return false, err
+	}
+
+	if val != "" {
+		return true, nil
+	}
+
+	if condition == "" {
+		return false, nil
+	}
+
+	ok, err := expression.Evaluate(condition, env, failure)
+	if err != nil {
+		return false, err
+	}
+
+	return !ok, nil
+}
@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/environment"
+	. "github.com/onsi/gomega"
+)
+
+type DisabledSuite struct{}
+
+func (s *DisabledSuite) TestIsDisabled(t sweet.T) {
+	env := environment.New([]string{"BRANCH=main", "SKIP=${BRANCH}"})
+
+	cases := []struct {
+		disabled  string
+		condition string
+		failure   bool
+		expected  bool
+	}{
+		{"", "", false, false},
+		{"${SKIP}", "", false, true},
+		{"", `BRANCH == "main"`, false, false},
+		{"", `BRANCH != "main"`, false, true},
+		{"", `failed()`, false, true},
+		{"", `failed()`, true, false},
+		{"${SKIP}", `BRANCH == "main"`, false, true},
+	}
+
+	for _, c := range cases {
+		disabled, err := isDisabled(c.disabled, c.condition, env, c.failure)
+		Expect(err).To(BeNil())
+		Expect(disabled).To(Equal(c.expected), c.disabled+" "+c.condition)
+	}
+}
+
+func (s *DisabledSuite) TestIsDisabledInvalidCondition(t sweet.T) {
+	_, err := isDisabled("", `BRANCH ==`, environment.New(nil), false)
+	Expect(err).NotTo(BeNil())
+}
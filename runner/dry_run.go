@@ -0,0 +1,495 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+
+	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/options"
+	"github.com/ij-build/ij/paths"
+	"github.com/ij-build/ij/runtime"
+	"github.com/ij-build/ij/scratch"
+	"github.com/ij-build/ij/util"
+)
+
+type (
+	DryRunner struct {
+		ctx               context.Context
+		logger            logging.Logger
+		config            *config.Config
+		taskRunnerFactory TaskRunnerFactory
+		scratch           *scratch.ScratchSpace
+		cleanup           *Cleanup
+		env               []string
+		filter            *TaskFilter
+	}
+
+	dryTaskRunner struct {
+		logger    logging.Logger
+		prefix    *logging.Prefix
+		factory   BuilderSetFactory
+		onSuccess HookFunc
+	}
+
+	nopWriteCloser struct {
+		io.Writer
+	}
+)
+
+// SetupDryRunner creates a runner which walks the given plans in the same
+// manner as a real run, but prints the command that each task would invoke
+// instead of invoking it. No scratch directories, networks, or containers
+// are created.
+func SetupDryRunner(
+	cfg *config.Config,
+	appOptions *options.AppOptions,
+	runOptions *options.RunOptions,
+) (*DryRunner, error) {
+	var (
+		cleanup           = NewCleanup()
+		ctx               = runOptions.Context
+		taskRunnerFactory TaskRunnerFactory
+	)
+
+	filter, err := setupFilter(cfg, runOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	runID, err := setupRunID()
+	if err != nil {
+		return nil, err
+	}
+
+	enableHostSSHAgent, err := shouldEnableHostSSHAgent(
+		runOptions.EnableContainerSSHAgent,
+		cfg.Options.SSHIdentities,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	scratch := scratch.NewScratchSpace(
+		runID,
+		appOptions.ProjectDir,
+		appOptions.ScratchRoot,
+		runOptions.KeepWorkspace,
+	)
+
+	fileFactory := appOptions.FileFactory
+	if fileFactory == nil {
+		fileFactory = func(prefix string) (io.WriteCloser, io.WriteCloser, error) {
+			return nopWriteCloser{ioutil.Discard}, nopWriteCloser{ioutil.Discard}, nil
+		}
+	}
+
+	logger, _, err := setupLogger(
+		cleanup,
+		scratch,
+		appOptions.Quiet,
+		appOptions.Verbose,
+		!appOptions.DisableColor,
+		fileFactory,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Commands are only printed, so the daemon is never contacted
+	containerRuntime := runtime.NewCLIRuntime(cfg.Options.Runtime, logger)
+
+	containerOptions := &containerOptions{
+		EnableHostSSHAgent:      enableHostSSHAgent,
+		EnableContainerSSHAgent: runOptions.EnableContainerSSHAgent,
+		CPUShares:               runOptions.CPUShares,
+		Memory:                  runOptions.Memory,
+	}
+
+	taskRunnerFactory = func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		switch t := task.(type) {
+		case *config.BuildTask:
+			return &dryTaskRunner{
+				logger:    logger,
+				prefix:    prefix,
+				factory:   dryBuildTaskFactory(containerRuntime, scratch.Workspace(), t, env),
+				onSuccess: buildTaskTagHook(t, env),
+			}
+
+		case *config.HostTask:
+			factory := func() (*command.Builder, error) {
+				dir, err := hostTaskWorkdir(scratch, t)
+				if err != nil {
+					return nil, err
+				}
+
+				logger.Info(prefix, "Would run on host from %s", dir)
+
+				return hostTaskCommandBuilderFactory(
+					dryScriptWriter(scratch, logger, prefix),
+					t,
+					env,
+				), nil
+			}
+
+			return &dryTaskRunner{
+				logger:  logger,
+				prefix:  prefix,
+				factory: NewMultiFactory(factory),
+			}
+
+		case *config.PushTask:
+			return &dryTaskRunner{
+				logger:  logger,
+				prefix:  prefix,
+				factory: dryPushTaskFactory(containerRuntime, context, t, env),
+			}
+
+		case *config.RemoveTask:
+			return &dryTaskRunner{
+				logger:  logger,
+				prefix:  prefix,
+				factory: dryRemoveTaskFactory(containerRuntime, context, t, env),
+			}
+
+		case *config.RunTask:
+			return &dryTaskRunner{
+				logger: logger,
+				prefix: prefix,
+				factory: dryRunTaskFactory(
+					containerRuntime,
+					runID,
+					cfg,
+					containerOptions,
+					scratch,
+					dryScriptWriter(scratch, logger, prefix),
+					t,
+					env,
+				),
+				onSuccess: dryExportEnvironmentHook(t, env, logger, prefix),
+			}
+
+		case *config.PlanTask:
+			runner := NewPlanRunner(
+				ctx,
+				cfg,
+				taskRunnerFactory,
+				logger,
+				appOptions.Env,
+				filter,
+			)
+
+			return NewPlanTaskRunnerFactory(
+				runner,
+				logger,
+			)(
+				t,
+				env,
+				prefix,
+			)
+		}
+
+		panic("unexpected task type")
+	}
+
+	return &DryRunner{
+		ctx:               ctx,
+		logger:            logger,
+		config:            cfg,
+		taskRunnerFactory: taskRunnerFactory,
+		scratch:           scratch,
+		cleanup:           cleanup,
+		env:               appOptions.Env,
+		filter:            filter,
+	}, nil
+}
+
+func (r *DryRunner) Run(plans []string) bool {
+	r.logger.Info(
+		nil,
+		"Beginning dry run",
+	)
+
+	defer r.cleanup.Cleanup()
+
+	transferer := paths.NewTransferer(
+		r.scratch.Project(),
+		r.scratch.Scratch(),
+		r.scratch.Workspace(),
+		r.logger,
+	)
+
+	pairs, err := transferer.ListImports(
+		r.config.Import.Files,
+		r.config.Import.Excludes,
+	)
+
+	if err != nil {
+		r.logger.Error(
+			nil,
+			"Failed to list files to import to workspace: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	for _, pair := range pairs {
+		if pair.Src == pair.Dest {
+			r.logger.Info(nil, "Would import %s", pair.Src)
+		} else {
+			r.logger.Info(nil, "Would import %s as %s", pair.Src, pair.Dest)
+		}
+	}
+
+	var (
+		failure     = false
+		rootContext = NewRunContext(nil)
+	)
+
+	for _, name := range plans {
+		runner := NewPlanRunner(
+			r.ctx,
+			r.config,
+			r.taskRunnerFactory,
+			r.logger,
+			r.env,
+			r.filter,
+		)
+
+		newContext := NewRunContext(rootContext)
+		newContext.Failure = failure
+
+		if !runner.Run(newContext, name, logging.NewPrefix()) {
+			failure = true
+		}
+	}
+
+	if failure {
+		return false
+	}
+
+	for _, pattern := range r.config.Export.Files {
+		r.logger.Info(nil, "Would export files matching %s", pattern)
+	}
+
+	for _, pattern := range r.config.Export.Excludes {
+		r.logger.Info(nil, "Would not export files matching %s", pattern)
+	}
+
+	return true
+}
+
+func (r *dryTaskRunner) Run(context *RunContext) bool {
+	builders, err := r.factory()
+	if err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to build command args: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	for _, builder := range builders {
+		args, stdin, err := builder.Build()
+		if err != nil {
+			r.logger.Error(
+				r.prefix,
+				"Failed to build command args: %s",
+				err.Error(),
+			)
+
+			return false
+		}
+
+		if stdin != nil {
+			stdin.Close()
+		}
+
+		r.logger.Info(
+			r.prefix,
+			"Would run: %s",
+			shellquote.Join(command.MaskSecrets(args)...),
+		)
+	}
+
+	if r.onSuccess != nil {
+		if err := r.onSuccess(context); err != nil {
+			r.logger.Error(
+				r.prefix,
+				"Success hook failed: %s",
+				err.Error(),
+			)
+
+			return false
+		}
+	}
+
+	return true
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+//
+// Helpers
+
+// dryBuildTaskFactory creates the command which builds the image of the
+// given task with the command line client.
+func dryBuildTaskFactory(
+	containerRuntime runtime.ContainerRuntime,
+	workspace string,
+	task *config.BuildTask,
+	env environment.Environment,
+) BuilderSetFactory {
+	return func() ([]*command.Builder, error) {
+		options, err := buildTaskOptionsFactory(workspace, task, env)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*command.Builder{command.NewBuilder(containerRuntime.BuildArgs(options), nil)}, nil
+	}
+}
+
+// dryPushTaskFactory creates the commands which push the images of the
+// given task with the command line client.
+func dryPushTaskFactory(
+	containerRuntime runtime.ContainerRuntime,
+	context *RunContext,
+	task *config.PushTask,
+	env environment.Environment,
+) BuilderSetFactory {
+	return func() ([]*command.Builder, error) {
+		images, err := pushTaskImages(context, task, env)
+		if err != nil {
+			return nil, err
+		}
+
+		builders := []*command.Builder{}
+		for _, image := range images {
+			builders = append(builders, command.NewBuilder(containerRuntime.PushArgs(image), nil))
+		}
+
+		return builders, nil
+	}
+}
+
+// dryRemoveTaskFactory creates the commands which remove the images of the
+// given task with the command line client.
+func dryRemoveTaskFactory(
+	containerRuntime runtime.ContainerRuntime,
+	context *RunContext,
+	task *config.RemoveTask,
+	env environment.Environment,
+) BuilderSetFactory {
+	return func() ([]*command.Builder, error) {
+		images, err := removeTaskImages(context, task, env)
+		if err != nil {
+			return nil, err
+		}
+
+		builders := []*command.Builder{}
+		for _, image := range images {
+			builders = append(builders, command.NewBuilder(containerRuntime.RemoveImageArgs(image), nil))
+		}
+
+		return builders, nil
+	}
+}
+
+// dryRunTaskFactory creates the command which runs the container of the
+// given task with the command line client.
+func dryRunTaskFactory(
+	containerRuntime runtime.ContainerRuntime,
+	runID string,
+	cfg *config.Config,
+	containerOptions *containerOptions,
+	scratch *scratch.ScratchSpace,
+	writeScript ScriptWriter,
+	task *config.RunTask,
+	env environment.Environment,
+) BuilderSetFactory {
+	factory := func() (*command.Builder, error) {
+		containerName, err := util.MakeID()
+		if err != nil {
+			return nil, err
+		}
+
+		options, err := runTaskRunOptionsFactory(
+			runID,
+			cfg,
+			containerOptions,
+			scratch,
+			writeScript,
+			task,
+			containerName,
+			env,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return command.NewBuilder(containerRuntime.RunArgs(options), nil), nil
+	}
+
+	return NewMultiFactory(factory)
+}
+
+// dryScriptWriter prints the script of a run task in place of writing it
+// to the scratch directory and returns the path where it would be written.
+func dryScriptWriter(
+	scratchSpace *scratch.ScratchSpace,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+) ScriptWriter {
+	return func(script string) (string, error) {
+		for _, line := range strings.Split(strings.TrimRight(script, "\n"), "\n") {
+			logger.Info(prefix, "Script: %s", line)
+		}
+
+		return filepath.Join(scratchSpace.Runpath(), scratch.ScriptsDir, "script"), nil
+	}
+}
+
+// dryExportEnvironmentHook prints the environment files that a run task
+// would inject into subsequent tasks.
+func dryExportEnvironmentHook(
+	task *config.RunTask,
+	env environment.Environment,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+) HookFunc {
+	return func(context *RunContext) error {
+		paths, err := env.ExpandSlice(task.ExportEnvironmentFiles)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range paths {
+			logger.Info(prefix, "Would inject environment from file %s", path)
+		}
+
+		return nil
+	}
+}
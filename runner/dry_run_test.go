@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
+	"github.com/ij-build/ij/scratch"
+	"github.com/kballard/go-shellquote"
+	. "github.com/onsi/gomega"
+)
+
+type DryRunSuite struct{}
+
+func (s *DryRunSuite) TestRunBuildTask(t sweet.T) {
+	var (
+		task = &config.BuildTask{
+			Dockerfile: "Dockerfile",
+			Tags:       []string{"app:${VERSION}"},
+		}
+
+		env     = environment.New([]string{"VERSION=1.2"})
+		context = NewRunContext(nil)
+	)
+
+	runner := &dryTaskRunner{
+		logger:    logging.NilLogger,
+		prefix:    logging.NewPrefix("build"),
+		factory:   dryBuildTaskFactory(runtime.NewFakeRuntime(), "/workspace", task, env),
+		onSuccess: buildTaskTagHook(task, env),
+	}
+
+	Expect(runner.Run(context)).To(BeTrue())
+	Expect(context.GetTags()).To(Equal([]string{"app:1.2"}))
+}
+
+func (s *DryRunSuite) TestRunRunTask(t sweet.T) {
+	var (
+		logger = &recordingLogger{Logger: logging.NilLogger}
+		prefix = logging.NewPrefix("test")
+		space  = scratch.NewScratchSpace("run", "/project", "/project", false)
+		env    = environment.New([]string{"GO=1.21", "API_SECRET=hunter2"})
+	)
+
+	task := &config.RunTask{
+		Image:       "golang:${GO}",
+		Command:     "go test ./...",
+		Healthcheck: &config.Healthcheck{},
+		Mounts: []*config.Mount{
+			&config.Mount{Type: "volume", Source: "gomod", Target: "/go/pkg/mod"},
+		},
+	}
+
+	runner := &dryTaskRunner{
+		logger: logger,
+		prefix: prefix,
+		factory: dryRunTaskFactory(
+			runtime.NewCLIRuntime("docker", logging.NilLogger),
+			"run",
+			&config.Config{Options: &config.Options{}},
+			&containerOptions{},
+			space,
+			dryScriptWriter(space, logger, prefix),
+			task,
+			env,
+		),
+	}
+
+	Expect(runner.Run(NewRunContext(nil))).To(BeTrue())
+	Expect(logger.lines).To(HaveLen(1))
+
+	line := logger.lines[0]
+	Expect(line).To(HavePrefix("info: Would run: docker run"))
+	Expect(line).To(ContainSubstring("golang:1.21 go test ./..."))
+	Expect(line).To(ContainSubstring("-e GO=1.21"))
+	Expect(line).To(ContainSubstring("--mount type=volume,source=gomod,target=/go/pkg/mod"))
+	Expect(line).To(ContainSubstring("-e " + shellquote.Join("API_SECRET="+command.SecretMask)))
+	Expect(strings.Contains(line, "hunter2")).To(BeFalse())
+}
+
+func (s *DryRunSuite) TestRunBuildError(t sweet.T) {
+	runner := &dryTaskRunner{
+		logger: logging.NilLogger,
+		prefix: logging.NewPrefix("build"),
+		factory: func() ([]*command.Builder, error) {
+			return nil, fmt.Errorf("utoh")
+		},
+	}
+
+	Expect(runner.Run(NewRunContext(nil))).To(BeFalse())
+}
@@ -0,0 +1,46 @@
+package runner
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type (
+	failFastKey struct{}
+
+	failFastFlag struct {
+		parent    *failFastFlag
+		triggered int32
+	}
+)
+
+// withFailFast derives a context shared by sibling tasks. The returned
+// function cancels the context on behalf of a sibling task that failed.
+func withFailFast(ctx context.Context) (context.Context, func()) {
+	parent, _ := ctx.Value(failFastKey{}).(*failFastFlag)
+	flag := &failFastFlag{parent: parent}
+
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, failFastKey{}, flag))
+
+	return ctx, func() {
+		atomic.StoreInt32(&flag.triggered, 1)
+		cancel()
+	}
+}
+
+// cancelled determines if the given context was canceled due to the failure
+// of a sibling task (and not because of a timeout or an interrupt).
+func cancelled(ctx context.Context) bool {
+	if ctx.Err() != context.Canceled {
+		return false
+	}
+
+	flag, _ := ctx.Value(failFastKey{}).(*failFastFlag)
+	for ; flag != nil; flag = flag.parent {
+		if atomic.LoadInt32(&flag.triggered) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"context"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type FailFastSuite struct{}
+
+func (s *FailFastSuite) TestCancelled(t sweet.T) {
+	ctx, failFast := withFailFast(context.Background())
+	taskCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	Expect(cancelled(taskCtx)).To(BeFalse())
+	failFast()
+	Expect(cancelled(taskCtx)).To(BeTrue())
+}
+
+func (s *FailFastSuite) TestCancelledNested(t sweet.T) {
+	ctx, failFast := withFailFast(context.Background())
+	nestedCtx, nestedFailFast := withFailFast(ctx)
+	defer nestedFailFast()
+
+	failFast()
+	Expect(cancelled(nestedCtx)).To(BeTrue())
+}
+
+func (s *FailFastSuite) TestCancelledByParent(t sweet.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, failFast := withFailFast(parent)
+	defer failFast()
+
+	cancel()
+	Expect(ctx.Err()).NotTo(BeNil())
+	Expect(cancelled(ctx)).To(BeFalse())
+}
@@ -0,0 +1,133 @@
+package runner
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ij-build/ij/config"
+)
+
+// TaskFilter selects the stage tasks of a run. A pattern is a glob that
+// is matched against the trailing components of the `plan/stage/task`
+// path of a stage task, so `test` matches the task test of any stage and
+// `build/*` matches every task of the stage build.
+type TaskFilter struct {
+	only      []string
+	skip      []string
+	fromStage string
+}
+
+func NewTaskFilter(only, skip []string, fromStage string) (*TaskFilter, error) {
+	for _, pattern := range append(append([]string{}, only...), skip...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("malformed task pattern %s", pattern)
+		}
+	}
+
+	if len(only) == 0 && len(skip) == 0 && fromStage == "" {
+		return nil, nil
+	}
+
+	return &TaskFilter{
+		only:      only,
+		skip:      skip,
+		fromStage: fromStage,
+	}, nil
+}
+
+// Validate ensures that the stage named by the from-stage option is
+// declared by at least one plan.
+func (f *TaskFilter) Validate(config *config.Config) error {
+	if f == nil || f.fromStage == "" {
+		return nil
+	}
+
+	for _, plan := range config.Plans {
+		if fromStageIndex(plan, f.fromStage) >= 0 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown stage %s", f.fromStage)
+}
+
+// SkipsStage determines if the stage precedes the stage at which the run
+// of the plan should begin.
+func (f *TaskFilter) SkipsStage(plan *config.Plan, stage *config.Stage) bool {
+	if f == nil || f.fromStage == "" {
+		return false
+	}
+
+	index := fromStageIndex(plan, f.fromStage)
+	if index < 0 {
+		return false
+	}
+
+	for i, other := range plan.Stages {
+		if other == stage {
+			return i < index
+		}
+	}
+
+	return false
+}
+
+// SkipsTask determines if the given stage task is excluded from the run.
+// Plan tasks are not subject to the only patterns so that the tasks of
+// the plans they invoke can be selected individually.
+func (f *TaskFilter) SkipsTask(plan *config.Plan, stage *config.Stage, task config.Task) bool {
+	if f == nil {
+		return false
+	}
+
+	if f.SkipsStage(plan, stage) {
+		return true
+	}
+
+	components := []string{plan.Name, stage.Name, task.GetName()}
+
+	if matchesAny(f.skip, components) {
+		return true
+	}
+
+	if _, ok := task.(*config.PlanTask); ok {
+		return false
+	}
+
+	return len(f.only) > 0 && !matchesAny(f.only, components)
+}
+
+//
+// Helpers
+
+// fromStageIndex returns the index of the stage of the plan named by the
+// from-stage option, which is either `stage` or `plan/stage`.
+func fromStageIndex(plan *config.Plan, fromStage string) int {
+	if parts := strings.SplitN(fromStage, "/", 2); len(parts) == 2 {
+		if parts[0] != plan.Name {
+			return -1
+		}
+
+		fromStage = parts[1]
+	}
+
+	return plan.StageIndex(fromStage)
+}
+
+func matchesAny(patterns []string, components []string) bool {
+	for _, pattern := range patterns {
+		n := len(strings.Split(pattern, "/"))
+		if n > len(components) {
+			continue
+		}
+
+		name := strings.Join(components[len(components)-n:], "/")
+
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
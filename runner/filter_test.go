@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/config"
+	. "github.com/onsi/gomega"
+)
+
+type FilterSuite struct{}
+
+func (s *FilterSuite) TestSkipsTaskOnly(t sweet.T) {
+	plan, build, test := newFilterTestPlan()
+
+	filter, err := NewTaskFilter([]string{"unit"}, nil, "")
+	Expect(err).To(BeNil())
+	Expect(filter.SkipsTask(plan, test, newFilterTestTask("unit"))).To(BeFalse())
+	Expect(filter.SkipsTask(plan, test, newFilterTestTask("integration"))).To(BeTrue())
+	Expect(filter.SkipsTask(plan, build, newFilterTestTask("compile"))).To(BeTrue())
+
+	filter, err = NewTaskFilter([]string{"build/*", "p/test/int*"}, nil, "")
+	Expect(err).To(BeNil())
+	Expect(filter.SkipsTask(plan, build, newFilterTestTask("compile"))).To(BeFalse())
+	Expect(filter.SkipsTask(plan, test, newFilterTestTask("integration"))).To(BeFalse())
+	Expect(filter.SkipsTask(plan, test, newFilterTestTask("unit"))).To(BeTrue())
+}
+
+func (s *FilterSuite) TestSkipsTaskSkip(t sweet.T) {
+	plan, build, test := newFilterTestPlan()
+
+	filter, err := NewTaskFilter(nil, []string{"test/integration"}, "")
+	Expect(err).To(BeNil())
+	Expect(filter.SkipsTask(plan, test, newFilterTestTask("integration"))).To(BeTrue())
+	Expect(filter.SkipsTask(plan, test, newFilterTestTask("unit"))).To(BeFalse())
+	Expect(filter.SkipsTask(plan, build, newFilterTestTask("integration"))).To(BeFalse())
+}
+
+func (s *FilterSuite) TestSkipsTaskPlanTask(t sweet.T) {
+	plan, _, test := newFilterTestPlan()
+	task := &config.PlanTask{TaskMeta: config.TaskMeta{Name: "nested"}, Name: "other"}
+
+	filter, err := NewTaskFilter([]string{"unit"}, nil, "")
+	Expect(err).To(BeNil())
+	Expect(filter.SkipsTask(plan, test, task)).To(BeFalse())
+
+	filter, err = NewTaskFilter(nil, []string{"nested"}, "")
+	Expect(err).To(BeNil())
+	Expect(filter.SkipsTask(plan, test, task)).To(BeTrue())
+}
+
+func (s *FilterSuite) TestSkipsStage(t sweet.T) {
+	plan, build, test := newFilterTestPlan()
+
+	filter, err := NewTaskFilter(nil, nil, "test")
+	Expect(err).To(BeNil())
+	Expect(filter.SkipsStage(plan, build)).To(BeTrue())
+	Expect(filter.SkipsStage(plan, test)).To(BeFalse())
+	Expect(filter.SkipsTask(plan, build, newFilterTestTask("compile"))).To(BeTrue())
+
+	filter, err = NewTaskFilter(nil, nil, "other/test")
+	Expect(err).To(BeNil())
+	Expect(filter.SkipsStage(plan, build)).To(BeFalse())
+}
+
+func (s *FilterSuite) TestNil(t sweet.T) {
+	plan, build, _ := newFilterTestPlan()
+
+	filter, err := NewTaskFilter(nil, nil, "")
+	Expect(err).To(BeNil())
+	Expect(filter).To(BeNil())
+	Expect(filter.SkipsStage(plan, build)).To(BeFalse())
+	Expect(filter.SkipsTask(plan, build, newFilterTestTask("compile"))).To(BeFalse())
+}
+
+func (s *FilterSuite) TestMalformedPattern(t sweet.T) {
+	_, err := NewTaskFilter([]string{"[unit"}, nil, "")
+	Expect(err).To(MatchError("malformed task pattern [unit"))
+}
+
+func (s *FilterSuite) TestValidate(t sweet.T) {
+	plan, _, _ := newFilterTestPlan()
+	cfg := &config.Config{Plans: map[string]*config.Plan{"p": plan}}
+
+	filter, err := NewTaskFilter(nil, nil, "p/test")
+	Expect(err).To(BeNil())
+	Expect(filter.Validate(cfg)).To(BeNil())
+
+	filter, err = NewTaskFilter(nil, nil, "deploy")
+	Expect(err).To(BeNil())
+	Expect(filter.Validate(cfg)).To(MatchError("unknown stage deploy"))
+}
+
+func newFilterTestPlan() (*config.Plan, *config.Stage, *config.Stage) {
+	var (
+		build = &config.Stage{Name: "build"}
+		test  = &config.Stage{Name: "test"}
+	)
+
+	return &config.Plan{Name: "p", Stages: []*config.Stage{build, test}}, build, test
+}
+
+func newFilterTestTask(name string) config.Task {
+	return &config.RunTask{TaskMeta: config.TaskMeta{Name: name}}
+}
@@ -0,0 +1,294 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kballard/go-shellquote"
+
+	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/scratch"
+	"github.com/ij-build/ij/volume"
+)
+
+type (
+	HostTaskRunnerFactory func(
+		*config.HostTask,
+		*config.RetryPolicy,
+		environment.Environment,
+		*logging.Prefix,
+	) TaskRunner
+
+	hostTaskRunner struct {
+		ctx         context.Context
+		scratch     *scratch.ScratchSpace
+		volume      *volume.Volume
+		logger      logging.Logger
+		task        *config.HostTask
+		retryPolicy *config.RetryPolicy
+		env         environment.Environment
+		prefix      *logging.Prefix
+	}
+)
+
+func NewHostTaskRunnerFactory(
+	ctx context.Context,
+	scratch *scratch.ScratchSpace,
+	volume *volume.Volume,
+	logger logging.Logger,
+) HostTaskRunnerFactory {
+	return func(
+		task *config.HostTask,
+		retryPolicy *config.RetryPolicy,
+		env environment.Environment,
+		prefix *logging.Prefix,
+	) TaskRunner {
+		return &hostTaskRunner{
+			ctx:         ctx,
+			scratch:     scratch,
+			volume:      volume,
+			logger:      logger,
+			task:        task,
+			retryPolicy: retryPolicy,
+			env:         env,
+			prefix:      prefix,
+		}
+	}
+}
+
+func (r *hostTaskRunner) Run(context *RunContext) bool {
+	r.logger.Info(
+		r.prefix,
+		"Beginning task",
+	)
+
+	// A task invoked from the workspace must see the files written by
+	// containers, and containers must see the files written by the task
+	inWorkspace := r.task.Workdir == config.HostWorkdirWorkspace
+
+	if !inWorkspace {
+		return runWithRetries(
+			r.ctx,
+			r.logger,
+			r.prefix,
+			r.retryPolicy,
+			r.runAttempt,
+		)
+	}
+
+	if !r.pullWorkspace() {
+		return false
+	}
+
+	stamps, err := snapshotWorkspace(r.volume, r.scratch)
+	if err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to snapshot workspace: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	ok := runWithRetries(
+		r.ctx,
+		r.logger,
+		r.prefix,
+		r.retryPolicy,
+		r.runAttempt,
+	)
+
+	if !r.pushChanges(stamps) {
+		return false
+	}
+
+	return ok
+}
+
+func (r *hostTaskRunner) runAttempt() (bool, error) {
+	args, _, err := hostTaskCommandBuilderFactory(
+		r.scratch.WriteScript,
+		r.task,
+		r.env,
+	).Build()
+
+	if err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to build command args: %s",
+			err.Error(),
+		)
+
+		return false, nil
+	}
+
+	dir, err := hostTaskWorkdir(r.scratch, r.task)
+	if err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to determine working directory: %s",
+			err.Error(),
+		)
+
+		return false, nil
+	}
+
+	env, err := hostTaskEnvironment(r.env)
+	if err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to build environment: %s",
+			err.Error(),
+		)
+
+		return false, nil
+	}
+
+	err = command.NewHostRunner(r.logger, dir, env).Run(
+		r.ctx,
+		args,
+		nil,
+		r.prefix,
+	)
+
+	if err != nil {
+		reportError(
+			r.ctx,
+			r.logger,
+			r.prefix,
+			"Command failed: %s",
+			err.Error(),
+		)
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// pushChanges copies only the files the task created or modified into the
+// workspace volume so that files written by containers since the workspace
+// was pulled are not overwritten.
+func (r *hostTaskRunner) pushChanges(stamps map[string]fileStamp) bool {
+	if r.volume == nil {
+		return true
+	}
+
+	changed, err := changedWorkspacePaths(r.scratch, stamps)
+	if err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to determine changed files: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	return pushWorkspacePaths(
+		r.ctx,
+		r.volume,
+		r.scratch,
+		r.logger,
+		r.prefix,
+		changed,
+	)
+}
+
+func (r *hostTaskRunner) pullWorkspace() bool {
+	return pullWorkspace(
+		r.ctx,
+		r.volume,
+		r.scratch,
+		r.logger,
+		r.prefix,
+	)
+}
+
+func hostTaskCommandBuilderFactory(
+	writeScript ScriptWriter,
+	task *config.HostTask,
+	env environment.Environment,
+) *command.Builder {
+	addCommand := func(cb *command.Builder) error {
+		if task.Script != "" {
+			script, err := env.ExpandString(task.Script)
+			if err != nil {
+				return err
+			}
+
+			path, err := writeScript(script)
+			if err != nil {
+				return err
+			}
+
+			shell, err := env.ExpandString(task.Shell)
+			if err != nil {
+				return err
+			}
+
+			if shell == "" {
+				shell = "/bin/sh"
+			}
+
+			cb.AddArgs(shell, path)
+			return nil
+		}
+
+		command, err := env.ExpandString(task.Command)
+		if err != nil {
+			return err
+		}
+
+		commandArgs, err := shellquote.Split(command)
+		if err != nil {
+			return err
+		}
+
+		if len(commandArgs) == 0 {
+			return fmt.Errorf("no command or script supplied")
+		}
+
+		cb.AddArgs(commandArgs...)
+		return nil
+	}
+
+	return command.NewBuilder(nil, []command.BuildFunc{addCommand})
+}
+
+//
+// Helpers
+
+// hostTaskWorkdir returns the directory from which the task is invoked,
+// which is the project directory unless the task declares otherwise.
+func hostTaskWorkdir(scratch *scratch.ScratchSpace, task *config.HostTask) (string, error) {
+	switch task.Workdir {
+	case "", config.HostWorkdirProject:
+		return scratch.Project(), nil
+	case config.HostWorkdirWorkspace:
+		return scratch.Workspace(), nil
+	}
+
+	return "", fmt.Errorf("unknown workdir %s", task.Workdir)
+}
+
+// hostTaskEnvironment returns the environment of the host process with the
+// task environment layered on top.
+func hostTaskEnvironment(env environment.Environment) ([]string, error) {
+	lines := os.Environ()
+	for _, line := range env.Serialize() {
+		expanded, err := env.ExpandString(line)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, expanded)
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/scratch"
+	. "github.com/onsi/gomega"
+)
+
+type HostTaskSuite struct{}
+
+func (s *HostTaskSuite) TestRun(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	scratch := scratch.NewScratchSpace("abcdef0", name, name, false)
+	Expect(scratch.Setup()).To(BeNil())
+
+	for _, workdir := range []string{"project", "workspace"} {
+		task := &config.HostTask{
+			Script:  "echo -n ${VALUE} > out.txt",
+			Workdir: workdir,
+		}
+
+		runner := NewHostTaskRunnerFactory(
+			context.Background(),
+			scratch,
+			nil,
+			logging.NilLogger,
+		)(
+			task,
+			&config.RetryPolicy{},
+			environment.New([]string{"VALUE=" + workdir}),
+			logging.NewPrefix("host"),
+		)
+
+		Expect(runner.Run(NewRunContext(nil))).To(BeTrue())
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(scratch.Project(), "out.txt"))
+	Expect(err).To(BeNil())
+	Expect(string(data)).To(Equal("project"))
+
+	data, err = ioutil.ReadFile(filepath.Join(scratch.Workspace(), "out.txt"))
+	Expect(err).To(BeNil())
+	Expect(string(data)).To(Equal("workspace"))
+}
+
+func (s *HostTaskSuite) TestRunFailure(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	scratch := scratch.NewScratchSpace("abcdef0", name, name, false)
+	Expect(scratch.Setup()).To(BeNil())
+
+	runner := NewHostTaskRunnerFactory(
+		context.Background(),
+		scratch,
+		nil,
+		logging.NilLogger,
+	)(
+		&config.HostTask{Command: "false"},
+		&config.RetryPolicy{},
+		environment.New(nil),
+		logging.NewPrefix("host"),
+	)
+
+	Expect(runner.Run(NewRunContext(nil))).To(BeFalse())
+}
+
+func (s *HostTaskSuite) TestCommand(t sweet.T) {
+	args, _, err := hostTaskCommandBuilderFactory(
+		nil,
+		&config.HostTask{Command: "git tag 'v${VERSION}'"},
+		environment.New([]string{"VERSION=1.2"}),
+	).Build()
+
+	Expect(err).To(BeNil())
+	Expect(args).To(Equal([]string{"git", "tag", "v1.2"}))
+}
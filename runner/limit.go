@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"context"
+
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/util"
+)
+
+type limitedTaskRunner struct {
+	ctx    context.Context
+	runner TaskRunner
+	jobs   util.Semaphore
+}
+
+// limitTaskRunnerFactory wraps the given factory so that the runners it
+// creates share the given job slots. Plan tasks do not hold a slot while
+// running, as the tasks nested within the plan require slots themselves.
+func limitTaskRunnerFactory(factory TaskRunnerFactory, jobs util.Semaphore) TaskRunnerFactory {
+	if jobs == nil {
+		return factory
+	}
+
+	return func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		runner := factory(ctx, context, task, retryPolicy, prefix, env)
+
+		if _, ok := task.(*config.PlanTask); ok {
+			return runner
+		}
+
+		return &limitedTaskRunner{
+			ctx:    ctx,
+			runner: runner,
+			jobs:   jobs,
+		}
+	}
+}
+
+func (r *limitedTaskRunner) Run(context *RunContext) bool {
+	if !r.jobs.Acquire(r.ctx) {
+		return false
+	}
+
+	defer r.jobs.Release()
+	return r.runner.Run(context)
+}
@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/util"
+	. "github.com/onsi/gomega"
+)
+
+type LimitSuite struct{}
+
+func (s *LimitSuite) TestLimitTaskRunnerFactory(t sweet.T) {
+	var (
+		mutex   = sync.Mutex{}
+		running = 0
+		maximum = 0
+		block   = make(chan struct{})
+	)
+
+	factory := limitTaskRunnerFactory(func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return testTaskRunner(func(context *RunContext) bool {
+			mutex.Lock()
+			running++
+			if running > maximum {
+				maximum = running
+			}
+			mutex.Unlock()
+
+			<-block
+
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+			return true
+		})
+	}, util.NewSemaphore(2))
+
+	funcs := []func(){}
+	for i := 0; i < 5; i++ {
+		runner := factory(
+			context.Background(),
+			NewRunContext(nil),
+			&config.RunTask{},
+			nil,
+			logging.NewPrefix(),
+			nil,
+		)
+
+		funcs = append(funcs, func() { runner.Run(NewRunContext(nil)) })
+	}
+
+	wg := util.RunParallel(funcs...)
+
+	Eventually(func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return running
+	}).Should(Equal(2))
+
+	close(block)
+	wg.Wait()
+	Expect(maximum).To(Equal(2))
+}
+
+func (s *LimitSuite) TestLimitTaskRunnerFactoryPlanTask(t sweet.T) {
+	runner := testTaskRunner(func(context *RunContext) bool { return true })
+
+	factory := limitTaskRunnerFactory(func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return runner
+	}, util.NewSemaphore(1))
+
+	created := factory(
+		context.Background(),
+		NewRunContext(nil),
+		&config.PlanTask{},
+		nil,
+		logging.NewPrefix(),
+		nil,
+	)
+
+	Expect(created).To(BeAssignableToTypeOf(runner))
+}
@@ -14,8 +14,25 @@ func TestMain(m *testing.M) {
 	sweet.Run(m, func(s *sweet.S) {
 		s.RegisterPlugin(junit.NewPlugin())
 
+		s.AddSuite(&ArtifactsSuite{})
 		s.AddSuite(&CleanupSuite{})
 		s.AddSuite(&ContainerListSuite{})
 		s.AddSuite(&ContextSuite{})
+		s.AddSuite(&DebugSuite{})
+		s.AddSuite(&DisabledSuite{})
+		s.AddSuite(&DryRunSuite{})
+		s.AddSuite(&FailFastSuite{})
+		s.AddSuite(&FilterSuite{})
+		s.AddSuite(&HostTaskSuite{})
+		s.AddSuite(&LimitSuite{})
+		s.AddSuite(&MountsSuite{})
+		s.AddSuite(&PlanGraphSuite{})
+		s.AddSuite(&ReadinessSuite{})
+		s.AddSuite(&RunStateSuite{})
+		s.AddSuite(&RunTaskSuite{})
+		s.AddSuite(&ServiceLogsSuite{})
+		s.AddSuite(&StageSuite{})
+		s.AddSuite(&TimeoutSuite{})
+		s.AddSuite(&WorkspaceVolumeSuite{})
 	})
 }
@@ -0,0 +1,133 @@
+package runner
+
+import (
+	"fmt"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ij-build/ij/config"
+)
+
+// volumeNamePattern matches the names the daemon accepts for a named volume.
+var volumeNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// resolveHostPath returns the absolute form of a host path given in a config
+// or override file. A leading ~ refers to the home directory of the invoking
+// user, and a relative path is relative to the project directory.
+func resolveHostPath(path, projectDir string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		current, err := user.Current()
+		if err != nil {
+			return "", fmt.Errorf("failed to get current user (%s)", err.Error())
+		}
+
+		path = filepath.Join(current.HomeDir, path[1:])
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectDir, path)
+	}
+
+	return filepath.Clean(path), nil
+}
+
+// checkAllowedMount returns an error unless the given host path is one of
+// the allowed paths or is contained within one. Symlinks are resolved so
+// that a link within an allowed path cannot expose a path outside of it.
+func checkAllowedMount(source string, allowed []string, projectDir string) error {
+	resolved := evalSymlinks(source)
+
+	for _, path := range allowed {
+		path, err := resolveHostPath(path, projectDir)
+		if err != nil {
+			return err
+		}
+
+		if isWithin(resolved, evalSymlinks(path)) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"bind mount source %s is not allowed (see the allowed-mounts option)",
+		source,
+	)
+}
+
+// checkMountFields returns an error if the expanded source or target of a
+// mount contains a character which delimits the fields of a --mount option.
+// Such a value could otherwise add a second source or a volume option after
+// the allowed-mounts check has passed.
+func checkMountFields(source, target string) error {
+	for _, value := range []string{source, target} {
+		if strings.ContainsAny(value, `,"=`) {
+			return fmt.Errorf(
+				"mount path %s may not contain a comma, quote, or equals sign",
+				value,
+			)
+		}
+	}
+
+	return nil
+}
+
+// checkVolumeName returns an error unless the source of a volume mount is a
+// plain volume name.
+func checkVolumeName(source string) error {
+	if !volumeNamePattern.MatchString(source) {
+		return fmt.Errorf("volume mount source %s is not a valid volume name", source)
+	}
+
+	return nil
+}
+
+// checkAllowedPrivileges returns an error if a task requests a privileged
+// container, a host device, or an added capability without the
+// allow-privileged option, which can only be set by an override file.
+func checkAllowedPrivileges(privileged bool, devices, capabilities []string, options *config.Options) error {
+	if options != nil && options.AllowPrivileged {
+		return nil
+	}
+
+	if privileged {
+		return fmt.Errorf("privileged containers are not allowed (see the allow-privileged option)")
+	}
+
+	if len(devices) > 0 {
+		return fmt.Errorf(
+			"device %s is not allowed (see the allow-privileged option)",
+			devices[0],
+		)
+	}
+
+	if len(capabilities) > 0 {
+		return fmt.Errorf(
+			"capability %s is not allowed (see the allow-privileged option)",
+			capabilities[0],
+		)
+	}
+
+	return nil
+}
+
+//
+// Helpers
+
+func evalSymlinks(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+
+	return path
+}
+
+func isWithin(path, parent string) bool {
+	rel, err := filepath.Rel(parent, path)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type MountsSuite struct{}
+
+func (s *MountsSuite) TestResolveHostPath(t sweet.T) {
+	current, err := user.Current()
+	Expect(err).To(BeNil())
+
+	path, err := resolveHostPath("~/.m2", "/project")
+	Expect(err).To(BeNil())
+	Expect(path).To(Equal(filepath.Join(current.HomeDir, ".m2")))
+
+	path, err = resolveHostPath("certs/../certs/ca.pem", "/project")
+	Expect(err).To(BeNil())
+	Expect(path).To(Equal("/project/certs/ca.pem"))
+
+	path, err = resolveHostPath("/etc/ssl/certs/", "/project")
+	Expect(err).To(BeNil())
+	Expect(path).To(Equal("/etc/ssl/certs"))
+}
+
+func (s *MountsSuite) TestCheckAllowedMount(t sweet.T) {
+	allowed := []string{"/etc/ssl/certs", "vendor"}
+
+	Expect(checkAllowedMount("/etc/ssl/certs", allowed, "/project")).To(BeNil())
+	Expect(checkAllowedMount("/etc/ssl/certs/ca.pem", allowed, "/project")).To(BeNil())
+	Expect(checkAllowedMount("/project/vendor/lib", allowed, "/project")).To(BeNil())
+	Expect(checkAllowedMount("/etc/ssl/certs-other", allowed, "/project")).NotTo(BeNil())
+	Expect(checkAllowedMount("/etc/passwd", allowed, "/project")).NotTo(BeNil())
+	Expect(checkAllowedMount("/etc/passwd", nil, "/project")).NotTo(BeNil())
+}
+
+func (s *MountsSuite) TestCheckAllowedMountSymlink(t sweet.T) {
+	dir, err := ioutil.TempDir("", "ij-test")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	allowed := filepath.Join(dir, "allowed")
+	secret := filepath.Join(dir, "secret")
+	Expect(os.Mkdir(allowed, 0755)).To(BeNil())
+	Expect(os.Mkdir(secret, 0755)).To(BeNil())
+	Expect(os.Symlink(secret, filepath.Join(allowed, "link"))).To(BeNil())
+
+	Expect(checkAllowedMount(allowed, []string{allowed}, dir)).To(BeNil())
+	Expect(checkAllowedMount(filepath.Join(allowed, "link"), []string{allowed}, dir)).NotTo(BeNil())
+}
@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"os/user"
 
-	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/runtime"
 )
 
 const FlashPermissionsImage = "alpine:3.8"
@@ -32,26 +32,17 @@ func (r *Runner) flashPermissions() error {
 		return err
 	}
 
-	builder := command.NewBuilder([]string{
-		"docker",
-		"run",
-		"--rm",
-	}, nil)
-
-	builder.AddArgs(FlashPermissionsImage)
-	builder.AddArgs("chown", fmt.Sprintf("%s:%s", user.Uid, user.Gid), "-R", ".")
-	builder.AddFlagValue("-w", "/workspace")
-	builder.AddFlagValue("-v", fmt.Sprintf("%s:/workspace", r.scratch.Workspace()))
-
-	args, _, err := builder.Build()
-	if err != nil {
-		return err
+	// The user options may change the default user of the container to
+	// the invoking user, who cannot take ownership of files of other users
+	options := &runtime.RunOptions{
+		Image:   FlashPermissionsImage,
+		Args:    []string{"chown", fmt.Sprintf("%s:%s", user.Uid, user.Gid), "-R", "."},
+		User:    "root",
+		Workdir: "/workspace",
+		Binds:   []string{fmt.Sprintf("%s:/workspace", r.scratch.Workspace())},
+		Remove:  true,
+		MapUser: true,
 	}
 
-	return command.NewRunner(r.logger).Run(
-		r.ctx,
-		args,
-		nil,
-		nil,
-	)
+	return r.runtime.Run(r.ctx, options, r.logger, nil)
 }
@@ -14,6 +14,7 @@ type PlanRunner struct {
 	taskRunnerFactory TaskRunnerFactory
 	logger            logging.Logger
 	env               []string
+	filter            *TaskFilter
 }
 
 func NewPlanRunner(
@@ -22,6 +23,7 @@ func NewPlanRunner(
 	taskRunnerFactory TaskRunnerFactory,
 	logger logging.Logger,
 	env []string,
+	filter *TaskFilter,
 ) *PlanRunner {
 	return &PlanRunner{
 		ctx:               ctx,
@@ -29,6 +31,7 @@ func NewPlanRunner(
 		taskRunnerFactory: taskRunnerFactory,
 		logger:            logger,
 		env:               env,
+		filter:            filter,
 	}
 }
 
@@ -42,12 +45,7 @@ func (r *PlanRunner) IsDisabled(context *RunContext, name string) (bool, error)
 			environment.New(r.env),
 		)
 
-		val, err := env.ExpandString(plan.Disabled)
-		if err != nil {
-			return false, err
-		}
-
-		return val != "", nil
+		return isDisabled(plan.Disabled, plan.If, env, context.Failure)
 	}
 
 	return false, nil
@@ -82,7 +80,7 @@ func (r *PlanRunner) Run(
 	if err != nil {
 		r.logger.Info(
 			prefix,
-			"Failed to expand environment for disabled check: %s",
+			"Failed to evaluate disabled check: %s",
 			err.Error(),
 		)
 
@@ -162,15 +160,45 @@ func (r *PlanRunner) runPlan(
 	prefix *logging.Prefix,
 ) bool {
 	var (
-		plan    = r.config.Plans[name]
-		failure = context.Failure
+		plan        = r.config.Plans[name]
+		ctx, cancel = withTimeout(r.ctx, plan.Timeout)
 	)
 
+	defer cancel()
+
+	var ok bool
+	if plan.HasNeeds() {
+		ok = r.runGraph(ctx, context, plan, prefix)
+	} else {
+		ok = r.runStages(ctx, context, plan, prefix)
+	}
+
+	if timedOut(ctx, r.ctx) {
+		r.logger.Error(
+			prefix,
+			"Plan timed out after %s",
+			plan.Timeout,
+		)
+
+		return false
+	}
+
+	return ok
+}
+
+func (r *PlanRunner) runStages(
+	ctx context.Context,
+	context *RunContext,
+	plan *config.Plan,
+	prefix *logging.Prefix,
+) bool {
+	failure := context.Failure
+
 	for _, stage := range plan.Stages {
 		stagePrefix := prefix.Append(stage.Name)
 
 		runner := NewStageRunner(
-			r.ctx,
+			ctx,
 			r.logger,
 			r.config,
 			r.taskRunnerFactory,
@@ -178,6 +206,7 @@ func (r *PlanRunner) runPlan(
 			stage,
 			stagePrefix,
 			r.env,
+			r.filter,
 		)
 
 		if !stage.ShouldRun(failure) || len(stage.Tasks) == 0 {
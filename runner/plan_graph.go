@@ -0,0 +1,246 @@
+package runner
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/util"
+)
+
+type graphStage struct {
+	runner    *StageRunner
+	ambiguous map[string]struct{}
+	semaphore util.Semaphore
+	once      sync.Once
+	ctx       context.Context
+	cancel    func()
+	failFast  func()
+	disabled  bool
+	err       error
+}
+
+// runGraph runs the stage tasks of the plan as soon as the tasks they need
+// have completed. A task inherits the failure of any task it depends on,
+// so the run-mode of its stage is evaluated against the failures upstream
+// of the task rather than against every task that ran before it.
+func (r *PlanRunner) runGraph(
+	ctx context.Context,
+	context *RunContext,
+	plan *config.Plan,
+	prefix *logging.Prefix,
+) bool {
+	nodes, err := plan.Graph()
+	if err != nil {
+		r.logger.Error(
+			prefix,
+			"Failed to schedule plan: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	var (
+		stages   = map[*config.Stage]*graphStage{}
+		done     = make([]chan struct{}, len(nodes))
+		failures = make([]bool, len(nodes))
+		wg       = sync.WaitGroup{}
+		slots    util.Semaphore
+	)
+
+	if r.config.Options.ForceSequential {
+		slots = util.NewSemaphore(1)
+	}
+
+	for _, stage := range plan.Stages {
+		runner := NewStageRunner(
+			ctx,
+			r.logger,
+			r.config,
+			r.taskRunnerFactory,
+			plan,
+			stage,
+			prefix.Append(stage.Name),
+			r.env,
+			r.filter,
+		)
+
+		stages[stage] = &graphStage{
+			runner:    runner,
+			ambiguous: runner.ambiguousNames(),
+			semaphore: util.NewSemaphore(stage.MaxParallel),
+		}
+	}
+
+	for i := range nodes {
+		done[i] = make(chan struct{})
+	}
+
+	for i, node := range nodes {
+		wg.Add(1)
+
+		go func(i int, node *config.PlanNode) {
+			defer wg.Done()
+			defer close(done[i])
+
+			failure := context.Failure
+			for _, need := range node.Needs {
+				<-done[need]
+
+				if failures[need] {
+					failure = true
+				}
+			}
+
+			// A skipped task passes along the failure of its dependencies
+			failures[i] = failure
+
+			if !node.Stage.ShouldRun(failure) {
+				return
+			}
+
+			stage := stages[node.Stage]
+
+			if !slots.Acquire(ctx) {
+				failures[i] = true
+				return
+			}
+
+			defer slots.Release()
+
+			newContext := NewRunContext(context)
+			newContext.Failure = failure
+
+			if !stage.begin(ctx, newContext) {
+				failures[i] = true
+				return
+			}
+
+			if stage.disabled {
+				return
+			}
+
+			_, ambiguous := stage.ambiguous[node.StageTask.Name]
+
+			runners := stage.runner.buildStageTaskRunnerFuncs(
+				stage.ctx,
+				node.StageTask,
+				node.Index,
+				newContext,
+				ambiguous,
+			)
+
+			// Each instance of a matrix takes its own slot of the stage
+			runners = withSemaphore(ctx, stage.semaphore, runners)
+
+			var ok bool
+			if len(runners) == 1 || !node.Stage.Parallel || r.config.Options.ForceSequential {
+				ok = runSequential(runners)
+			} else {
+				ok = runParallel(stage.ctx, runners, 0, stage.failFast)
+			}
+
+			if !ok {
+				failures[i] = true
+
+				if stage.failFast != nil {
+					stage.failFast()
+				}
+			}
+		}(i, node)
+	}
+
+	wg.Wait()
+
+	for _, stage := range plan.Stages {
+		stages[stage].end(ctx)
+	}
+
+	for _, failure := range failures {
+		if failure {
+			return false
+		}
+	}
+
+	return !context.Failure
+}
+
+// begin checks if the stage is disabled and starts its timeout. This
+// happens once, when the first task of the stage is ready to run.
+func (s *graphStage) begin(ctx context.Context, context *RunContext) bool {
+	s.once.Do(func() {
+		logger := s.runner.logger
+		prefix := s.runner.prefix
+
+		if s.disabled, s.err = s.runner.IsDisabled(context); s.err != nil {
+			logger.Info(
+				prefix,
+				"Failed to evaluate disabled check: %s",
+				s.err.Error(),
+			)
+
+			return
+		}
+
+		if s.disabled {
+			logger.Warn(
+				prefix,
+				"Stage is disabled",
+			)
+
+			return
+		}
+
+		logger.Info(
+			prefix,
+			"Beginning stage",
+		)
+
+		s.ctx, s.cancel = withTimeout(ctx, s.runner.stage.Timeout)
+
+		if s.runner.isFailFast() {
+			s.ctx, s.failFast = withFailFast(s.ctx)
+		}
+	})
+
+	return s.err == nil
+}
+
+// end releases the timeout of the stage, if it was started.
+func (s *graphStage) end(ctx context.Context) {
+	if s.cancel == nil {
+		return
+	}
+
+	if timedOut(s.ctx, ctx) {
+		s.runner.logger.Error(
+			s.runner.prefix,
+			"Stage timed out after %s",
+			s.runner.stage.Timeout,
+		)
+	}
+
+	s.cancel()
+}
+
+// withSemaphore wraps each runner so that it holds a slot of the given
+// semaphore while it runs.
+func withSemaphore(ctx context.Context, semaphore util.Semaphore, runners []TaskRunnerFunc) []TaskRunnerFunc {
+	wrapped := []TaskRunnerFunc{}
+	for _, r := range runners {
+		runner := r
+
+		wrapped = append(wrapped, func() bool {
+			if !semaphore.Acquire(ctx) {
+				return false
+			}
+
+			defer semaphore.Release()
+			return runner()
+		})
+	}
+
+	return wrapped
+}
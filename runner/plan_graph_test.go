@@ -0,0 +1,221 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	PlanGraphSuite struct{}
+
+	testTaskRunner func(*RunContext) bool
+)
+
+func (f testTaskRunner) Run(context *RunContext) bool {
+	return f(context)
+}
+
+func (s *PlanGraphSuite) TestRunGraph(t sweet.T) {
+	var (
+		order  = []string{}
+		runner = newTestGraphRunner(&order, "lint")
+	)
+
+	Expect(runner.Run(NewRunContext(nil), "default", logging.NewPrefix())).To(BeFalse())
+	Expect(order).To(ConsistOf("lint", "build", "package", "notify"))
+	Expect(indexOf(order, "build")).To(BeNumerically("<", indexOf(order, "package")))
+	Expect(indexOf(order, "package")).To(BeNumerically("<", indexOf(order, "notify")))
+}
+
+func (s *PlanGraphSuite) TestRunGraphSuccess(t sweet.T) {
+	var (
+		order  = []string{}
+		runner = newTestGraphRunner(&order)
+	)
+
+	Expect(runner.Run(NewRunContext(nil), "default", logging.NewPrefix())).To(BeTrue())
+	Expect(order).To(ConsistOf("lint", "build", "unit-test", "package"))
+	Expect(indexOf(order, "lint")).To(BeNumerically("<", indexOf(order, "unit-test")))
+	Expect(indexOf(order, "build")).To(BeNumerically("<", indexOf(order, "package")))
+}
+
+func (s *PlanGraphSuite) TestRunGraphPreviousFailure(t sweet.T) {
+	var (
+		order   = []string{}
+		runner  = newTestGraphRunner(&order)
+		context = NewRunContext(nil)
+	)
+
+	context.Failure = true
+	Expect(runner.Run(context, "default", logging.NewPrefix())).To(BeFalse())
+	Expect(order).To(Equal([]string{"notify"}))
+}
+
+func (s *PlanGraphSuite) TestRunGraphMatrixMaxParallel(t sweet.T) {
+	var (
+		mutex   = sync.Mutex{}
+		running = 0
+		peak    = 0
+		count   = 0
+	)
+
+	plan := &config.Plan{
+		Name: "default",
+		Stages: []*config.Stage{
+			&config.Stage{
+				Name:        "s1",
+				RunMode:     config.RunModeOnSuccess,
+				Parallel:    true,
+				MaxParallel: 2,
+				Tasks: []*config.StageTask{
+					&config.StageTask{
+						Name: "test",
+						Matrix: &config.Matrix{
+							Variables: map[string][]string{"GO": []string{"1.9", "1.10", "1.11", "1.12"}},
+						},
+					},
+				},
+			},
+			&config.Stage{
+				Name:    "s2",
+				RunMode: config.RunModeOnSuccess,
+				Tasks: []*config.StageTask{
+					&config.StageTask{Name: "package", Needs: []string{"test"}},
+				},
+			},
+		},
+	}
+
+	factory := func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return testTaskRunner(func(context *RunContext) bool {
+			mutex.Lock()
+			running++
+			count++
+			if running > peak {
+				peak = running
+			}
+			mutex.Unlock()
+
+			time.Sleep(time.Millisecond * 20)
+
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+			return true
+		})
+	}
+
+	runner := NewPlanRunner(
+		context.Background(),
+		&config.Config{
+			Options: &config.Options{},
+			Tasks: map[string]config.Task{
+				"test":    &config.RunTask{TaskMeta: config.TaskMeta{Name: "test"}},
+				"package": &config.RunTask{TaskMeta: config.TaskMeta{Name: "package"}},
+			},
+			Plans: map[string]*config.Plan{"default": plan},
+		},
+		factory,
+		logging.NilLogger,
+		nil,
+		nil,
+	)
+
+	Expect(runner.Run(NewRunContext(nil), "default", logging.NewPrefix())).To(BeTrue())
+	Expect(count).To(Equal(5))
+	Expect(peak).To(Equal(2))
+}
+
+func newTestGraphRunner(order *[]string, failing ...string) *PlanRunner {
+	var (
+		mutex = sync.Mutex{}
+		tasks = map[string]config.Task{}
+	)
+
+	for _, name := range []string{"lint", "unit-test", "build", "package", "notify"} {
+		tasks[name] = &config.RunTask{TaskMeta: config.TaskMeta{Name: name}}
+	}
+
+	plan := &config.Plan{
+		Name: "default",
+		Stages: []*config.Stage{
+			&config.Stage{
+				Name:    "s1",
+				RunMode: config.RunModeOnSuccess,
+				Tasks: []*config.StageTask{
+					&config.StageTask{Name: "lint"},
+					&config.StageTask{Name: "build"},
+				},
+			},
+			&config.Stage{
+				Name:    "s2",
+				RunMode: config.RunModeOnSuccess,
+				Tasks: []*config.StageTask{
+					&config.StageTask{Name: "unit-test", Needs: []string{"lint"}},
+					&config.StageTask{Name: "package", Needs: []string{"build"}},
+				},
+			},
+			&config.Stage{
+				Name:    "s3",
+				RunMode: config.RunModeOnFailure,
+				Tasks: []*config.StageTask{
+					&config.StageTask{Name: "notify"},
+				},
+			},
+		},
+	}
+
+	factory := func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return testTaskRunner(func(context *RunContext) bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			*order = append(*order, task.GetName())
+			return indexOf(failing, task.GetName()) < 0
+		})
+	}
+
+	return NewPlanRunner(
+		context.Background(),
+		&config.Config{
+			Options: &config.Options{},
+			Tasks:   tasks,
+			Plans:   map[string]*config.Plan{"default": plan},
+		},
+		factory,
+		logging.NilLogger,
+		nil,
+		nil,
+	)
+}
+
+func indexOf(values []string, value string) int {
+	for i, other := range values {
+		if other == value {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -3,64 +3,84 @@ package runner
 import (
 	"context"
 
-	"github.com/ij-build/ij/command"
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/environment"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 )
 
 type PushTaskRunnerFactory func(
 	*RunContext,
 	*config.PushTask,
+	*config.RetryPolicy,
 	environment.Environment,
 	*logging.Prefix,
 ) TaskRunner
 
 func NewPushTaskRunnerFactory(
 	ctx context.Context,
+	runtime runtime.ContainerRuntime,
 	logger logging.Logger,
 ) PushTaskRunnerFactory {
 	return func(
 		context *RunContext,
 		task *config.PushTask,
+		retryPolicy *config.RetryPolicy,
 		env environment.Environment,
 		prefix *logging.Prefix,
 	) TaskRunner {
 		return NewBaseRunner(
 			ctx,
-			makePushTaskCommandFactory(context, task, env),
+			pushTaskOperationFactory(runtime, context, task, env),
+			retryPolicy,
 			logger,
 			prefix,
 		)
 	}
 }
 
-func makePushTaskCommandFactory(
+func pushTaskOperationFactory(
+	runtime runtime.ContainerRuntime,
 	context *RunContext,
 	task *config.PushTask,
 	env environment.Environment,
-) BuilderSetFactory {
-	return func() ([]*command.Builder, error) {
-		images, err := env.ExpandSlice(task.Images)
+) OperationSetFactory {
+	return func() ([]Operation, error) {
+		images, err := pushTaskImages(context, task, env)
 		if err != nil {
 			return nil, err
 		}
 
-		if task.IncludeBuilt {
-			images = append(images, context.GetTags()...)
+		operations := []Operation{}
+		for _, image := range images {
+			operations = append(operations, pushOperation(runtime, image))
 		}
 
-		builders := []*command.Builder{}
-		for _, image := range images {
-			builder := command.NewBuilder([]string{
-				"docker",
-				"push",
-				image,
-			}, nil)
+		return operations, nil
+	}
+}
 
-			builders = append(builders, builder)
-		}
+func pushOperation(containerRuntime runtime.ContainerRuntime, image string) Operation {
+	return func(ctx context.Context, logger logging.Logger, prefix *logging.Prefix) error {
+		return containerRuntime.Push(ctx, image, logger, prefix)
+	}
+}
 
-		return builders, nil
+// pushTaskImages returns the images pushed by the given task, including the
+// images built by previous tasks if requested.
+func pushTaskImages(
+	context *RunContext,
+	task *config.PushTask,
+	env environment.Environment,
+) ([]string, error) {
+	images, err := env.ExpandSlice(task.Images)
+	if err != nil {
+		return nil, err
 	}
+
+	if task.IncludeBuilt {
+		images = append(images, context.GetTags()...)
+	}
+
+	return images, nil
 }
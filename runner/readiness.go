@@ -0,0 +1,402 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
+	"github.com/ij-build/ij/util"
+)
+
+type (
+	// readinessProbe determines if a detached container is ready to serve.
+	// An error is returned only if the probe itself could not be performed.
+	readinessProbe struct {
+		description string
+		check       func(ctx context.Context) (bool, error)
+	}
+
+	// logMatcher is a logger which records whether any line of container
+	// output written to it matches a pattern.
+	logMatcher struct {
+		pattern *regexp.Regexp
+		matched bool
+		mutex   sync.Mutex
+	}
+)
+
+const (
+	// ReadinessProbeImage is the image of the helper container from which
+	// network probes are performed. It is attached to the run network so
+	// that the container under test is addressable by name.
+	ReadinessProbeImage = "busybox:1"
+
+	// DefaultReadinessTimeout is the maximum time to wait for a container
+	// to become ready when the task does not declare a timeout.
+	DefaultReadinessTimeout = time.Minute
+)
+
+var httpStatusPattern = regexp.MustCompile(`HTTP/\S+\s+(\d{3})`)
+
+// awaitReadiness polls the readiness probes of the task until every probe
+// succeeds. The task fails if this does not happen within the timeout.
+func (r *runTaskRunner) awaitReadiness(containerName string) bool {
+	probes, err := buildReadinessProbes(
+		r.runID,
+		r.runtime,
+		r.containerLists.ContainerStopper,
+		containerName,
+		r.task.Readiness,
+		r.env,
+	)
+
+	if err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to build readiness probes: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	timeout := r.task.Readiness.Timeout
+	if timeout == 0 {
+		timeout = DefaultReadinessTimeout
+	}
+
+	interval := r.task.Readiness.Interval
+	if interval == 0 {
+		interval = r.config.Options.HealthcheckInterval
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, timeout)
+	defer cancel()
+
+	for {
+		state, err := getContainerState(
+			ctx,
+			r.runtime,
+			containerName,
+			r.logger,
+			r.prefix,
+		)
+
+		if err != nil && ctx.Err() == nil {
+			reportError(
+				r.ctx,
+				r.logger,
+				r.prefix,
+				"Failed to check container state: %s",
+				err.Error(),
+			)
+
+			return false
+		}
+
+		if err == nil && !r.checkRunning(containerName, state) {
+			return false
+		}
+
+		pending := []*readinessProbe{}
+		for _, probe := range probes {
+			ready, err := probe.check(ctx)
+			if err != nil && ctx.Err() == nil {
+				reportError(
+					r.ctx,
+					r.logger,
+					r.prefix,
+					"Failed to perform %s readiness probe: %s",
+					probe.description,
+					err.Error(),
+				)
+
+				return false
+			}
+
+			if !ready {
+				pending = append(pending, probe)
+			}
+		}
+
+		if len(pending) == 0 {
+			r.logger.Info(
+				r.prefix,
+				"Container is ready",
+			)
+
+			return true
+		}
+
+		r.logger.Info(
+			r.prefix,
+			"Container is not yet ready (waiting on %s)",
+			describeReadinessProbes(pending),
+		)
+
+		probes = pending
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			if r.ctx.Err() == nil {
+				r.logger.Error(
+					r.prefix,
+					"Container did not become ready within %s",
+					timeout,
+				)
+			}
+
+			return false
+		}
+	}
+}
+
+func buildReadinessProbes(
+	runID string,
+	containerRuntime runtime.ContainerRuntime,
+	stopper *ContainerList,
+	containerName string,
+	readiness *config.Readiness,
+	env environment.Environment,
+) ([]*readinessProbe, error) {
+	probes := []*readinessProbe{}
+
+	if readiness.TCPPort != 0 {
+		probes = append(probes, &readinessProbe{
+			description: "tcp",
+			check: func(ctx context.Context) (bool, error) {
+				_, _, err := runProbeContainer(ctx, containerRuntime, stopper, func(probeName string) *runtime.RunOptions {
+					return tcpProbeOptions(runID, probeName, containerName, readiness.TCPPort)
+				})
+
+				if err != nil && isProbeFailure(err) {
+					return false, err
+				}
+
+				return err == nil, nil
+			},
+		})
+	}
+
+	if readiness.HTTP != nil {
+		path, err := env.ExpandString(readiness.HTTP.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			port     = readiness.HTTP.Port
+			expected = readiness.HTTP.Status
+		)
+
+		probes = append(probes, &readinessProbe{
+			description: "http",
+			check: func(ctx context.Context) (bool, error) {
+				_, errOut, err := runProbeContainer(ctx, containerRuntime, stopper, func(probeName string) *runtime.RunOptions {
+					return httpProbeOptions(runID, probeName, containerName, port, path)
+				})
+
+				status := parseHTTPStatus(errOut)
+				if status == 0 && err != nil && isProbeFailure(err) {
+					return false, err
+				}
+
+				return httpStatusMatches(expected, status), nil
+			},
+		})
+	}
+
+	if readiness.LogPattern != "" {
+		pattern, err := env.ExpandString(readiness.LogPattern)
+		if err != nil {
+			return nil, err
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("malformed log pattern: %s", err.Error())
+		}
+
+		probes = append(probes, &readinessProbe{
+			description: "log",
+			check: func(ctx context.Context) (bool, error) {
+				matcher := &logMatcher{pattern: re}
+				if err := containerRuntime.Logs(ctx, containerName, &runtime.LogsOptions{}, matcher, nil); err != nil {
+					return false, err
+				}
+
+				return matcher.Matched(), nil
+			},
+		})
+	}
+
+	return probes, nil
+}
+
+// runProbeContainer runs the helper container described by the given
+// function and returns its output. The container is registered with the
+// stopper so that it does not outlive an interrupted run.
+func runProbeContainer(
+	ctx context.Context,
+	containerRuntime runtime.ContainerRuntime,
+	stopper *ContainerList,
+	buildOptions func(probeName string) *runtime.RunOptions,
+) (string, string, error) {
+	probeName, err := util.MakeID()
+	if err != nil {
+		return "", "", err
+	}
+
+	stopper.Add(probeName)
+
+	out, errOut, err := containerRuntime.RunForOutput(ctx, buildOptions(probeName))
+	if ctx.Err() != nil {
+		// Abandoning the container does not stop it
+		stopper.ExecuteOne(probeName)
+	} else {
+		stopper.Remove(probeName)
+	}
+
+	return out, errOut, err
+}
+
+func tcpProbeOptions(
+	runID string,
+	probeName string,
+	containerName string,
+	port int,
+) *runtime.RunOptions {
+	return probeOptions(
+		runID,
+		probeName,
+		"nc",
+		"-z",
+		"-w",
+		"1",
+		containerName,
+		strconv.Itoa(port),
+	)
+}
+
+func httpProbeOptions(
+	runID string,
+	probeName string,
+	containerName string,
+	port int,
+	path string,
+) *runtime.RunOptions {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return probeOptions(
+		runID,
+		probeName,
+		"wget",
+		"-S",
+		"-q",
+		"-O",
+		"/dev/null",
+		"-T",
+		"1",
+		fmt.Sprintf("http://%s:%d%s", containerName, port, path),
+	)
+}
+
+// probeOptions describes a helper container on the run network which
+// invokes the given probe command.
+func probeOptions(
+	runID string,
+	probeName string,
+	probeCommand ...string,
+) *runtime.RunOptions {
+	return &runtime.RunOptions{
+		Name:    probeName,
+		Image:   ReadinessProbeImage,
+		Args:    probeCommand,
+		Network: runID,
+		Remove:  true,
+	}
+}
+
+//
+// Helpers
+
+// isProbeFailure determines if the helper container could not be run at
+// all, as opposed to the probe command reporting an unready container.
+func isProbeFailure(err error) bool {
+	code := command.ExitCode(err)
+	return code < 0 || code >= 125
+}
+
+// parseHTTPStatus returns the status of the last response in the server
+// headers printed by wget, or zero if no response was received.
+func parseHTTPStatus(output string) int {
+	matches := httpStatusPattern.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return 0
+	}
+
+	status, _ := strconv.Atoi(matches[len(matches)-1][1])
+	return status
+}
+
+// httpStatusMatches determines if the response status is the expected
+// status, or any 2xx status if none is expected.
+func httpStatusMatches(expected, status int) bool {
+	if expected == 0 {
+		return status >= 200 && status < 300
+	}
+
+	return status == expected
+}
+
+func (m *logMatcher) Debug(prefix *logging.Prefix, format string, args ...interface{}) {}
+
+func (m *logMatcher) Info(prefix *logging.Prefix, format string, args ...interface{}) {
+	m.match(fmt.Sprintf(format, args...))
+}
+
+func (m *logMatcher) Warn(prefix *logging.Prefix, format string, args ...interface{}) {
+	m.match(fmt.Sprintf(format, args...))
+}
+
+func (m *logMatcher) Error(prefix *logging.Prefix, format string, args ...interface{}) {
+	m.match(fmt.Sprintf(format, args...))
+}
+
+// Matched determines if any line written to the matcher matches its pattern.
+func (m *logMatcher) Matched() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.matched
+}
+
+func (m *logMatcher) match(line string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.pattern.MatchString(line) {
+		m.matched = true
+	}
+}
+
+func describeReadinessProbes(probes []*readinessProbe) string {
+	descriptions := []string{}
+	for _, probe := range probes {
+		descriptions = append(descriptions, probe.description)
+	}
+
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,131 @@
+package runner
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
+	. "github.com/onsi/gomega"
+)
+
+type ReadinessSuite struct{}
+
+func (s *ReadinessSuite) TestBuildReadinessProbes(t sweet.T) {
+	readiness := &config.Readiness{
+		TCPPort:    5432,
+		HTTP:       &config.HTTPProbe{Port: 8080, Path: "/health"},
+		LogPattern: "ready to accept ${KIND}",
+	}
+
+	probes, err := buildReadinessProbes(
+		"run-id",
+		runtime.NewFakeRuntime(),
+		NewContainerList("", func(string) {}, logging.NilLogger),
+		"container",
+		readiness,
+		environment.New([]string{"KIND=connections"}),
+	)
+
+	Expect(err).To(BeNil())
+	Expect(describeReadinessProbes(probes)).To(Equal("tcp, http, log"))
+}
+
+func (s *ReadinessSuite) TestBuildReadinessProbesMalformedPattern(t sweet.T) {
+	_, err := buildReadinessProbes(
+		"run-id",
+		runtime.NewFakeRuntime(),
+		NewContainerList("", func(string) {}, logging.NilLogger),
+		"container",
+		&config.Readiness{LogPattern: "("},
+		environment.New(nil),
+	)
+
+	Expect(err).NotTo(BeNil())
+	Expect(err.Error()).To(HavePrefix("malformed log pattern"))
+}
+
+func (s *ReadinessSuite) TestReadinessProbesUseRuntime(t sweet.T) {
+	containerRuntime := runtime.NewFakeRuntime()
+	containerRuntime.ContainerLogs["container"] = []string{"starting", "ready to accept connections"}
+
+	readiness := &config.Readiness{
+		TCPPort:    5432,
+		HTTP:       &config.HTTPProbe{Port: 8080, Path: "/health"},
+		LogPattern: "^ready to accept connections$",
+	}
+
+	stopper := NewContainerList("", func(string) {}, logging.NilLogger)
+
+	probes, err := buildReadinessProbes(
+		"run-id",
+		containerRuntime,
+		stopper,
+		"container",
+		readiness,
+		environment.New(nil),
+	)
+
+	Expect(err).To(BeNil())
+
+	ready := []bool{}
+	for _, probe := range probes {
+		ok, err := probe.check(context.Background())
+		Expect(err).To(BeNil())
+		ready = append(ready, ok)
+	}
+
+	// The http probe receives no response headers from the fake runtime
+	Expect(ready).To(Equal([]bool{true, false, true}))
+	Expect(containerRuntime.Runs).To(HaveLen(2))
+	Expect(containerRuntime.Runs[0].Args).To(Equal([]string{"nc", "-z", "-w", "1", "container", "5432"}))
+	Expect(stopper.containers).To(BeEmpty())
+}
+
+func (s *ReadinessSuite) TestTCPProbeOptions(t sweet.T) {
+	Expect(tcpProbeOptions("run-id", "probe", "container", 5432)).To(Equal(&runtime.RunOptions{
+		Name:    "probe",
+		Image:   ReadinessProbeImage,
+		Args:    []string{"nc", "-z", "-w", "1", "container", "5432"},
+		Network: "run-id",
+		Remove:  true,
+	}))
+}
+
+func (s *ReadinessSuite) TestHTTPProbeOptions(t sweet.T) {
+	options := httpProbeOptions("run-id", "probe", "container", 8080, "health")
+	Expect(options.Network).To(Equal("run-id"))
+	Expect(options.Args[len(options.Args)-1]).To(Equal("http://container:8080/health"))
+
+	options = httpProbeOptions("run-id", "probe", "container", 8080, "")
+	Expect(options.Args[len(options.Args)-1]).To(Equal("http://container:8080/"))
+}
+
+func (s *ReadinessSuite) TestParseHTTPStatus(t sweet.T) {
+	Expect(parseHTTPStatus("")).To(Equal(0))
+	Expect(parseHTTPStatus("wget: can't connect to remote host: Connection refused")).To(Equal(0))
+	Expect(parseHTTPStatus("  HTTP/1.1 200 OK\n  Content-Type: text/plain")).To(Equal(200))
+	Expect(parseHTTPStatus("  HTTP/1.1 302 Found\n  Location: /\n  HTTP/1.1 503 Service Unavailable")).To(Equal(503))
+}
+
+func (s *ReadinessSuite) TestHTTPStatusMatches(t sweet.T) {
+	Expect(httpStatusMatches(0, 200)).To(BeTrue())
+	Expect(httpStatusMatches(0, 204)).To(BeTrue())
+	Expect(httpStatusMatches(0, 0)).To(BeFalse())
+	Expect(httpStatusMatches(0, 404)).To(BeFalse())
+	Expect(httpStatusMatches(404, 404)).To(BeTrue())
+	Expect(httpStatusMatches(404, 200)).To(BeFalse())
+}
+
+func (s *ReadinessSuite) TestLogMatcher(t sweet.T) {
+	matcher := &logMatcher{pattern: regexp.MustCompile(`^ready on port \d+$`)}
+	matcher.Info(nil, "%s", "starting")
+	Expect(matcher.Matched()).To(BeFalse())
+	matcher.Error(nil, "%s", "not ready on port 80")
+	Expect(matcher.Matched()).To(BeFalse())
+	matcher.Error(nil, "%s", "ready on port 80")
+	Expect(matcher.Matched()).To(BeTrue())
+}
@@ -3,65 +3,85 @@ package runner
 import (
 	"context"
 
-	"github.com/ij-build/ij/command"
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/environment"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 )
 
 type RemoveTaskRunnerFactory func(
 	*RunContext,
 	*config.RemoveTask,
+	*config.RetryPolicy,
 	environment.Environment,
 	*logging.Prefix,
 ) TaskRunner
 
 func NewRemoveTaskRunnerFactory(
 	ctx context.Context,
+	runtime runtime.ContainerRuntime,
 	logger logging.Logger,
 ) RemoveTaskRunnerFactory {
 	return func(
 		context *RunContext,
 		task *config.RemoveTask,
+		retryPolicy *config.RetryPolicy,
 		env environment.Environment,
 		prefix *logging.Prefix,
 	) TaskRunner {
 		return NewBaseRunner(
 			ctx,
-			removeTaskComandFactory(context, task, env),
+			removeTaskOperationFactory(runtime, context, task, env),
+			retryPolicy,
 			logger,
 			prefix,
 		)
 	}
 }
 
-func removeTaskComandFactory(
+func removeTaskOperationFactory(
+	runtime runtime.ContainerRuntime,
 	context *RunContext,
 	task *config.RemoveTask,
 	env environment.Environment,
-) BuilderSetFactory {
-	return func() ([]*command.Builder, error) {
-		images, err := env.ExpandSlice(task.Images)
+) OperationSetFactory {
+	return func() ([]Operation, error) {
+		images, err := removeTaskImages(context, task, env)
 		if err != nil {
 			return nil, err
 		}
 
-		if task.IncludeBuilt {
-			images = append(images, context.GetTags()...)
+		operations := []Operation{}
+		for _, image := range images {
+			operations = append(operations, removeImageOperation(runtime, image))
 		}
 
-		builders := []*command.Builder{}
-		for _, image := range images {
-			builder := command.NewBuilder([]string{
-				"docker",
-				"rmi",
-				"-f",
-				image,
-			}, nil)
+		return operations, nil
+	}
+}
 
-			builders = append(builders, builder)
-		}
+func removeImageOperation(containerRuntime runtime.ContainerRuntime, image string) Operation {
+	return func(ctx context.Context, logger logging.Logger, prefix *logging.Prefix) error {
+		logger.Info(prefix, "Removing image %s", image)
+		return containerRuntime.RemoveImage(ctx, image)
+	}
+}
 
-		return builders, nil
+// removeTaskImages returns the images removed by the given task, including
+// the images built by previous tasks if requested.
+func removeTaskImages(
+	context *RunContext,
+	task *config.RemoveTask,
+	env environment.Environment,
+) ([]string, error) {
+	images, err := env.ExpandSlice(task.Images)
+	if err != nil {
+		return nil, err
 	}
+
+	if task.IncludeBuilt {
+		images = append(images, context.GetTags()...)
+	}
+
+	return images, nil
 }
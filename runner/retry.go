@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"context"
+	"time"
+
+	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/logging"
+)
+
+// AttemptFunc performs a single attempt of a task. On failure, a non-nil
+// error indicates that the failure came from an invoked command and may
+// be retried; a nil error indicates that the failure is permanent.
+type AttemptFunc func() (bool, error)
+
+func runWithRetries(
+	ctx context.Context,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+	policy *config.RetryPolicy,
+	attempt AttemptFunc,
+) bool {
+	attempts := policy.Attempts()
+
+	for i := 1; ; i++ {
+		if i > 1 {
+			logger.Info(
+				prefix,
+				"Beginning attempt %d of %d",
+				i,
+				attempts,
+			)
+		}
+
+		ok, err := attempt()
+		if ok {
+			if i > 1 {
+				logger.Info(
+					prefix,
+					"Task succeeded after %d attempts",
+					i,
+				)
+			}
+
+			return true
+		}
+
+		if err == nil || ctx.Err() != nil || !policy.ShouldRetry(i, command.ExitCode(err)) {
+			if i > 1 {
+				reportError(
+					ctx,
+					logger,
+					prefix,
+					"Task failed after %d attempts",
+					i,
+				)
+			}
+
+			return false
+		}
+
+		delay := policy.Delay(i)
+
+		logger.Warn(
+			prefix,
+			"Attempt %d of %d failed, retrying in %s",
+			i,
+			attempts,
+			delay,
+		)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
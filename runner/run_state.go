@@ -0,0 +1,50 @@
+package runner
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ij-build/ij/scratch"
+)
+
+// RunState is the portion of a run that is persisted to the run directory
+// so that a failed run can be resumed by a later invocation.
+type RunState struct {
+	Completed     []string `json:"completed"`
+	FailedAllowed []string `json:"failed-allowed"`
+	Tags          []string `json:"tags"`
+	ExportedEnv   []string `json:"exported-env"`
+}
+
+// LoadRunState reads the state persisted by a previous run.
+func LoadRunState(scratchSpace *scratch.ScratchSpace) (*RunState, error) {
+	data, err := ioutil.ReadFile(runStatePath(scratchSpace))
+	if err != nil {
+		return nil, err
+	}
+
+	state := &RunState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Write persists the state to the run directory.
+func (s *RunState) Write(scratchSpace *scratch.ScratchSpace) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(runStatePath(scratchSpace), data, 0644)
+}
+
+//
+// Helpers
+
+func runStatePath(scratchSpace *scratch.ScratchSpace) string {
+	return filepath.Join(scratchSpace.Runpath(), scratch.StateFile)
+}
@@ -0,0 +1,41 @@
+package runner
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/scratch"
+	. "github.com/onsi/gomega"
+)
+
+type RunStateSuite struct{}
+
+func (s *RunStateSuite) TestWriteAndLoad(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	scratch := scratch.NewScratchSpace("abcdef0", name, name, true)
+	Expect(scratch.Setup()).To(BeNil())
+
+	state := &RunState{
+		Completed:     []string{"p/s/x"},
+		FailedAllowed: []string{"p/s/y"},
+		Tags:          []string{"app:latest"},
+		ExportedEnv:   []string{"X=1"},
+	}
+
+	Expect(state.Write(scratch)).To(BeNil())
+
+	loaded, err := LoadRunState(scratch)
+	Expect(err).To(BeNil())
+	Expect(loaded).To(Equal(state))
+}
+
+func (s *RunStateSuite) TestLoadMissing(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	_, err := LoadRunState(scratch.NewScratchSpace("abcdef0", name, name, true))
+	Expect(err).NotTo(BeNil())
+}
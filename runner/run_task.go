@@ -11,17 +11,20 @@ import (
 
 	"github.com/kballard/go-shellquote"
 
-	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/cache"
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/environment"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 	"github.com/ij-build/ij/scratch"
 	"github.com/ij-build/ij/util"
+	"github.com/ij-build/ij/volume"
 )
 
 type (
 	RunTaskRunnerFactory func(
 		*config.RunTask,
+		*config.RetryPolicy,
 		environment.Environment,
 		*logging.Prefix,
 	) TaskRunner
@@ -30,28 +33,42 @@ type (
 		ctx              context.Context
 		config           *config.Config
 		runID            string
+		runtime          runtime.ContainerRuntime
 		scratch          *scratch.ScratchSpace
 		containerLists   *ContainerLists
 		containerOptions *containerOptions
 		logger           logging.Logger
 		loggerFactory    *logging.LoggerFactory
+		cache            *cache.Cache
+		debugger         *Debugger
+		serviceLogs      *ServiceLogs
 		task             *config.RunTask
+		retryPolicy      *config.RetryPolicy
 		env              environment.Environment
 		prefix           *logging.Prefix
+		failedContainer  string
 	}
 
+	// ScriptWriter persists the given script and returns the path of
+	// the file on the host.
+	ScriptWriter func(script string) (string, error)
+
 	containerOptions struct {
 		EnableHostSSHAgent      bool
 		EnableContainerSSHAgent bool
 		CPUShares               string
 		Memory                  string
+		KeepContainer           bool
+		Volume                  *volume.Volume
+		Caches                  *volume.Caches
 	}
 
-	runTaskCommandBuilderState struct {
+	runTaskOptionsState struct {
 		runID            string
 		config           *config.Config
 		containerOptions *containerOptions
 		scratch          *scratch.ScratchSpace
+		writeScript      ScriptWriter
 		task             *config.RunTask
 		containerName    string
 		env              environment.Environment
@@ -59,35 +76,46 @@ type (
 )
 
 const (
-	DefaultWorkspacePath = "/workspace"
-	ScriptPath           = "/tmp/ij/script"
+	DefaultWorkspacePath    = "/workspace"
+	ScriptPath              = "/tmp/ij/script"
+	ExitedContainerLogLines = 20
 )
 
 func NewRunTaskRunnerFactory(
 	ctx context.Context,
 	cfg *config.Config,
 	runID string,
+	runtime runtime.ContainerRuntime,
 	scratch *scratch.ScratchSpace,
 	containerLists *ContainerLists,
 	containerOptions *containerOptions,
 	logger logging.Logger,
 	loggerFactory *logging.LoggerFactory,
+	cache *cache.Cache,
+	debugger *Debugger,
+	serviceLogs *ServiceLogs,
 ) RunTaskRunnerFactory {
 	return func(
 		task *config.RunTask,
+		retryPolicy *config.RetryPolicy,
 		env environment.Environment,
 		prefix *logging.Prefix,
 	) TaskRunner {
 		return &runTaskRunner{
 			ctx:              ctx,
 			runID:            runID,
+			runtime:          runtime,
 			config:           cfg,
 			scratch:          scratch,
 			containerLists:   containerLists,
 			containerOptions: containerOptions,
 			logger:           logger,
 			loggerFactory:    loggerFactory,
+			cache:            cache,
+			debugger:         debugger,
+			serviceLogs:      serviceLogs,
 			task:             task,
+			retryPolicy:      retryPolicy,
 			env:              env,
 			prefix:           prefix,
 		}
@@ -100,6 +128,44 @@ func (r *runTaskRunner) Run(context *RunContext) bool {
 		"Beginning task",
 	)
 
+	if r.cache != nil && r.task.Cache != nil && !r.task.Detach {
+		return r.runCached(context)
+	}
+
+	return r.runWithRetries(context)
+}
+
+func (r *runTaskRunner) runWithRetries(context *RunContext) bool {
+	ok := runWithRetries(
+		r.ctx,
+		r.logger,
+		r.prefix,
+		r.retryPolicy,
+		func() (bool, error) {
+			// Only the container of the final attempt is debugged
+			r.removeFailedContainer()
+			return r.runAttempt(context)
+		},
+	)
+
+	if !ok && r.failedContainer != "" && r.debugger != nil && r.ctx.Err() == nil {
+		r.debugger.Debug(r.failedContainer, r.buildDebugCommand, r.prefix)
+	}
+
+	r.removeFailedContainer()
+	return ok
+}
+
+func (r *runTaskRunner) removeFailedContainer() {
+	if r.failedContainer == "" {
+		return
+	}
+
+	removeContainer(r.runtime, r.failedContainer, r.logger, r.prefix)
+	r.failedContainer = ""
+}
+
+func (r *runTaskRunner) runAttempt(context *RunContext) (bool, error) {
 	containerName, err := util.MakeID()
 	if err != nil {
 		r.logger.Error(
@@ -108,7 +174,7 @@ func (r *runTaskRunner) Run(context *RunContext) bool {
 			err.Error(),
 		)
 
-		return false
+		return false, nil
 	}
 
 	r.logger.Info(
@@ -117,11 +183,12 @@ func (r *runTaskRunner) Run(context *RunContext) bool {
 		containerName,
 	)
 
-	builder, err := runTaskCommandBuilderFactory(
+	options, err := runTaskRunOptionsFactory(
 		r.runID,
 		r.config,
 		r.containerOptions,
 		r.scratch,
+		r.scratch.WriteScript,
 		r.task,
 		containerName,
 		r.env,
@@ -130,36 +197,25 @@ func (r *runTaskRunner) Run(context *RunContext) bool {
 	if err != nil {
 		r.logger.Error(
 			r.prefix,
-			"Failed to build command args: %s",
-			err.Error(),
-		)
-
-		return false
-	}
-
-	args, _, err := builder.Build()
-	if err != nil {
-		r.logger.Error(
-			r.prefix,
-			"Failed to build command args: %s",
+			"Failed to build container options: %s",
 			err.Error(),
 		)
 
-		return false
+		return false, nil
 	}
 
 	if r.task.Detach {
-		return r.runInBackground(containerName, args)
+		return r.runInBackground(containerName, options)
 	}
 
-	return r.runInForeground(context, containerName, args)
+	return r.runInForeground(context, containerName, options)
 }
 
 func (r *runTaskRunner) runInForeground(
 	context *RunContext,
 	containerName string,
-	args []string,
-) bool {
+	options *runtime.RunOptions,
+) (bool, error) {
 	logger, err := r.loggerFactory.Logger(
 		r.prefix.Serialize(logging.NilColorPicker),
 		false,
@@ -172,20 +228,39 @@ func (r *runTaskRunner) runInForeground(
 			err.Error(),
 		)
 
-		return false
+		return false, nil
 	}
 
 	r.containerLists.NetworkDisconnector.Add(containerName)
 	defer r.containerLists.NetworkDisconnector.Remove(containerName)
 
-	err = command.NewRunner(logger).Run(
+	if r.containerOptions.KeepContainer {
+		// The container is not run with --rm so that it can be committed
+		// if the attempt fails, and is removed once retries are exhausted
+		defer func() {
+			if r.failedContainer != containerName {
+				removeContainer(r.runtime, containerName, r.logger, r.prefix)
+			}
+		}()
+	}
+
+	detach := attachWorkspace(r.containerOptions.Volume)
+
+	err = r.runtime.Run(
 		r.ctx,
-		args,
-		nil,
+		options,
+		logger,
 		r.prefix,
 	)
 
 	if err != nil {
+		if deadlineExceeded(r.ctx) || cancelled(r.ctx) {
+			// Canceling the run does not stop the container
+			stopContainer(r.runtime, containerName, r.logger, r.prefix)
+		}
+
+		detach()
+
 		reportError(
 			r.ctx,
 			r.logger,
@@ -194,10 +269,64 @@ func (r *runTaskRunner) runInForeground(
 			err.Error(),
 		)
 
-		return false
+		if r.containerOptions.KeepContainer {
+			r.failedContainer = containerName
+		}
+
+		return false, err
+	}
+
+	detach()
+	return r.exportEnvironmentFiles(context), nil
+}
+
+// buildDebugCommand creates the args of an interactive container running
+// the given snapshot of a failed container of this task.
+func (r *runTaskRunner) buildDebugCommand(image string) ([]string, error) {
+	containerName, err := util.MakeID()
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := runTaskDebugOptionsFactory(
+		r.runID,
+		r.config,
+		r.containerOptions,
+		r.scratch,
+		r.scratch.WriteScript,
+		r.task,
+		containerName,
+		image,
+		r.env,
+	)
+
+	if err != nil {
+		return nil, err
 	}
 
-	return r.exportEnvironmentFiles(context)
+	return r.runtime.RunArgs(options), nil
+}
+
+func (r *runTaskRunner) pushWorkspacePaths(files []string) bool {
+	return pushWorkspacePaths(
+		r.ctx,
+		r.containerOptions.Volume,
+		r.scratch,
+		r.logger,
+		r.prefix,
+		files,
+	)
+}
+
+func (r *runTaskRunner) pullWorkspacePatterns(patterns []string) bool {
+	return pullWorkspacePatterns(
+		r.ctx,
+		r.containerOptions.Volume,
+		r.scratch,
+		r.logger,
+		r.prefix,
+		patterns,
+	)
 }
 
 func (r *runTaskRunner) exportEnvironmentFiles(context *RunContext) bool {
@@ -214,6 +343,10 @@ func (r *runTaskRunner) exportEnvironmentFiles(context *RunContext) bool {
 		return false
 	}
 
+	if !r.pullWorkspacePatterns(paths) {
+		return false
+	}
+
 	for _, path := range paths {
 		if !r.exportEnvironmentFile(context, path) {
 			return false
@@ -285,13 +418,14 @@ func (r *runTaskRunner) exportEnvironmentFile(context *RunContext, path string)
 	return true
 }
 
-func (r *runTaskRunner) runInBackground(containerName string, args []string) bool {
+func (r *runTaskRunner) runInBackground(containerName string, options *runtime.RunOptions) (bool, error) {
 	r.containerLists.ContainerStopper.Add(containerName)
 
-	_, _, err := command.NewRunner(r.logger).RunForOutput(
+	err := r.runtime.Run(
 		context.Background(),
-		args,
-		nil,
+		options,
+		r.logger,
+		r.prefix,
 	)
 
 	if err != nil {
@@ -303,11 +437,22 @@ func (r *runTaskRunner) runInBackground(containerName string, args []string) boo
 			err.Error(),
 		)
 
-		return false
+		return false, err
+	}
+
+	if r.serviceLogs != nil {
+		if err := r.serviceLogs.Follow(containerName, r.prefix); err != nil {
+			r.logger.Warn(
+				r.prefix,
+				"Failed to follow container output: %s",
+				err.Error(),
+			)
+		}
 	}
 
 	hasHealthcheck, err := hasHealthcheck(
 		r.ctx,
+		r.runtime,
 		containerName,
 		r.logger,
 		r.prefix,
@@ -322,26 +467,48 @@ func (r *runTaskRunner) runInBackground(containerName string, args []string) boo
 			err.Error(),
 		)
 
-		return false
+		return false, nil
 	}
 
-	if !hasHealthcheck {
-		return true
+	if hasHealthcheck && !r.monitor(containerName) {
+		if deadlineExceeded(r.ctx) || cancelled(r.ctx) {
+			r.containerLists.ContainerStopper.ExecuteOne(containerName)
+		}
+
+		return false, nil
 	}
 
-	return r.monitor(containerName)
+	if r.task.Readiness != nil && !r.awaitReadiness(containerName) {
+		if deadlineExceeded(r.ctx) || cancelled(r.ctx) {
+			r.containerLists.ContainerStopper.ExecuteOne(containerName)
+		}
+
+		return false, nil
+	}
+
+	return true, nil
 }
 
 func (r *runTaskRunner) monitor(containerName string) bool {
+	ctx := r.ctx
+
+	maxWait := r.task.Healthcheck.MaxWait
+	if maxWait > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(r.ctx, maxWait)
+		defer cancel()
+	}
+
 	for {
-		status, err := getHealthStatus(
-			r.ctx,
+		state, err := getContainerState(
+			ctx,
+			r.runtime,
 			containerName,
 			r.logger,
 			r.prefix,
 		)
 
-		if err != nil {
+		if err != nil && ctx.Err() == nil {
 			reportError(
 				r.ctx,
 				r.logger,
@@ -353,85 +520,201 @@ func (r *runTaskRunner) monitor(containerName string) bool {
 			return false
 		}
 
-		if status == "healthy" {
+		if err == nil {
+			if !r.checkRunning(containerName, state) {
+				return false
+			}
+
+			if state.Health == "healthy" {
+				r.logger.Info(
+					r.prefix,
+					"Container is healthy",
+				)
+
+				return true
+			}
+
 			r.logger.Info(
 				r.prefix,
-				"Container is healthy",
+				"Container is not yet healthy (currently %s)",
+				state.Health,
 			)
-
-			return true
 		}
 
-		r.logger.Info(
-			r.prefix,
-			"Container is not yet healthy (currently %s)",
-			status,
-		)
-
 		select {
 		case <-time.After(r.config.Options.HealthcheckInterval):
-		case <-r.ctx.Done():
+		case <-ctx.Done():
+			if r.ctx.Err() == nil {
+				r.logger.Error(
+					r.prefix,
+					"Container did not become healthy within %s",
+					maxWait,
+				)
+			}
+
 			return false
 		}
 	}
 }
 
-func runTaskCommandBuilderFactory(
+// checkRunning determines if the given container state is running. If the
+// container has exited or restarted, the tail of its output is logged.
+func (r *runTaskRunner) checkRunning(containerName string, state *runtime.ContainerState) bool {
+	switch {
+	case state.Status == "exited" || state.Status == "dead":
+		r.logger.Error(
+			r.prefix,
+			"Container exited with status %d",
+			state.ExitCode,
+		)
+
+	case state.Status == "restarting" || state.RestartCount > 0:
+		r.logger.Error(
+			r.prefix,
+			"Container restarted (last exited with status %d)",
+			state.ExitCode,
+		)
+
+	default:
+		return true
+	}
+
+	r.logger.Error(
+		r.prefix,
+		"Last %d lines of container output follow",
+		ExitedContainerLogLines,
+	)
+
+	options := &runtime.LogsOptions{
+		Tail: ExitedContainerLogLines,
+	}
+
+	if err := r.runtime.Logs(context.Background(), containerName, options, r.logger, r.prefix); err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to read container output: %s",
+			err.Error(),
+		)
+	}
+
+	return false
+}
+
+func runTaskRunOptionsFactory(
 	runID string,
 	config *config.Config,
 	containerOptions *containerOptions,
 	scratch *scratch.ScratchSpace,
+	writeScript ScriptWriter,
 	task *config.RunTask,
 	containerName string,
 	env environment.Environment,
-) (*command.Builder, error) {
-	s := &runTaskCommandBuilderState{
+) (*runtime.RunOptions, error) {
+	s := &runTaskOptionsState{
 		runID:            runID,
 		config:           config,
 		containerOptions: containerOptions,
 		scratch:          scratch,
+		writeScript:      writeScript,
 		task:             task,
 		containerName:    containerName,
 		env:              env,
 	}
 
-	return command.NewBuilder(
-		[]string{
-			"docker",
-			"run",
-			"--rm",
-		},
-		[]command.BuildFunc{
-			s.addImageArg,
-			s.addCommandOptions, // Populates command, must come second
-			s.addScriptOptions,  // Populates command, must come second
-			s.addContainerName,
-			s.addDetachOptions,
-			s.addEnvironmentOptions,
-			s.addHealthcheckOptions,
-			s.addLimitOptions,
-			s.addNetworkOptions,
-			s.addSSHOptions,
-			s.addUserOptions,
-			s.addWorkspaceOptions,
-		},
-	), nil
+	// Detached containers are removed by the container stopper so
+	// that their state can be inspected if they exit early
+	options := &runtime.RunOptions{
+		Detach: task.Detach,
+		Remove: !containerOptions.KeepContainer && !task.Detach,
+	}
+
+	return options, s.apply(
+		options,
+		s.addImageOption,
+		s.addCommandOptions,
+		s.addScriptOptions,
+		s.addCacheOptions,
+		s.addContainerName,
+		s.addEnvironmentOptions,
+		s.addHealthcheckOptions,
+		s.addLimitOptions,
+		s.addMountOptions,
+		s.addNetworkOptions,
+		s.addPortOptions,
+		s.addSSHOptions,
+		s.addUserOptions,
+		s.addWorkspaceOptions,
+	)
+}
+
+// apply invokes each of the given functions on the options in order and
+// returns the first error.
+func (s *runTaskOptionsState) apply(
+	options *runtime.RunOptions,
+	funcs ...func(*runtime.RunOptions) error,
+) error {
+	for _, f := range funcs {
+		if err := f(options); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 //
-// Builders
+// Options
 
-func (s *runTaskCommandBuilderState) addImageArg(cb *command.Builder) error {
+func (s *runTaskOptionsState) addImageOption(options *runtime.RunOptions) error {
 	image, err := s.env.ExpandString(s.task.Image)
 	if err != nil {
 		return err
 	}
 
-	cb.AddArgs(image)
+	options.Image = image
 	return nil
 }
 
-func (s *runTaskCommandBuilderState) addCommandOptions(cb *command.Builder) error {
+// addCacheOptions mounts the volumes backing the dependency caches used by
+// the task. The volumes are created on first use unless only the command is
+// being built for a dry run.
+func (s *runTaskOptionsState) addCacheOptions(options *runtime.RunOptions) error {
+	for _, name := range s.task.Caches {
+		cache := s.config.Caches[name]
+
+		path, err := s.env.ExpandString(cache.Path)
+		if err != nil {
+			return err
+		}
+
+		key, err := s.env.ExpandString(cache.Key)
+		if err != nil {
+			return err
+		}
+
+		if err := checkMountFields("", path); err != nil {
+			return err
+		}
+
+		source := volume.CacheVolumeName(s.scratch.Project(), name, key)
+
+		if caches := s.containerOptions.Caches; caches != nil {
+			if source, err = caches.Ensure(context.Background(), name, key); err != nil {
+				return err
+			}
+		}
+
+		options.Mounts = append(options.Mounts, &runtime.Mount{
+			Type:   config.MountTypeVolume,
+			Source: source,
+			Target: path,
+		})
+	}
+
+	return nil
+}
+
+func (s *runTaskOptionsState) addCommandOptions(options *runtime.RunOptions) error {
 	if s.task.Script != "" {
 		return nil
 	}
@@ -451,117 +734,265 @@ func (s *runTaskCommandBuilderState) addCommandOptions(cb *command.Builder) erro
 		return err
 	}
 
-	cb.AddFlagValue("--entrypoint", entrypoint)
-	cb.AddArgs(commandArgs...)
+	options.Entrypoint = entrypoint
+	options.Args = commandArgs
 	return nil
 }
 
-func (s *runTaskCommandBuilderState) addContainerName(cb *command.Builder) error {
+func (s *runTaskOptionsState) addContainerName(options *runtime.RunOptions) error {
 	containerName, err := s.env.ExpandString(s.containerName)
 	if err != nil {
 		return err
 	}
 
-	cb.AddFlagValue("--name", containerName)
+	options.Name = containerName
 	return nil
 }
 
-func (s *runTaskCommandBuilderState) addDetachOptions(cb *command.Builder) error {
-	if s.task.Detach {
-		cb.AddFlag("-d")
-	}
-
-	return nil
-}
-
-func (s *runTaskCommandBuilderState) addEnvironmentOptions(cb *command.Builder) error {
+func (s *runTaskOptionsState) addEnvironmentOptions(options *runtime.RunOptions) error {
 	for _, line := range s.env.Serialize() {
 		expanded, err := s.env.ExpandString(line)
 		if err != nil {
 			return err
 		}
 
-		cb.AddFlagValue("-e", expanded)
+		options.Env = append(options.Env, expanded)
 	}
 
 	return nil
 }
 
-func (s *runTaskCommandBuilderState) addHealthcheckOptions(cb *command.Builder) error {
+func (s *runTaskOptionsState) addHealthcheckOptions(options *runtime.RunOptions) error {
 	command, err := s.env.ExpandString(s.task.Healthcheck.Command)
 	if err != nil {
 		return err
 	}
 
-	cb.AddFlagValue("--health-cmd", command)
-	cb.AddFlagValue("--health-interval", s.task.Healthcheck.Interval.String())
-	cb.AddFlagValue("--health-start-period", s.task.Healthcheck.StartPeriod.String())
-	cb.AddFlagValue("--health-timeout", s.task.Healthcheck.Timeout.String())
+	healthcheck := &runtime.Healthcheck{
+		Command:     command,
+		Interval:    s.task.Healthcheck.Interval,
+		StartPeriod: s.task.Healthcheck.StartPeriod,
+		Timeout:     s.task.Healthcheck.Timeout,
+		Retries:     s.task.Healthcheck.Retries,
+	}
 
-	if s.task.Healthcheck.Retries > 0 {
-		cb.AddFlagValue("--health-retries", fmt.Sprintf(
-			"%d",
-			s.task.Healthcheck.Retries,
-		))
+	if *healthcheck != (runtime.Healthcheck{}) {
+		options.Healthcheck = healthcheck
 	}
 
 	return nil
 }
 
-func (s *runTaskCommandBuilderState) addLimitOptions(cb *command.Builder) error {
-	cb.AddFlagValue("--cpu-shares", s.containerOptions.CPUShares)
-	cb.AddFlagValue("--memory", s.containerOptions.Memory)
+// addLimitOptions adds the resource limits and runtime options of the task.
+// The limits given on the command line apply only to tasks which do not set
+// their own.
+func (s *runTaskOptionsState) addLimitOptions(options *runtime.RunOptions) error {
+	cpuShares, err := s.env.ExpandString(s.task.CPUShares)
+	if err != nil {
+		return err
+	}
+
+	memory, err := s.env.ExpandString(s.task.Memory)
+	if err != nil {
+		return err
+	}
+
+	shmSize, err := s.env.ExpandString(s.task.ShmSize)
+	if err != nil {
+		return err
+	}
+
+	platform, err := s.env.ExpandString(s.task.Platform)
+	if err != nil {
+		return err
+	}
+
+	if cpuShares == "" {
+		cpuShares = s.containerOptions.CPUShares
+	}
+
+	if memory == "" {
+		memory = s.containerOptions.Memory
+	}
+
+	options.CPUShares = cpuShares
+	options.Memory = memory
+	options.ShmSize = shmSize
+	options.Platform = platform
+
+	if options.Ulimits, err = s.env.ExpandSlice(s.task.Ulimits); err != nil {
+		return err
+	}
+
+	if options.CapAdd, err = s.env.ExpandSlice(s.task.CapAdd); err != nil {
+		return err
+	}
+
+	if options.Tmpfs, err = s.env.ExpandSlice(s.task.Tmpfs); err != nil {
+		return err
+	}
+
+	if options.Devices, err = s.env.ExpandSlice(s.task.Devices); err != nil {
+		return err
+	}
+
+	if err := checkAllowedPrivileges(s.task.Privileged, options.Devices, options.CapAdd, s.config.Options); err != nil {
+		return err
+	}
+
+	options.Privileged = s.task.Privileged
 	return nil
 }
 
-func (s *runTaskCommandBuilderState) addNetworkOptions(cb *command.Builder) error {
+// addMountOptions adds the additional mounts of the task. The source of a
+// bind mount must be within one of the paths allowed by override files, and
+// the source of a volume mount must be a plain volume name.
+func (s *runTaskOptionsState) addMountOptions(options *runtime.RunOptions) error {
+	for _, mount := range s.task.Mounts {
+		source, err := s.env.ExpandString(mount.Source)
+		if err != nil {
+			return err
+		}
+
+		target, err := s.env.ExpandString(mount.Target)
+		if err != nil {
+			return err
+		}
+
+		switch mount.Type {
+		case config.MountTypeBind:
+			if source, err = resolveHostPath(source, s.scratch.Project()); err != nil {
+				return err
+			}
+
+			if err := checkAllowedMount(source, s.config.Options.AllowedMounts, s.scratch.Project()); err != nil {
+				return err
+			}
+
+		case config.MountTypeVolume:
+			if err := checkVolumeName(source); err != nil {
+				return err
+			}
+		}
+
+		if err := checkMountFields(source, target); err != nil {
+			return err
+		}
+
+		options.Mounts = append(options.Mounts, &runtime.Mount{
+			Type:     mount.Type,
+			Source:   source,
+			Target:   target,
+			ReadOnly: mount.ReadOnly,
+		})
+	}
+
+	return nil
+}
+
+func (s *runTaskOptionsState) addNetworkOptions(options *runtime.RunOptions) error {
 	hostname, err := s.env.ExpandString(s.task.Hostname)
 	if err != nil {
 		return err
 	}
 
-	cb.AddFlagValue("--network", s.runID)
-	cb.AddFlagValue("--network-alias", hostname)
+	options.Network = s.runID
+	options.Alias = hostname
+	return nil
+}
+
+func (s *runTaskOptionsState) addPortOptions(options *runtime.RunOptions) error {
+	ports, err := s.env.ExpandSlice(s.task.Ports)
+	if err != nil {
+		return err
+	}
+
+	options.Ports = ports
 	return nil
 }
 
-func (s *runTaskCommandBuilderState) addScriptOptions(cb *command.Builder) error {
+func (s *runTaskOptionsState) addScriptOptions(options *runtime.RunOptions) error {
 	if s.task.Script == "" {
 		return nil
 	}
 
-	script, err := s.env.ExpandString(s.task.Script)
+	scriptPath, err := s.mountScript(options)
 	if err != nil {
 		return err
 	}
 
-	path, err := s.scratch.WriteScript(script)
+	shell, err := s.shell()
 	if err != nil {
 		return err
 	}
 
+	options.Entrypoint = shell
+	options.Args = []string{scriptPath}
+	return nil
+}
+
+func (s *runTaskOptionsState) addScriptMount(options *runtime.RunOptions) error {
+	if s.task.Script == "" {
+		return nil
+	}
+
+	_, err := s.mountScript(options)
+	return err
+}
+
+// mountScript writes the script of the task and mounts it into the
+// container. The path of the script within the container is returned.
+func (s *runTaskOptionsState) mountScript(options *runtime.RunOptions) (string, error) {
+	script, err := s.env.ExpandString(s.task.Script)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := s.writeScript(script)
+	if err != nil {
+		return "", err
+	}
+
+	if volume := s.containerOptions.Volume; volume != nil {
+		name, err := volume.PushScript(context.Background(), path)
+		if err != nil {
+			return "", err
+		}
+
+		mount := fmt.Sprintf(
+			"%s:%s:ro",
+			volume.ScriptsName(),
+			ScriptsVolumePath,
+		)
+
+		options.Binds = append(options.Binds, mount)
+		return ScriptsVolumePath + "/" + name, nil
+	}
+
 	mount := fmt.Sprintf(
 		"%s:%s",
 		path,
 		ScriptPath,
 	)
 
+	options.Binds = append(options.Binds, mount)
+	return ScriptPath, nil
+}
+
+func (s *runTaskOptionsState) shell() (string, error) {
 	shell, err := s.env.ExpandString(s.task.Shell)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if shell == "" {
 		shell = "/bin/sh"
 	}
 
-	cb.AddFlagValue("-v", mount)
-	cb.AddFlagValue("--entrypoint", shell)
-	cb.AddArgs(ScriptPath)
-	return nil
+	return shell, nil
 }
 
-func (s *runTaskCommandBuilderState) addUserOptions(cb *command.Builder) error {
+func (s *runTaskOptionsState) addUserOptions(options *runtime.RunOptions) error {
 	uid, err := s.env.ExpandString("${UID}")
 	if err != nil {
 		return err
@@ -577,30 +1008,30 @@ func (s *runTaskCommandBuilderState) addUserOptions(cb *command.Builder) error {
 		return err
 	}
 
-	cb.AddFlagValue("--user", username)
-	cb.AddFlagValue("-e", fmt.Sprintf("UID=%s", uid))
-	cb.AddFlagValue("-e", fmt.Sprintf("GID=%s", gid))
+	options.MapUser = true
+	options.User = username
+	options.Env = append(options.Env, fmt.Sprintf("UID=%s", uid), fmt.Sprintf("GID=%s", gid))
 	return nil
 }
 
-func (s *runTaskCommandBuilderState) addSSHOptions(cb *command.Builder) error {
+func (s *runTaskOptionsState) addSSHOptions(options *runtime.RunOptions) error {
 	if s.containerOptions.EnableHostSSHAgent {
 		// Outside of the ssh-agent container we can just mount the host auth socket.
 		authSock := os.Getenv("SSH_AUTH_SOCK")
-		cb.AddFlagValue("-e", "SSH_AUTH_SOCK")
-		cb.AddFlagValue("-v", fmt.Sprintf("%s:%s", authSock, authSock))
+		options.Env = append(options.Env, fmt.Sprintf("SSH_AUTH_SOCK=%s", authSock))
+		options.Binds = append(options.Binds, fmt.Sprintf("%s:%s", authSock, authSock))
 	}
 
 	if s.containerOptions.EnableContainerSSHAgent {
 		// Mount the socket from the ssh-agent container
-		cb.AddFlagValue("-e", fmt.Sprintf("SSH_AUTH_SOCK=%s", SocketPath))
-		cb.AddFlagValue("--volumes-from", fmt.Sprintf("%s-ssh-agent", s.runID))
+		options.Env = append(options.Env, fmt.Sprintf("SSH_AUTH_SOCK=%s", SocketPath))
+		options.VolumesFrom = append(options.VolumesFrom, fmt.Sprintf("%s-ssh-agent", s.runID))
 	}
 
 	return nil
 }
 
-func (s *runTaskCommandBuilderState) addWorkspaceOptions(cb *command.Builder) error {
+func (s *runTaskOptionsState) addWorkspaceOptions(options *runtime.RunOptions) error {
 	workspace, err := s.env.ExpandString(s.task.Workspace)
 	if err != nil {
 		return err
@@ -617,14 +1048,19 @@ func (s *runTaskCommandBuilderState) addWorkspaceOptions(cb *command.Builder) er
 		workspace = DefaultWorkspacePath
 	}
 
+	source := s.scratch.Workspace()
+	if s.containerOptions.Volume != nil {
+		source = s.containerOptions.Volume.WorkspaceName()
+	}
+
 	mount := fmt.Sprintf(
 		"%s:%s",
-		s.scratch.Workspace(),
+		source,
 		workspace,
 	)
 
-	cb.AddFlagValue("-v", mount)
-	cb.AddFlagValue("-w", workspace)
+	options.Binds = append(options.Binds, mount)
+	options.Workdir = workspace
 	return nil
 }
 
@@ -633,58 +1069,33 @@ func (s *runTaskCommandBuilderState) addWorkspaceOptions(cb *command.Builder) er
 
 func hasHealthcheck(
 	ctx context.Context,
+	runtime runtime.ContainerRuntime,
 	containerName string,
 	logger logging.Logger,
 	prefix *logging.Prefix,
 ) (bool, error) {
 	logger.Debug(prefix, "Checking if container has a healthcheck")
-
-	args := []string{
-		"docker",
-		"inspect",
-		"-f",
-		"{{if .Config.Healthcheck}}true{{else}}false{{end}}",
-		containerName,
-	}
-
-	out, _, err := command.NewRunner(logger).RunForOutput(
-		ctx,
-		args,
-		nil,
-	)
-
-	if err != nil {
-		return false, err
-	}
-
-	return strings.TrimSpace(out) == "true", nil
+	return runtime.HasHealthcheck(ctx, containerName)
 }
 
-func getHealthStatus(
+func getImageID(
 	ctx context.Context,
-	containerName string,
+	runtime runtime.ContainerRuntime,
+	image string,
 	logger logging.Logger,
 	prefix *logging.Prefix,
 ) (string, error) {
-	logger.Debug(prefix, "Checking container health")
-
-	args := []string{
-		"docker",
-		"inspect",
-		"-f",
-		"{{.State.Health.Status}}",
-		containerName,
-	}
-
-	out, _, err := command.NewRunner(logger).RunForOutput(
-		ctx,
-		args,
-		nil,
-	)
-
-	if err != nil {
-		return "", err
-	}
+	logger.Debug(prefix, "Checking image id")
+	return runtime.ImageID(ctx, image)
+}
 
-	return strings.TrimSpace(out), nil
+func getContainerState(
+	ctx context.Context,
+	runtime runtime.ContainerRuntime,
+	containerName string,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+) (*runtime.ContainerState, error) {
+	logger.Debug(prefix, "Checking container state")
+	return runtime.Inspect(ctx, containerName)
 }
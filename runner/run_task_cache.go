@@ -0,0 +1,204 @@
+package runner
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/ij-build/ij/cache"
+	"github.com/ij-build/ij/paths"
+)
+
+// runCached restores the outputs of the task from the cache when there is
+// an entry for the current inputs, task definition, and image. Otherwise,
+// the task is run and its outputs are stored for subsequent runs.
+func (r *runTaskRunner) runCached(context *RunContext) bool {
+	inputsKey, err := r.inputsKey()
+	if err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to hash cache inputs: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	// An image that is not yet available locally can't have cached outputs
+	imageID, _ := r.imageID()
+
+	if imageID != "" {
+		key, err := cache.Key("", nil, inputsKey, imageID)
+		if err != nil {
+			r.logger.Error(
+				r.prefix,
+				"Failed to hash cache inputs: %s",
+				err.Error(),
+			)
+
+			return false
+		}
+
+		hit, err := r.cache.Restore(key, r.scratch.Workspace())
+		if err != nil {
+			r.logger.Warn(
+				r.prefix,
+				"Failed to restore outputs from cache: %s",
+				err.Error(),
+			)
+		}
+
+		if hit {
+			r.logger.Info(
+				r.prefix,
+				"Restored outputs from cache entry %s",
+				key,
+			)
+
+			if !r.pushRestoredOutputs() {
+				return false
+			}
+
+			return r.exportEnvironmentFiles(context)
+		}
+	}
+
+	r.logger.Info(
+		r.prefix,
+		"No cached outputs for task",
+	)
+
+	if !r.runWithRetries(context) {
+		return false
+	}
+
+	if err := r.storeOutputs(inputsKey, imageID); err != nil {
+		r.logger.Warn(
+			r.prefix,
+			"Failed to store outputs in cache: %s",
+			err.Error(),
+		)
+	}
+
+	return true
+}
+
+// inputsKey hashes the input files of the task along with the task
+// definition and its environment. This must be computed before the task
+// runs, as the task may modify its own inputs.
+func (r *runTaskRunner) inputsKey() (string, error) {
+	inputs, err := r.matchCachePatterns(r.task.Cache.Inputs, true)
+	if err != nil {
+		return "", err
+	}
+
+	definition, err := json.Marshal(r.task)
+	if err != nil {
+		return "", err
+	}
+
+	return cache.Key(
+		r.scratch.Workspace(),
+		inputs,
+		string(definition),
+		r.config.Workspace,
+		strings.Join(r.env.Serialize(), "\n"),
+	)
+}
+
+func (r *runTaskRunner) storeOutputs(inputsKey, imageID string) error {
+	if imageID == "" {
+		id, err := r.imageID()
+		if err != nil {
+			return err
+		}
+
+		imageID = id
+	}
+
+	key, err := cache.Key("", nil, inputsKey, imageID)
+	if err != nil {
+		return err
+	}
+
+	outputs, err := r.matchCachePatterns(r.task.Cache.Outputs, true)
+	if err != nil {
+		return err
+	}
+
+	if err := r.cache.Store(key, r.scratch.Workspace(), outputs); err != nil {
+		return err
+	}
+
+	r.logger.Info(
+		r.prefix,
+		"Stored outputs in cache entry %s",
+		key,
+	)
+
+	return nil
+}
+
+func (r *runTaskRunner) imageID() (string, error) {
+	image, err := r.env.ExpandString(r.task.Image)
+	if err != nil {
+		return "", err
+	}
+
+	return getImageID(r.ctx, r.runtime, image, r.logger, r.prefix)
+}
+
+// pushRestoredOutputs copies the outputs restored from the cache into the
+// workspace volume. This is a no-op unless the workspace is held in a volume.
+func (r *runTaskRunner) pushRestoredOutputs() bool {
+	if r.containerOptions.Volume == nil {
+		return true
+	}
+
+	outputs, err := r.matchCachePatterns(r.task.Cache.Outputs, false)
+	if err != nil {
+		r.logger.Error(
+			r.prefix,
+			"Failed to match restored outputs: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	files := []string{}
+	for _, path := range outputs {
+		rel, err := filepath.Rel(r.scratch.Workspace(), path)
+		if err != nil {
+			r.logger.Error(
+				r.prefix,
+				"Failed to match restored outputs: %s",
+				err.Error(),
+			)
+
+			return false
+		}
+
+		files = append(files, rel)
+	}
+
+	return r.pushWorkspacePaths(files)
+}
+
+// matchCachePatterns returns the files of the host workspace that match the
+// given patterns. When pull is set and the workspace is held in a volume,
+// the paths which may contain a match are first copied out of the volume.
+func (r *runTaskRunner) matchCachePatterns(patterns []string, pull bool) ([]string, error) {
+	expanded, err := r.env.ExpandSlice(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	if volume := r.containerOptions.Volume; pull && volume != nil {
+		if err := volume.PullPaths(r.ctx, r.scratch.Workspace(), patternRoots(expanded)); err != nil {
+			return nil, err
+		}
+	}
+
+	return paths.Match(r.scratch.Workspace(), expanded)
+}
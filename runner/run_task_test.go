@@ -0,0 +1,290 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
+	"github.com/ij-build/ij/scratch"
+	"github.com/ij-build/ij/volume"
+	. "github.com/onsi/gomega"
+)
+
+type RunTaskSuite struct{}
+
+func (s *RunTaskSuite) TestMonitorHealthy(t sweet.T) {
+	containerRuntime := runtime.NewFakeRuntime()
+	containerRuntime.Containers["c1"] = &runtime.ContainerState{
+		Status: "running",
+		Health: "healthy",
+	}
+
+	runner := testMonitorRunner(containerRuntime)
+	Expect(runner.monitor("c1")).To(BeTrue())
+}
+
+func (s *RunTaskSuite) TestMonitorInspectError(t sweet.T) {
+	containerRuntime := runtime.NewFakeRuntime()
+	containerRuntime.Errors["Inspect"] = fmt.Errorf("utoh")
+
+	runner := testMonitorRunner(containerRuntime)
+	Expect(runner.monitor("c1")).To(BeFalse())
+}
+
+func (s *RunTaskSuite) TestDetachedContainerNotRemoved(t sweet.T) {
+	options, err := testRunOptions(
+		&config.Config{},
+		&containerOptions{},
+		&config.RunTask{Image: "redis", Detach: true, Healthcheck: &config.Healthcheck{}},
+		environment.New(nil),
+	)
+
+	Expect(err).To(BeNil())
+	Expect(options.Detach).To(BeTrue())
+	Expect(options.Remove).To(BeFalse())
+}
+
+func testMonitorRunner(containerRuntime runtime.ContainerRuntime) *runTaskRunner {
+	return &runTaskRunner{
+		ctx:     context.Background(),
+		config:  &config.Config{Options: &config.Options{}},
+		runtime: containerRuntime,
+		logger:  logging.NilLogger,
+		task:    &config.RunTask{Healthcheck: &config.Healthcheck{}},
+		prefix:  logging.NewPrefix("db"),
+	}
+}
+
+func (s *RunTaskSuite) TestPodmanOptions(t sweet.T) {
+	options, err := testRunOptions(
+		&config.Config{},
+		&containerOptions{},
+		&config.RunTask{Image: "redis", Hostname: "cache", Healthcheck: &config.Healthcheck{}},
+		environment.New(nil),
+	)
+
+	Expect(err).To(BeNil())
+
+	args := runtime.NewCLIRuntime(runtime.PodmanExecutable, logging.NilLogger).RunArgs(options)
+	Expect(args[:2]).To(Equal([]string{"podman", "run"}))
+	Expect(args).To(ContainElement("--userns=keep-id"))
+	Expect(hasFlagValue(args, "--network", "run:alias=cache")).To(BeTrue())
+	Expect(args).NotTo(ContainElement("--network-alias"))
+}
+
+func (s *RunTaskSuite) TestLimitOptions(t sweet.T) {
+	build := func(task *config.RunTask) *runtime.RunOptions {
+		task.Image = "postgres"
+		task.Healthcheck = &config.Healthcheck{}
+
+		options, err := testRunOptions(
+			&config.Config{Options: &config.Options{AllowPrivileged: true}},
+			&containerOptions{CPUShares: "256", Memory: "512m"},
+			task,
+			environment.New([]string{"SHM=1g"}),
+		)
+
+		Expect(err).To(BeNil())
+		return options
+	}
+
+	options := build(&config.RunTask{})
+	Expect(options.CPUShares).To(Equal("256"))
+	Expect(options.Memory).To(Equal("512m"))
+	Expect(options.Privileged).To(BeFalse())
+
+	options = build(&config.RunTask{
+		Memory:     "4g",
+		ShmSize:    "${SHM}",
+		Ulimits:    []string{"nofile=1024:2048"},
+		Privileged: true,
+		CapAdd:     []string{"SYS_PTRACE", "NET_ADMIN"},
+		Tmpfs:      []string{"/run"},
+		Devices:    []string{"/dev/fuse"},
+		Platform:   "linux/amd64",
+	})
+
+	Expect(options.CPUShares).To(Equal("256"))
+	Expect(options.Memory).To(Equal("4g"))
+	Expect(options.ShmSize).To(Equal("1g"))
+	Expect(options.Ulimits).To(Equal([]string{"nofile=1024:2048"}))
+	Expect(options.CapAdd).To(Equal([]string{"SYS_PTRACE", "NET_ADMIN"}))
+	Expect(options.Tmpfs).To(Equal([]string{"/run"}))
+	Expect(options.Devices).To(Equal([]string{"/dev/fuse"}))
+	Expect(options.Platform).To(Equal("linux/amd64"))
+	Expect(options.Privileged).To(BeTrue())
+}
+
+func (s *RunTaskSuite) TestLimitOptionsPrivilegedNotAllowed(t sweet.T) {
+	build := func(task *config.RunTask) error {
+		task.Image = "postgres"
+		task.Healthcheck = &config.Healthcheck{}
+
+		_, err := testRunOptions(
+			&config.Config{Options: &config.Options{}},
+			&containerOptions{},
+			task,
+			environment.New([]string{"DEVICE=/dev/sda"}),
+		)
+
+		return err
+	}
+
+	Expect(build(&config.RunTask{Privileged: true})).To(MatchError("privileged containers are not allowed (see the allow-privileged option)"))
+	Expect(build(&config.RunTask{Devices: []string{"${DEVICE}"}})).To(MatchError("device /dev/sda is not allowed (see the allow-privileged option)"))
+	Expect(build(&config.RunTask{CapAdd: []string{"SYS_ADMIN"}})).To(MatchError("capability SYS_ADMIN is not allowed (see the allow-privileged option)"))
+	Expect(build(&config.RunTask{CapAdd: []string{"ALL"}})).To(MatchError("capability ALL is not allowed (see the allow-privileged option)"))
+}
+
+func (s *RunTaskSuite) TestMountOptions(t sweet.T) {
+	build := func(task *config.RunTask) (*runtime.RunOptions, error) {
+		task.Image = "maven"
+		task.Healthcheck = &config.Healthcheck{}
+
+		cfg := &config.Config{
+			Options: &config.Options{AllowedMounts: []string{"/etc/ssl/certs", "cache"}},
+		}
+
+		return testRunOptions(
+			cfg,
+			&containerOptions{},
+			task,
+			environment.New([]string{
+				"CERTS=/etc/ssl/certs",
+				"PORT=8080",
+				"SUFFIX=x,source=/etc",
+				"OPTS=,volume-opt=o=bind,volume-opt=device=/etc",
+			}),
+		)
+	}
+
+	options, err := build(&config.RunTask{
+		Mounts: []*config.Mount{
+			&config.Mount{Type: "bind", Source: "${CERTS}", Target: "/certs", ReadOnly: true},
+			&config.Mount{Type: "bind", Source: "cache/m2", Target: "/root/.m2"},
+			&config.Mount{Type: "volume", Source: "gradle", Target: "/cache"},
+			&config.Mount{Type: "tmpfs", Target: "/tmp"},
+		},
+		Ports: []string{"${PORT}:80", "127.0.0.1:5005:5005"},
+	})
+
+	Expect(err).To(BeNil())
+	Expect(options.Mounts).To(Equal([]*runtime.Mount{
+		&runtime.Mount{Type: "bind", Source: "/etc/ssl/certs", Target: "/certs", ReadOnly: true},
+		&runtime.Mount{Type: "bind", Source: "/project/cache/m2", Target: "/root/.m2"},
+		&runtime.Mount{Type: "volume", Source: "gradle", Target: "/cache"},
+		&runtime.Mount{Type: "tmpfs", Target: "/tmp"},
+	}))
+
+	Expect(options.Ports).To(Equal([]string{"8080:80", "127.0.0.1:5005:5005"}))
+
+	_, err = build(&config.RunTask{
+		Mounts: []*config.Mount{
+			&config.Mount{Type: "bind", Source: "/etc", Target: "/host-etc"},
+		},
+	})
+
+	Expect(err).To(MatchError("bind mount source /etc is not allowed (see the allowed-mounts option)"))
+
+	_, err = build(&config.RunTask{
+		Mounts: []*config.Mount{
+			&config.Mount{Type: "bind", Source: "cache/${SUFFIX}", Target: "/host-etc"},
+		},
+	})
+
+	Expect(err).To(MatchError("mount path /project/cache/x,source=/etc may not contain a comma, quote, or equals sign"))
+
+	_, err = build(&config.RunTask{
+		Mounts: []*config.Mount{
+			&config.Mount{Type: "volume", Source: "data${OPTS}", Target: "/host-etc"},
+		},
+	})
+
+	Expect(err).To(MatchError("volume mount source data,volume-opt=o=bind,volume-opt=device=/etc is not a valid volume name"))
+
+	_, err = build(&config.RunTask{
+		Mounts: []*config.Mount{
+			&config.Mount{Type: "tmpfs", Target: "/tmp${OPTS}"},
+		},
+	})
+
+	Expect(err).To(HaveOccurred())
+}
+
+func (s *RunTaskSuite) TestCacheOptions(t sweet.T) {
+	cfg := &config.Config{
+		Options: &config.Options{},
+		Caches: map[string]*config.DependencyCache{
+			"gomod": &config.DependencyCache{Name: "gomod", Path: "/go/pkg/mod", Key: "go-${GIT_BRANCH}"},
+			"npm":   &config.DependencyCache{Name: "npm", Path: "${HOME}/.npm"},
+		},
+	}
+
+	options, err := testRunOptions(
+		cfg,
+		&containerOptions{},
+		&config.RunTask{
+			Image:       "golang",
+			Healthcheck: &config.Healthcheck{},
+			Caches:      []string{"gomod", "npm"},
+		},
+		environment.New([]string{"GIT_BRANCH=feature/x", "HOME=/root"}),
+	)
+
+	Expect(err).To(BeNil())
+	Expect(options.Mounts).To(Equal([]*runtime.Mount{
+		&runtime.Mount{Type: "volume", Source: volume.CacheVolumeName("/project", "gomod", "go-feature/x"), Target: "/go/pkg/mod"},
+		&runtime.Mount{Type: "volume", Source: volume.CacheVolumeName("/project", "npm", ""), Target: "/root/.npm"},
+	}))
+}
+
+func (s *RunTaskSuite) TestRunOptions(t sweet.T) {
+	options, err := testRunOptions(
+		&config.Config{},
+		&containerOptions{},
+		&config.RunTask{
+			Image:       "golang:${GO}",
+			Command:     "go test './...'",
+			Entrypoint:  "/usr/bin/env",
+			User:        "build",
+			Healthcheck: &config.Healthcheck{Command: "true", Retries: 3},
+		},
+		environment.New([]string{"GO=1.21"}),
+	)
+
+	Expect(err).To(BeNil())
+	Expect(options.Name).To(Equal("c1"))
+	Expect(options.Image).To(Equal("golang:1.21"))
+	Expect(options.Entrypoint).To(Equal("/usr/bin/env"))
+	Expect(options.Args).To(Equal([]string{"go", "test", "./..."}))
+	Expect(options.User).To(Equal("build"))
+	Expect(options.MapUser).To(BeTrue())
+	Expect(options.Remove).To(BeTrue())
+	Expect(options.Env).To(ContainElement("GO=1.21"))
+	Expect(options.Network).To(Equal("run"))
+	Expect(options.Binds).To(Equal([]string{"/project/.ij/run/workspace:" + DefaultWorkspacePath}))
+	Expect(options.Workdir).To(Equal(DefaultWorkspacePath))
+	Expect(options.Healthcheck).To(Equal(&runtime.Healthcheck{Command: "true", Retries: 3}))
+}
+
+func testRunOptions(
+	cfg *config.Config,
+	containerOptions *containerOptions,
+	task *config.RunTask,
+	env environment.Environment,
+) (*runtime.RunOptions, error) {
+	return runTaskRunOptionsFactory(
+		"run",
+		cfg,
+		containerOptions,
+		scratch.NewScratchSpace("run", "/project", "/project", false),
+		nil,
+		task,
+		"c1",
+		env,
+	)
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/logging"
 	"github.com/ij-build/ij/paths"
+	"github.com/ij-build/ij/runtime"
 	"github.com/ij-build/ij/scratch"
+	"github.com/ij-build/ij/volume"
 )
 
 type Runner struct {
@@ -20,8 +23,13 @@ type Runner struct {
 	scratch           *scratch.ScratchSpace
 	cleanup           *Cleanup
 	runID             string
+	state             *RunState
 	cancel            func()
 	env               []string
+	filter            *TaskFilter
+	serviceLogs       *ServiceLogs
+	volume            *volume.Volume
+	runtime           runtime.ContainerRuntime
 }
 
 var shutdownSignals = []syscall.Signal{
@@ -37,8 +45,13 @@ func NewRunner(
 	scratch *scratch.ScratchSpace,
 	cleanup *Cleanup,
 	runID string,
+	state *RunState,
 	cancel func(),
 	env []string,
+	filter *TaskFilter,
+	serviceLogs *ServiceLogs,
+	volume *volume.Volume,
+	runtime runtime.ContainerRuntime,
 ) *Runner {
 	return &Runner{
 		ctx:               ctx,
@@ -48,15 +61,26 @@ func NewRunner(
 		scratch:           scratch,
 		cleanup:           cleanup,
 		runID:             runID,
+		state:             state,
 		cancel:            cancel,
 		env:               env,
+		filter:            filter,
+		serviceLogs:       serviceLogs,
+		volume:            volume,
+		runtime:           runtime,
 	}
 }
 
 func (r *Runner) Run(plans []string) bool {
+	verb := "Beginning"
+	if r.state != nil {
+		verb = "Resuming"
+	}
+
 	r.logger.Info(
 		nil,
-		"Beginning run %s",
+		"%s run %s",
+		verb,
 		r.runID,
 	)
 
@@ -78,30 +102,28 @@ func (r *Runner) Run(plans []string) bool {
 		r.logger,
 	)
 
-	r.logger.Info(
-		nil,
-		"Importing files to workspace",
-	)
-
-	importErr := transferer.Import(
-		r.config.Import.Files,
-		r.config.Import.Excludes,
+	var (
+		failure     = false
+		rootContext = NewRunContext(nil)
 	)
 
-	if importErr != nil {
-		r.logger.Error(
+	if r.state != nil {
+		// The workspace already holds the imported files along with
+		// anything written by the tasks that completed in the run
+		r.logger.Info(
 			nil,
-			"Failed to import files to workspace: %s",
-			importErr.Error(),
+			"Reusing workspace of run %s",
+			r.runID,
 		)
 
+		rootContext.Restore(r.state)
+	} else if !r.importFiles(transferer) {
 		return false
 	}
 
-	var (
-		failure     = false
-		rootContext = NewRunContext(nil)
-	)
+	if !pushWorkspace(r.ctx, r.volume, r.scratch, r.logger, nil) {
+		return false
+	}
 
 	for _, name := range plans {
 		runner := NewPlanRunner(
@@ -110,6 +132,7 @@ func (r *Runner) Run(plans []string) bool {
 			r.taskRunnerFactory,
 			r.logger,
 			r.env,
+			r.filter,
 		)
 
 		newContext := NewRunContext(rootContext)
@@ -117,10 +140,37 @@ func (r *Runner) Run(plans []string) bool {
 
 		if !runner.Run(newContext, name, logging.NewPrefix()) {
 			failure = true
+
+			// Only holds output when echoing on failure
+			r.serviceLogs.Flush()
 		}
 	}
 
-	r.tryFlashPermissions()
+	if failedAllowed := rootContext.GetFailedAllowed(); len(failedAllowed) > 0 {
+		r.logger.Warn(
+			nil,
+			"Tasks failed (allowed): %s",
+			strings.Join(failedAllowed, ", "),
+		)
+	}
+
+	if err := rootContext.State().Write(r.scratch); err != nil {
+		r.logger.Warn(
+			nil,
+			"Failed to write run state: %s",
+			err.Error(),
+		)
+	}
+
+	if r.volume != nil {
+		// Files copied out of the volume are already owned by the
+		// current user, and the host workspace can't be mounted
+		if !pullWorkspace(r.ctx, r.volume, r.scratch, r.logger, nil) {
+			return false
+		}
+	} else {
+		r.tryFlashPermissions()
+	}
 
 	if failure {
 		return false
@@ -149,6 +199,30 @@ func (r *Runner) Run(plans []string) bool {
 	return true
 }
 
+func (r *Runner) importFiles(transferer *paths.Transferer) bool {
+	r.logger.Info(
+		nil,
+		"Importing files to workspace",
+	)
+
+	importErr := transferer.Import(
+		r.config.Import.Files,
+		r.config.Import.Excludes,
+	)
+
+	if importErr != nil {
+		r.logger.Error(
+			nil,
+			"Failed to import files to workspace: %s",
+			importErr.Error(),
+		)
+
+		return false
+	}
+
+	return true
+}
+
 func (r *Runner) watchSignals() {
 	signals := make(chan os.Signal, 1)
 
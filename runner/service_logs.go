@@ -0,0 +1,169 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
+)
+
+type (
+	// ServiceLogs follows the output of detached containers into the log
+	// files of the task that started them. Depending on the mode, the
+	// output is also echoed to the console as it arrives, or held until
+	// a plan fails.
+	ServiceLogs struct {
+		mode          string
+		runtime       runtime.ContainerRuntime
+		logger        logging.Logger
+		loggerFactory *logging.LoggerFactory
+		buffer        []*serviceLogLine
+		mutex         sync.Mutex
+		wg            sync.WaitGroup
+	}
+
+	serviceLogLine struct {
+		prefix  *logging.Prefix
+		text    string
+		isError bool
+	}
+
+	// bufferedLogger writes to the log files of a detached container and
+	// retains each line so that it can be echoed later.
+	bufferedLogger struct {
+		logging.Logger
+		logs *ServiceLogs
+	}
+)
+
+const (
+	ServiceLogsNever     = "never"
+	ServiceLogsAlways    = "always"
+	ServiceLogsOnFailure = "on-failure"
+
+	// MaxBufferedServiceLogLines bounds the output held for echoing on
+	// failure. The oldest lines are discarded first.
+	MaxBufferedServiceLogLines = 10000
+)
+
+func NewServiceLogs(
+	mode string,
+	runtime runtime.ContainerRuntime,
+	logger logging.Logger,
+	loggerFactory *logging.LoggerFactory,
+) *ServiceLogs {
+	return &ServiceLogs{
+		mode:          mode,
+		runtime:       runtime,
+		logger:        logger,
+		loggerFactory: loggerFactory,
+	}
+}
+
+// Follow streams the output of the given container until it stops.
+func (l *ServiceLogs) Follow(containerName string, prefix *logging.Prefix) error {
+	logger, err := l.makeLogger(prefix)
+	if err != nil {
+		return err
+	}
+
+	options := &runtime.LogsOptions{
+		Follow: true,
+	}
+
+	l.wg.Add(1)
+
+	go func() {
+		defer l.wg.Done()
+
+		// The logs end once the container stops, which occurs at the
+		// end of the run if not sooner
+		_ = l.runtime.Logs(
+			context.Background(),
+			containerName,
+			options,
+			logger,
+			prefix,
+		)
+	}()
+
+	return nil
+}
+
+// Flush echoes the output held since the last flush to the console.
+func (l *ServiceLogs) Flush() {
+	l.mutex.Lock()
+	buffer := l.buffer
+	l.buffer = nil
+	l.mutex.Unlock()
+
+	if len(buffer) == 0 {
+		return
+	}
+
+	l.logger.Info(
+		nil,
+		"Output of detached containers follows",
+	)
+
+	for _, line := range buffer {
+		if line.isError {
+			l.logger.Error(line.prefix, "%s", line.text)
+		} else {
+			l.logger.Info(line.prefix, "%s", line.text)
+		}
+	}
+}
+
+// Wait blocks until the output of every followed container has been
+// written. This must be called after all detached containers have been
+// stopped and before the log processor is shut down.
+func (l *ServiceLogs) Wait() {
+	l.wg.Wait()
+}
+
+func (l *ServiceLogs) makeLogger(prefix *logging.Prefix) (logging.Logger, error) {
+	name := prefix.Serialize(logging.NilColorPicker)
+
+	if l.mode == ServiceLogsAlways {
+		return l.loggerFactory.Logger(name, false)
+	}
+
+	logger, err := l.loggerFactory.FileLogger(name, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.mode == ServiceLogsOnFailure {
+		return &bufferedLogger{Logger: logger, logs: l}, nil
+	}
+
+	return logger, nil
+}
+
+func (l *ServiceLogs) add(prefix *logging.Prefix, text string, isError bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.buffer = append(l.buffer, &serviceLogLine{
+		prefix:  prefix,
+		text:    text,
+		isError: isError,
+	})
+
+	if len(l.buffer) > MaxBufferedServiceLogLines {
+		l.buffer = l.buffer[len(l.buffer)-MaxBufferedServiceLogLines:]
+	}
+}
+
+func (l *bufferedLogger) Info(prefix *logging.Prefix, format string, args ...interface{}) {
+	l.Logger.Info(prefix, format, args...)
+	l.logs.add(prefix, fmt.Sprintf(format, args...), false)
+}
+
+func (l *bufferedLogger) Error(prefix *logging.Prefix, format string, args ...interface{}) {
+	l.Logger.Error(prefix, format, args...)
+	l.logs.add(prefix, fmt.Sprintf(format, args...), true)
+}
@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
+	. "github.com/onsi/gomega"
+)
+
+type ServiceLogsSuite struct{}
+
+type recordingLogger struct {
+	logging.Logger
+	lines []string
+}
+
+func (s *ServiceLogsSuite) TestFlush(t sweet.T) {
+	var (
+		console = &recordingLogger{Logger: logging.NilLogger}
+		logs    = NewServiceLogs(ServiceLogsOnFailure, runtime.NewFakeRuntime(), console, nil)
+		logger  = &bufferedLogger{Logger: logging.NilLogger, logs: logs}
+		prefix  = logging.NewPrefix("db")
+	)
+
+	logger.Info(prefix, "ready on port %d", 5432)
+	logger.Error(prefix, "%s", "slow query")
+	logger.Debug(prefix, "ignored")
+	Expect(console.lines).To(BeEmpty())
+
+	logs.Flush()
+	Expect(console.lines).To(Equal([]string{
+		"info: Output of detached containers follows",
+		"info: ready on port 5432",
+		"error: slow query",
+	}))
+
+	console.lines = nil
+	logs.Flush()
+	Expect(console.lines).To(BeEmpty())
+}
+
+func (s *ServiceLogsSuite) TestFlushDiscardsOldestLines(t sweet.T) {
+	var (
+		console = &recordingLogger{Logger: logging.NilLogger}
+		logs    = NewServiceLogs(ServiceLogsOnFailure, runtime.NewFakeRuntime(), console, nil)
+		logger  = &bufferedLogger{Logger: logging.NilLogger, logs: logs}
+	)
+
+	for i := 0; i < MaxBufferedServiceLogLines+5; i++ {
+		logger.Info(nil, "line %d", i)
+	}
+
+	logs.Flush()
+	Expect(console.lines).To(HaveLen(MaxBufferedServiceLogLines + 1))
+	Expect(console.lines[1]).To(Equal("info: line 5"))
+}
+
+func (s *ServiceLogsSuite) TestFlushEmpty(t sweet.T) {
+	console := &recordingLogger{Logger: logging.NilLogger}
+	NewServiceLogs(ServiceLogsAlways, runtime.NewFakeRuntime(), console, nil).Flush()
+	Expect(console.lines).To(BeEmpty())
+}
+
+func (l *recordingLogger) Info(prefix *logging.Prefix, format string, args ...interface{}) {
+	l.lines = append(l.lines, "info: "+fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Error(prefix *logging.Prefix, format string, args ...interface{}) {
+	l.lines = append(l.lines, "error: "+fmt.Sprintf(format, args...))
+}
@@ -4,17 +4,20 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"time"
 
+	"github.com/ij-build/ij/cache"
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/environment"
 	"github.com/ij-build/ij/logging"
 	"github.com/ij-build/ij/network"
 	"github.com/ij-build/ij/options"
+	"github.com/ij-build/ij/paths"
 	"github.com/ij-build/ij/registry"
+	"github.com/ij-build/ij/runtime"
 	"github.com/ij-build/ij/scratch"
 	"github.com/ij-build/ij/ssh"
 	"github.com/ij-build/ij/util"
+	"github.com/ij-build/ij/volume"
 )
 
 func SetupRunner(
@@ -23,16 +26,35 @@ func SetupRunner(
 	runOptions *options.RunOptions,
 ) (runner *Runner, err error) {
 	var (
-		cleanup           = NewCleanup()
-		ctx, cancel       = setupContext(runOptions.Context, runOptions.PlanTimeout)
-		logger            logging.Logger
-		loggerFactory     *logging.LoggerFactory
-		runID             string
-		scratch           *scratch.ScratchSpace
-		taskRunnerFactory TaskRunnerFactory
+		cleanup                   = NewCleanup()
+		ctx, cancel, pauseTimeout = withPausableTimeout(runOptions.Context, runOptions.PlanTimeout)
+		logger                    logging.Logger
+		loggerFactory             *logging.LoggerFactory
+		runID                     string
+		scratch                   *scratch.ScratchSpace
+		state                     *RunState
+		taskRunnerFactory         TaskRunnerFactory
+		resume                    = runOptions.Resume != ""
 	)
 
-	if runID, err = setupRunID(); err != nil {
+	filter, err := setupFilter(cfg, runOptions)
+	if err != nil {
+		return
+	}
+
+	if resume {
+		runID = runOptions.Resume
+
+		state, err = setupResume(
+			runID,
+			appOptions.ProjectDir,
+			appOptions.ScratchRoot,
+		)
+
+		if err != nil {
+			return
+		}
+	} else if runID, err = setupRunID(); err != nil {
 		return
 	}
 
@@ -50,7 +72,7 @@ func SetupRunner(
 		appOptions.ProjectDir,
 		appOptions.ScratchRoot,
 		cleanup,
-		runOptions.KeepWorkspace,
+		runOptions.KeepWorkspace || resume,
 	)
 
 	if err != nil {
@@ -58,7 +80,8 @@ func SetupRunner(
 	}
 
 	defer func() {
-		if err == nil {
+		// Never discard the workspace of the run being resumed
+		if err == nil || resume {
 			return
 		}
 
@@ -87,9 +110,23 @@ func SetupRunner(
 		scratch.Prune(logger)
 	})
 
+	containerRuntime := runtime.NewRuntime(ctx, cfg.Options.Runtime, logger)
+
+	// Registered before the container lists so that the output of
+	// detached containers is drained after they are stopped
+	serviceLogs := NewServiceLogs(
+		runOptions.ServiceLogs,
+		containerRuntime,
+		logger,
+		loggerFactory,
+	)
+
+	cleanup.Register(serviceLogs.Wait)
+
 	_, err = setupNetwork(
 		ctx,
 		runID,
+		containerRuntime,
 		cleanup,
 		logger,
 	)
@@ -98,8 +135,30 @@ func SetupRunner(
 		return
 	}
 
+	// Registered before the container lists so that the volume is
+	// removed after the containers using it are stopped
+	workspaceVolume, err := setupVolume(
+		ctx,
+		runID,
+		runOptions.WorkspaceMode,
+		containerRuntime,
+		cleanup,
+		logger,
+	)
+
+	if err != nil {
+		return
+	}
+
+	caches := volume.NewCaches(
+		appOptions.ProjectDir,
+		containerRuntime,
+		logger,
+	)
+
 	containerLists := setupContainerLists(
 		runID,
+		containerRuntime,
 		cleanup,
 		logger,
 	)
@@ -113,6 +172,7 @@ func SetupRunner(
 		err = startSSHAgent(
 			runID,
 			cfg.Options.SSHIdentities,
+			containerRuntime,
 			scratch,
 			containerLists,
 			logger,
@@ -131,9 +191,20 @@ func SetupRunner(
 		}
 	}
 
+	taskCache, err := setupCache(runOptions.NoCache)
+	if err != nil {
+		return
+	}
+
+	var debugger *Debugger
+	if runOptions.DebugOnFailure {
+		debugger = NewDebugger(containerRuntime, logger, loggerFactory.Processor(), cancel, pauseTimeout)
+	}
+
 	err = setupRegistries(
 		ctx,
 		cfg,
+		containerRuntime,
 		cleanup,
 		logger,
 		appOptions.Env,
@@ -145,8 +216,10 @@ func SetupRunner(
 	}
 
 	taskRunnerFactory = func(
+		ctx context.Context,
 		context *RunContext,
 		task config.Task,
+		retryPolicy *config.RetryPolicy,
 		prefix *logging.Prefix,
 		env environment.Environment,
 	) TaskRunner {
@@ -154,10 +227,25 @@ func SetupRunner(
 		case *config.BuildTask:
 			return NewBuildTaskRunnerFactory(
 				ctx,
+				containerRuntime,
 				scratch.Workspace(),
 				logger,
 			)(
 				t,
+				retryPolicy,
+				env,
+				prefix,
+			)
+
+		case *config.HostTask:
+			return NewHostTaskRunnerFactory(
+				ctx,
+				scratch,
+				workspaceVolume,
+				logger,
+			)(
+				t,
+				retryPolicy,
 				env,
 				prefix,
 			)
@@ -165,10 +253,12 @@ func SetupRunner(
 		case *config.PushTask:
 			return NewPushTaskRunnerFactory(
 				ctx,
+				containerRuntime,
 				logger,
 			)(
 				context,
 				t,
+				retryPolicy,
 				env,
 				prefix,
 			)
@@ -176,10 +266,12 @@ func SetupRunner(
 		case *config.RemoveTask:
 			return NewRemoveTaskRunnerFactory(
 				ctx,
+				containerRuntime,
 				logger,
 			)(
 				context,
 				t,
+				retryPolicy,
 				env,
 				prefix,
 			)
@@ -190,19 +282,27 @@ func SetupRunner(
 				EnableContainerSSHAgent: runOptions.EnableContainerSSHAgent,
 				CPUShares:               runOptions.CPUShares,
 				Memory:                  runOptions.Memory,
+				KeepContainer:           runOptions.DebugOnFailure,
+				Volume:                  workspaceVolume,
+				Caches:                  caches,
 			}
 
 			return NewRunTaskRunnerFactory(
 				ctx,
 				cfg,
 				runID,
+				containerRuntime,
 				scratch,
 				containerLists,
 				containerOptions,
 				logger,
 				loggerFactory,
+				taskCache,
+				debugger,
+				serviceLogs,
 			)(
 				t,
+				retryPolicy,
 				env,
 				prefix,
 			)
@@ -214,6 +314,7 @@ func SetupRunner(
 				taskRunnerFactory,
 				logger,
 				appOptions.Env,
+				filter,
 			)
 
 			return NewPlanTaskRunnerFactory(
@@ -229,6 +330,24 @@ func SetupRunner(
 		panic("unexpected task type")
 	}
 
+	taskRunnerFactory = artifactTaskRunnerFactory(
+		taskRunnerFactory,
+		scratch,
+		workspaceVolume,
+		logger,
+	)
+
+	taskRunnerFactory = limitTaskRunnerFactory(
+		taskRunnerFactory,
+		util.NewSemaphore(cfg.Options.Jobs),
+	)
+
+	// Applied last so that a task waiting on a debug shell holds no slot
+	taskRunnerFactory = debugGateTaskRunnerFactory(
+		taskRunnerFactory,
+		debugger,
+	)
+
 	runner = NewRunner(
 		ctx,
 		logger,
@@ -237,8 +356,13 @@ func SetupRunner(
 		scratch,
 		cleanup,
 		runID,
+		state,
 		cancel,
 		appOptions.Env,
+		filter,
+		serviceLogs,
+		workspaceVolume,
+		containerRuntime,
 	)
 
 	return
@@ -247,14 +371,6 @@ func SetupRunner(
 //
 // Setup Functions
 
-func setupContext(ctx context.Context, timeout time.Duration) (context.Context, func()) {
-	if timeout == 0 {
-		return context.WithCancel(ctx)
-	}
-
-	return context.WithTimeout(ctx, timeout)
-}
-
 func setupRunID() (string, error) {
 	id, err := util.MakeID()
 	if err != nil {
@@ -269,6 +385,24 @@ func setupRunID() (string, error) {
 	return id, nil
 }
 
+func setupFilter(cfg *config.Config, runOptions *options.RunOptions) (*TaskFilter, error) {
+	filter, err := NewTaskFilter(
+		runOptions.Only,
+		runOptions.Skip,
+		runOptions.FromStage,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := filter.Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
 func setupScratch(
 	runID string,
 	projectDir string,
@@ -295,6 +429,64 @@ func setupScratch(
 	return scratch, nil
 }
 
+func setupResume(
+	runID string,
+	projectDir string,
+	scratchRoot string,
+) (*RunState, error) {
+	scratch := scratch.NewScratchSpace(
+		runID,
+		projectDir,
+		scratchRoot,
+		true,
+	)
+
+	exists, err := paths.DirExists(scratch.Workspace())
+	if err == nil && !exists {
+		err = fmt.Errorf("run %s has no workspace (was it run with --keep-workspace?)", runID)
+	}
+
+	if err != nil {
+		logging.EmergencyLog(
+			"error: failed to resume run: %s",
+			err.Error(),
+		)
+
+		return nil, err
+	}
+
+	state, err := LoadRunState(scratch)
+	if err != nil {
+		logging.EmergencyLog(
+			"error: failed to load state of run %s: %s",
+			runID,
+			err.Error(),
+		)
+
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func setupCache(noCache bool) (*cache.Cache, error) {
+	if noCache {
+		return nil, nil
+	}
+
+	root, err := cache.DefaultRoot()
+	if err != nil {
+		logging.EmergencyLog(
+			"error: failed to locate cache directory: %s",
+			err.Error(),
+		)
+
+		return nil, err
+	}
+
+	return cache.NewCache(root), nil
+}
+
 func setupLogger(
 	cleanup *Cleanup,
 	scratch *scratch.ScratchSpace,
@@ -336,11 +528,12 @@ func setupLogger(
 
 func setupContainerLists(
 	runID string,
+	runtime runtime.ContainerRuntime,
 	cleanup *Cleanup,
 	logger logging.Logger,
 ) *ContainerLists {
-	containerStopper := NewContainerStopper(logger)
-	networkDisconnector := NewNetworkDisconnector(runID, logger)
+	containerStopper := NewContainerStopper(runtime, logger)
+	networkDisconnector := NewNetworkDisconnector(runID, runtime, logger)
 
 	cleanup.Register(containerStopper.Execute)
 	cleanup.Register(networkDisconnector.Execute)
@@ -354,6 +547,7 @@ func setupContainerLists(
 func setupRegistries(
 	ctx context.Context,
 	cfg *config.Config,
+	runtime runtime.ContainerRuntime,
 	cleanup *Cleanup,
 	logger logging.Logger,
 	env []string,
@@ -373,6 +567,7 @@ func setupRegistries(
 		logger,
 		registryEnv,
 		cfg.Registries,
+		runtime,
 	)
 
 	if err != nil {
@@ -406,10 +601,11 @@ func setupRegistries(
 func setupNetwork(
 	ctx context.Context,
 	runID string,
+	runtime runtime.ContainerRuntime,
 	cleanup *Cleanup,
 	logger logging.Logger,
 ) (*network.Network, error) {
-	network, err := network.NewNetwork(ctx, runID, logger)
+	network, err := network.NewNetwork(ctx, runID, runtime, logger)
 	if err != nil {
 		reportError(
 			ctx,
@@ -426,6 +622,35 @@ func setupNetwork(
 	return network, nil
 }
 
+func setupVolume(
+	ctx context.Context,
+	runID string,
+	workspaceMode string,
+	runtime runtime.ContainerRuntime,
+	cleanup *Cleanup,
+	logger logging.Logger,
+) (*volume.Volume, error) {
+	if workspaceMode != WorkspaceModeVolume {
+		return nil, nil
+	}
+
+	volume, err := volume.NewVolume(ctx, runID, runtime, logger)
+	if err != nil {
+		reportError(
+			ctx,
+			logger,
+			nil,
+			"error: failed to create workspace volume: %s",
+			err.Error(),
+		)
+
+		return nil, err
+	}
+
+	cleanup.Register(volume.Teardown)
+	return volume, nil
+}
+
 //
 // Helpers
 
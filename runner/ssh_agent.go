@@ -6,8 +6,8 @@ import (
 	"os/user"
 	"path/filepath"
 
-	"github.com/ij-build/ij/command"
 	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
 	"github.com/ij-build/ij/scratch"
 )
 
@@ -20,33 +20,24 @@ const (
 func startSSHAgent(
 	runID string,
 	identities []string,
+	runtime runtime.ContainerRuntime,
 	scratch *scratch.ScratchSpace,
 	containerLists *ContainerLists,
 	logger logging.Logger,
 ) error {
 	containerName := fmt.Sprintf("%s-ssh-agent", runID)
 
-	if err := startContainer(runID, containerName, scratch, containerLists, logger); err != nil {
+	if err := startContainer(runID, runtime, containerName, scratch, containerLists, logger); err != nil {
 		return err
 	}
 
-	if err := addKeys(containerName, logger); err != nil {
-		command.NewRunner(logger).RunForOutput(
-			context.Background(),
-			[]string{"docker", "kill", containerName},
-			nil,
-		)
-
+	if err := addKeys(runtime, containerName, logger); err != nil {
+		runtime.Kill(context.Background(), containerName)
 		return err
 	}
 
-	if err := ensureKeys(containerName, identities, logger); err != nil {
-		command.NewRunner(logger).RunForOutput(
-			context.Background(),
-			[]string{"docker", "kill", containerName},
-			nil,
-		)
-
+	if err := ensureKeys(runtime, containerName, identities, logger); err != nil {
+		runtime.Kill(context.Background(), containerName)
 		return err
 	}
 
@@ -55,51 +46,36 @@ func startSSHAgent(
 
 func startContainer(
 	runID string,
+	runtime runtime.ContainerRuntime,
 	containerName string,
 	scratch *scratch.ScratchSpace,
 	containerLists *ContainerLists,
 	logger logging.Logger,
 ) error {
-	builder, err := sshAgentCommandBuilderFactory(
+	options, err := sshAgentRunOptions(
 		runID,
 		scratch,
 		containerName,
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to build command args: %s", err.Error())
-	}
-
-	args, _, err := builder.Build()
-	if err != nil {
-		return fmt.Errorf("failed to build command args: %s", err.Error())
+		return fmt.Errorf("failed to build container options: %s", err.Error())
 	}
 
 	containerLists.ContainerStopper.Add(containerName)
 
-	_, errOutput, err := command.NewRunner(logger).RunForOutput(
-		context.Background(),
-		args,
-		nil,
-	)
-
-	if err != nil {
-		return fmt.Errorf("failed to start ssh-agent container: %s, %s", err.Error(), errOutput)
+	if err := runtime.Run(context.Background(), options, logger, nil); err != nil {
+		return fmt.Errorf("failed to start ssh-agent container: %s", err.Error())
 	}
 
 	return nil
 }
 
-func addKeys(containerName string, logger logging.Logger) error {
-	_, errOutput, err := command.NewRunner(logger).RunForOutput(
+func addKeys(runtime runtime.ContainerRuntime, containerName string, logger logging.Logger) error {
+	_, errOutput, err := runtime.Exec(
 		context.Background(),
-		[]string{
-			"docker",
-			"exec",
-			containerName,
-			"/ij/add-keys.sh",
-		},
-		nil,
+		containerName,
+		[]string{"/ij/add-keys.sh"},
 	)
 
 	if err != nil {
@@ -109,22 +85,22 @@ func addKeys(containerName string, logger logging.Logger) error {
 	return nil
 }
 
-func ensureKeys(containerName string, identities []string, logger logging.Logger) error {
-	identityArgs := []string{}
+func ensureKeys(
+	runtime runtime.ContainerRuntime,
+	containerName string,
+	identities []string,
+	logger logging.Logger,
+) error {
+	args := []string{"/ij/ij-ensure-keys-available"}
 	for _, identity := range identities {
-		identityArgs = append(identityArgs, "--ssh-identity")
-		identityArgs = append(identityArgs, identity)
+		args = append(args, "--ssh-identity")
+		args = append(args, identity)
 	}
 
-	_, errOutput, err := command.NewRunner(logger).RunForOutput(
+	_, errOutput, err := runtime.Exec(
 		context.Background(),
-		append([]string{
-			"docker",
-			"exec",
-			containerName,
-			"/ij/ij-ensure-keys-available",
-		}, identityArgs...),
-		nil,
+		containerName,
+		args,
 	)
 
 	if err != nil {
@@ -134,27 +110,24 @@ func ensureKeys(containerName string, identities []string, logger logging.Logger
 	return nil
 }
 
-func sshAgentCommandBuilderFactory(
+func sshAgentRunOptions(
 	runID string,
 	scratch *scratch.ScratchSpace,
 	containerName string,
-) (*command.Builder, error) {
+) (*runtime.RunOptions, error) {
 	current, err := user.Current()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current user (%s)", err.Error())
 	}
 
-	builder := command.NewBuilder([]string{
-		"docker",
-		"run",
-		"--rm",
-	}, nil)
-
-	builder.AddArgs(SSHAgentImage)
-	builder.AddFlagValue("-v", fmt.Sprintf("%s:/root/.ssh", filepath.Join(current.HomeDir, ".ssh")))
-	builder.AddFlagValue("--name", containerName)
-	builder.AddFlag("-d")
-	builder.AddFlagValue("--network", runID)
+	options := &runtime.RunOptions{
+		Name:    containerName,
+		Image:   SSHAgentImage,
+		Binds:   []string{fmt.Sprintf("%s:/root/.ssh", filepath.Join(current.HomeDir, ".ssh"))},
+		Network: runID,
+		Detach:  true,
+		Remove:  true,
+	}
 
-	return builder, nil
+	return options, nil
 }
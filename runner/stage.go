@@ -21,6 +21,7 @@ type (
 		stage             *config.Stage
 		prefix            *logging.Prefix
 		env               []string
+		filter            *TaskFilter
 	}
 
 	TaskRunnerFunc func() bool
@@ -35,6 +36,7 @@ func NewStageRunner(
 	stage *config.Stage,
 	prefix *logging.Prefix,
 	env []string,
+	filter *TaskFilter,
 ) *StageRunner {
 	return &StageRunner{
 		ctx:               ctx,
@@ -45,6 +47,7 @@ func NewStageRunner(
 		stage:             stage,
 		prefix:            prefix,
 		env:               env,
+		filter:            filter,
 	}
 }
 
@@ -58,12 +61,7 @@ func (r *StageRunner) IsDisabled(context *RunContext) (bool, error) {
 		environment.New(r.env),
 	)
 
-	val, err := env.ExpandString(r.stage.Disabled)
-	if err != nil {
-		return false, err
-	}
-
-	return val != "", nil
+	return isDisabled(r.stage.Disabled, r.stage.If, env, context.Failure)
 }
 
 func (r *StageRunner) Run(context *RunContext) bool {
@@ -71,7 +69,7 @@ func (r *StageRunner) Run(context *RunContext) bool {
 	if err != nil {
 		r.logger.Info(
 			r.prefix,
-			"Failed to expand environment for disabled check: %s",
+			"Failed to evaluate disabled check: %s",
 			err.Error(),
 		)
 
@@ -87,64 +85,131 @@ func (r *StageRunner) Run(context *RunContext) bool {
 		return true
 	}
 
+	if r.filter.SkipsStage(r.plan, r.stage) {
+		r.logger.Info(
+			r.prefix,
+			"Stage skipped by filter",
+		)
+
+		return true
+	}
+
 	r.logger.Info(
 		r.prefix,
 		"Beginning stage",
 	)
 
+	ctx, cancel := withTimeout(r.ctx, r.stage.Timeout)
+	defer cancel()
+
 	var (
 		runners   = []TaskRunnerFunc{}
-		ambiguous = map[string]struct{}{}
-		names     = map[string]struct{}{}
+		ambiguous = r.ambiguousNames()
+		taskCtx   = ctx
+		failFast  func()
 	)
 
-	for _, stageTask := range r.stage.Tasks {
-		if _, ok := names[stageTask.Name]; ok {
-			ambiguous[stageTask.Name] = struct{}{}
-		}
-
-		names[stageTask.Name] = struct{}{}
+	if r.isFailFast() {
+		taskCtx, failFast = withFailFast(ctx)
 	}
 
 	for i, stageTask := range r.stage.Tasks {
 		_, ok := ambiguous[stageTask.Name]
 
-		runners = append(runners, r.buildTaskRunnerFunc(
+		runners = append(runners, r.buildStageTaskRunnerFuncs(
+			taskCtx,
 			stageTask,
 			i,
-			r.config.Tasks[stageTask.Name],
 			context,
 			ok,
-		))
+		)...)
 	}
 
+	var ok bool
 	if !r.stage.Parallel || r.config.Options.ForceSequential {
-		return runSequential(runners)
+		ok = runSequential(runners)
+	} else {
+		ok = runParallel(ctx, runners, r.stage.MaxParallel, failFast)
+	}
+
+	if !ok && timedOut(ctx, r.ctx) {
+		r.logger.Error(
+			r.prefix,
+			"Stage timed out after %s",
+			r.stage.Timeout,
+		)
 	}
 
-	return runParallel(runners)
+	return ok
+}
+
+// buildStageTaskRunnerFuncs creates a runner for each instance of the
+// matrix of the given stage task.
+func (r *StageRunner) buildStageTaskRunnerFuncs(
+	ctx context.Context,
+	stageTask *config.StageTask,
+	index int,
+	context *RunContext,
+	ambiguous bool,
+) []TaskRunnerFunc {
+	runners := []TaskRunnerFunc{}
+	for _, instance := range stageTask.Instances() {
+		runners = append(runners, r.buildTaskRunnerFunc(
+			ctx,
+			stageTask,
+			index,
+			instance,
+			r.config.Tasks[stageTask.Name],
+			context,
+			ambiguous,
+		))
+	}
+
+	return runners
 }
 
 func (r *StageRunner) buildTaskRunnerFunc(
+	ctx context.Context,
 	stageTask *config.StageTask,
 	index int,
+	instance config.MatrixInstance,
 	task config.Task,
 	context *RunContext,
 	ambiguous bool,
 ) TaskRunnerFunc {
-	name := task.GetName()
+	var (
+		taskPrefix = r.taskPrefix(task, index, instance, ambiguous)
+		taskKey    = taskPrefix.Serialize(logging.NilColorPicker)
+	)
 
-	if ambiguous {
-		name = fmt.Sprintf(
-			"%s.%d",
-			name,
-			index,
-		)
-	}
+	return func() bool {
+		if cancelled(ctx) {
+			r.logger.Warn(
+				taskPrefix,
+				"Task cancelled",
+			)
+
+			return false
+		}
+
+		if r.filter.SkipsTask(r.plan, r.stage, task) {
+			r.logger.Info(
+				taskPrefix,
+				"Task skipped by filter",
+			)
 
-	taskPrefix := r.prefix.Append(name)
+			return true
+		}
+
+		if context.CompletedPreviously(taskKey) {
+			r.logger.Info(
+				taskPrefix,
+				"Task already completed successfully in resumed run",
+			)
+
+			return true
+		}
 
-	return func() bool {
 		env := environment.Merge(
 			environment.New(r.config.Environment),
 			environment.New(task.GetEnvironment()),
@@ -152,22 +217,23 @@ func (r *StageRunner) buildTaskRunnerFunc(
 			environment.New(r.plan.Environment),
 			environment.New(r.stage.Environment),
 			environment.New(stageTask.Environment),
+			environment.New(instance.Environment()),
 			environment.New(context.GetExportedEnv()),
 			environment.New(r.env),
 		)
 
-		val, err := env.ExpandString(stageTask.Disabled)
+		disabled, err := isDisabled(stageTask.Disabled, stageTask.If, env, context.Failure)
 		if err != nil {
 			r.logger.Error(
 				taskPrefix,
-				"Failed to expand environment for disabled check: %s",
+				"Failed to evaluate disabled check: %s",
 				err.Error(),
 			)
 
 			return false
 		}
 
-		if val != "" {
+		if disabled {
 			r.logger.Warn(
 				taskPrefix,
 				"Task is disabled",
@@ -191,20 +257,57 @@ func (r *StageRunner) buildTaskRunnerFunc(
 			return false
 		}
 
+		taskCtx, cancel := withTimeout(ctx, task.GetTimeout())
+		defer cancel()
+
 		runner := r.taskRunnerFactory(
+			taskCtx,
 			context,
 			task,
+			stageTask.RetryPolicy(task),
 			taskPrefix,
 			env,
 		)
 
 		if !runner.Run(context) {
-			reportError(
-				r.ctx,
-				r.logger,
-				taskPrefix,
-				"Task has failed",
-			)
+			if cancelled(taskCtx) {
+				r.logger.Warn(
+					taskPrefix,
+					"Task cancelled",
+				)
+
+				return false
+			}
+
+			timeout := timedOut(taskCtx, ctx)
+
+			if timeout {
+				r.logger.Error(
+					taskPrefix,
+					"Task timed out after %s",
+					task.GetTimeout(),
+				)
+			}
+
+			// An interrupted run is never excused by allow-failure
+			if stageTask.AllowsFailure(task) && ctx.Err() == nil {
+				r.logger.Warn(
+					taskPrefix,
+					"Task failed (allowed)",
+				)
+
+				context.MarkFailedAllowed(taskKey)
+				return true
+			}
+
+			if !timeout {
+				reportError(
+					ctx,
+					r.logger,
+					taskPrefix,
+					"Task has failed",
+				)
+			}
 
 			return false
 		}
@@ -214,10 +317,66 @@ func (r *StageRunner) buildTaskRunnerFunc(
 			"Task has completed successfully",
 		)
 
+		context.MarkCompleted(taskKey)
 		return true
 	}
 }
 
+// isFailFast determines if the failure of one task in this stage should
+// cancel the tasks running alongside it.
+func (r *StageRunner) isFailFast() bool {
+	return r.stage.FailFast || r.config.Options.FailFast
+}
+
+// ambiguousNames returns the set of task names that occur more than once
+// in this stage. Such tasks are logged with their index as a suffix.
+func (r *StageRunner) ambiguousNames() map[string]struct{} {
+	var (
+		ambiguous = map[string]struct{}{}
+		names     = map[string]struct{}{}
+	)
+
+	for _, stageTask := range r.stage.Tasks {
+		if _, ok := names[stageTask.Name]; ok {
+			ambiguous[stageTask.Name] = struct{}{}
+		}
+
+		names[stageTask.Name] = struct{}{}
+	}
+
+	return ambiguous
+}
+
+// taskPrefix returns the prefix of a single instance of a stage task.
+// Instances of a matrix are labeled with their variable values, as in
+// `test[db=pg14,go=1.21]`.
+func (r *StageRunner) taskPrefix(
+	task config.Task,
+	index int,
+	instance config.MatrixInstance,
+	ambiguous bool,
+) *logging.Prefix {
+	name := task.GetName()
+
+	if ambiguous {
+		name = fmt.Sprintf(
+			"%s.%d",
+			name,
+			index,
+		)
+	}
+
+	if label := instance.Label(); label != "" {
+		name = fmt.Sprintf(
+			"%s[%s]",
+			name,
+			label,
+		)
+	}
+
+	return r.prefix.Append(name)
+}
+
 //
 // Helpers
 
@@ -231,8 +390,17 @@ func runSequential(runners []TaskRunnerFunc) bool {
 	return true
 }
 
-func runParallel(runners []TaskRunnerFunc) bool {
-	failures := make(chan bool, len(runners))
+func runParallel(
+	ctx context.Context,
+	runners []TaskRunnerFunc,
+	maxParallel int,
+	failFast func(),
+) bool {
+	var (
+		failures  = make(chan bool, len(runners))
+		semaphore = util.NewSemaphore(maxParallel)
+	)
+
 	defer close(failures)
 
 	funcs := []func(){}
@@ -240,8 +408,19 @@ func runParallel(runners []TaskRunnerFunc) bool {
 		runner := r
 
 		funcs = append(funcs, func() {
+			if !semaphore.Acquire(ctx) {
+				failures <- false
+				return
+			}
+
+			defer semaphore.Release()
+
 			if ok := runner(); !ok {
 				failures <- false
+
+				if failFast != nil {
+					failFast()
+				}
 			}
 		})
 	}
@@ -0,0 +1,243 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/environment"
+	"github.com/ij-build/ij/logging"
+	. "github.com/onsi/gomega"
+)
+
+type StageSuite struct{}
+
+func (s *StageSuite) TestRunFailFast(t sweet.T) {
+	var (
+		cancelled = make(chan struct{})
+		stage     = &config.Stage{
+			Name:     "s",
+			Parallel: true,
+			FailFast: true,
+			Tasks: []*config.StageTask{
+				&config.StageTask{Name: "fail"},
+				&config.StageTask{Name: "wait"},
+			},
+		}
+	)
+
+	factory := func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return testTaskRunner(func(context *RunContext) bool {
+			if task.GetName() == "fail" {
+				return false
+			}
+
+			select {
+			case <-ctx.Done():
+				close(cancelled)
+			case <-time.After(time.Second * 5):
+			}
+
+			return false
+		})
+	}
+
+	runner := newTestStageRunner(stage, factory)
+	Expect(runner.Run(NewRunContext(nil))).To(BeFalse())
+	Expect(cancelled).To(BeClosed())
+}
+
+func (s *StageSuite) TestRunWithoutFailFast(t sweet.T) {
+	var (
+		finished = make(chan struct{})
+		stage    = &config.Stage{
+			Name:     "s",
+			Parallel: true,
+			Tasks: []*config.StageTask{
+				&config.StageTask{Name: "fail"},
+				&config.StageTask{Name: "wait"},
+			},
+		}
+	)
+
+	factory := func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return testTaskRunner(func(context *RunContext) bool {
+			if task.GetName() == "fail" {
+				return false
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Millisecond * 50):
+				close(finished)
+			}
+
+			return true
+		})
+	}
+
+	runner := newTestStageRunner(stage, factory)
+	Expect(runner.Run(NewRunContext(nil))).To(BeFalse())
+	Expect(finished).To(BeClosed())
+}
+
+func (s *StageSuite) TestRunAllowFailure(t sweet.T) {
+	stage := &config.Stage{
+		Name: "s",
+		Tasks: []*config.StageTask{
+			&config.StageTask{Name: "fail", AllowFailure: true},
+			&config.StageTask{Name: "wait"},
+		},
+	}
+
+	ran := false
+
+	factory := func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return testTaskRunner(func(context *RunContext) bool {
+			if task.GetName() == "fail" {
+				return false
+			}
+
+			ran = true
+			return true
+		})
+	}
+
+	rootContext := NewRunContext(nil)
+	runner := newTestStageRunner(stage, factory)
+	Expect(runner.Run(rootContext)).To(BeTrue())
+	Expect(ran).To(BeTrue())
+
+	state := rootContext.State()
+	Expect(state.FailedAllowed).To(Equal([]string{"p/s/fail"}))
+	Expect(state.Completed).To(Equal([]string{"p/s/wait"}))
+}
+
+func (s *StageSuite) TestRunResumed(t sweet.T) {
+	var (
+		ran         = []string{}
+		rootContext = NewRunContext(nil)
+		stage       = &config.Stage{
+			Name: "s",
+			Tasks: []*config.StageTask{
+				&config.StageTask{Name: "fail"},
+				&config.StageTask{Name: "wait"},
+			},
+		}
+	)
+
+	factory := func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return testTaskRunner(func(context *RunContext) bool {
+			ran = append(ran, task.GetName())
+			return true
+		})
+	}
+
+	rootContext.Restore(&RunState{Completed: []string{"p/s/fail"}})
+
+	runner := newTestStageRunner(stage, factory)
+	Expect(runner.Run(rootContext)).To(BeTrue())
+	Expect(ran).To(Equal([]string{"wait"}))
+	Expect(rootContext.State().Completed).To(Equal([]string{"p/s/fail", "p/s/wait"}))
+}
+
+func (s *StageSuite) TestRunMatrix(t sweet.T) {
+	var (
+		mutex    = sync.Mutex{}
+		prefixes = []string{}
+		values   = []string{}
+		stage    = &config.Stage{
+			Name:     "s",
+			Parallel: true,
+			Tasks: []*config.StageTask{
+				&config.StageTask{
+					Name: "wait",
+					Matrix: &config.Matrix{
+						Variables: map[string][]string{
+							"GO": []string{"1.20", "1.21"},
+							"DB": []string{"pg14"},
+						},
+					},
+				},
+			},
+		}
+	)
+
+	factory := func(
+		ctx context.Context,
+		context *RunContext,
+		task config.Task,
+		retryPolicy *config.RetryPolicy,
+		prefix *logging.Prefix,
+		env environment.Environment,
+	) TaskRunner {
+		return testTaskRunner(func(context *RunContext) bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			prefixes = append(prefixes, prefix.Serialize(logging.NilColorPicker))
+			values = append(values, env["GO"]+"/"+env["DB"])
+			return true
+		})
+	}
+
+	runner := newTestStageRunner(stage, factory)
+	Expect(runner.Run(NewRunContext(nil))).To(BeTrue())
+	Expect(prefixes).To(ConsistOf("p/s/wait[DB=pg14,GO=1.20]", "p/s/wait[DB=pg14,GO=1.21]"))
+	Expect(values).To(ConsistOf("1.20/pg14", "1.21/pg14"))
+}
+
+func newTestStageRunner(stage *config.Stage, factory TaskRunnerFactory) *StageRunner {
+	plan := &config.Plan{
+		Name:   "p",
+		Stages: []*config.Stage{stage},
+	}
+
+	return NewStageRunner(
+		context.Background(),
+		logging.NilLogger,
+		&config.Config{
+			Options: &config.Options{},
+			Tasks: map[string]config.Task{
+				"fail": &config.RunTask{TaskMeta: config.TaskMeta{Name: "fail"}},
+				"wait": &config.RunTask{TaskMeta: config.TaskMeta{Name: "wait"}},
+			},
+		},
+		factory,
+		plan,
+		stage,
+		logging.NewPrefix("p", "s"),
+		nil,
+		nil,
+	)
+}
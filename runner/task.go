@@ -2,6 +2,7 @@ package runner
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/ij-build/ij/command"
 	"github.com/ij-build/ij/config"
@@ -21,39 +22,49 @@ type (
 	}
 
 	TaskRunnerFactory func(
+		context.Context,
 		*RunContext,
 		config.Task,
+		*config.RetryPolicy,
 		*logging.Prefix,
 		environment.Environment,
 	) TaskRunner
 
 	baseRunner struct {
-		ctx       context.Context
-		factory   BuilderSetFactory
-		logger    logging.Logger
-		prefix    *logging.Prefix
-		onSuccess HookFunc
-		onFailure HookFunc
+		ctx         context.Context
+		factory     OperationSetFactory
+		retryPolicy *config.RetryPolicy
+		logger      logging.Logger
+		prefix      *logging.Prefix
+		onSuccess   HookFunc
+		onFailure   HookFunc
 	}
 
-	HookFunc          func(context *RunContext) error
-	BuilderFactory    func() (*command.Builder, error)
-	BuilderSetFactory func() ([]*command.Builder, error)
+	HookFunc            func(context *RunContext) error
+	BuilderFactory      func() (*command.Builder, error)
+	BuilderSetFactory   func() ([]*command.Builder, error)
+	OperationSetFactory func() ([]Operation, error)
+
+	// Operation is a single step of a task performed by a base runner. Its
+	// output is written to the given logger under the given prefix.
+	Operation func(ctx context.Context, logger logging.Logger, prefix *logging.Prefix) error
 )
 
 func NewBaseRunner(
 	ctx context.Context,
-	factory BuilderSetFactory,
+	factory OperationSetFactory,
+	retryPolicy *config.RetryPolicy,
 	logger logging.Logger,
 	prefix *logging.Prefix,
 ) BaseRunner {
 	return &baseRunner{
-		ctx:       ctx,
-		logger:    logger,
-		prefix:    prefix,
-		factory:   factory,
-		onSuccess: func(context *RunContext) error { return nil },
-		onFailure: func(context *RunContext) error { return nil },
+		ctx:         ctx,
+		logger:      logger,
+		prefix:      prefix,
+		factory:     factory,
+		retryPolicy: retryPolicy,
+		onSuccess:   func(context *RunContext) error { return nil },
+		onFailure:   func(context *RunContext) error { return nil },
 	}
 }
 
@@ -63,7 +74,23 @@ func (r *baseRunner) Run(context *RunContext) bool {
 		"Beginning task",
 	)
 
-	builders, err := r.factory()
+	ok := runWithRetries(
+		r.ctx,
+		r.logger,
+		r.prefix,
+		r.retryPolicy,
+		r.runAttempt,
+	)
+
+	if !ok {
+		return r.runFailureHook(context)
+	}
+
+	return r.runSuccessHook(context)
+}
+
+func (r *baseRunner) runAttempt() (bool, error) {
+	operations, err := r.factory()
 	if err != nil {
 		r.logger.Error(
 			r.prefix,
@@ -71,29 +98,11 @@ func (r *baseRunner) Run(context *RunContext) bool {
 			err.Error(),
 		)
 
-		return r.runFailureHook(context)
+		return false, nil
 	}
 
-	for _, builder := range builders {
-		args, stdin, err := builder.Build()
-		if err != nil {
-			r.logger.Error(
-				r.prefix,
-				"Failed to build command args: %s",
-				err.Error(),
-			)
-
-			return r.runFailureHook(context)
-		}
-
-		err = command.NewRunner(r.logger).Run(
-			r.ctx,
-			args,
-			stdin,
-			r.prefix,
-		)
-
-		if err != nil {
+	for _, operation := range operations {
+		if err := operation(r.ctx, r.logger, r.prefix); err != nil {
 			reportError(
 				r.ctx,
 				r.logger,
@@ -102,11 +111,11 @@ func (r *baseRunner) Run(context *RunContext) bool {
 				err.Error(),
 			)
 
-			return r.runFailureHook(context)
+			return false, err
 		}
 	}
 
-	return r.runSuccessHook(context)
+	return true, nil
 }
 
 func (r *baseRunner) RegisterOnSuccess(hookFunc HookFunc) {
@@ -157,3 +166,32 @@ func NewMultiFactory(factory BuilderFactory) BuilderSetFactory {
 		return []*command.Builder{builder}, nil
 	}
 }
+
+// NewCommandOperations converts a factory of command builders into a factory
+// of operations which invoke each command.
+func NewCommandOperations(factory BuilderSetFactory) OperationSetFactory {
+	return func() ([]Operation, error) {
+		builders, err := factory()
+		if err != nil {
+			return nil, err
+		}
+
+		operations := []Operation{}
+		for _, builder := range builders {
+			operations = append(operations, commandOperation(builder))
+		}
+
+		return operations, nil
+	}
+}
+
+func commandOperation(builder *command.Builder) Operation {
+	return func(ctx context.Context, logger logging.Logger, prefix *logging.Prefix) error {
+		args, stdin, err := builder.Build()
+		if err != nil {
+			return fmt.Errorf("failed to build command args: %s", err.Error())
+		}
+
+		return command.NewRunner(logger).Run(ctx, args, stdin, prefix)
+	}
+}
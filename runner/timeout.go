@@ -0,0 +1,130 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	// PauseFunc stops the clock of a timeout and returns a function which
+	// restarts it.
+	PauseFunc func() (resume func())
+
+	// pausableTimeout is a context which is canceled once its clock has run
+	// for the given duration. Its clock does not run while it is paused.
+	pausableTimeout struct {
+		context.Context
+		done      chan struct{}
+		err       error
+		timer     *time.Timer
+		started   time.Time
+		remaining time.Duration
+		paused    int
+		mutex     sync.Mutex
+	}
+)
+
+// withTimeout derives a context from the given parent which is canceled once
+// the given duration elapses. A zero duration imposes no additional deadline.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, func()) {
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// withPausableTimeout is like withTimeout, but also returns a function which
+// stops the clock of the timeout until the function it returns is called.
+func withPausableTimeout(ctx context.Context, timeout time.Duration) (context.Context, func(), PauseFunc) {
+	if timeout == 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, func() func() { return func() {} }
+	}
+
+	t := &pausableTimeout{
+		Context:   ctx,
+		done:      make(chan struct{}),
+		remaining: timeout,
+	}
+
+	t.mutex.Lock()
+	t.start()
+	t.mutex.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			t.finish(ctx.Err())
+		case <-t.done:
+		}
+	}()
+
+	return t, func() { t.finish(context.Canceled) }, t.pause
+}
+
+func (t *pausableTimeout) Done() <-chan struct{} {
+	return t.done
+}
+
+func (t *pausableTimeout) Err() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.err
+}
+
+func (t *pausableTimeout) pause() func() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.paused++; t.paused == 1 && t.timer.Stop() {
+		t.remaining -= time.Since(t.started)
+	}
+
+	once := sync.Once{}
+
+	return func() {
+		once.Do(func() {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+
+			if t.paused--; t.paused == 0 && t.err == nil {
+				t.start()
+			}
+		})
+	}
+}
+
+func (t *pausableTimeout) start() {
+	t.started = time.Now()
+	t.timer = time.AfterFunc(t.remaining, func() {
+		t.finish(context.DeadlineExceeded)
+	})
+}
+
+func (t *pausableTimeout) finish(err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.err != nil {
+		return
+	}
+
+	t.err = err
+	t.timer.Stop()
+	close(t.done)
+}
+
+// timedOut determines if the given context was canceled because its own
+// deadline elapsed (and not because its parent was canceled).
+func timedOut(ctx, parent context.Context) bool {
+	return ctx.Err() == context.DeadlineExceeded && parent.Err() == nil
+}
+
+// deadlineExceeded determines if the given context, or any of its ancestors,
+// was canceled because a deadline elapsed.
+func deadlineExceeded(ctx context.Context) bool {
+	return ctx.Err() == context.DeadlineExceeded
+}
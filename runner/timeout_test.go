@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"context"
+	"time"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type TimeoutSuite struct{}
+
+func (s *TimeoutSuite) TestWithTimeout(t sweet.T) {
+	ctx, cancel := withTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	Eventually(ctx.Done()).Should(BeClosed())
+	Expect(timedOut(ctx, context.Background())).To(BeTrue())
+	Expect(deadlineExceeded(ctx)).To(BeTrue())
+}
+
+func (s *TimeoutSuite) TestWithTimeoutZero(t sweet.T) {
+	ctx, cancel := withTimeout(context.Background(), 0)
+	_, ok := ctx.Deadline()
+	Expect(ok).To(BeFalse())
+
+	cancel()
+	Expect(timedOut(ctx, context.Background())).To(BeFalse())
+	Expect(deadlineExceeded(ctx)).To(BeFalse())
+}
+
+func (s *TimeoutSuite) TestTimedOutParentCanceled(t sweet.T) {
+	parent, cancelParent := withTimeout(context.Background(), time.Millisecond)
+	defer cancelParent()
+
+	ctx, cancel := withTimeout(parent, time.Hour)
+	defer cancel()
+
+	Eventually(ctx.Done()).Should(BeClosed())
+	Expect(timedOut(ctx, parent)).To(BeFalse())
+	Expect(deadlineExceeded(ctx)).To(BeTrue())
+}
+
+func (s *TimeoutSuite) TestWithPausableTimeout(t sweet.T) {
+	ctx, cancel, pause := withPausableTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	child, cancelChild := withTimeout(ctx, time.Hour)
+	defer cancelChild()
+
+	resume := pause()
+	Consistently(ctx.Done(), time.Millisecond*200).ShouldNot(BeClosed())
+
+	resume()
+	resume()
+	Eventually(ctx.Done()).Should(BeClosed())
+	Eventually(child.Done()).Should(BeClosed())
+	Expect(timedOut(ctx, context.Background())).To(BeTrue())
+	Expect(deadlineExceeded(child)).To(BeTrue())
+}
+
+func (s *TimeoutSuite) TestWithPausableTimeoutCanceled(t sweet.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel, _ := withPausableTimeout(parent, time.Hour)
+	defer cancel()
+
+	cancelParent()
+	Eventually(ctx.Done()).Should(BeClosed())
+	Expect(ctx.Err()).To(Equal(context.Canceled))
+	Expect(deadlineExceeded(ctx)).To(BeFalse())
+}
@@ -0,0 +1,236 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/scratch"
+	"github.com/ij-build/ij/volume"
+)
+
+const (
+	WorkspaceModeBind   = "bind"
+	WorkspaceModeVolume = "volume"
+
+	// ScriptsVolumePath is the path at which the scripts volume is mounted
+	// into run task containers when the workspace is held in a volume.
+	ScriptsVolumePath = "/tmp/ij/scripts"
+)
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// pushWorkspace copies the entire host workspace into the workspace volume
+// once files have been imported. This is a no-op unless the workspace is
+// held in a volume.
+func pushWorkspace(
+	ctx context.Context,
+	volume *volume.Volume,
+	scratch *scratch.ScratchSpace,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+) bool {
+	if volume == nil {
+		return true
+	}
+
+	if err := volume.Push(ctx, scratch.Workspace()); err != nil {
+		reportError(
+			ctx,
+			logger,
+			prefix,
+			"Failed to copy workspace into volume: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	return true
+}
+
+// pullWorkspace copies the entire workspace volume into the host workspace
+// before files are exported or a host task runs within the workspace. This
+// is a no-op unless the workspace is held in a volume.
+func pullWorkspace(
+	ctx context.Context,
+	volume *volume.Volume,
+	scratch *scratch.ScratchSpace,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+) bool {
+	if volume == nil {
+		return true
+	}
+
+	if err := volume.Pull(ctx, scratch.Workspace()); err != nil {
+		reportError(
+			ctx,
+			logger,
+			prefix,
+			"Failed to copy workspace out of volume: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	return true
+}
+
+// pushWorkspacePaths copies the given files of the host workspace, which
+// are relative to the workspace, into the workspace volume. This is a no-op
+// unless the workspace is held in a volume.
+func pushWorkspacePaths(
+	ctx context.Context,
+	volume *volume.Volume,
+	scratch *scratch.ScratchSpace,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+	files []string,
+) bool {
+	if volume == nil {
+		return true
+	}
+
+	if err := volume.PushPaths(ctx, scratch.Workspace(), files); err != nil {
+		reportError(
+			ctx,
+			logger,
+			prefix,
+			"Failed to copy files into volume: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	return true
+}
+
+// pullWorkspacePatterns copies the paths of the workspace volume which may
+// contain a match of the given patterns into the host workspace. This is a
+// no-op unless the workspace is held in a volume.
+func pullWorkspacePatterns(
+	ctx context.Context,
+	volume *volume.Volume,
+	scratch *scratch.ScratchSpace,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+	patterns []string,
+) bool {
+	if volume == nil {
+		return true
+	}
+
+	if err := volume.PullPaths(ctx, scratch.Workspace(), patternRoots(patterns)); err != nil {
+		reportError(
+			ctx,
+			logger,
+			prefix,
+			"Failed to copy files out of volume: %s",
+			err.Error(),
+		)
+
+		return false
+	}
+
+	return true
+}
+
+// attachWorkspace marks the workspace volume as in use by a container until
+// the returned function is called. This is a no-op unless the workspace is
+// held in a volume.
+func attachWorkspace(volume *volume.Volume) func() {
+	if volume == nil {
+		return func() {}
+	}
+
+	volume.Attach()
+	return volume.Detach
+}
+
+// patternRoots returns the directories (or files) of the workspace which
+// contain every match of the given patterns. This is the leading portion
+// of each pattern without glob characters. A pattern beginning with a glob
+// is rooted at the workspace itself.
+func patternRoots(patterns []string) []string {
+	roots := []string{}
+	for _, pattern := range patterns {
+		components := []string{}
+		for _, component := range strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/") {
+			if strings.ContainsAny(component, "*?[{") {
+				break
+			}
+
+			components = append(components, component)
+		}
+
+		root := filepath.Join(components...)
+		if root == "" || filepath.IsAbs(root) || strings.HasPrefix(root, "..") {
+			return []string{"."}
+		}
+
+		roots = append(roots, root)
+	}
+
+	return roots
+}
+
+// snapshotWorkspace records the size and modification time of each file
+// of the host workspace when the workspace is held in a volume.
+func snapshotWorkspace(volume *volume.Volume, scratch *scratch.ScratchSpace) (map[string]fileStamp, error) {
+	if volume == nil {
+		return nil, nil
+	}
+
+	stamps := map[string]fileStamp{}
+	err := walkWorkspace(scratch, func(path string, info os.FileInfo) {
+		stamps[path] = fileStamp{info.Size(), info.ModTime()}
+	})
+
+	return stamps, err
+}
+
+// changedWorkspacePaths returns the files of the host workspace which were
+// created or modified since the given snapshot was taken.
+func changedWorkspacePaths(scratch *scratch.ScratchSpace, stamps map[string]fileStamp) ([]string, error) {
+	changed := []string{}
+	err := walkWorkspace(scratch, func(path string, info os.FileInfo) {
+		if stamp, ok := stamps[path]; !ok || stamp != (fileStamp{info.Size(), info.ModTime()}) {
+			changed = append(changed, path)
+		}
+	})
+
+	return changed, err
+}
+
+// walkWorkspace invokes the given function with the workspace-relative path
+// of each file and symlink of the host workspace.
+func walkWorkspace(scratch *scratch.ScratchSpace, f func(string, os.FileInfo)) error {
+	root := scratch.Workspace()
+
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		f(rel, info)
+		return nil
+	})
+}
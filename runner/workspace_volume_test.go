@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/scratch"
+	. "github.com/onsi/gomega"
+)
+
+type WorkspaceVolumeSuite struct{}
+
+func (s *WorkspaceVolumeSuite) TestPatternRoots(t sweet.T) {
+	Expect(patternRoots(nil)).To(BeEmpty())
+	Expect(patternRoots([]string{"vendor", "bin/*", "dist/**/*.tar.gz", "out/"})).To(Equal([]string{
+		"vendor",
+		"bin",
+		"dist",
+		"out",
+	}))
+
+	Expect(patternRoots([]string{"bin/*", "*.txt"})).To(Equal([]string{"."}))
+	Expect(patternRoots([]string{"bin/*", "../outside"})).To(Equal([]string{"."}))
+}
+
+func (s *WorkspaceVolumeSuite) TestChangedWorkspacePaths(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	scratch := scratch.NewScratchSpace("abcdef0", name, name, false)
+	Expect(scratch.Setup()).To(BeNil())
+
+	workspace := scratch.Workspace()
+	Expect(os.MkdirAll(filepath.Join(workspace, "src"), 0755)).To(BeNil())
+	Expect(ioutil.WriteFile(filepath.Join(workspace, "src", "a"), []byte("a"), 0644)).To(BeNil())
+	Expect(ioutil.WriteFile(filepath.Join(workspace, "src", "b"), []byte("b"), 0644)).To(BeNil())
+
+	stamps := map[string]fileStamp{}
+	Expect(walkWorkspace(scratch, func(path string, info os.FileInfo) {
+		stamps[path] = fileStamp{info.Size(), info.ModTime()}
+	})).To(BeNil())
+
+	later := time.Now().Add(time.Minute)
+	Expect(os.Chtimes(filepath.Join(workspace, "src", "b"), later, later)).To(BeNil())
+	Expect(ioutil.WriteFile(filepath.Join(workspace, "c"), []byte("c"), 0644)).To(BeNil())
+
+	changed, err := changedWorkspacePaths(scratch, stamps)
+	Expect(err).To(BeNil())
+	Expect(changed).To(ConsistOf("c", filepath.Join("src", "b")))
+}
@@ -0,0 +1,501 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/logging"
+)
+
+type cliRuntime struct {
+	executable    string
+	logger        logging.Logger
+	runner        command.Runner
+	runnerFactory func(logging.Logger) command.Runner
+}
+
+const containerStateFormat = "{{.State.Status}} {{.State.ExitCode}} {{.RestartCount}} {{if .State.Health}}{{.State.Health.Status}}{{end}}"
+
+func newCLIRuntime(
+	executable string,
+	logger logging.Logger,
+	runner command.Runner,
+) *cliRuntime {
+	return &cliRuntime{
+		executable:    executable,
+		logger:        logger,
+		runner:        runner,
+		runnerFactory: command.NewRunner,
+	}
+}
+
+func (r *cliRuntime) Run(ctx context.Context, options *RunOptions, logger logging.Logger, prefix *logging.Prefix) error {
+	return r.runContainer(ctx, r.RunArgs(options), options.Detach, logger, prefix)
+}
+
+func (r *cliRuntime) RunForOutput(ctx context.Context, options *RunOptions) (string, string, error) {
+	return r.runner.RunForOutput(ctx, r.RunArgs(options), nil)
+}
+
+func (r *cliRuntime) CreateContainer(ctx context.Context, options *RunOptions) error {
+	_, _, err := r.runner.RunForOutput(
+		ctx,
+		r.runArgs("create", options, r.addNetworkOptions, r.addUserOptions),
+		nil,
+	)
+
+	return err
+}
+
+func (r *cliRuntime) Build(ctx context.Context, options *BuildOptions, logger logging.Logger, prefix *logging.Prefix) error {
+	return r.runnerFactory(logger).Run(ctx, r.BuildArgs(options), nil, prefix)
+}
+
+func (r *cliRuntime) Push(ctx context.Context, image string, logger logging.Logger, prefix *logging.Prefix) error {
+	return r.runnerFactory(logger).Run(ctx, r.PushArgs(image), nil, prefix)
+}
+
+func (r *cliRuntime) Exec(ctx context.Context, containerName string, args []string) (string, string, error) {
+	return r.runner.RunForOutput(
+		ctx,
+		r.commandArgs(append([]string{"exec", containerName}, args...)...),
+		nil,
+	)
+}
+
+func (r *cliRuntime) Logs(ctx context.Context, containerName string, options *LogsOptions, logger logging.Logger, prefix *logging.Prefix) error {
+	cb := command.NewBuilder(r.commandArgs("logs"), nil)
+
+	if options.Tail > 0 {
+		cb.AddFlagValue("--tail", strconv.Itoa(options.Tail))
+	}
+
+	if options.Follow {
+		cb.AddFlag("-f")
+	}
+
+	cb.AddArgs(containerName)
+	return r.runnerFactory(logger).Run(ctx, buildArgs(cb), nil, prefix)
+}
+
+func (r *cliRuntime) Commit(ctx context.Context, containerName string) (string, error) {
+	return r.output(ctx, "commit", containerName)
+}
+
+func (r *cliRuntime) RunArgs(options *RunOptions) []string {
+	return r.runArgs("run", options, r.addNetworkOptions, r.addUserOptions)
+}
+
+func (r *cliRuntime) BuildArgs(options *BuildOptions) []string {
+	cb := command.NewBuilder(r.commandArgs("build"), nil)
+	cb.AddFlagValue("-f", options.Dockerfile)
+	cb.AddFlagValue("--target", options.Target)
+
+	for _, tag := range options.Tags {
+		cb.AddFlagValue("-t", tag)
+	}
+
+	for _, label := range options.Labels {
+		cb.AddFlagValue("--label", label)
+	}
+
+	cb.AddArgs(options.ContextDir)
+	return buildArgs(cb)
+}
+
+func (r *cliRuntime) PushArgs(image string) []string {
+	return r.commandArgs("push", image)
+}
+
+func (r *cliRuntime) RemoveImageArgs(image string) []string {
+	return r.commandArgs("rmi", "-f", image)
+}
+
+func (r *cliRuntime) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, PingTimeout)
+	defer cancel()
+
+	_, _, err := r.runner.RunForOutput(
+		ctx,
+		r.commandArgs("ps", "-q"),
+		nil,
+	)
+
+	return err
+}
+
+func (r *cliRuntime) Inspect(ctx context.Context, containerName string) (*ContainerState, error) {
+	out, err := r.output(ctx, "inspect", "-f", containerStateFormat, containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseContainerState(out)
+}
+
+func (r *cliRuntime) HasHealthcheck(ctx context.Context, containerName string) (bool, error) {
+	out, err := r.output(
+		ctx,
+		"inspect",
+		"-f",
+		"{{if .Config.Healthcheck}}true{{else}}false{{end}}",
+		containerName,
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	return out == "true", nil
+}
+
+func (r *cliRuntime) ImageID(ctx context.Context, image string) (string, error) {
+	return r.output(ctx, "image", "inspect", "-f", "{{.Id}}", image)
+}
+
+func (r *cliRuntime) Kill(ctx context.Context, containerName string) error {
+	_, err := r.output(ctx, "kill", containerName)
+	return err
+}
+
+func (r *cliRuntime) Remove(ctx context.Context, containerName string) error {
+	_, err := r.output(ctx, "rm", "-f", containerName)
+	return err
+}
+
+func (r *cliRuntime) RemoveImage(ctx context.Context, image string) error {
+	_, _, err := r.runner.RunForOutput(ctx, r.RemoveImageArgs(image), nil)
+	return err
+}
+
+func (r *cliRuntime) CopyTo(ctx context.Context, containerName, path string, archive io.Reader) error {
+	_, _, err := r.runner.RunForOutput(
+		ctx,
+		r.commandArgs("cp", "-a", "-", containerName+":"+path),
+		ioutil.NopCloser(archive),
+	)
+
+	return err
+}
+
+func (r *cliRuntime) CopyFrom(ctx context.Context, containerName, path, dest string) error {
+	_, errOut, err := r.runner.RunForOutput(
+		ctx,
+		r.commandArgs("cp", containerName+":"+path, dest),
+		nil,
+	)
+
+	if err != nil && isMissingPath(errOut) {
+		return ErrNoSuchPath
+	}
+
+	return err
+}
+
+func (r *cliRuntime) CreateVolume(ctx context.Context, name string, labels map[string]string) error {
+	cb := command.NewBuilder(r.commandArgs("volume", "create"), nil)
+
+	keys := []string{}
+	for key := range labels {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		cb.AddFlagValue("--label", fmt.Sprintf("%s=%s", key, labels[key]))
+	}
+
+	cb.AddArgs(name)
+
+	_, _, err := r.runner.RunForOutput(ctx, buildArgs(cb), nil)
+	return err
+}
+
+func (r *cliRuntime) VolumeLabels(ctx context.Context, name string) (map[string]string, error) {
+	out, err := r.output(ctx, "volume", "inspect", "-f", "{{json .Labels}}", name)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{}
+	if err := json.Unmarshal([]byte(out), &labels); err != nil {
+		return nil, fmt.Errorf("malformed labels of volume %s", name)
+	}
+
+	return labels, nil
+}
+
+func (r *cliRuntime) ListVolumes(ctx context.Context, label string) ([]string, error) {
+	out, err := r.output(ctx, "volume", "ls", "-q", "--filter", "label="+label)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(out), nil
+}
+
+func (r *cliRuntime) RemoveVolume(ctx context.Context, name string) error {
+	_, err := r.output(ctx, "volume", "rm", name)
+	return err
+}
+
+func (r *cliRuntime) CreateNetwork(ctx context.Context, name string) error {
+	_, err := r.output(ctx, "network", "create", name)
+	return err
+}
+
+func (r *cliRuntime) RemoveNetwork(ctx context.Context, name string) error {
+	_, err := r.output(ctx, "network", "rm", name)
+	return err
+}
+
+func (r *cliRuntime) DisconnectNetwork(ctx context.Context, name, containerName string) error {
+	_, err := r.output(ctx, "network", "disconnect", "--force", name, containerName)
+	return err
+}
+
+func (r *cliRuntime) Login(ctx context.Context, server, username, password string) error {
+	builder := command.NewBuilder(r.commandArgs("login"), nil)
+	builder.AddArgs(server)
+	builder.AddFlagValue("-u", username)
+	builder.AddFlag("--password-stdin")
+	builder.SetStdin(ioutil.NopCloser(bytes.NewReader([]byte(password))))
+
+	args, stdin, err := builder.Build()
+	if err != nil {
+		return err
+	}
+
+	return r.runner.Run(
+		ctx,
+		args,
+		stdin,
+		nil,
+	)
+}
+
+func (r *cliRuntime) Logout(ctx context.Context, server string) error {
+	return r.runner.Run(
+		ctx,
+		r.commandArgs("logout", server),
+		nil,
+		nil,
+	)
+}
+
+// addNetworkOptions adds the options which attach a container to the given
+// network, where it is reachable by the given alias.
+func (r *cliRuntime) addNetworkOptions(cb *command.Builder, network, alias string) {
+	cb.AddFlagValue("--network", network)
+	cb.AddFlagValue("--network-alias", alias)
+}
+
+// addUserOptions adds the options which make files written by a container
+// to a bind mount owned by the user invoking ij.
+func (r *cliRuntime) addUserOptions(cb *command.Builder) {
+}
+
+// runArgs creates the args of a run or create command. The given functions
+// add the network and user options, which differ between clients.
+func (r *cliRuntime) runArgs(
+	subcommand string,
+	options *RunOptions,
+	addNetworkOptions func(cb *command.Builder, network, alias string),
+	addUserOptions func(cb *command.Builder),
+) []string {
+	prelude := r.commandArgs(subcommand)
+
+	if options.Remove {
+		prelude = append(prelude, "--rm")
+	}
+
+	if options.Interactive {
+		prelude = append(prelude, "-it")
+	}
+
+	cb := command.NewBuilder(prelude, nil)
+
+	if options.Detach {
+		cb.AddFlag("-d")
+	}
+
+	cb.AddFlagValue("--name", options.Name)
+	cb.AddFlagValue("--entrypoint", options.Entrypoint)
+	addFlagValues(cb, "-e", options.Env)
+
+	if options.MapUser {
+		addUserOptions(cb)
+	}
+
+	cb.AddFlagValue("--user", options.User)
+
+	if options.Network != "" {
+		addNetworkOptions(cb, options.Network, options.Alias)
+	}
+
+	addFlagValues(cb, "-v", options.Binds)
+
+	for _, mount := range options.Mounts {
+		cb.AddFlagValue("--mount", formatMount(mount))
+	}
+
+	addFlagValues(cb, "--volumes-from", options.VolumesFrom)
+	addFlagValues(cb, "-p", options.Ports)
+	cb.AddFlagValue("-w", options.Workdir)
+
+	if healthcheck := options.Healthcheck; healthcheck != nil {
+		cb.AddFlagValue("--health-cmd", healthcheck.Command)
+		addDurationFlag(cb, "--health-interval", healthcheck.Interval)
+		addDurationFlag(cb, "--health-start-period", healthcheck.StartPeriod)
+		addDurationFlag(cb, "--health-timeout", healthcheck.Timeout)
+
+		if healthcheck.Retries > 0 {
+			cb.AddFlagValue("--health-retries", strconv.Itoa(healthcheck.Retries))
+		}
+	}
+
+	cb.AddFlagValue("--cpu-shares", options.CPUShares)
+	cb.AddFlagValue("--memory", options.Memory)
+	cb.AddFlagValue("--shm-size", options.ShmSize)
+	cb.AddFlagValue("--platform", options.Platform)
+
+	if options.Privileged {
+		cb.AddFlag("--privileged")
+	}
+
+	addFlagValues(cb, "--ulimit", options.Ulimits)
+	addFlagValues(cb, "--cap-add", options.CapAdd)
+	addFlagValues(cb, "--tmpfs", options.Tmpfs)
+	addFlagValues(cb, "--device", options.Devices)
+
+	cb.AddArgs(options.Image)
+	cb.AddArgs(options.Args...)
+	return buildArgs(cb)
+}
+
+// runContainer invokes the given run command. The output of a foreground
+// container is written to the given logger. A detached container writes
+// only its id, which is discarded.
+func (r *cliRuntime) runContainer(
+	ctx context.Context,
+	args []string,
+	detach bool,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+) error {
+	if detach {
+		_, _, err := r.runnerFactory(logger).RunForOutput(ctx, args, nil)
+		return err
+	}
+
+	return r.runnerFactory(logger).Run(ctx, args, nil, prefix)
+}
+
+// commandArgs returns the args which invoke the given subcommand of the
+// command line client.
+func (r *cliRuntime) commandArgs(args ...string) []string {
+	return append([]string{r.executable}, args...)
+}
+
+// output invokes the given subcommand and returns its trimmed output.
+func (r *cliRuntime) output(ctx context.Context, args ...string) (string, error) {
+	out, _, err := r.runner.RunForOutput(
+		ctx,
+		r.commandArgs(args...),
+		nil,
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+//
+// Helpers
+
+func parseContainerState(out string) (*ContainerState, error) {
+	fields := strings.Fields(out)
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("malformed container state %q", strings.TrimSpace(out))
+	}
+
+	exitCode, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed container state %q", strings.TrimSpace(out))
+	}
+
+	restartCount, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed container state %q", strings.TrimSpace(out))
+	}
+
+	state := &ContainerState{
+		Status:       fields[0],
+		ExitCode:     exitCode,
+		RestartCount: restartCount,
+	}
+
+	if len(fields) > 3 {
+		state.Health = fields[3]
+	}
+
+	return state, nil
+}
+
+// formatMount serializes a mount as the value of a --mount option.
+func formatMount(mount *Mount) string {
+	fields := []string{"type=" + mount.Type}
+
+	if mount.Source != "" {
+		fields = append(fields, "source="+mount.Source)
+	}
+
+	fields = append(fields, "target="+mount.Target)
+
+	if mount.ReadOnly {
+		fields = append(fields, "readonly")
+	}
+
+	return strings.Join(fields, ",")
+}
+
+// isMissingPath determines if the error output of a copy indicates that
+// the source path does not exist.
+func isMissingPath(errOut string) bool {
+	errOut = strings.ToLower(errOut)
+
+	return strings.Contains(errOut, "no such container:path") ||
+		strings.Contains(errOut, "could not find the file") ||
+		strings.Contains(errOut, "no such file or directory")
+}
+
+func addFlagValues(cb *command.Builder, flag string, values []string) {
+	for _, value := range values {
+		cb.AddFlagValue(flag, value)
+	}
+}
+
+func addDurationFlag(cb *command.Builder, flag string, duration time.Duration) {
+	if duration > 0 {
+		cb.AddFlagValue(flag, duration.String())
+	}
+}
+
+// buildArgs returns the args of a builder without build functions, which
+// cannot fail.
+func buildArgs(cb *command.Builder) []string {
+	args, _, _ := cb.Build()
+	return args
+}
@@ -0,0 +1,327 @@
+package runtime
+
+//go:generate go-mockgen -f github.com/ij-build/ij/command -i Runner -o mock_runner_test.go
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/aphistic/sweet"
+	. "github.com/efritz/go-mockgen/matchers"
+	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/logging"
+	. "github.com/onsi/gomega"
+)
+
+type CLISuite struct{}
+
+func (s *CLISuite) TestCommandArgs(t sweet.T) {
+	runtime := newCLIRuntime("docker", logging.NilLogger, NewMockRunner())
+	Expect(runtime.commandArgs("run", "--rm")).To(Equal([]string{"docker", "run", "--rm"}))
+}
+
+func (s *CLISuite) TestInspect(t sweet.T) {
+	runner := NewMockRunner()
+	runner.RunForOutputFunc.SetDefaultReturn("running 0 1 healthy\n", "", nil)
+
+	state, err := newCLIRuntime("docker", logging.NilLogger, runner).Inspect(
+		context.Background(),
+		"c1",
+	)
+
+	Expect(err).To(BeNil())
+	Expect(state).To(Equal(&ContainerState{
+		Status:       "running",
+		ExitCode:     0,
+		RestartCount: 1,
+		Health:       "healthy",
+	}))
+
+	Expect(runner.RunForOutputFunc).To(BeCalledWith(BeAnything(), []string{
+		"docker", "inspect", "-f", containerStateFormat, "c1",
+	}, BeAnything()))
+}
+
+func (s *CLISuite) TestHasHealthcheck(t sweet.T) {
+	runner := NewMockRunner()
+	runner.RunForOutputFunc.PushReturn("true\n", "", nil)
+	runner.RunForOutputFunc.PushReturn("false\n", "", nil)
+
+	runtime := newCLIRuntime("docker", logging.NilLogger, runner)
+
+	ok, err := runtime.HasHealthcheck(context.Background(), "c1")
+	Expect(err).To(BeNil())
+	Expect(ok).To(BeTrue())
+
+	ok, err = runtime.HasHealthcheck(context.Background(), "c2")
+	Expect(err).To(BeNil())
+	Expect(ok).To(BeFalse())
+}
+
+func (s *CLISuite) TestNetwork(t sweet.T) {
+	runner := NewMockRunner()
+	runtime := newCLIRuntime("docker", logging.NilLogger, runner)
+
+	Expect(runtime.CreateNetwork(context.Background(), "abcdef0")).To(BeNil())
+	Expect(runtime.DisconnectNetwork(context.Background(), "abcdef0", "c1")).To(BeNil())
+	Expect(runtime.RemoveNetwork(context.Background(), "abcdef0")).To(BeNil())
+
+	Expect(runner.RunForOutputFunc).To(BeCalledN(3))
+	Expect(runner.RunForOutputFunc).To(BeCalledWith(BeAnything(), []string{
+		"docker", "network", "create", "abcdef0",
+	}, BeAnything()))
+	Expect(runner.RunForOutputFunc).To(BeCalledWith(BeAnything(), []string{
+		"docker", "network", "disconnect", "--force", "abcdef0", "c1",
+	}, BeAnything()))
+	Expect(runner.RunForOutputFunc).To(BeCalledWith(BeAnything(), []string{
+		"docker", "network", "rm", "abcdef0",
+	}, BeAnything()))
+}
+
+func (s *CLISuite) TestLogs(t sweet.T) {
+	runner := NewMockRunner()
+	runtime := newCLIRuntime("docker", logging.NilLogger, runner)
+	runtime.runnerFactory = func(logging.Logger) command.Runner { return runner }
+
+	Expect(runtime.Logs(context.Background(), "c1", &LogsOptions{Tail: 20}, logging.NilLogger, nil)).To(BeNil())
+	Expect(runtime.Logs(context.Background(), "c2", &LogsOptions{Follow: true}, logging.NilLogger, nil)).To(BeNil())
+
+	Expect(runner.RunFunc).To(BeCalledN(2))
+	Expect(runner.RunFunc.History()[0].Arg1).To(Equal([]string{"docker", "logs", "--tail", "20", "c1"}))
+	Expect(runner.RunFunc.History()[1].Arg1).To(Equal([]string{"docker", "logs", "-f", "c2"}))
+}
+
+func (s *CLISuite) TestExecAndCommit(t sweet.T) {
+	runner := NewMockRunner()
+	runner.RunForOutputFunc.PushReturn("ok\n", "", nil)
+	runner.RunForOutputFunc.PushReturn("sha256:abcdef\n", "", nil)
+
+	runtime := newCLIRuntime("docker", logging.NilLogger, runner)
+
+	out, _, err := runtime.Exec(context.Background(), "c1", []string{"/ij/add-keys.sh"})
+	Expect(err).To(BeNil())
+	Expect(out).To(Equal("ok\n"))
+
+	id, err := runtime.Commit(context.Background(), "c1")
+	Expect(err).To(BeNil())
+	Expect(id).To(Equal("sha256:abcdef"))
+
+	Expect(runtime.RemoveImage(context.Background(), id)).To(BeNil())
+
+	history := runner.RunForOutputFunc.History()
+	Expect(history).To(HaveLen(3))
+	Expect(history[0].Arg1).To(Equal([]string{"docker", "exec", "c1", "/ij/add-keys.sh"}))
+	Expect(history[1].Arg1).To(Equal([]string{"docker", "commit", "c1"}))
+	Expect(history[2].Arg1).To(Equal([]string{"docker", "rmi", "-f", "sha256:abcdef"}))
+}
+
+func (s *CLISuite) TestCreateContainer(t sweet.T) {
+	runner := NewMockRunner()
+	runtime := newCLIRuntime("docker", logging.NilLogger, runner)
+
+	Expect(runtime.CreateContainer(context.Background(), &RunOptions{
+		Name:  "abcdef0-volume",
+		Image: "busybox:1",
+		Binds: []string{"abcdef0-workspace:/workspace"},
+	})).To(BeNil())
+
+	Expect(runner.RunForOutputFunc).To(BeCalledWith(BeAnything(), []string{
+		"docker", "create",
+		"--name", "abcdef0-volume",
+		"-v", "abcdef0-workspace:/workspace",
+		"busybox:1",
+	}, BeAnything()))
+}
+
+func (s *CLISuite) TestCopy(t sweet.T) {
+	runner := NewMockRunner()
+	runner.RunForOutputFunc.SetDefaultHook(func(ctx context.Context, args []string, _ io.ReadCloser) (string, string, error) {
+		if args[2] == "c1:/workspace/missing" {
+			return "", "Error: No such container:path: c1:/workspace/missing", fmt.Errorf("exit status 1")
+		}
+
+		return "", "", nil
+	})
+
+	runtime := newCLIRuntime("docker", logging.NilLogger, runner)
+
+	Expect(runtime.CopyTo(context.Background(), "c1", "/workspace", bytes.NewReader(nil))).To(BeNil())
+	Expect(runtime.CopyFrom(context.Background(), "c1", "/workspace/.", "/ws")).To(BeNil())
+	Expect(runtime.CopyFrom(context.Background(), "c1", "/workspace/missing", "/ws")).To(Equal(ErrNoSuchPath))
+
+	history := runner.RunForOutputFunc.History()
+	Expect(history[0].Arg1).To(Equal([]string{"docker", "cp", "-a", "-", "c1:/workspace"}))
+	Expect(history[0].Arg2).NotTo(BeNil())
+	Expect(history[1].Arg1).To(Equal([]string{"docker", "cp", "c1:/workspace/.", "/ws"}))
+}
+
+func (s *CLISuite) TestVolumes(t sweet.T) {
+	runner := NewMockRunner()
+	runner.RunForOutputFunc.SetDefaultHook(func(ctx context.Context, args []string, _ io.ReadCloser) (string, string, error) {
+		switch args[2] {
+		case "ls":
+			return "vol-b\nvol-a\n", "", nil
+		case "inspect":
+			return `{"ij.cache":"gomod"}` + "\n", "", nil
+		}
+
+		return "", "", nil
+	})
+
+	runtime := newCLIRuntime("docker", logging.NilLogger, runner)
+
+	Expect(runtime.CreateVolume(context.Background(), "vol-a", map[string]string{
+		"ij.project": "/src/project",
+		"ij.cache":   "gomod",
+	})).To(BeNil())
+
+	names, err := runtime.ListVolumes(context.Background(), "ij.project=/src/project")
+	Expect(err).To(BeNil())
+	Expect(names).To(Equal([]string{"vol-b", "vol-a"}))
+
+	labels, err := runtime.VolumeLabels(context.Background(), "vol-a")
+	Expect(err).To(BeNil())
+	Expect(labels).To(Equal(map[string]string{"ij.cache": "gomod"}))
+
+	Expect(runtime.RemoveVolume(context.Background(), "vol-a")).To(BeNil())
+
+	history := runner.RunForOutputFunc.History()
+	Expect(history[0].Arg1).To(Equal([]string{
+		"docker", "volume", "create",
+		"--label", "ij.cache=gomod",
+		"--label", "ij.project=/src/project",
+		"vol-a",
+	}))
+	Expect(history[1].Arg1).To(Equal([]string{"docker", "volume", "ls", "-q", "--filter", "label=ij.project=/src/project"}))
+	Expect(history[2].Arg1).To(Equal([]string{"docker", "volume", "inspect", "-f", "{{json .Labels}}", "vol-a"}))
+	Expect(history[3].Arg1).To(Equal([]string{"docker", "volume", "rm", "vol-a"}))
+}
+
+func (s *CLISuite) TestVolumeLabelsMalformed(t sweet.T) {
+	runner := NewMockRunner()
+	runner.RunForOutputFunc.SetDefaultReturn("<no value>\n", "", nil)
+
+	_, err := newCLIRuntime("docker", logging.NilLogger, runner).VolumeLabels(context.Background(), "vol-a")
+	Expect(err).To(MatchError("malformed labels of volume vol-a"))
+}
+
+func (s *CLISuite) TestLogin(t sweet.T) {
+	runner := NewMockRunner()
+	runtime := newCLIRuntime("docker", logging.NilLogger, runner)
+
+	Expect(runtime.Login(context.Background(), "docker.io", "admin", "secret")).To(BeNil())
+	Expect(runner.RunFunc).To(BeCalledOnce())
+	Expect(runner.RunFunc).To(BeCalledWith(BeAnything(), []string{
+		"docker",
+		"login",
+		"-u",
+		"admin",
+		"--password-stdin",
+		"docker.io",
+	}, BeAnything(), BeAnything()))
+
+	stdin := runner.RunFunc.History()[0].Arg2
+	content, _ := ioutil.ReadAll(stdin)
+	Expect(string(content)).To(Equal("secret"))
+}
+
+func (s *CLISuite) TestRunArgs(t sweet.T) {
+	runtime := newCLIRuntime("docker", logging.NilLogger, NewMockRunner())
+
+	args := runtime.RunArgs(&RunOptions{
+		Name:        "c1",
+		Image:       "golang:1.21",
+		Entrypoint:  "/bin/sh",
+		Args:        []string{"/tmp/ij/script"},
+		Env:         []string{"GO=1.21"},
+		Workdir:     "/workspace",
+		Network:     "run",
+		Alias:       "build",
+		Remove:      true,
+		Binds:       []string{"/scratch/workspace:/workspace"},
+		Mounts:      []*Mount{&Mount{Type: "volume", Source: "gomod", Target: "/go/pkg/mod"}},
+		Healthcheck: &Healthcheck{Command: "true", Interval: time.Second, Retries: 3},
+		Memory:      "1g",
+	})
+
+	Expect(args).To(Equal([]string{
+		"docker", "run", "--rm",
+		"--name", "c1",
+		"--entrypoint", "/bin/sh",
+		"-e", "GO=1.21",
+		"--network", "run",
+		"--network-alias", "build",
+		"-v", "/scratch/workspace:/workspace",
+		"--mount", "type=volume,source=gomod,target=/go/pkg/mod",
+		"-w", "/workspace",
+		"--health-cmd", "true",
+		"--health-interval", "1s",
+		"--health-retries", "3",
+		"--memory", "1g",
+		"golang:1.21",
+		"/tmp/ij/script",
+	}))
+
+	args = runtime.RunArgs(&RunOptions{Image: "redis", Detach: true})
+	Expect(args).To(Equal([]string{"docker", "run", "-d", "redis"}))
+}
+
+func (s *CLISuite) TestBuildArgs(t sweet.T) {
+	runtime := newCLIRuntime("docker", logging.NilLogger, NewMockRunner())
+
+	args := runtime.BuildArgs(&BuildOptions{
+		ContextDir: "/workspace",
+		Dockerfile: "Dockerfile.ci",
+		Tags:       []string{"app:1.2", "app:latest"},
+		Labels:     []string{"version=1.2"},
+	})
+
+	Expect(args).To(Equal([]string{
+		"docker", "build",
+		"-f", "Dockerfile.ci",
+		"-t", "app:1.2",
+		"-t", "app:latest",
+		"--label", "version=1.2",
+		"/workspace",
+	}))
+
+	Expect(runtime.PushArgs("app:1.2")).To(Equal([]string{"docker", "push", "app:1.2"}))
+	Expect(runtime.RemoveImageArgs("app:1.2")).To(Equal([]string{"docker", "rmi", "-f", "app:1.2"}))
+}
+
+func (s *CLISuite) TestFormatMount(t sweet.T) {
+	Expect(formatMount(&Mount{Type: "bind", Source: "/etc/ssl/certs", Target: "/certs", ReadOnly: true})).To(Equal("type=bind,source=/etc/ssl/certs,target=/certs,readonly"))
+	Expect(formatMount(&Mount{Type: "volume", Source: "gradle", Target: "/cache"})).To(Equal("type=volume,source=gradle,target=/cache"))
+	Expect(formatMount(&Mount{Type: "tmpfs", Target: "/tmp"})).To(Equal("type=tmpfs,target=/tmp"))
+}
+
+func (s *CLISuite) TestParseContainerState(t sweet.T) {
+	state, err := parseContainerState("running 0 0 starting\n")
+	Expect(err).To(BeNil())
+	Expect(state).To(Equal(&ContainerState{
+		Status:       "running",
+		ExitCode:     0,
+		RestartCount: 0,
+		Health:       "starting",
+	}))
+
+	state, err = parseContainerState("exited 137 2 \n")
+	Expect(err).To(BeNil())
+	Expect(state).To(Equal(&ContainerState{
+		Status:       "exited",
+		ExitCode:     137,
+		RestartCount: 2,
+	}))
+}
+
+func (s *CLISuite) TestParseContainerStateMalformed(t sweet.T) {
+	_, err := parseContainerState("")
+	Expect(err).NotTo(BeNil())
+
+	_, err = parseContainerState("running zero 0")
+	Expect(err).NotTo(BeNil())
+}
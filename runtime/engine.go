@@ -0,0 +1,355 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ij-build/ij/logging"
+)
+
+type (
+	// engineRuntime performs operations through the Engine API of the
+	// Docker daemon. Operations which have no native implementation are
+	// performed through the embedded command line runtime.
+	engineRuntime struct {
+		*cliRuntime
+		client *http.Client
+		logger logging.Logger
+	}
+
+	engineContainer struct {
+		State struct {
+			Status   string
+			ExitCode int
+			Health   *struct {
+				Status string
+			}
+		}
+		RestartCount int
+		Config       struct {
+			Healthcheck *json.RawMessage
+		}
+	}
+
+	engineImage struct {
+		ID string `json:"Id"`
+	}
+
+	engineVolume struct {
+		Name   string
+		Labels map[string]string
+	}
+
+	engineVolumeList struct {
+		Volumes []*engineVolume
+	}
+
+	engineError struct {
+		Message string `json:"message"`
+	}
+
+	// EngineError is an error response of the Engine API.
+	EngineError struct {
+		StatusCode int
+		Message    string
+	}
+)
+
+// EngineAPIVersion is the version of the Engine API requested by ij. This
+// is supported by every daemon since Docker 1.13.
+const EngineAPIVersion = "v1.25"
+
+func (e *EngineError) Error() string {
+	return e.Message
+}
+
+func newEngineRuntime(
+	socketPath string,
+	cli *cliRuntime,
+	logger logging.Logger,
+) *engineRuntime {
+	dialer := &net.Dialer{}
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}
+
+	return &engineRuntime{
+		cliRuntime: cli,
+		client:     &http.Client{Transport: transport},
+		logger:     logger,
+	}
+}
+
+func (r *engineRuntime) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, PingTimeout)
+	defer cancel()
+
+	return r.do(ctx, "GET", "/_ping", nil, nil, nil)
+}
+
+func (r *engineRuntime) Inspect(ctx context.Context, containerName string) (*ContainerState, error) {
+	container, err := r.inspectContainer(ctx, containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &ContainerState{
+		Status:       container.State.Status,
+		ExitCode:     container.State.ExitCode,
+		RestartCount: container.RestartCount,
+	}
+
+	if container.State.Health != nil {
+		state.Health = container.State.Health.Status
+	}
+
+	return state, nil
+}
+
+func (r *engineRuntime) HasHealthcheck(ctx context.Context, containerName string) (bool, error) {
+	container, err := r.inspectContainer(ctx, containerName)
+	if err != nil {
+		return false, err
+	}
+
+	return container.Config.Healthcheck != nil, nil
+}
+
+func (r *engineRuntime) ImageID(ctx context.Context, image string) (string, error) {
+	payload := &engineImage{}
+	if err := r.do(ctx, "GET", "/images/"+image+"/json", nil, nil, payload); err != nil {
+		return "", err
+	}
+
+	return payload.ID, nil
+}
+
+func (r *engineRuntime) Kill(ctx context.Context, containerName string) error {
+	return r.do(ctx, "POST", "/containers/"+containerName+"/kill", nil, nil, nil)
+}
+
+func (r *engineRuntime) Remove(ctx context.Context, containerName string) error {
+	query := url.Values{"force": []string{"1"}}
+	return r.do(ctx, "DELETE", "/containers/"+containerName, query, nil, nil)
+}
+
+func (r *engineRuntime) Commit(ctx context.Context, containerName string) (string, error) {
+	query := url.Values{"container": []string{containerName}}
+
+	payload := &engineImage{}
+	if err := r.do(ctx, "POST", "/commit", query, nil, payload); err != nil {
+		return "", err
+	}
+
+	return payload.ID, nil
+}
+
+func (r *engineRuntime) RemoveImage(ctx context.Context, image string) error {
+	query := url.Values{"force": []string{"1"}}
+	return r.do(ctx, "DELETE", "/images/"+image, query, nil, nil)
+}
+
+func (r *engineRuntime) CreateVolume(ctx context.Context, name string, labels map[string]string) error {
+	body := &engineVolume{
+		Name:   name,
+		Labels: labels,
+	}
+
+	return r.do(ctx, "POST", "/volumes/create", nil, body, nil)
+}
+
+func (r *engineRuntime) VolumeLabels(ctx context.Context, name string) (map[string]string, error) {
+	payload := &engineVolume{}
+	if err := r.do(ctx, "GET", "/volumes/"+name, nil, nil, payload); err != nil {
+		return nil, err
+	}
+
+	if payload.Labels == nil {
+		return map[string]string{}, nil
+	}
+
+	return payload.Labels, nil
+}
+
+func (r *engineRuntime) ListVolumes(ctx context.Context, label string) ([]string, error) {
+	filters, err := json.Marshal(map[string][]string{"label": []string{label}})
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"filters": []string{string(filters)}}
+
+	payload := &engineVolumeList{}
+	if err := r.do(ctx, "GET", "/volumes", query, nil, payload); err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, volume := range payload.Volumes {
+		names = append(names, volume.Name)
+	}
+
+	return names, nil
+}
+
+func (r *engineRuntime) RemoveVolume(ctx context.Context, name string) error {
+	return r.do(ctx, "DELETE", "/volumes/"+name, nil, nil, nil)
+}
+
+func (r *engineRuntime) CreateNetwork(ctx context.Context, name string) error {
+	body := map[string]interface{}{
+		"Name":           name,
+		"CheckDuplicate": true,
+	}
+
+	return r.do(ctx, "POST", "/networks/create", nil, body, nil)
+}
+
+func (r *engineRuntime) RemoveNetwork(ctx context.Context, name string) error {
+	return r.do(ctx, "DELETE", "/networks/"+name, nil, nil, nil)
+}
+
+func (r *engineRuntime) DisconnectNetwork(ctx context.Context, name, containerName string) error {
+	body := map[string]interface{}{
+		"Container": containerName,
+		"Force":     true,
+	}
+
+	return r.do(ctx, "POST", "/networks/"+name+"/disconnect", nil, body, nil)
+}
+
+func (r *engineRuntime) inspectContainer(ctx context.Context, containerName string) (*engineContainer, error) {
+	payload := &engineContainer{}
+	if err := r.do(ctx, "GET", "/containers/"+containerName+"/json", nil, nil, payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// do performs a request against the Engine API. The body, if non-nil, is
+// serialized as JSON. The response is deserialized into the given payload,
+// if non-nil. A response with an error status is converted into an error
+// carrying the message given by the daemon.
+func (r *engineRuntime) do(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	body interface{},
+	payload interface{},
+) error {
+	var (
+		reader      io.Reader
+		contentType string
+	)
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	resp, err := r.send(ctx, method, path, query, contentType, reader, nil)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if payload == nil {
+		_, err := io.Copy(ioutil.Discard, resp.Body)
+		return err
+	}
+
+	return json.NewDecoder(resp.Body).Decode(payload)
+}
+
+// send performs a request against the Engine API with the given raw body
+// and returns the response, whose body must be closed by the caller. A
+// response with an error status is converted into an *EngineError.
+func (r *engineRuntime) send(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	contentType string,
+	body io.Reader,
+	header http.Header,
+) (*http.Response, error) {
+	if r.logger != nil {
+		r.logger.Debug(
+			nil,
+			"Requesting %s %s",
+			method,
+			path,
+		)
+	}
+
+	u := &url.URL{
+		Scheme:   "http",
+		Host:     "docker",
+		Path:     "/" + EngineAPIVersion + path,
+		RawQuery: query.Encode(),
+	}
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, readEngineError(resp)
+	}
+
+	return resp, nil
+}
+
+//
+// Helpers
+
+func readEngineError(resp *http.Response) error {
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	payload := &engineError{}
+	if err := json.Unmarshal(data, payload); err != nil || payload.Message == "" {
+		payload.Message = fmt.Sprintf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	return &EngineError{
+		StatusCode: resp.StatusCode,
+		Message:    payload.Message,
+	}
+}
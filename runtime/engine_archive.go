@@ -0,0 +1,152 @@
+package runtime
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// CopyTo extracts a tar archive into a directory of a container through the
+// Engine API.
+func (r *engineRuntime) CopyTo(ctx context.Context, containerName, dest string, archive io.Reader) error {
+	query := url.Values{
+		"path":       []string{dest},
+		"copyUIDGID": []string{"1"},
+	}
+
+	resp, err := r.send(ctx, "PUT", "/containers/"+containerName+"/archive", query, "application/x-tar", archive, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// CopyFrom requests an archive of a path of a container through the Engine
+// API and extracts it into the given host directory. The entries of the
+// archive are rooted at the base name of the path, which is stripped when
+// copying the contents of a directory.
+func (r *engineRuntime) CopyFrom(ctx context.Context, containerName, src, dest string) error {
+	contents := strings.HasSuffix(src, "/.")
+	if contents {
+		src = strings.TrimSuffix(src, "/.")
+	}
+
+	query := url.Values{"path": []string{src}}
+
+	resp, err := r.send(ctx, "GET", "/containers/"+containerName+"/archive", query, "", nil, nil)
+	if err != nil {
+		if isMissingArchivePath(err) {
+			return ErrNoSuchPath
+		}
+
+		return err
+	}
+
+	defer resp.Body.Close()
+	return extractArchive(resp.Body, dest, contents)
+}
+
+//
+// Helpers
+
+// extractArchive writes the directories, regular files, and symlinks of the
+// given archive into the given directory. If contents is set, the top-level
+// entry of the archive is stripped so that only its contents are written.
+func extractArchive(r io.Reader, dest string, contents bool) error {
+	reader := tar.NewReader(r)
+
+	for {
+		header, err := reader.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "/"))
+
+		if contents {
+			parts := strings.SplitN(name, "/", 2)
+			if len(parts) < 2 {
+				continue
+			}
+
+			name = parts[1]
+		}
+
+		if name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("illegal path %s in archive", header.Name)
+		}
+
+		if err := extractEntry(reader, header, filepath.Join(dest, filepath.FromSlash(name))); err != nil {
+			return err
+		}
+	}
+}
+
+func extractEntry(reader *tar.Reader, header *tar.Header, target string) error {
+	mode := os.FileMode(header.Mode).Perm()
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		if err := os.MkdirAll(target, mode); err != nil {
+			return err
+		}
+
+		return os.Chmod(target, mode)
+
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(file, reader); err != nil {
+			file.Close()
+			return err
+		}
+
+		if err := file.Close(); err != nil {
+			return err
+		}
+
+		return os.Chmod(target, mode)
+
+	case tar.TypeSymlink:
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return os.Symlink(header.Linkname, target)
+	}
+
+	return nil
+}
+
+// isMissingArchivePath determines if an archive request failed because the
+// path, rather than the container, does not exist.
+func isMissingArchivePath(err error) bool {
+	engineErr, ok := err.(*EngineError)
+	if !ok || engineErr.StatusCode != 404 {
+		return false
+	}
+
+	return !strings.Contains(strings.ToLower(engineErr.Message), "no such container")
+}
@@ -0,0 +1,133 @@
+package runtime
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type EngineArchiveSuite struct{}
+
+func (s *EngineArchiveSuite) TestCopyTo(t sweet.T) {
+	var method, path, copyUIDGID, contentType string
+	var body []byte
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/containers/c1/archive", func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.Query().Get("path")
+		copyUIDGID = r.URL.Query().Get("copyUIDGID")
+		contentType = r.Header.Get("Content-Type")
+		body, _ = ioutil.ReadAll(r.Body)
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	archive := testArchive(map[string]string{"bin/app": "app"})
+	Expect(runtime.CopyTo(context.Background(), "c1", "/workspace", bytes.NewReader(archive))).To(BeNil())
+	Expect(method).To(Equal("PUT"))
+	Expect(path).To(Equal("/workspace"))
+	Expect(copyUIDGID).To(Equal("1"))
+	Expect(contentType).To(Equal("application/x-tar"))
+	Expect(body).To(Equal(archive))
+}
+
+func (s *EngineArchiveSuite) TestCopyFrom(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	requested := []string{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/containers/c1/archive", func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Query().Get("path")
+		requested = append(requested, path)
+
+		switch path {
+		case "/workspace":
+			w.Write(testArchive(map[string]string{
+				"workspace/":          "",
+				"workspace/README.md": "readme",
+			}))
+
+		case "/workspace/dist/linux":
+			w.Write(testArchive(map[string]string{
+				"linux/":    "",
+				"linux/app": "app",
+			}))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Could not find the file /workspace/missing in container c1"}`))
+		}
+	})
+
+	mux.HandleFunc("/v1.25/containers/c2/archive", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "No such container: c2"}`))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	Expect(runtime.CopyFrom(context.Background(), "c1", "/workspace/.", name)).To(BeNil())
+	Expect(runtime.CopyFrom(context.Background(), "c1", "/workspace/dist/linux", filepath.Join(name, "dist"))).To(BeNil())
+	Expect(runtime.CopyFrom(context.Background(), "c1", "/workspace/missing", name)).To(Equal(ErrNoSuchPath))
+	Expect(runtime.CopyFrom(context.Background(), "c2", "/workspace/missing", name)).To(MatchError("No such container: c2"))
+	Expect(requested).To(Equal([]string{"/workspace", "/workspace/dist/linux", "/workspace/missing"}))
+
+	content, err := ioutil.ReadFile(filepath.Join(name, "README.md"))
+	Expect(err).To(BeNil())
+	Expect(string(content)).To(Equal("readme"))
+
+	content, err = ioutil.ReadFile(filepath.Join(name, "dist", "linux", "app"))
+	Expect(err).To(BeNil())
+	Expect(string(content)).To(Equal("app"))
+}
+
+func (s *EngineArchiveSuite) TestExtractArchiveIllegalPath(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	archive := testArchive(map[string]string{"../escape": "oops"})
+	Expect(extractArchive(bytes.NewReader(archive), name, false)).To(MatchError("illegal path ../escape in archive"))
+}
+
+//
+// Helpers
+
+// testArchive creates a tar archive of the given entries. Names ending with
+// a slash are directories. Entries are written in order of their names.
+func testArchive(entries map[string]string) []byte {
+	names := []string{}
+	for name := range entries {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	buffer := &bytes.Buffer{}
+	writer := tar.NewWriter(buffer)
+
+	for _, name := range names {
+		if name[len(name)-1] == '/' {
+			writer.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeDir, Mode: 0755})
+			continue
+		}
+
+		writer.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(entries[name]))})
+		writer.Write([]byte(entries[name]))
+	}
+
+	writer.Close()
+	return buffer.Bytes()
+}
@@ -0,0 +1,311 @@
+package runtime
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-zglob"
+
+	"github.com/ij-build/ij/logging"
+)
+
+// externalDockerfileName is the name under which a dockerfile outside of
+// the build context is added to the context.
+const externalDockerfileName = ".ij-dockerfile"
+
+// Build builds an image through the Engine API from an archive of the context
+// directory which excludes the paths matched by its .dockerignore file. The
+// builder of the Engine API does not support BuildKit features, so an image
+// whose dockerfile declares a syntax directive, which names a target stage,
+// or which is built while DOCKER_BUILDKIT=1 is set is built with the command
+// line client instead.
+func (r *engineRuntime) Build(ctx context.Context, options *BuildOptions, logger logging.Logger, prefix *logging.Prefix) error {
+	contextDir, err := filepath.Abs(options.ContextDir)
+	if err != nil {
+		return err
+	}
+
+	dockerfile := filepath.Join(contextDir, "Dockerfile")
+	if options.Dockerfile != "" {
+		if dockerfile, err = filepath.Abs(options.Dockerfile); err != nil {
+			return err
+		}
+	}
+
+	if os.Getenv("DOCKER_BUILDKIT") == "1" || options.Target != "" || hasSyntaxDirective(dockerfile) {
+		if r.logger != nil {
+			r.logger.Debug(
+				prefix,
+				"Building image with the command line client",
+			)
+		}
+
+		return r.cliRuntime.Build(ctx, options, logger, prefix)
+	}
+
+	header, err := r.registryConfigHeader(ctx)
+	if err != nil {
+		return err
+	}
+
+	dockerfileName, external := contextPath(contextDir, dockerfile)
+	if external {
+		dockerfileName = externalDockerfileName
+	}
+
+	labels := map[string]string{}
+	for _, label := range options.Labels {
+		parts := strings.SplitN(label, "=", 2)
+		labels[parts[0]] = strings.Join(parts[1:], "")
+	}
+
+	serializedLabels, err := json.Marshal(labels)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{
+		"dockerfile": []string{dockerfileName},
+		"labels":     []string{string(serializedLabels)},
+		"rm":         []string{"1"},
+		"t":          options.Tags,
+	}
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+
+	go func() {
+		archive := &contextArchive{
+			root:           contextDir,
+			dockerfileName: dockerfileName,
+		}
+
+		if external {
+			archive.dockerfile = dockerfile
+		}
+
+		writer.CloseWithError(archive.write(writer))
+	}()
+
+	resp, err := r.send(ctx, "POST", "/build", query, "application/x-tar", reader, header)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	return readEngineMessages(resp.Body, logger, prefix)
+}
+
+type contextArchive struct {
+	root           string
+	dockerfile     string
+	dockerfileName string
+	excludes       []string
+}
+
+// write writes a tar archive of the build context. The dockerfile and the
+// .dockerignore file are always included. A dockerfile outside of the context
+// is added under the name of the dockerfile.
+func (a *contextArchive) write(w io.Writer) error {
+	excludes, err := readDockerignore(a.root)
+	if err != nil {
+		return err
+	}
+
+	a.excludes = excludes
+	tw := tar.NewWriter(w)
+
+	if err := filepath.Walk(a.root, a.walk(tw)); err != nil {
+		return err
+	}
+
+	if a.dockerfile != "" {
+		info, err := os.Stat(a.dockerfile)
+		if err != nil {
+			return err
+		}
+
+		if err := writeArchiveEntry(tw, a.dockerfile, a.dockerfileName, info); err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+func (a *contextArchive) walk(tw *tar.Writer) filepath.WalkFunc {
+	return func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(a.root, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if rel != a.dockerfileName && rel != ".dockerignore" && isExcluded(rel, a.excludes) {
+			if info.IsDir() && !hasExceptions(a.excludes) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		return writeArchiveEntry(tw, path, rel, info)
+	}
+}
+
+//
+// Helpers
+
+// hasSyntaxDirective determines if the given dockerfile selects a frontend
+// with a syntax parser directive, which requires BuildKit.
+func hasSyntaxDirective(dockerfile string) bool {
+	file, err := os.Open(dockerfile)
+	if err != nil {
+		return false
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "#") {
+			return false
+		}
+
+		directive := strings.SplitN(strings.TrimSpace(strings.TrimPrefix(line, "#")), "=", 2)
+		if len(directive) == 2 && strings.ToLower(strings.TrimSpace(directive[0])) == "syntax" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// contextPath returns the slash-separated path of the given file relative to
+// the context directory and whether the file lies outside of it.
+func contextPath(contextDir, path string) (string, bool) {
+	rel, err := filepath.Rel(contextDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", true
+	}
+
+	return filepath.ToSlash(rel), false
+}
+
+// readDockerignore returns the patterns of the .dockerignore file of the
+// given context directory.
+func readDockerignore(contextDir string) ([]string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(contextDir, ".dockerignore"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	patterns := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		exception := strings.HasPrefix(line, "!")
+		line = strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(line, "!")), "/")
+
+		if exception {
+			line = "!" + line
+		}
+
+		patterns = append(patterns, line)
+	}
+
+	return patterns, nil
+}
+
+// isExcluded determines if the given path is matched by the patterns of a
+// .dockerignore file. A pattern also matches every path beneath a matching
+// directory, and the last matching pattern decides.
+func isExcluded(rel string, patterns []string) bool {
+	excluded := false
+
+	for _, pattern := range patterns {
+		exception := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+
+		for candidate := rel; candidate != "."; candidate = path.Dir(candidate) {
+			if ok, _ := zglob.Match(pattern, candidate); ok {
+				excluded = !exception
+				break
+			}
+		}
+	}
+
+	return excluded
+}
+
+func hasExceptions(patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeArchiveEntry(tw *tar.Writer, path, name string, info os.FileInfo) error {
+	link := ""
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+
+		link = target
+	}
+
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	_, err = io.Copy(tw, file)
+	return err
+}
@@ -0,0 +1,209 @@
+package runtime
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/aphistic/sweet"
+	. "github.com/efritz/go-mockgen/matchers"
+	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/logging"
+	. "github.com/onsi/gomega"
+)
+
+type EngineBuildSuite struct{}
+
+func (s *EngineBuildSuite) TestBuild(t sweet.T) {
+	defer testDockerConfig(`{}`)()
+
+	dir, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(dir)
+
+	testWriteFiles(dir, map[string]string{
+		"Dockerfile":          "FROM alpine\nCOPY . /app\n",
+		".dockerignore":       "# logs\n*.log\n!keep.log\nnode_modules\n",
+		"main.go":             "package main",
+		"debug.log":           "",
+		"keep.log":            "",
+		"node_modules/x/x.js": "",
+		"pkg/util.go":         "package pkg",
+	})
+
+	var (
+		query   url.Values
+		entries []string
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/build", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		entries = testReadArchive(r.Body)
+
+		w.Write([]byte(`{"stream": "Step 1/2 : FROM alpine\n"}`))
+		w.Write([]byte(`{"stream": " ---> abcdef\n"}`))
+		w.Write([]byte(`{"aux": {"ID": "sha256:abcdef"}}`))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	var (
+		logger = NewMockLogger()
+		prefix = logging.NewPrefix("task")
+	)
+
+	err := runtime.Build(context.Background(), &BuildOptions{
+		ContextDir: dir,
+		Tags:       []string{"app:1.2", "app:latest"},
+		Labels:     []string{"version=1.2"},
+	}, logger, prefix)
+
+	Expect(err).To(BeNil())
+	Expect(query["t"]).To(Equal([]string{"app:1.2", "app:latest"}))
+	Expect(query.Get("dockerfile")).To(Equal("Dockerfile"))
+	Expect(query.Get("labels")).To(MatchJSON(`{"version": "1.2"}`))
+	Expect(entries).To(Equal([]string{
+		".dockerignore",
+		"Dockerfile",
+		"keep.log",
+		"main.go",
+		"pkg/",
+		"pkg/util.go",
+	}))
+
+	Expect(logger.InfoFunc).To(BeCalledN(2))
+	Expect(logger.InfoFunc).To(BeCalledWith(prefix, "%s", "Step 1/2 : FROM alpine"))
+}
+
+func (s *EngineBuildSuite) TestBuildExternalDockerfile(t sweet.T) {
+	defer testDockerConfig(`{}`)()
+
+	dir, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(dir)
+
+	testWriteFiles(dir, map[string]string{
+		"docker/Dockerfile": "FROM alpine\n",
+		"context/main.go":   "package main",
+	})
+
+	var (
+		dockerfile string
+		entries    []string
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/build", func(w http.ResponseWriter, r *http.Request) {
+		dockerfile = r.URL.Query().Get("dockerfile")
+		entries = testReadArchive(r.Body)
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	err := runtime.Build(context.Background(), &BuildOptions{
+		ContextDir: filepath.Join(dir, "context"),
+		Dockerfile: filepath.Join(dir, "docker", "Dockerfile"),
+	}, logging.NilLogger, nil)
+
+	Expect(err).To(BeNil())
+	Expect(dockerfile).To(Equal(externalDockerfileName))
+	Expect(entries).To(Equal([]string{externalDockerfileName, "main.go"}))
+}
+
+func (s *EngineBuildSuite) TestBuildError(t sweet.T) {
+	defer testDockerConfig(`{}`)()
+
+	dir, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(dir)
+
+	testWriteFiles(dir, map[string]string{"Dockerfile": "FROM alpine\nRUN false\n"})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/build", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"stream": "Step 2/2 : RUN false\n"}`))
+		w.Write([]byte(`{"error": "The command '/bin/sh -c false' returned a non-zero code: 1"}`))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	err := runtime.Build(context.Background(), &BuildOptions{ContextDir: dir}, logging.NilLogger, nil)
+	Expect(err).To(MatchError("The command '/bin/sh -c false' returned a non-zero code: 1"))
+}
+
+func (s *EngineBuildSuite) TestBuildKitUsesCLI(t sweet.T) {
+	dir, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(dir)
+
+	testWriteFiles(dir, map[string]string{
+		"Dockerfile":       "FROM alpine\n",
+		"Dockerfile.mount": "# syntax=docker/dockerfile:1\nFROM alpine\nRUN --mount=type=cache,target=/root true\n",
+	})
+
+	runtime, teardown := testEngineRuntime(http.NewServeMux())
+	defer teardown()
+
+	runner := NewMockRunner()
+	runtime.runnerFactory = func(logging.Logger) command.Runner { return runner }
+
+	Expect(runtime.Build(context.Background(), &BuildOptions{
+		ContextDir: dir,
+		Target:     "test",
+	}, logging.NilLogger, nil)).To(BeNil())
+
+	Expect(runtime.Build(context.Background(), &BuildOptions{
+		ContextDir: dir,
+		Dockerfile: filepath.Join(dir, "Dockerfile.mount"),
+	}, logging.NilLogger, nil)).To(BeNil())
+
+	Expect(runner.RunFunc).To(BeCalledN(2))
+	Expect(runner.RunFunc.History()[0].Arg1).To(ContainElement("--target"))
+}
+
+func (s *EngineBuildSuite) TestIsExcluded(t sweet.T) {
+	patterns := []string{"**/*.tmp", "build", "!build/keep", "docs/*.md"}
+
+	Expect(isExcluded("a.tmp", patterns)).To(BeTrue())
+	Expect(isExcluded("src/a.tmp", patterns)).To(BeTrue())
+	Expect(isExcluded("build/out/app", patterns)).To(BeTrue())
+	Expect(isExcluded("build/keep", patterns)).To(BeFalse())
+	Expect(isExcluded("docs/index.md", patterns)).To(BeTrue())
+	Expect(isExcluded("docs/api/index.md", patterns)).To(BeFalse())
+	Expect(isExcluded("src/main.go", patterns)).To(BeFalse())
+}
+
+//
+// Helpers
+
+func testWriteFiles(dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(BeNil())
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(BeNil())
+	}
+}
+
+func testReadArchive(r io.Reader) []string {
+	names := []string{}
+	reader := tar.NewReader(r)
+
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		Expect(err).To(BeNil())
+		names = append(names, header.Name)
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,158 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/ij-build/ij/logging"
+)
+
+type (
+	// engineMessage is an entry of the progress stream returned by the
+	// pull, push, and build endpoints.
+	engineMessage struct {
+		Stream   string `json:"stream"`
+		Status   string `json:"status"`
+		ID       string `json:"id"`
+		Progress string `json:"progress"`
+		Error    string `json:"error"`
+	}
+
+	// lineWriter invokes a function for each complete line written to it.
+	lineWriter struct {
+		buffer []byte
+		emit   func(line string)
+	}
+)
+
+// Push pushes an image through the Engine API with the credentials stored
+// by the command line client for its registry.
+func (r *engineRuntime) Push(ctx context.Context, image string, logger logging.Logger, prefix *logging.Prefix) error {
+	name, tag := splitImageTag(image)
+
+	header, err := r.registryAuthHeader(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.send(ctx, "POST", "/images/"+name+"/push", url.Values{"tag": []string{tag}}, "", nil, header)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	return readEngineMessages(resp.Body, logger, prefix)
+}
+
+// pull pulls an image through the Engine API with the credentials stored
+// by the command line client for its registry.
+func (r *engineRuntime) pull(ctx context.Context, image string, logger logging.Logger, prefix *logging.Prefix) error {
+	name, tag := splitImageTag(image)
+
+	header, err := r.registryAuthHeader(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{
+		"fromImage": []string{name},
+		"tag":       []string{tag},
+	}
+
+	resp, err := r.send(ctx, "POST", "/images/create", query, "", nil, header)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	return readEngineMessages(resp.Body, logger, prefix)
+}
+
+//
+// Helpers
+
+// readEngineMessages writes the output of a progress stream to the given
+// logger. Progress bars are omitted. An error in the stream is returned.
+func readEngineMessages(r io.Reader, logger logging.Logger, prefix *logging.Prefix) error {
+	stream := newLineWriter(func(line string) { logger.Info(prefix, "%s", line) })
+	defer stream.Flush()
+
+	decoder := json.NewDecoder(r)
+
+	for {
+		message := &engineMessage{}
+		if err := decoder.Decode(message); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		if message.Error != "" {
+			return errors.New(message.Error)
+		}
+
+		if message.Stream != "" {
+			stream.Write([]byte(message.Stream))
+			continue
+		}
+
+		if message.Status == "" || message.Progress != "" {
+			continue
+		}
+
+		if message.ID != "" {
+			logger.Info(prefix, "%s: %s", message.ID, message.Status)
+		} else {
+			logger.Info(prefix, "%s", message.Status)
+		}
+	}
+}
+
+// splitImageTag splits an image reference into its repository and its tag
+// or digest. A reference without either refers to the latest tag.
+func splitImageTag(image string) (string, string) {
+	if index := strings.LastIndex(image, "@"); index >= 0 {
+		return image[:index], image[index+1:]
+	}
+
+	if index := strings.LastIndex(image, ":"); index > strings.LastIndex(image, "/") {
+		return image[:index], image[index+1:]
+	}
+
+	return image, "latest"
+}
+
+func newLineWriter(emit func(line string)) *lineWriter {
+	return &lineWriter{emit: emit}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+
+	for {
+		index := bytes.IndexByte(w.buffer, '\n')
+		if index < 0 {
+			break
+		}
+
+		w.emit(strings.TrimSuffix(string(w.buffer[:index]), "\r"))
+		w.buffer = w.buffer[index+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush emits the trailing partial line, if any.
+func (w *lineWriter) Flush() {
+	if len(w.buffer) > 0 {
+		w.emit(string(w.buffer))
+		w.buffer = nil
+	}
+}
@@ -0,0 +1,147 @@
+package runtime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/aphistic/sweet"
+	. "github.com/efritz/go-mockgen/matchers"
+	"github.com/ij-build/ij/logging"
+	. "github.com/onsi/gomega"
+)
+
+type EngineImageSuite struct{}
+
+func (s *EngineImageSuite) TestPush(t sweet.T) {
+	defer testDockerConfig(`{"auths": {"registry.example.com": {"auth": "YWRtaW46c2VjcmV0"}}}`)()
+
+	var (
+		tag  string
+		auth = &registryAuth{}
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/images/registry.example.com/app/push", func(w http.ResponseWriter, r *http.Request) {
+		tag = r.URL.Query().Get("tag")
+		data, _ := base64.URLEncoding.DecodeString(r.Header.Get("X-Registry-Auth"))
+		json.Unmarshal(data, auth)
+
+		w.Write([]byte(`{"status": "Preparing", "id": "abc"}`))
+		w.Write([]byte(`{"status": "Pushing", "id": "abc", "progress": "[==>   ]"}`))
+		w.Write([]byte(`{"status": "1.2: digest: sha256:abcdef size: 528"}`))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	var (
+		logger = NewMockLogger()
+		prefix = logging.NewPrefix("task")
+	)
+
+	Expect(runtime.Push(context.Background(), "registry.example.com/app:1.2", logger, prefix)).To(BeNil())
+	Expect(tag).To(Equal("1.2"))
+	Expect(auth).To(Equal(&registryAuth{
+		Username:      "admin",
+		Password:      "secret",
+		ServerAddress: "registry.example.com",
+	}))
+
+	Expect(logger.InfoFunc).To(BeCalledN(2))
+	Expect(logger.InfoFunc).To(BeCalledWith(prefix, "%s: %s", "abc", "Preparing"))
+	Expect(logger.InfoFunc).To(BeCalledWith(prefix, "%s", "1.2: digest: sha256:abcdef size: 528"))
+}
+
+func (s *EngineImageSuite) TestPushError(t sweet.T) {
+	defer testDockerConfig(`{}`)()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/images/app/push", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "The push refers to repository [docker.io/library/app]"}`))
+		w.Write([]byte(`{"error": "denied: requested access to the resource is denied"}`))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	err := runtime.Push(context.Background(), "app", logging.NilLogger, nil)
+	Expect(err).To(MatchError("denied: requested access to the resource is denied"))
+}
+
+func (s *EngineImageSuite) TestRegistryAuthCredentialHelper(t sweet.T) {
+	defer testDockerConfig(`{"credsStore": "desktop", "credHelpers": {"gcr.io": "gcloud"}}`)()
+
+	runtime, teardown := testEngineRuntime(http.NewServeMux())
+	defer teardown()
+
+	runner := runtime.runner.(*MockRunner)
+	runner.RunForOutputFunc.SetDefaultReturn(`{"Username": "admin", "Secret": "secret"}`, "", nil)
+
+	config, err := readDockerConfig()
+	Expect(err).To(BeNil())
+
+	auth, err := runtime.registryAuth(context.Background(), config, "gcr.io")
+	Expect(err).To(BeNil())
+	Expect(auth).To(Equal(&registryAuth{Username: "admin", Password: "secret", ServerAddress: "gcr.io"}))
+	Expect(runner.RunForOutputFunc.History()[0].Arg1).To(Equal([]string{"docker-credential-gcloud", "get"}))
+
+	_, err = runtime.registryAuth(context.Background(), config, DefaultRegistry)
+	Expect(err).To(BeNil())
+	Expect(runner.RunForOutputFunc.History()[1].Arg1).To(Equal([]string{"docker-credential-desktop", "get"}))
+}
+
+func (s *EngineImageSuite) TestRegistryServer(t sweet.T) {
+	Expect(registryServer("app")).To(Equal(DefaultRegistry))
+	Expect(registryServer("library/app")).To(Equal(DefaultRegistry))
+	Expect(registryServer("registry.example.com/app")).To(Equal("registry.example.com"))
+	Expect(registryServer("localhost:5000/app")).To(Equal("localhost:5000"))
+	Expect(registryServer("localhost/app")).To(Equal("localhost"))
+}
+
+func (s *EngineImageSuite) TestSplitImageTag(t sweet.T) {
+	for image, expected := range map[string][]string{
+		"app":                              []string{"app", "latest"},
+		"app:1.2":                          []string{"app", "1.2"},
+		"localhost:5000/app":               []string{"localhost:5000/app", "latest"},
+		"localhost:5000/app:1.2":           []string{"localhost:5000/app", "1.2"},
+		"registry.example.com/app@sha256:": []string{"registry.example.com/app", "sha256:"},
+	} {
+		name, tag := splitImageTag(image)
+		Expect([]string{name, tag}).To(Equal(expected))
+	}
+}
+
+func (s *EngineImageSuite) TestLineWriter(t sweet.T) {
+	lines := []string{}
+	writer := newLineWriter(func(line string) { lines = append(lines, line) })
+
+	writer.Write([]byte("a\r\nb"))
+	writer.Write([]byte("c\n\nd"))
+	Expect(lines).To(Equal([]string{"a", "bc", ""}))
+
+	writer.Flush()
+	Expect(lines).To(Equal([]string{"a", "bc", "", "d"}))
+}
+
+//
+// Helpers
+
+// testDockerConfig points the config directory of the command line client
+// to a temporary directory holding the given config file.
+func testDockerConfig(content string) func() {
+	name, _ := ioutil.TempDir("", "ij-test")
+	Expect(ioutil.WriteFile(filepath.Join(name, "config.json"), []byte(content), 0644)).To(BeNil())
+
+	previous := os.Getenv("DOCKER_CONFIG")
+	os.Setenv("DOCKER_CONFIG", name)
+
+	return func() {
+		os.Setenv("DOCKER_CONFIG", previous)
+		os.RemoveAll(name)
+	}
+}
@@ -0,0 +1,679 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ij-build/ij/logging"
+)
+
+type (
+	engineCreateRequest struct {
+		Image            string
+		Entrypoint       []string                `json:",omitempty"`
+		Cmd              []string                `json:",omitempty"`
+		Env              []string                `json:",omitempty"`
+		WorkingDir       string                  `json:",omitempty"`
+		User             string                  `json:",omitempty"`
+		ExposedPorts     map[string]struct{}     `json:",omitempty"`
+		Healthcheck      *engineHealthcheck      `json:",omitempty"`
+		HostConfig       *engineHostConfig       `json:",omitempty"`
+		NetworkingConfig *engineNetworkingConfig `json:",omitempty"`
+	}
+
+	engineHealthcheck struct {
+		Test     []string `json:",omitempty"`
+		Interval int64    `json:",omitempty"`
+		Timeout  int64    `json:",omitempty"`
+		Retries  int      `json:",omitempty"`
+	}
+
+	engineHostConfig struct {
+		Binds        []string                        `json:",omitempty"`
+		Mounts       []*engineMount                  `json:",omitempty"`
+		VolumesFrom  []string                        `json:",omitempty"`
+		NetworkMode  string                          `json:",omitempty"`
+		PortBindings map[string][]*enginePortBinding `json:",omitempty"`
+		CPUShares    int64                           `json:"CpuShares,omitempty"`
+		Memory       int64                           `json:",omitempty"`
+		ShmSize      int64                           `json:",omitempty"`
+		Privileged   bool                            `json:",omitempty"`
+		Ulimits      []*engineUlimit                 `json:",omitempty"`
+		CapAdd       []string                        `json:",omitempty"`
+		Tmpfs        map[string]string               `json:",omitempty"`
+		Devices      []*engineDevice                 `json:",omitempty"`
+	}
+
+	engineMount struct {
+		Type     string
+		Source   string `json:",omitempty"`
+		Target   string
+		ReadOnly bool `json:",omitempty"`
+	}
+
+	enginePortBinding struct {
+		HostIP   string `json:"HostIp"`
+		HostPort string
+	}
+
+	engineUlimit struct {
+		Name string
+		Soft int64
+		Hard int64
+	}
+
+	engineDevice struct {
+		PathOnHost        string
+		PathInContainer   string
+		CgroupPermissions string
+	}
+
+	engineNetworkingConfig struct {
+		EndpointsConfig map[string]*engineEndpoint
+	}
+
+	engineEndpoint struct {
+		Aliases []string `json:",omitempty"`
+	}
+
+	engineCreated struct {
+		ID string `json:"Id"`
+	}
+
+	engineWaited struct {
+		StatusCode int
+	}
+
+	engineExec struct {
+		ExitCode int
+	}
+
+	// unsupportedOptionError is returned when a container cannot be created
+	// through the Engine API, in which case it is run with the command line
+	// client instead.
+	unsupportedOptionError struct {
+		option string
+		value  string
+	}
+)
+
+var sizePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)([kmgtp]?)b?$`)
+
+func (e *unsupportedOptionError) Error() string {
+	return fmt.Sprintf("unsupported %s %q", e.option, e.value)
+}
+
+// Run creates and starts a container through the Engine API. The image is
+// pulled if it does not exist. Options which cannot be expressed in the API
+// version used by ij (a platform, a healthcheck start period, an interactive
+// terminal, or values which cannot be parsed here) cause the container to be
+// run with the command line client instead.
+func (r *engineRuntime) Run(ctx context.Context, options *RunOptions, logger logging.Logger, prefix *logging.Prefix) error {
+	request, err := newEngineCreateRequest(options)
+	if err != nil {
+		r.debugUnsupported(prefix, err)
+		return r.cliRuntime.Run(ctx, options, logger, prefix)
+	}
+
+	id, err := r.createContainer(ctx, options, request, logger, prefix)
+	if err != nil {
+		return err
+	}
+
+	if options.Detach {
+		return r.do(ctx, "POST", "/containers/"+id+"/start", nil, nil, nil)
+	}
+
+	stdout := newLineWriter(func(line string) { logger.Info(prefix, "%s", line) })
+	stderr := newLineWriter(func(line string) { logger.Error(prefix, "%s", line) })
+
+	defer stderr.Flush()
+	defer stdout.Flush()
+
+	return r.runAttached(ctx, id, options.Remove, stdout, stderr)
+}
+
+// RunForOutput creates and starts a container through the Engine API and
+// returns its output once it exits. Options which cannot be expressed in
+// the API cause the container to be run with the command line client.
+func (r *engineRuntime) RunForOutput(ctx context.Context, options *RunOptions) (string, string, error) {
+	request, err := newEngineCreateRequest(options)
+	if err != nil {
+		r.debugUnsupported(nil, err)
+		return r.cliRuntime.RunForOutput(ctx, options)
+	}
+
+	id, err := r.createContainer(ctx, options, request, r.pullLogger(), nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	err = r.runAttached(ctx, id, options.Remove, stdout, stderr)
+	return stdout.String(), stderr.String(), err
+}
+
+// CreateContainer creates a container through the Engine API without
+// starting it. The image is pulled if it does not exist.
+func (r *engineRuntime) CreateContainer(ctx context.Context, options *RunOptions) error {
+	request, err := newEngineCreateRequest(options)
+	if err != nil {
+		r.debugUnsupported(nil, err)
+		return r.cliRuntime.CreateContainer(ctx, options)
+	}
+
+	_, err = r.createContainer(ctx, options, request, r.pullLogger(), nil)
+	return err
+}
+
+// Logs writes the output of a container to the given logger. Lines written
+// to standard error are logged as errors.
+func (r *engineRuntime) Logs(ctx context.Context, containerName string, options *LogsOptions, logger logging.Logger, prefix *logging.Prefix) error {
+	query := url.Values{
+		"stdout": []string{"1"},
+		"stderr": []string{"1"},
+	}
+
+	if options.Tail > 0 {
+		query.Set("tail", strconv.Itoa(options.Tail))
+	}
+
+	if options.Follow {
+		query.Set("follow", "1")
+	}
+
+	resp, err := r.send(ctx, "GET", "/containers/"+containerName+"/logs", query, "", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	stdout := newLineWriter(func(line string) { logger.Info(prefix, "%s", line) })
+	stderr := newLineWriter(func(line string) { logger.Error(prefix, "%s", line) })
+
+	defer stderr.Flush()
+	defer stdout.Flush()
+
+	return demultiplex(resp.Body, stdout, stderr)
+}
+
+// Exec runs a command in a running container through the Engine API and
+// returns its output once it exits.
+func (r *engineRuntime) Exec(ctx context.Context, containerName string, args []string) (string, string, error) {
+	request := map[string]interface{}{
+		"AttachStdout": true,
+		"AttachStderr": true,
+		"Cmd":          args,
+	}
+
+	created := &engineCreated{}
+	if err := r.do(ctx, "POST", "/containers/"+containerName+"/exec", nil, request, created); err != nil {
+		return "", "", err
+	}
+
+	resp, err := r.send(
+		ctx,
+		"POST",
+		"/exec/"+created.ID+"/start",
+		nil,
+		"application/json",
+		strings.NewReader(`{"Detach": false, "Tty": false}`),
+		nil,
+	)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	err = demultiplex(resp.Body, stdout, stderr)
+	resp.Body.Close()
+
+	if err != nil {
+		return stdout.String(), stderr.String(), err
+	}
+
+	payload := &engineExec{}
+	if err := r.do(ctx, "GET", "/exec/"+created.ID+"/json", nil, nil, payload); err != nil {
+		return stdout.String(), stderr.String(), err
+	}
+
+	if payload.ExitCode != 0 {
+		return stdout.String(), stderr.String(), &ExitError{Code: payload.ExitCode}
+	}
+
+	return stdout.String(), stderr.String(), nil
+}
+
+// debugUnsupported logs the reason a container is run or created with the
+// command line client instead of the Engine API.
+func (r *engineRuntime) debugUnsupported(prefix *logging.Prefix, err error) {
+	if r.logger != nil {
+		r.logger.Debug(
+			prefix,
+			"Running container with the command line client: %s",
+			err.Error(),
+		)
+	}
+}
+
+// pullLogger returns the logger to which the progress of an image pulled
+// on behalf of an operation without a logger is written.
+func (r *engineRuntime) pullLogger() logging.Logger {
+	if r.logger == nil {
+		return logging.NilLogger
+	}
+
+	return r.logger
+}
+
+// createContainer creates a container and returns its id. The Engine API
+// does not pull missing images on create, so a missing image is pulled and
+// the container is created again.
+func (r *engineRuntime) createContainer(
+	ctx context.Context,
+	options *RunOptions,
+	request *engineCreateRequest,
+	logger logging.Logger,
+	prefix *logging.Prefix,
+) (string, error) {
+	query := url.Values{}
+	if options.Name != "" {
+		query.Set("name", options.Name)
+	}
+
+	payload := &engineCreated{}
+	err := r.do(ctx, "POST", "/containers/create", query, request, payload)
+
+	if isMissingImage(err) {
+		if err := r.pull(ctx, options.Image, logger, prefix); err != nil {
+			return "", err
+		}
+
+		err = r.do(ctx, "POST", "/containers/create", query, request, payload)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return payload.ID, nil
+}
+
+// runAttached starts a created container and copies its output to the given
+// writers until it exits. If the context is canceled, the container is left
+// to be stopped by the caller.
+func (r *engineRuntime) runAttached(
+	ctx context.Context,
+	id string,
+	remove bool,
+	stdout io.Writer,
+	stderr io.Writer,
+) error {
+	query := url.Values{
+		"stream": []string{"1"},
+		"stdout": []string{"1"},
+		"stderr": []string{"1"},
+	}
+
+	resp, err := r.send(ctx, "POST", "/containers/"+id+"/attach", query, "", nil, nil)
+	if err != nil {
+		r.removeContainer(id, remove)
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = demultiplex(resp.Body, stdout, stderr)
+	}()
+
+	payload := &engineWaited{}
+	if err = r.do(ctx, "POST", "/containers/"+id+"/start", nil, nil, nil); err == nil {
+		err = r.do(ctx, "POST", "/containers/"+id+"/wait", nil, nil, payload)
+	}
+
+	if err != nil {
+		// The stream does not end until the container exits
+		resp.Body.Close()
+	}
+
+	<-done
+	resp.Body.Close()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	r.removeContainer(id, remove)
+
+	if err != nil {
+		return err
+	}
+
+	if payload.StatusCode != 0 {
+		return &ExitError{Code: payload.StatusCode}
+	}
+
+	return nil
+}
+
+// removeContainer removes a container which has exited if it was created
+// with the remove option. As with the command line client, a failure to
+// remove the container does not fail the run.
+func (r *engineRuntime) removeContainer(id string, remove bool) {
+	if !remove {
+		return
+	}
+
+	if err := r.Remove(context.Background(), id); err != nil && r.logger != nil {
+		r.logger.Debug(
+			nil,
+			"Failed to remove container %s: %s",
+			id,
+			err.Error(),
+		)
+	}
+}
+
+//
+// Helpers
+
+// newEngineCreateRequest translates run options into the body of a create
+// request.
+func newEngineCreateRequest(options *RunOptions) (*engineCreateRequest, error) {
+	if options.Interactive {
+		return nil, &unsupportedOptionError{"option", "-it"}
+	}
+
+	if options.Platform != "" {
+		return nil, &unsupportedOptionError{"platform", options.Platform}
+	}
+
+	hostConfig := &engineHostConfig{
+		Binds:       options.Binds,
+		VolumesFrom: options.VolumesFrom,
+		NetworkMode: options.Network,
+		Privileged:  options.Privileged,
+		CapAdd:      options.CapAdd,
+	}
+
+	request := &engineCreateRequest{
+		Image:      options.Image,
+		Cmd:        options.Args,
+		Env:        options.Env,
+		WorkingDir: options.Workdir,
+		User:       options.User,
+		HostConfig: hostConfig,
+	}
+
+	if options.Entrypoint != "" {
+		request.Entrypoint = []string{options.Entrypoint}
+	}
+
+	if options.Network != "" && options.Alias != "" {
+		request.NetworkingConfig = &engineNetworkingConfig{
+			EndpointsConfig: map[string]*engineEndpoint{
+				options.Network: {Aliases: []string{options.Alias}},
+			},
+		}
+	}
+
+	for _, mount := range options.Mounts {
+		hostConfig.Mounts = append(hostConfig.Mounts, &engineMount{
+			Type:     mount.Type,
+			Source:   mount.Source,
+			Target:   mount.Target,
+			ReadOnly: mount.ReadOnly,
+		})
+	}
+
+	if healthcheck := options.Healthcheck; healthcheck != nil {
+		if healthcheck.StartPeriod > 0 {
+			return nil, &unsupportedOptionError{"healthcheck start period", healthcheck.StartPeriod.String()}
+		}
+
+		request.Healthcheck = &engineHealthcheck{
+			Interval: int64(healthcheck.Interval),
+			Timeout:  int64(healthcheck.Timeout),
+			Retries:  healthcheck.Retries,
+		}
+
+		if healthcheck.Command != "" {
+			request.Healthcheck.Test = []string{"CMD-SHELL", healthcheck.Command}
+		}
+	}
+
+	if err := addEngineResources(hostConfig, options); err != nil {
+		return nil, err
+	}
+
+	for _, port := range options.Ports {
+		if err := addEnginePort(request, port); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, value := range options.Ulimits {
+		ulimit, err := parseUlimit(value)
+		if err != nil {
+			return nil, err
+		}
+
+		hostConfig.Ulimits = append(hostConfig.Ulimits, ulimit)
+	}
+
+	for _, value := range options.Tmpfs {
+		if hostConfig.Tmpfs == nil {
+			hostConfig.Tmpfs = map[string]string{}
+		}
+
+		parts := strings.SplitN(value, ":", 2)
+		hostConfig.Tmpfs[parts[0]] = strings.Join(parts[1:], "")
+	}
+
+	for _, value := range options.Devices {
+		device, err := parseDevice(value)
+		if err != nil {
+			return nil, err
+		}
+
+		hostConfig.Devices = append(hostConfig.Devices, device)
+	}
+
+	return request, nil
+}
+
+func addEngineResources(hostConfig *engineHostConfig, options *RunOptions) error {
+	if options.CPUShares != "" {
+		cpuShares, err := strconv.ParseInt(options.CPUShares, 10, 64)
+		if err != nil {
+			return &unsupportedOptionError{"cpu shares", options.CPUShares}
+		}
+
+		hostConfig.CPUShares = cpuShares
+	}
+
+	if options.Memory != "" {
+		memory, ok := parseSize(options.Memory)
+		if !ok {
+			return &unsupportedOptionError{"memory", options.Memory}
+		}
+
+		hostConfig.Memory = memory
+	}
+
+	if options.ShmSize != "" {
+		shmSize, ok := parseSize(options.ShmSize)
+		if !ok {
+			return &unsupportedOptionError{"shm size", options.ShmSize}
+		}
+
+		hostConfig.ShmSize = shmSize
+	}
+
+	return nil
+}
+
+// addEnginePort publishes a port given in the form [[ip:]host:]container[/proto].
+// Port ranges are not translated.
+func addEnginePort(request *engineCreateRequest, value string) error {
+	protocol := "tcp"
+	spec := value
+
+	if index := strings.LastIndex(spec, "/"); index >= 0 {
+		spec, protocol = spec[:index], spec[index+1:]
+	}
+
+	binding := &enginePortBinding{}
+	parts := strings.Split(spec, ":")
+
+	switch len(parts) {
+	case 1:
+	case 2:
+		binding.HostPort = parts[0]
+	case 3:
+		binding.HostIP, binding.HostPort = parts[0], parts[1]
+	default:
+		return &unsupportedOptionError{"port", value}
+	}
+
+	containerPort := parts[len(parts)-1]
+
+	for _, port := range []string{containerPort, binding.HostPort} {
+		if _, err := strconv.ParseUint(port, 10, 16); port != "" && err != nil {
+			return &unsupportedOptionError{"port", value}
+		}
+	}
+
+	if containerPort == "" {
+		return &unsupportedOptionError{"port", value}
+	}
+
+	key := containerPort + "/" + protocol
+
+	if request.ExposedPorts == nil {
+		request.ExposedPorts = map[string]struct{}{}
+		request.HostConfig.PortBindings = map[string][]*enginePortBinding{}
+	}
+
+	request.ExposedPorts[key] = struct{}{}
+	request.HostConfig.PortBindings[key] = append(request.HostConfig.PortBindings[key], binding)
+	return nil
+}
+
+// parseSize parses a size such as 512m or 1g into bytes, where units are
+// powers of 1024.
+func parseSize(value string) (int64, bool) {
+	matches := sizePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	if matches == nil {
+		return 0, false
+	}
+
+	size, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	if unit := matches[2]; unit != "" {
+		size *= math.Pow(1024, float64(strings.Index("kmgtp", unit)+1))
+	}
+
+	return int64(size), true
+}
+
+// parseUlimit parses a ulimit given in the form name=soft[:hard].
+func parseUlimit(value string) (*engineUlimit, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return nil, &unsupportedOptionError{"ulimit", value}
+	}
+
+	limits := strings.SplitN(parts[1], ":", 2)
+
+	soft, err := strconv.ParseInt(limits[0], 10, 64)
+	if err != nil {
+		return nil, &unsupportedOptionError{"ulimit", value}
+	}
+
+	hard := soft
+	if len(limits) == 2 {
+		if hard, err = strconv.ParseInt(limits[1], 10, 64); err != nil {
+			return nil, &unsupportedOptionError{"ulimit", value}
+		}
+	}
+
+	return &engineUlimit{Name: parts[0], Soft: soft, Hard: hard}, nil
+}
+
+// parseDevice parses a device given in the form host[:container[:permissions]].
+func parseDevice(value string) (*engineDevice, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 || (len(parts) > 1 && !strings.HasPrefix(parts[1], "/")) {
+		return nil, &unsupportedOptionError{"device", value}
+	}
+
+	device := &engineDevice{
+		PathOnHost:        parts[0],
+		PathInContainer:   parts[0],
+		CgroupPermissions: "rwm",
+	}
+
+	if len(parts) > 1 {
+		device.PathInContainer = parts[1]
+	}
+
+	if len(parts) > 2 {
+		device.CgroupPermissions = parts[2]
+	}
+
+	return device, nil
+}
+
+func isMissingImage(err error) bool {
+	engineErr, ok := err.(*EngineError)
+	if !ok || engineErr.StatusCode != 404 {
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(engineErr.Message), "no such image")
+}
+
+// demultiplex copies the frames of the output stream of a container without
+// a terminal to the writer of the stream from which they originate. Each
+// frame is preceded by a header holding the stream type and frame size.
+func demultiplex(r io.Reader, stdout, stderr io.Writer) error {
+	header := make([]byte, 8)
+
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		w := stdout
+		if header[0] == 2 {
+			w = stderr
+		}
+
+		if _, err := io.CopyN(w, r, int64(binary.BigEndian.Uint32(header[4:]))); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package runtime
+
+//go:generate go-mockgen -f github.com/ij-build/ij/logging -i Logger -o mock_logger_test.go
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/aphistic/sweet"
+	. "github.com/efritz/go-mockgen/matchers"
+	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/logging"
+	. "github.com/onsi/gomega"
+)
+
+type EngineRunSuite struct{}
+
+func (s *EngineRunSuite) TestRun(t sweet.T) {
+	var (
+		created = &engineCreateRequest{}
+		calls   = []string{}
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "create "+r.URL.Query().Get("name"))
+		json.NewDecoder(r.Body).Decode(created)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id": "abc"}`))
+	})
+
+	mux.HandleFunc("/v1.25/containers/abc/attach", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "attach")
+		w.Write(frame(1, "hello\nwor"))
+		w.Write(frame(1, "ld\n"))
+		w.Write(frame(2, "oops\n"))
+	})
+
+	mux.HandleFunc("/v1.25/containers/abc/start", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "start")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/v1.25/containers/abc/wait", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "wait")
+		w.Write([]byte(`{"StatusCode": 3}`))
+	})
+
+	mux.HandleFunc("/v1.25/containers/abc", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "remove")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	var (
+		logger = NewMockLogger()
+		prefix = logging.NewPrefix("task")
+	)
+
+	err := runtime.Run(context.Background(), &RunOptions{
+		Name:    "c1",
+		Image:   "alpine",
+		Args:    []string{"echo", "hello"},
+		Network: "run",
+		Alias:   "build",
+		Remove:  true,
+	}, logger, prefix)
+
+	Expect(err).To(Equal(&ExitError{Code: 3}))
+	Expect(command.ExitCode(err)).To(Equal(3))
+	Expect(calls).To(Equal([]string{"create c1", "attach", "start", "wait", "remove"}))
+	Expect(created.Image).To(Equal("alpine"))
+	Expect(created.Cmd).To(Equal([]string{"echo", "hello"}))
+	Expect(created.HostConfig.NetworkMode).To(Equal("run"))
+	Expect(created.NetworkingConfig.EndpointsConfig["run"].Aliases).To(Equal([]string{"build"}))
+
+	Expect(logger.InfoFunc).To(BeCalledN(2))
+	Expect(logger.InfoFunc).To(BeCalledWith(prefix, "%s", "hello"))
+	Expect(logger.InfoFunc).To(BeCalledWith(prefix, "%s", "world"))
+	Expect(logger.ErrorFunc).To(BeCalledWith(prefix, "%s", "oops"))
+}
+
+func (s *EngineRunSuite) TestRunDetached(t sweet.T) {
+	calls := []string{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "create")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id": "abc"}`))
+	})
+
+	mux.HandleFunc("/v1.25/containers/abc/start", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "start")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	Expect(runtime.Run(context.Background(), &RunOptions{Image: "redis", Detach: true}, logging.NilLogger, nil)).To(BeNil())
+	Expect(calls).To(Equal([]string{"create", "start"}))
+}
+
+func (s *EngineRunSuite) TestRunPullsMissingImage(t sweet.T) {
+	var (
+		attempts  = 0
+		fromImage string
+		tag       string
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		if attempts++; attempts == 1 {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "No such image: redis:5"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id": "abc"}`))
+	})
+
+	mux.HandleFunc("/v1.25/images/create", func(w http.ResponseWriter, r *http.Request) {
+		fromImage = r.URL.Query().Get("fromImage")
+		tag = r.URL.Query().Get("tag")
+		w.Write([]byte(`{"status": "Pulling from library/redis", "id": "5"}`))
+	})
+
+	mux.HandleFunc("/v1.25/containers/abc/start", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	Expect(runtime.Run(context.Background(), &RunOptions{Image: "redis:5", Detach: true}, logging.NilLogger, nil)).To(BeNil())
+	Expect(attempts).To(Equal(2))
+	Expect(fromImage).To(Equal("redis"))
+	Expect(tag).To(Equal("5"))
+}
+
+func (s *EngineRunSuite) TestRunUnsupportedOptionUsesCLI(t sweet.T) {
+	runtime, teardown := testEngineRuntime(http.NewServeMux())
+	defer teardown()
+
+	runner := NewMockRunner()
+	runtime.runnerFactory = func(logging.Logger) command.Runner { return runner }
+
+	err := runtime.Run(context.Background(), &RunOptions{
+		Image:    "alpine",
+		Platform: "linux/arm64",
+	}, logging.NilLogger, nil)
+
+	Expect(err).To(BeNil())
+	Expect(runner.RunFunc).To(BeCalledOnce())
+	Expect(runner.RunFunc.History()[0].Arg1).To(ContainElement("--platform"))
+}
+
+func (s *EngineRunSuite) TestRunForOutput(t sweet.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id": "abc"}`))
+	})
+
+	mux.HandleFunc("/v1.25/containers/abc/attach", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(frame(1, "token"))
+		w.Write(frame(2, "HTTP/1.1 200 OK\n"))
+	})
+
+	mux.HandleFunc("/v1.25/containers/abc/start", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/v1.25/containers/abc/wait", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"StatusCode": 0}`))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	out, errOut, err := runtime.RunForOutput(context.Background(), &RunOptions{Image: "busybox:1"})
+	Expect(err).To(BeNil())
+	Expect(out).To(Equal("token"))
+	Expect(errOut).To(Equal("HTTP/1.1 200 OK\n"))
+}
+
+func (s *EngineRunSuite) TestCreateContainer(t sweet.T) {
+	var (
+		created = &engineCreateRequest{}
+		name    string
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		name = r.URL.Query().Get("name")
+		json.NewDecoder(r.Body).Decode(created)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id": "abc"}`))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	Expect(runtime.CreateContainer(context.Background(), &RunOptions{
+		Name:  "abcdef0-volume",
+		Image: "busybox:1",
+		Binds: []string{"abcdef0-workspace:/workspace"},
+	})).To(BeNil())
+
+	Expect(name).To(Equal("abcdef0-volume"))
+	Expect(created.HostConfig.Binds).To(Equal([]string{"abcdef0-workspace:/workspace"}))
+}
+
+func (s *EngineRunSuite) TestLogs(t sweet.T) {
+	var tail, follow string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/containers/c1/logs", func(w http.ResponseWriter, r *http.Request) {
+		tail = r.URL.Query().Get("tail")
+		follow = r.URL.Query().Get("follow")
+		w.Write(frame(1, "ready\n"))
+		w.Write(frame(2, "warning"))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	var (
+		logger = NewMockLogger()
+		prefix = logging.NewPrefix("db")
+	)
+
+	Expect(runtime.Logs(context.Background(), "c1", &LogsOptions{Tail: 20}, logger, prefix)).To(BeNil())
+	Expect(tail).To(Equal("20"))
+	Expect(follow).To(BeEmpty())
+	Expect(logger.InfoFunc).To(BeCalledWith(prefix, "%s", "ready"))
+	Expect(logger.ErrorFunc).To(BeCalledWith(prefix, "%s", "warning"))
+
+	Expect(runtime.Logs(context.Background(), "c1", &LogsOptions{Follow: true}, logger, prefix)).To(BeNil())
+	Expect(tail).To(BeEmpty())
+	Expect(follow).To(Equal("1"))
+}
+
+func (s *EngineRunSuite) TestExec(t sweet.T) {
+	var (
+		created  = map[string]interface{}{}
+		exitCode = 0
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/containers/c1/exec", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&created)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id": "e1"}`))
+	})
+
+	mux.HandleFunc("/v1.25/exec/e1/start", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(frame(1, "added\n"))
+		w.Write(frame(2, "missing key\n"))
+	})
+
+	mux.HandleFunc("/v1.25/exec/e1/json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]int{"ExitCode": exitCode})
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	out, errOut, err := runtime.Exec(context.Background(), "c1", []string{"/ij/add-keys.sh"})
+	Expect(err).To(BeNil())
+	Expect(out).To(Equal("added\n"))
+	Expect(errOut).To(Equal("missing key\n"))
+	Expect(created["Cmd"]).To(Equal([]interface{}{"/ij/add-keys.sh"}))
+
+	exitCode = 2
+	_, _, err = runtime.Exec(context.Background(), "c1", []string{"/ij/add-keys.sh"})
+	Expect(err).To(Equal(&ExitError{Code: 2}))
+}
+
+func (s *EngineRunSuite) TestNewEngineCreateRequest(t sweet.T) {
+	request, err := newEngineCreateRequest(&RunOptions{
+		Image:       "postgres",
+		Entrypoint:  "/bin/sh",
+		Ports:       []string{"5432", "8080:80", "127.0.0.1:5005:5005/udp"},
+		Healthcheck: &Healthcheck{Command: "pg_isready", Interval: time.Second, Retries: 3},
+		CPUShares:   "512",
+		Memory:      "1g",
+		ShmSize:     "256m",
+		Ulimits:     []string{"nofile=1024:2048", "nproc=64"},
+		Tmpfs:       []string{"/run:size=64m", "/tmp"},
+		Devices:     []string{"/dev/fuse", "/dev/sda:/dev/xvda:r"},
+		Mounts:      []*Mount{&Mount{Type: "volume", Source: "data", Target: "/data", ReadOnly: true}},
+	})
+
+	Expect(err).To(BeNil())
+	Expect(request.Entrypoint).To(Equal([]string{"/bin/sh"}))
+	Expect(request.ExposedPorts).To(HaveLen(3))
+	Expect(request.HostConfig.PortBindings).To(Equal(map[string][]*enginePortBinding{
+		"5432/tcp": []*enginePortBinding{&enginePortBinding{}},
+		"80/tcp":   []*enginePortBinding{&enginePortBinding{HostPort: "8080"}},
+		"5005/udp": []*enginePortBinding{&enginePortBinding{HostIP: "127.0.0.1", HostPort: "5005"}},
+	}))
+
+	Expect(request.Healthcheck).To(Equal(&engineHealthcheck{
+		Test:     []string{"CMD-SHELL", "pg_isready"},
+		Interval: int64(time.Second),
+		Retries:  3,
+	}))
+
+	Expect(request.HostConfig.CPUShares).To(Equal(int64(512)))
+	Expect(request.HostConfig.Memory).To(Equal(int64(1 << 30)))
+	Expect(request.HostConfig.ShmSize).To(Equal(int64(256 << 20)))
+	Expect(request.HostConfig.Ulimits).To(Equal([]*engineUlimit{
+		&engineUlimit{Name: "nofile", Soft: 1024, Hard: 2048},
+		&engineUlimit{Name: "nproc", Soft: 64, Hard: 64},
+	}))
+
+	Expect(request.HostConfig.Tmpfs).To(Equal(map[string]string{"/run": "size=64m", "/tmp": ""}))
+	Expect(request.HostConfig.Devices).To(Equal([]*engineDevice{
+		&engineDevice{PathOnHost: "/dev/fuse", PathInContainer: "/dev/fuse", CgroupPermissions: "rwm"},
+		&engineDevice{PathOnHost: "/dev/sda", PathInContainer: "/dev/xvda", CgroupPermissions: "r"},
+	}))
+
+	Expect(request.HostConfig.Mounts).To(Equal([]*engineMount{
+		&engineMount{Type: "volume", Source: "data", Target: "/data", ReadOnly: true},
+	}))
+}
+
+func (s *EngineRunSuite) TestNewEngineCreateRequestUnsupported(t sweet.T) {
+	for _, options := range []*RunOptions{
+		&RunOptions{Interactive: true},
+		&RunOptions{Platform: "linux/amd64"},
+		&RunOptions{Healthcheck: &Healthcheck{StartPeriod: time.Second}},
+		&RunOptions{Ports: []string{"8000-8010:8000-8010"}},
+		&RunOptions{Memory: "lots"},
+		&RunOptions{Ulimits: []string{"nofile"}},
+	} {
+		_, err := newEngineCreateRequest(options)
+		Expect(err).To(BeAssignableToTypeOf(&unsupportedOptionError{}))
+	}
+}
+
+func (s *EngineRunSuite) TestParseSize(t sweet.T) {
+	for value, expected := range map[string]int64{
+		"512":   512,
+		"2k":    2048,
+		"512m":  512 << 20,
+		"1.5GB": 3 << 29,
+	} {
+		size, ok := parseSize(value)
+		Expect(ok).To(BeTrue())
+		Expect(size).To(Equal(expected))
+	}
+
+	_, ok := parseSize("1x")
+	Expect(ok).To(BeFalse())
+}
+
+//
+// Helpers
+
+func frame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, []byte(payload)...)
+}
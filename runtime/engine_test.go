@@ -0,0 +1,307 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/aphistic/sweet"
+	. "github.com/efritz/go-mockgen/matchers"
+	"github.com/ij-build/ij/logging"
+	. "github.com/onsi/gomega"
+)
+
+type EngineSuite struct{}
+
+func (s *EngineSuite) TestPing(t sweet.T) {
+	runtime, teardown := testEngineRuntime(http.NewServeMux())
+	defer teardown()
+
+	Expect(runtime.Ping(context.Background())).To(BeNil())
+}
+
+func (s *EngineSuite) TestPingUnreachable(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	runtime := newEngineRuntime(
+		filepath.Join(name, "docker.sock"),
+		newCLIRuntime("docker", logging.NilLogger, NewMockRunner()),
+		logging.NilLogger,
+	)
+
+	Expect(runtime.Ping(context.Background())).NotTo(BeNil())
+}
+
+func (s *EngineSuite) TestInspect(t sweet.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/containers/c1/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"State": {"Status": "running", "ExitCode": 0, "Health": {"Status": "healthy"}},
+			"RestartCount": 2,
+			"Config": {"Healthcheck": {"Test": ["CMD", "true"]}}
+		}`))
+	})
+
+	mux.HandleFunc("/v1.25/containers/c2/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"State": {"Status": "exited", "ExitCode": 137},
+			"RestartCount": 0,
+			"Config": {}
+		}`))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	state, err := runtime.Inspect(context.Background(), "c1")
+	Expect(err).To(BeNil())
+	Expect(state).To(Equal(&ContainerState{
+		Status:       "running",
+		ExitCode:     0,
+		RestartCount: 2,
+		Health:       "healthy",
+	}))
+
+	state, err = runtime.Inspect(context.Background(), "c2")
+	Expect(err).To(BeNil())
+	Expect(state).To(Equal(&ContainerState{
+		Status:   "exited",
+		ExitCode: 137,
+	}))
+
+	ok, err := runtime.HasHealthcheck(context.Background(), "c1")
+	Expect(err).To(BeNil())
+	Expect(ok).To(BeTrue())
+
+	ok, err = runtime.HasHealthcheck(context.Background(), "c2")
+	Expect(err).To(BeNil())
+	Expect(ok).To(BeFalse())
+}
+
+func (s *EngineSuite) TestImageID(t sweet.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/images/example.com/app:latest/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Id": "sha256:abcdef"}`))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	id, err := runtime.ImageID(context.Background(), "example.com/app:latest")
+	Expect(err).To(BeNil())
+	Expect(id).To(Equal("sha256:abcdef"))
+}
+
+func (s *EngineSuite) TestRemove(t sweet.T) {
+	var method, force string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/containers/c1", func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		force = r.URL.Query().Get("force")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	Expect(runtime.Remove(context.Background(), "c1")).To(BeNil())
+	Expect(method).To(Equal("DELETE"))
+	Expect(force).To(Equal("1"))
+}
+
+func (s *EngineSuite) TestCommitAndRemoveImage(t sweet.T) {
+	var container, method, force string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/commit", func(w http.ResponseWriter, r *http.Request) {
+		container = r.URL.Query().Get("container")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id": "sha256:abcdef"}`))
+	})
+
+	mux.HandleFunc("/v1.25/images/sha256:abcdef", func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		force = r.URL.Query().Get("force")
+		w.Write([]byte(`[{"Deleted": "sha256:abcdef"}]`))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	id, err := runtime.Commit(context.Background(), "c1")
+	Expect(err).To(BeNil())
+	Expect(id).To(Equal("sha256:abcdef"))
+	Expect(container).To(Equal("c1"))
+
+	Expect(runtime.RemoveImage(context.Background(), id)).To(BeNil())
+	Expect(method).To(Equal("DELETE"))
+	Expect(force).To(Equal("1"))
+}
+
+func (s *EngineSuite) TestVolumes(t sweet.T) {
+	var (
+		created = &engineVolume{}
+		filters string
+		removed string
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/volumes/create", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(created)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{}`))
+	})
+
+	mux.HandleFunc("/v1.25/volumes", func(w http.ResponseWriter, r *http.Request) {
+		filters = r.URL.Query().Get("filters")
+		w.Write([]byte(`{"Volumes": [{"Name": "vol-a"}, {"Name": "vol-b"}]}`))
+	})
+
+	mux.HandleFunc("/v1.25/volumes/vol-a", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			removed = "vol-a"
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Write([]byte(`{"Name": "vol-a", "Labels": {"ij.cache": "gomod"}}`))
+	})
+
+	mux.HandleFunc("/v1.25/volumes/vol-b", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Name": "vol-b", "Labels": null}`))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	Expect(runtime.CreateVolume(context.Background(), "vol-a", map[string]string{"ij.cache": "gomod"})).To(BeNil())
+	Expect(created).To(Equal(&engineVolume{Name: "vol-a", Labels: map[string]string{"ij.cache": "gomod"}}))
+
+	names, err := runtime.ListVolumes(context.Background(), "ij.project=/src/project")
+	Expect(err).To(BeNil())
+	Expect(names).To(Equal([]string{"vol-a", "vol-b"}))
+	Expect(filters).To(MatchJSON(`{"label": ["ij.project=/src/project"]}`))
+
+	labels, err := runtime.VolumeLabels(context.Background(), "vol-a")
+	Expect(err).To(BeNil())
+	Expect(labels).To(Equal(map[string]string{"ij.cache": "gomod"}))
+
+	labels, err = runtime.VolumeLabels(context.Background(), "vol-b")
+	Expect(err).To(BeNil())
+	Expect(labels).To(BeEmpty())
+
+	_, err = runtime.VolumeLabels(context.Background(), "vol-c")
+	Expect(err).NotTo(BeNil())
+
+	Expect(runtime.RemoveVolume(context.Background(), "vol-a")).To(BeNil())
+	Expect(removed).To(Equal("vol-a"))
+}
+
+func (s *EngineSuite) TestCreateNetwork(t sweet.T) {
+	body := map[string]interface{}{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/networks/create", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id": "n1"}`))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	Expect(runtime.CreateNetwork(context.Background(), "abcdef0")).To(BeNil())
+	Expect(body).To(HaveKeyWithValue("Name", "abcdef0"))
+}
+
+func (s *EngineSuite) TestErrorMessage(t sweet.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.25/containers/c1/kill", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "No such container: c1"}`))
+	})
+
+	mux.HandleFunc("/v1.25/containers/c2/kill", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("oops"))
+	})
+
+	runtime, teardown := testEngineRuntime(mux)
+	defer teardown()
+
+	Expect(runtime.Kill(context.Background(), "c1")).To(MatchError("No such container: c1"))
+	Expect(runtime.Kill(context.Background(), "c2")).To(MatchError("unexpected status 500: oops"))
+}
+
+func (s *EngineSuite) TestLoginUsesCLI(t sweet.T) {
+	runner := NewMockRunner()
+
+	runtime := newEngineRuntime(
+		"/does/not/exist",
+		newCLIRuntime("docker", logging.NilLogger, runner),
+		logging.NilLogger,
+	)
+
+	Expect(runtime.Login(context.Background(), "docker.io", "admin", "secret")).To(BeNil())
+	Expect(runner.RunFunc).To(BeCalledOnce())
+}
+
+func (s *EngineSuite) TestEngineSocketPath(t sweet.T) {
+	defer os.Setenv("DOCKER_HOST", os.Getenv("DOCKER_HOST"))
+	defer os.Setenv("DOCKER_CONTEXT", os.Getenv("DOCKER_CONTEXT"))
+	os.Setenv("DOCKER_CONTEXT", "")
+
+	os.Setenv("DOCKER_HOST", "")
+	path, ok := engineSocketPath()
+	Expect(ok).To(BeTrue())
+	Expect(path).To(Equal(DefaultSocketPath))
+
+	os.Setenv("DOCKER_HOST", "unix:///tmp/docker.sock")
+	path, ok = engineSocketPath()
+	Expect(ok).To(BeTrue())
+	Expect(path).To(Equal("/tmp/docker.sock"))
+
+	os.Setenv("DOCKER_HOST", "tcp://build.example.com:2376")
+	_, ok = engineSocketPath()
+	Expect(ok).To(BeFalse())
+
+	os.Setenv("DOCKER_HOST", "")
+	os.Setenv("DOCKER_CONTEXT", "remote")
+	_, ok = engineSocketPath()
+	Expect(ok).To(BeFalse())
+}
+
+//
+// Helpers
+
+func testEngineRuntime(mux *http.ServeMux) (*engineRuntime, func()) {
+	mux.HandleFunc("/v1.25/_ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+
+	name, _ := ioutil.TempDir("", "ij-test")
+	socketPath := filepath.Join(name, "docker.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	Expect(err).To(BeNil())
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+
+	runtime := newEngineRuntime(
+		socketPath,
+		newCLIRuntime("docker", logging.NilLogger, NewMockRunner()),
+		logging.NilLogger,
+	)
+
+	return runtime, func() {
+		server.Close()
+		os.RemoveAll(name)
+	}
+}
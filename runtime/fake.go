@@ -0,0 +1,481 @@
+package runtime
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ij-build/ij/logging"
+)
+
+// FakeRuntime is an in-memory runtime for tests. Containers and images are
+// made known to the runtime by populating its maps, and an error can be
+// injected into any operation by name (e.g. "CreateNetwork"). Operations
+// invoked with a canceled context fail as they would against a daemon.
+// Logins maps each server to the credentials used to log in, in the form
+// `username:password`. Outputs maps an image to the standard output of its
+// containers run for output, and ContainerLogs maps a container to its output lines.
+// The options of each container run or created, image built, and image
+// pushed are recorded. Archives copied into a container are recorded as the
+// names of their entries, keyed by `container:path`. Copying a path out of a
+// container fails if it is held in MissingPaths in the same form.
+type FakeRuntime struct {
+	Runs          []*RunOptions
+	Created       []*RunOptions
+	Builds        []*BuildOptions
+	Pushed        []string
+	Execs         [][]string
+	Containers    map[string]*ContainerState
+	Healthchecks  map[string]bool
+	Images        map[string]string
+	Volumes       map[string]map[string]string
+	Networks      map[string]struct{}
+	Logins        map[string]string
+	Outputs       map[string]string
+	ContainerLogs map[string][]string
+	Copied        map[string][]string
+	CopiedFrom    []string
+	MissingPaths  map[string]struct{}
+	Errors        map[string]error
+	Killed        []string
+	Removed       []string
+	RemovedImages []string
+	Disconnected  []string
+	LoggedOut     []string
+	mutex         sync.Mutex
+}
+
+var _ ContainerRuntime = &FakeRuntime{}
+
+func NewFakeRuntime() *FakeRuntime {
+	return &FakeRuntime{
+		Containers:    map[string]*ContainerState{},
+		Healthchecks:  map[string]bool{},
+		Images:        map[string]string{},
+		Volumes:       map[string]map[string]string{},
+		Networks:      map[string]struct{}{},
+		Logins:        map[string]string{},
+		Outputs:       map[string]string{},
+		ContainerLogs: map[string][]string{},
+		Copied:        map[string][]string{},
+		MissingPaths:  map[string]struct{}{},
+		Errors:        map[string]error{},
+	}
+}
+
+func (r *FakeRuntime) Run(ctx context.Context, options *RunOptions, logger logging.Logger, prefix *logging.Prefix) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "Run"); err != nil {
+		return err
+	}
+
+	r.Runs = append(r.Runs, options)
+	return nil
+}
+
+func (r *FakeRuntime) RunForOutput(ctx context.Context, options *RunOptions) (string, string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "RunForOutput"); err != nil {
+		return "", "", err
+	}
+
+	r.Runs = append(r.Runs, options)
+	return r.Outputs[options.Image], "", nil
+}
+
+func (r *FakeRuntime) CreateContainer(ctx context.Context, options *RunOptions) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "CreateContainer"); err != nil {
+		return err
+	}
+
+	r.Created = append(r.Created, options)
+	r.Containers[options.Name] = &ContainerState{Status: "created"}
+	return nil
+}
+
+func (r *FakeRuntime) Build(ctx context.Context, options *BuildOptions, logger logging.Logger, prefix *logging.Prefix) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "Build"); err != nil {
+		return err
+	}
+
+	r.Builds = append(r.Builds, options)
+	return nil
+}
+
+func (r *FakeRuntime) Push(ctx context.Context, image string, logger logging.Logger, prefix *logging.Prefix) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "Push"); err != nil {
+		return err
+	}
+
+	r.Pushed = append(r.Pushed, image)
+	return nil
+}
+
+func (r *FakeRuntime) Exec(ctx context.Context, containerName string, args []string) (string, string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "Exec"); err != nil {
+		return "", "", err
+	}
+
+	r.Execs = append(r.Execs, append([]string{containerName}, args...))
+	return "", "", nil
+}
+
+func (r *FakeRuntime) Logs(ctx context.Context, containerName string, options *LogsOptions, logger logging.Logger, prefix *logging.Prefix) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "Logs"); err != nil {
+		return err
+	}
+
+	lines := r.ContainerLogs[containerName]
+	if options.Tail > 0 && len(lines) > options.Tail {
+		lines = lines[len(lines)-options.Tail:]
+	}
+
+	for _, line := range lines {
+		logger.Info(prefix, "%s", line)
+	}
+
+	return nil
+}
+
+func (r *FakeRuntime) Commit(ctx context.Context, containerName string) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "Commit"); err != nil {
+		return "", err
+	}
+
+	if _, ok := r.Containers[containerName]; !ok {
+		return "", fmt.Errorf("no such container: %s", containerName)
+	}
+
+	id := fmt.Sprintf("sha256:%s", containerName)
+	r.Images[id] = id
+	return id, nil
+}
+
+func (r *FakeRuntime) RunArgs(options *RunOptions) []string {
+	return newCLIRuntime(DockerExecutable, nil, nil).RunArgs(options)
+}
+
+func (r *FakeRuntime) BuildArgs(options *BuildOptions) []string {
+	return newCLIRuntime(DockerExecutable, nil, nil).BuildArgs(options)
+}
+
+func (r *FakeRuntime) PushArgs(image string) []string {
+	return newCLIRuntime(DockerExecutable, nil, nil).PushArgs(image)
+}
+
+func (r *FakeRuntime) RemoveImageArgs(image string) []string {
+	return newCLIRuntime(DockerExecutable, nil, nil).RemoveImageArgs(image)
+}
+
+func (r *FakeRuntime) Ping(ctx context.Context) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.check(ctx, "Ping")
+}
+
+func (r *FakeRuntime) Inspect(ctx context.Context, containerName string) (*ContainerState, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "Inspect"); err != nil {
+		return nil, err
+	}
+
+	state, ok := r.Containers[containerName]
+	if !ok {
+		return nil, fmt.Errorf("no such container: %s", containerName)
+	}
+
+	copy := *state
+	return &copy, nil
+}
+
+func (r *FakeRuntime) HasHealthcheck(ctx context.Context, containerName string) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "HasHealthcheck"); err != nil {
+		return false, err
+	}
+
+	if _, ok := r.Containers[containerName]; !ok {
+		return false, fmt.Errorf("no such container: %s", containerName)
+	}
+
+	return r.Healthchecks[containerName], nil
+}
+
+func (r *FakeRuntime) ImageID(ctx context.Context, image string) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "ImageID"); err != nil {
+		return "", err
+	}
+
+	id, ok := r.Images[image]
+	if !ok {
+		return "", fmt.Errorf("no such image: %s", image)
+	}
+
+	return id, nil
+}
+
+func (r *FakeRuntime) Kill(ctx context.Context, containerName string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "Kill"); err != nil {
+		return err
+	}
+
+	r.Killed = append(r.Killed, containerName)
+
+	if state, ok := r.Containers[containerName]; ok {
+		state.Status = "exited"
+		state.ExitCode = 137
+	}
+
+	return nil
+}
+
+func (r *FakeRuntime) Remove(ctx context.Context, containerName string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "Remove"); err != nil {
+		return err
+	}
+
+	r.Removed = append(r.Removed, containerName)
+	delete(r.Containers, containerName)
+	return nil
+}
+
+func (r *FakeRuntime) RemoveImage(ctx context.Context, image string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "RemoveImage"); err != nil {
+		return err
+	}
+
+	r.RemovedImages = append(r.RemovedImages, image)
+	delete(r.Images, image)
+	return nil
+}
+
+func (r *FakeRuntime) CopyTo(ctx context.Context, containerName, path string, archive io.Reader) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "CopyTo"); err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%s:%s", containerName, path)
+	reader := tar.NewReader(archive)
+
+	for {
+		header, err := reader.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		r.Copied[key] = append(r.Copied[key], header.Name)
+	}
+}
+
+func (r *FakeRuntime) CopyFrom(ctx context.Context, containerName, path, dest string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "CopyFrom"); err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%s:%s", containerName, path)
+	if _, ok := r.MissingPaths[key]; ok {
+		return ErrNoSuchPath
+	}
+
+	r.CopiedFrom = append(r.CopiedFrom, key)
+	return nil
+}
+
+func (r *FakeRuntime) CreateVolume(ctx context.Context, name string, labels map[string]string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "CreateVolume"); err != nil {
+		return err
+	}
+
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	r.Volumes[name] = labels
+	return nil
+}
+
+func (r *FakeRuntime) VolumeLabels(ctx context.Context, name string) (map[string]string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "VolumeLabels"); err != nil {
+		return nil, err
+	}
+
+	labels, ok := r.Volumes[name]
+	if !ok {
+		return nil, fmt.Errorf("no such volume: %s", name)
+	}
+
+	return labels, nil
+}
+
+func (r *FakeRuntime) ListVolumes(ctx context.Context, label string) ([]string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "ListVolumes"); err != nil {
+		return nil, err
+	}
+
+	parts := strings.SplitN(label, "=", 2)
+
+	names := []string{}
+	for name, labels := range r.Volumes {
+		if value, ok := labels[parts[0]]; ok && (len(parts) == 1 || value == parts[1]) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func (r *FakeRuntime) RemoveVolume(ctx context.Context, name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "RemoveVolume"); err != nil {
+		return err
+	}
+
+	if _, ok := r.Volumes[name]; !ok {
+		return fmt.Errorf("no such volume: %s", name)
+	}
+
+	delete(r.Volumes, name)
+	return nil
+}
+
+func (r *FakeRuntime) CreateNetwork(ctx context.Context, name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "CreateNetwork"); err != nil {
+		return err
+	}
+
+	if _, ok := r.Networks[name]; ok {
+		return fmt.Errorf("network with name %s already exists", name)
+	}
+
+	r.Networks[name] = struct{}{}
+	return nil
+}
+
+func (r *FakeRuntime) RemoveNetwork(ctx context.Context, name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "RemoveNetwork"); err != nil {
+		return err
+	}
+
+	if _, ok := r.Networks[name]; !ok {
+		return fmt.Errorf("network %s not found", name)
+	}
+
+	delete(r.Networks, name)
+	return nil
+}
+
+func (r *FakeRuntime) DisconnectNetwork(ctx context.Context, name, containerName string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "DisconnectNetwork"); err != nil {
+		return err
+	}
+
+	r.Disconnected = append(r.Disconnected, containerName)
+	return nil
+}
+
+func (r *FakeRuntime) Login(ctx context.Context, server, username, password string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "Login"); err != nil {
+		return err
+	}
+
+	r.Logins[server] = fmt.Sprintf("%s:%s", username, password)
+	return nil
+}
+
+func (r *FakeRuntime) Logout(ctx context.Context, server string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.check(ctx, "Logout"); err != nil {
+		return err
+	}
+
+	r.LoggedOut = append(r.LoggedOut, server)
+	delete(r.Logins, server)
+	return nil
+}
+
+func (r *FakeRuntime) check(ctx context.Context, operation string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.Errors[operation]
+}
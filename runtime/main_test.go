@@ -0,0 +1,25 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/aphistic/sweet"
+	"github.com/aphistic/sweet-junit"
+	. "github.com/onsi/gomega"
+)
+
+func TestMain(m *testing.M) {
+	RegisterFailHandler(sweet.GomegaFail)
+
+	sweet.Run(m, func(s *sweet.S) {
+		s.RegisterPlugin(junit.NewPlugin())
+
+		s.AddSuite(&CLISuite{})
+		s.AddSuite(&EngineSuite{})
+		s.AddSuite(&EngineArchiveSuite{})
+		s.AddSuite(&EngineBuildSuite{})
+		s.AddSuite(&EngineImageSuite{})
+		s.AddSuite(&EngineRunSuite{})
+		s.AddSuite(&PodmanSuite{})
+	})
+}
@@ -4,7 +4,7 @@
 // using the command
 // $ go-mockgen -f github.com/ij-build/ij/command -i Runner -o mock_runner_test.go
 
-package network
+package runtime
 
 import (
 	"context"
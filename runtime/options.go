@@ -0,0 +1,92 @@
+package runtime
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// RunOptions describes a container to create and start. String values
+	// such as sizes, ports, and devices use the syntax of the corresponding
+	// options of the command line client.
+	RunOptions struct {
+		Name        string
+		Image       string
+		Entrypoint  string
+		Args        []string
+		Env         []string
+		Workdir     string
+		User        string
+		Network     string
+		Alias       string
+		Detach      bool
+		Remove      bool
+		Interactive bool
+		MapUser     bool
+		Binds       []string
+		Mounts      []*Mount
+		VolumesFrom []string
+		Ports       []string
+		Healthcheck *Healthcheck
+		CPUShares   string
+		Memory      string
+		ShmSize     string
+		Platform    string
+		Privileged  bool
+		Ulimits     []string
+		CapAdd      []string
+		Tmpfs       []string
+		Devices     []string
+	}
+
+	// Mount is an additional mount of a container. The type is one of
+	// bind, volume, or tmpfs.
+	Mount struct {
+		Type     string
+		Source   string
+		Target   string
+		ReadOnly bool
+	}
+
+	// Healthcheck overrides the healthcheck of the image of a container.
+	// The command is run by the default shell of the container.
+	Healthcheck struct {
+		Command     string
+		Interval    time.Duration
+		StartPeriod time.Duration
+		Timeout     time.Duration
+		Retries     int
+	}
+
+	// BuildOptions describes an image to build from the given context
+	// directory. A relative dockerfile is relative to the working directory
+	// of the process.
+	BuildOptions struct {
+		ContextDir string
+		Dockerfile string
+		Target     string
+		Tags       []string
+		Labels     []string
+	}
+
+	// LogsOptions selects the output of a container to read. A zero tail
+	// reads the entire output.
+	LogsOptions struct {
+		Tail   int
+		Follow bool
+	}
+
+	// ExitError is returned by Run, RunForOutput, and Exec when a foreground
+	// container or command exits with a non-zero status.
+	ExitError struct {
+		Code int
+	}
+)
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
@@ -0,0 +1,90 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/logging"
+)
+
+// podmanRuntime invokes the podman command line client. The client accepts
+// the same subcommands as the docker client for the operations of a run,
+// so only the operations whose semantics differ are overridden.
+type podmanRuntime struct {
+	*cliRuntime
+}
+
+func newPodmanRuntime(logger logging.Logger, runner command.Runner) *podmanRuntime {
+	return &podmanRuntime{
+		cliRuntime: newCLIRuntime(PodmanExecutable, logger, runner),
+	}
+}
+
+func (r *podmanRuntime) Run(ctx context.Context, options *RunOptions, logger logging.Logger, prefix *logging.Prefix) error {
+	return r.runContainer(ctx, r.RunArgs(options), options.Detach, logger, prefix)
+}
+
+func (r *podmanRuntime) RunForOutput(ctx context.Context, options *RunOptions) (string, string, error) {
+	return r.runner.RunForOutput(ctx, r.RunArgs(options), nil)
+}
+
+func (r *podmanRuntime) CreateContainer(ctx context.Context, options *RunOptions) error {
+	_, _, err := r.runner.RunForOutput(
+		ctx,
+		r.runArgs("create", options, r.addNetworkOptions, r.addUserOptions),
+		nil,
+	)
+
+	return err
+}
+
+func (r *podmanRuntime) RunArgs(options *RunOptions) []string {
+	return r.runArgs("run", options, r.addNetworkOptions, r.addUserOptions)
+}
+
+// addNetworkOptions attaches the alias to the network option itself. This
+// form is honored by every podman network backend.
+func (r *podmanRuntime) addNetworkOptions(cb *command.Builder, network, alias string) {
+	if alias == "" {
+		cb.AddFlagValue("--network", network)
+		return
+	}
+
+	cb.AddFlagValue("--network", fmt.Sprintf("%s:alias=%s", network, alias))
+}
+
+// addUserOptions maps the user invoking ij to the same uid and gid within
+// the container. Otherwise, files written by a rootless container are owned
+// by a subordinate id of the user on the host.
+func (r *podmanRuntime) addUserOptions(cb *command.Builder) {
+	cb.AddFlag("--userns=keep-id")
+}
+
+// Inspect returns the current state of the given container. Podman schedules
+// healthchecks with systemd timers, which are unavailable in most build
+// environments, so the healthcheck of a starting container is run explicitly
+// before its state is read.
+func (r *podmanRuntime) Inspect(ctx context.Context, containerName string) (*ContainerState, error) {
+	state, err := r.cliRuntime.Inspect(ctx, containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Status == "running" && state.Health == "starting" {
+		// A failing healthcheck exits with a non-zero status, but its
+		// outcome is read from the state of the container instead
+		_, _ = r.output(ctx, "healthcheck", "run", containerName)
+
+		if state, err = r.cliRuntime.Inspect(ctx, containerName); err != nil {
+			return nil, err
+		}
+	}
+
+	// Older versions of podman report exited containers as stopped
+	if state.Status == "stopped" {
+		state.Status = "exited"
+	}
+
+	return state, nil
+}
@@ -0,0 +1,91 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aphistic/sweet"
+	. "github.com/efritz/go-mockgen/matchers"
+	"github.com/ij-build/ij/logging"
+	. "github.com/onsi/gomega"
+)
+
+type PodmanSuite struct{}
+
+func (s *PodmanSuite) TestCommandArgs(t sweet.T) {
+	runtime := newPodmanRuntime(logging.NilLogger, NewMockRunner())
+	Expect(runtime.commandArgs("run", "--rm")).To(Equal([]string{"podman", "run", "--rm"}))
+}
+
+func (s *PodmanSuite) TestRunOptions(t sweet.T) {
+	runtime := newPodmanRuntime(logging.NilLogger, NewMockRunner())
+
+	args := runtime.RunArgs(&RunOptions{
+		Image:   "postgres",
+		Network: "abcdef0",
+		Alias:   "db",
+		MapUser: true,
+	})
+
+	Expect(args).To(Equal([]string{
+		"podman",
+		"run",
+		"--userns=keep-id",
+		"--network",
+		"abcdef0:alias=db",
+		"postgres",
+	}))
+}
+
+func (s *PodmanSuite) TestRunOptionsNoAlias(t sweet.T) {
+	runner := NewMockRunner()
+	runtime := newPodmanRuntime(logging.NilLogger, runner)
+
+	_, _, err := runtime.RunForOutput(context.Background(), &RunOptions{
+		Image:   "busybox:1",
+		Network: "abcdef0",
+		Remove:  true,
+	})
+
+	Expect(err).To(BeNil())
+	Expect(runner.RunForOutputFunc).To(BeCalledWith(BeAnything(), []string{
+		"podman", "run", "--rm", "--network", "abcdef0", "busybox:1",
+	}, BeAnything()))
+}
+
+func (s *PodmanSuite) TestInspectRunsHealthcheck(t sweet.T) {
+	runner := NewMockRunner()
+	runner.RunForOutputFunc.PushReturn("running 0 0 starting\n", "", nil)
+	runner.RunForOutputFunc.PushReturn("unhealthy\n", "", fmt.Errorf("exit status 1"))
+	runner.RunForOutputFunc.PushReturn("running 0 0 healthy\n", "", nil)
+
+	state, err := newPodmanRuntime(logging.NilLogger, runner).Inspect(
+		context.Background(),
+		"c1",
+	)
+
+	Expect(err).To(BeNil())
+	Expect(state.Health).To(Equal("healthy"))
+	Expect(runner.RunForOutputFunc).To(BeCalledN(3))
+	Expect(runner.RunForOutputFunc.History()[1].Arg1).To(Equal([]string{
+		"podman", "healthcheck", "run", "c1",
+	}))
+}
+
+func (s *PodmanSuite) TestInspectStopped(t sweet.T) {
+	runner := NewMockRunner()
+	runner.RunForOutputFunc.SetDefaultReturn("stopped 2 0\n", "", nil)
+
+	state, err := newPodmanRuntime(logging.NilLogger, runner).Inspect(
+		context.Background(),
+		"c1",
+	)
+
+	Expect(err).To(BeNil())
+	Expect(state).To(Equal(&ContainerState{
+		Status:   "exited",
+		ExitCode: 2,
+	}))
+
+	Expect(runner.RunForOutputFunc).To(BeCalledOnce())
+}
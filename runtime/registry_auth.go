@@ -0,0 +1,224 @@
+package runtime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type (
+	// dockerConfig holds the credentials stored by the command line client
+	// on login.
+	dockerConfig struct {
+		Auths       map[string]*dockerConfigAuth `json:"auths"`
+		CredsStore  string                       `json:"credsStore"`
+		CredHelpers map[string]string            `json:"credHelpers"`
+	}
+
+	dockerConfigAuth struct {
+		Auth          string `json:"auth"`
+		IdentityToken string `json:"identitytoken"`
+	}
+
+	registryAuth struct {
+		Username      string `json:"username,omitempty"`
+		Password      string `json:"password,omitempty"`
+		IdentityToken string `json:"identitytoken,omitempty"`
+		ServerAddress string `json:"serveraddress,omitempty"`
+	}
+
+	credentialHelperOutput struct {
+		Username string
+		Secret   string
+	}
+)
+
+// DefaultRegistry is the server under which the command line client stores
+// the credentials of Docker Hub.
+const DefaultRegistry = "https://index.docker.io/v1/"
+
+// registryAuthHeader returns the header which authenticates a pull or push
+// of the given repository. Anonymous access is requested if no credentials
+// are stored for its registry.
+func (r *engineRuntime) registryAuthHeader(ctx context.Context, repository string) (http.Header, error) {
+	config, err := readDockerConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := r.registryAuth(ctx, config, registryServer(repository))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(auth)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.Header{
+		"X-Registry-Auth": []string{base64.URLEncoding.EncodeToString(data)},
+	}, nil
+}
+
+// registryConfigHeader returns the header which authenticates the pulls of
+// base images during a build with the credentials of every registry to
+// which the command line client has logged in.
+func (r *engineRuntime) registryConfigHeader(ctx context.Context) (http.Header, error) {
+	config, err := readDockerConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	auths := map[string]*registryAuth{}
+	for server := range config.Auths {
+		auth, err := r.registryAuth(ctx, config, server)
+		if err != nil {
+			return nil, err
+		}
+
+		auths[server] = auth
+	}
+
+	data, err := json.Marshal(auths)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.Header{
+		"X-Registry-Config": []string{base64.URLEncoding.EncodeToString(data)},
+	}, nil
+}
+
+// registryAuth returns the credentials stored for the given server, either
+// in the config file or by a credential helper.
+func (r *engineRuntime) registryAuth(ctx context.Context, config *dockerConfig, server string) (*registryAuth, error) {
+	helper := config.CredsStore
+	if name, ok := config.CredHelpers[server]; ok {
+		helper = name
+	}
+
+	if helper != "" {
+		return r.helperAuth(ctx, helper, server)
+	}
+
+	for key, entry := range config.Auths {
+		if normalizeRegistry(key) != normalizeRegistry(server) {
+			continue
+		}
+
+		auth := &registryAuth{
+			IdentityToken: entry.IdentityToken,
+			ServerAddress: server,
+		}
+
+		if entry.Auth != "" {
+			data, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("malformed credentials for %s", server)
+			}
+
+			parts := strings.SplitN(string(data), ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("malformed credentials for %s", server)
+			}
+
+			auth.Username, auth.Password = parts[0], parts[1]
+		}
+
+		return auth, nil
+	}
+
+	return &registryAuth{ServerAddress: server}, nil
+}
+
+// helperAuth invokes the given credential helper to retrieve the credentials
+// of the given server.
+func (r *engineRuntime) helperAuth(ctx context.Context, helper, server string) (*registryAuth, error) {
+	out, errOut, err := r.runner.RunForOutput(
+		ctx,
+		[]string{"docker-credential-" + helper, "get"},
+		ioutil.NopCloser(strings.NewReader(server)),
+	)
+
+	if err != nil {
+		if strings.Contains(out+errOut, "credentials not found") {
+			return &registryAuth{ServerAddress: server}, nil
+		}
+
+		return nil, fmt.Errorf("failed to get credentials for %s from docker-credential-%s: %s", server, helper, err.Error())
+	}
+
+	payload := &credentialHelperOutput{}
+	if err := json.Unmarshal([]byte(out), payload); err != nil {
+		return nil, fmt.Errorf("malformed output of docker-credential-%s", helper)
+	}
+
+	if payload.Username == "<token>" {
+		return &registryAuth{IdentityToken: payload.Secret, ServerAddress: server}, nil
+	}
+
+	return &registryAuth{
+		Username:      payload.Username,
+		Password:      payload.Secret,
+		ServerAddress: server,
+	}, nil
+}
+
+//
+// Helpers
+
+// readDockerConfig reads the config file of the command line client. A
+// missing file holds no credentials.
+func readDockerConfig() (*dockerConfig, error) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return &dockerConfig{}, nil
+		}
+
+		dir = filepath.Join(home, ".docker")
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &dockerConfig{}, nil
+		}
+
+		return nil, err
+	}
+
+	config := &dockerConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("malformed docker config file: %s", err.Error())
+	}
+
+	return config, nil
+}
+
+// registryServer returns the server of the registry which holds the given
+// repository. A repository whose first component is not a hostname is held
+// by Docker Hub.
+func registryServer(repository string) string {
+	parts := strings.SplitN(repository, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0]
+	}
+
+	return DefaultRegistry
+}
+
+// normalizeRegistry strips the scheme and path from a server address so that
+// the keys written by different versions of the command line client match.
+func normalizeRegistry(server string) string {
+	server = strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+	return strings.SplitN(server, "/", 2)[0]
+}
@@ -0,0 +1,216 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ij-build/ij/command"
+	"github.com/ij-build/ij/logging"
+)
+
+type (
+	// ContainerRuntime performs the container operations of a run.
+	ContainerRuntime interface {
+		// Run creates and starts a container. Unless the container is
+		// detached, its output is written to the given logger and Run
+		// blocks until it exits. A non-zero exit status is returned as
+		// an *ExitError.
+		Run(ctx context.Context, options *RunOptions, logger logging.Logger, prefix *logging.Prefix) error
+
+		// RunForOutput creates and starts a container and blocks until it
+		// exits. Its standard output and standard error are returned
+		// instead of being logged. A non-zero exit status is returned as
+		// an *ExitError.
+		RunForOutput(ctx context.Context, options *RunOptions) (string, string, error)
+
+		// CreateContainer creates a container without starting it.
+		CreateContainer(ctx context.Context, options *RunOptions) error
+
+		// Build builds an image and writes the build output to the
+		// given logger.
+		Build(ctx context.Context, options *BuildOptions, logger logging.Logger, prefix *logging.Prefix) error
+
+		// Push pushes an image to its registry and writes the progress
+		// to the given logger.
+		Push(ctx context.Context, image string, logger logging.Logger, prefix *logging.Prefix) error
+
+		// Exec runs a command in the given running container and returns
+		// its standard output and standard error. A non-zero exit status
+		// is returned as an *ExitError.
+		Exec(ctx context.Context, containerName string, args []string) (string, string, error)
+
+		// Logs writes the output of the given container to the given
+		// logger. If the logs are followed, Logs blocks until the
+		// container stops.
+		Logs(ctx context.Context, containerName string, options *LogsOptions, logger logging.Logger, prefix *logging.Prefix) error
+
+		// Commit creates an image from the given container and returns
+		// the identifier of the image.
+		Commit(ctx context.Context, containerName string) (string, error)
+
+		// RunArgs, BuildArgs, PushArgs, and RemoveImageArgs return the
+		// args which invoke the command line client to perform the
+		// equivalent operation. These are used for interactive containers
+		// and dry runs.
+		RunArgs(options *RunOptions) []string
+		BuildArgs(options *BuildOptions) []string
+		PushArgs(image string) []string
+		RemoveImageArgs(image string) []string
+
+		// Ping returns an error if the runtime is not reachable.
+		Ping(ctx context.Context) error
+
+		// Inspect returns the current state of the given container.
+		Inspect(ctx context.Context, containerName string) (*ContainerState, error)
+
+		// HasHealthcheck determines if the given container was created
+		// with a healthcheck (either from the image or from options).
+		HasHealthcheck(ctx context.Context, containerName string) (bool, error)
+
+		// ImageID returns the content-addressable identifier of an image.
+		ImageID(ctx context.Context, image string) (string, error)
+
+		// Kill sends SIGKILL to the given container.
+		Kill(ctx context.Context, containerName string) error
+
+		// Remove forcibly removes the given container.
+		Remove(ctx context.Context, containerName string) error
+
+		// RemoveImage forcibly removes the given image.
+		RemoveImage(ctx context.Context, image string) error
+
+		// CopyTo extracts the given tar archive into the given directory
+		// of a container. The ownership of archived files is preserved.
+		CopyTo(ctx context.Context, containerName, path string, archive io.Reader) error
+
+		// CopyFrom copies the given path of a container into the given
+		// host directory. A path ending in /. copies the contents of the
+		// directory instead. ErrNoSuchPath is returned if the path does
+		// not exist.
+		CopyFrom(ctx context.Context, containerName, path, dest string) error
+
+		// CreateVolume creates a volume with the given labels.
+		CreateVolume(ctx context.Context, name string, labels map[string]string) error
+
+		// VolumeLabels returns the labels of the given volume. An error
+		// is returned if the volume does not exist.
+		VolumeLabels(ctx context.Context, name string) (map[string]string, error)
+
+		// ListVolumes returns the names of the volumes having the given
+		// label, in the form key=value.
+		ListVolumes(ctx context.Context, label string) ([]string, error)
+
+		RemoveVolume(ctx context.Context, name string) error
+		CreateNetwork(ctx context.Context, name string) error
+		RemoveNetwork(ctx context.Context, name string) error
+		DisconnectNetwork(ctx context.Context, name, containerName string) error
+
+		// Login stores credentials for the given registry so that
+		// subsequent pulls and pushes are authenticated.
+		Login(ctx context.Context, server, username, password string) error
+		Logout(ctx context.Context, server string) error
+	}
+
+	ContainerState struct {
+		Status       string
+		ExitCode     int
+		RestartCount int
+		Health       string
+	}
+)
+
+const (
+	DockerExecutable  = "docker"
+	PodmanExecutable  = "podman"
+	DefaultSocketPath = "/var/run/docker.sock"
+
+	// PingTimeout is the maximum time to wait for the daemon to respond
+	// before the runtime is considered unreachable.
+	PingTimeout = time.Second
+)
+
+// ErrNoSuchPath is returned when copying a path which does not exist out
+// of a container.
+var ErrNoSuchPath = errors.New("no such path")
+
+// NewRuntime creates a runtime for the given executable (docker, if empty).
+// A docker runtime talks to the Engine API of the Docker daemon over its
+// unix socket. If the daemon is configured to be reached by other means or
+// the socket does not respond, the runtime invokes the docker command line
+// client for every operation instead. A podman runtime always invokes the
+// podman command line client.
+func NewRuntime(ctx context.Context, name string, logger logging.Logger) ContainerRuntime {
+	if name == PodmanExecutable {
+		return NewCLIRuntime(name, logger)
+	}
+
+	cli := newCLIRuntime(
+		DockerExecutable,
+		logger,
+		command.NewRunner(logger),
+	)
+
+	socketPath, ok := engineSocketPath()
+	if !ok {
+		return cli
+	}
+
+	engine := newEngineRuntime(socketPath, cli, logger)
+
+	if err := engine.Ping(ctx); err != nil {
+		if logger != nil {
+			logger.Debug(
+				nil,
+				"Engine API is unreachable, falling back to the command line client: %s",
+				err.Error(),
+			)
+		}
+
+		return cli
+	}
+
+	return engine
+}
+
+// NewCLIRuntime creates a runtime which invokes the command line client of
+// the given executable (docker, if empty) for every operation.
+func NewCLIRuntime(name string, logger logging.Logger) ContainerRuntime {
+	runner := command.NewRunner(logger)
+
+	if name == PodmanExecutable {
+		return newPodmanRuntime(logger, runner)
+	}
+
+	return newCLIRuntime(
+		DockerExecutable,
+		logger,
+		runner,
+	)
+}
+
+//
+// Helpers
+
+// engineSocketPath returns the path of the socket on which the daemon used
+// by the command line client listens, if the client talks to the daemon over
+// a unix socket.
+func engineSocketPath() (string, bool) {
+	if context := os.Getenv("DOCKER_CONTEXT"); context != "" && context != "default" {
+		return "", false
+	}
+
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		return DefaultSocketPath, true
+	}
+
+	if strings.HasPrefix(host, "unix://") {
+		return strings.TrimPrefix(host, "unix://"), true
+	}
+
+	return "", false
+}
@@ -23,7 +23,9 @@ const (
 	ScratchDir   = ".ij"
 	WorkspaceDir = "workspace"
 	ScriptsDir   = "scripts"
+	ArtifactsDir = "artifacts"
 	LogsDir      = "logs"
+	StateFile    = "state.json"
 	OutLogSuffix = ".out.log"
 	ErrLogSuffix = ".err.log"
 )
@@ -81,6 +83,12 @@ func (s *ScratchSpace) Workspace() string {
 	return s.workspace
 }
 
+// Artifacts returns the directory which holds the outputs of the given
+// task.
+func (s *ScratchSpace) Artifacts(task string) string {
+	return filepath.Join(s.runpath, ArtifactsDir, task)
+}
+
 func (s *ScratchSpace) WriteScript(script string) (string, error) {
 	scriptID, err := util.MakeID()
 	if err != nil {
@@ -110,12 +118,14 @@ func (s *ScratchSpace) MakeLogFiles(prefix string) (*os.File, *os.File, error) {
 		return nil, nil, err
 	}
 
-	outfile, err := os.Create(outpath)
+	// Append so that a resumed run does not clobber the logs of the
+	// run that preceded it
+	outfile, err := openLogFile(outpath)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	errfile, err := os.Create(errpath)
+	errfile, err := openLogFile(errpath)
 	if err != nil {
 		outfile.Close()
 		return nil, nil, err
@@ -143,6 +153,15 @@ func (s *ScratchSpace) Prune(logger logging.Logger) error {
 		if err := os.RemoveAll(filepath.Join(s.runpath, ScriptsDir)); err != nil {
 			return err
 		}
+
+		logger.Debug(
+			nil,
+			"Removing artifacts directory",
+		)
+
+		if err := os.RemoveAll(filepath.Join(s.runpath, ArtifactsDir)); err != nil {
+			return err
+		}
 	}
 
 	logger.Debug(
@@ -178,3 +197,7 @@ func buildPath(path string) (string, error) {
 
 	return path, nil
 }
+
+func openLogFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+}
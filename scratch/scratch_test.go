@@ -24,6 +24,7 @@ func (s *ScratchSuite) TestPaths(t sweet.T) {
 	Expect(scratch.Scratch()).To(Equal(filepath.Join(name, ".ij")))
 	Expect(scratch.Runpath()).To(Equal(filepath.Join(name, ".ij", "abcdef0")))
 	Expect(scratch.Workspace()).To(Equal(filepath.Join(name, ".ij", "abcdef0", "workspace")))
+	Expect(scratch.Artifacts("build")).To(Equal(filepath.Join(name, ".ij", "abcdef0", "artifacts", "build")))
 
 	info, err := os.Stat(scratch.Workspace())
 	Expect(err).To(BeNil())
@@ -76,6 +77,27 @@ func (s *ScratchSuite) TestMakeLogFiles(t sweet.T) {
 	Expect(string(errContent)).To(Equal("log2\nlog4\n"))
 }
 
+func (s *ScratchSuite) TestMakeLogFilesAppends(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	scratch := NewScratchSpace("abcdef0", name, name, true)
+	scratch.Setup()
+
+	for _, line := range []string{"log1\n", "log2\n"} {
+		outFile, errFile, err := scratch.MakeLogFiles("build")
+		Expect(err).To(BeNil())
+
+		outFile.Write([]byte(line))
+		outFile.Close()
+		errFile.Close()
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(name, ".ij", "abcdef0", "logs", "build.out.log"))
+	Expect(err).To(BeNil())
+	Expect(string(content)).To(Equal("log1\nlog2\n"))
+}
+
 func (s *ScratchSuite) TestPrune(t sweet.T) {
 	name, _ := ioutil.TempDir("", "ij-test")
 	defer os.RemoveAll(name)
@@ -137,6 +159,7 @@ func (s *ScratchSuite) TestPruneDiscardWorkspace(t sweet.T) {
 	Expect(err).To(BeNil())
 	_, err = scratch.WriteScript("foo")
 	Expect(err).To(BeNil())
+	Expect(os.MkdirAll(scratch.Artifacts("build"), os.ModePerm)).To(BeNil())
 
 	// Populate log dir
 	outFile, errFile, err := scratch.MakeLogFiles("build")
@@ -157,6 +180,10 @@ func (s *ScratchSuite) TestPruneDiscardWorkspace(t sweet.T) {
 	// Scripts dir is removed
 	_, err = os.Stat(scratch.Runpath() + "/scripts")
 	Expect(os.IsNotExist(err)).To(BeTrue())
+
+	// Artifacts dir is removed
+	_, err = os.Stat(scratch.Runpath() + "/artifacts")
+	Expect(os.IsNotExist(err)).To(BeTrue())
 }
 
 func (s *ScratchSuite) TestTeardown(t sweet.T) {
@@ -0,0 +1,131 @@
+package subcommand
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/options"
+	"github.com/ij-build/ij/runtime"
+	"github.com/ij-build/ij/volume"
+)
+
+func NewCacheListCommand(appOptions *options.AppOptions) CommandRunner {
+	return func(config *config.Config) error {
+		return withCaches(config, appOptions, func(ctx context.Context, caches *volume.Caches, logger logging.Logger) error {
+			volumes, err := caches.List(ctx)
+			if err != nil {
+				return fmt.Errorf(
+					"failed to list cache volumes: %s",
+					err.Error(),
+				)
+			}
+
+			if len(volumes) == 0 {
+				fmt.Println("No cache volumes exist for this project.")
+				return nil
+			}
+
+			writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintf(writer, "CACHE\tKEY\tVOLUME\n")
+
+			for _, volume := range volumes {
+				fmt.Fprintf(
+					writer,
+					"%s\t%s\t%s\n",
+					volume.Cache,
+					volume.Key,
+					volume.Name,
+				)
+			}
+
+			return writer.Flush()
+		})
+	}
+}
+
+func NewCachePruneCommand(appOptions *options.AppOptions, cacheOptions *options.CacheOptions) CommandRunner {
+	return func(config *config.Config) error {
+		return withCaches(config, appOptions, func(ctx context.Context, caches *volume.Caches, logger logging.Logger) error {
+			volumes, err := caches.List(ctx)
+			if err != nil {
+				return fmt.Errorf(
+					"failed to list cache volumes: %s",
+					err.Error(),
+				)
+			}
+
+			for _, volume := range volumes {
+				if !shouldPruneCache(volume.Cache, cacheOptions.Caches) {
+					continue
+				}
+
+				logger.Info(
+					nil,
+					"Removing volume %s of cache %s",
+					volume.Name,
+					volume.Cache,
+				)
+
+				if err := caches.Remove(ctx, volume.Name); err != nil {
+					return fmt.Errorf(
+						"failed to remove cache volume: %s",
+						err.Error(),
+					)
+				}
+			}
+
+			return nil
+		})
+	}
+}
+
+func withCaches(
+	config *config.Config,
+	appOptions *options.AppOptions,
+	f func(context.Context, *volume.Caches, logging.Logger) error,
+) error {
+	logProcessor := logging.NewProcessor(
+		appOptions.Quiet,
+		appOptions.Verbose,
+		!appOptions.DisableColor,
+	)
+
+	logProcessor.Start()
+	defer logProcessor.Shutdown()
+
+	logger := logProcessor.Logger(
+		logging.NilWriter,
+		logging.NilWriter,
+		true,
+	)
+
+	ctx := context.Background()
+
+	caches := volume.NewCaches(
+		appOptions.ProjectDir,
+		runtime.NewRuntime(ctx, config.Options.Runtime, logger),
+		logger,
+	)
+
+	return f(ctx, caches, logger)
+}
+
+// shouldPruneCache determines if the volumes of the given cache should be
+// removed. An empty list of names selects the volumes of every cache.
+func shouldPruneCache(cache string, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+
+	for _, name := range names {
+		if name == cache {
+			return true
+		}
+	}
+
+	return false
+}
@@ -11,16 +11,20 @@ func Run(
 	command string,
 	config *config.Config,
 	appOptions *options.AppOptions,
+	cacheOptions *options.CacheOptions,
 	cleanOptions *options.CleanOptions,
 	runOptions *options.RunOptions,
 ) error {
 	runners := map[string]CommandRunner{
+		"cache list":  NewCacheListCommand(appOptions),
+		"cache prune": NewCachePruneCommand(appOptions, cacheOptions),
 		"clean":       NewCleanCommand(appOptions, cleanOptions),
 		"login":       NewLoginCommand(appOptions),
 		"logout":      NewLogoutCommand(appOptions),
 		"rotate-logs": NewRotateLogsCommand(appOptions),
 		"run":         NewRunCommand(appOptions, runOptions),
 		"show-config": NewShowConfigCommand(appOptions),
+		"show-params": NewShowParamsCommand(appOptions),
 	}
 
 	runner, ok := runners[command]
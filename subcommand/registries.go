@@ -9,6 +9,7 @@ import (
 	"github.com/ij-build/ij/logging"
 	"github.com/ij-build/ij/options"
 	"github.com/ij-build/ij/registry"
+	"github.com/ij-build/ij/runtime"
 )
 
 func NewLoginCommand(appOptions *options.AppOptions) CommandRunner {
@@ -60,11 +61,14 @@ func withRegistrySet(
 		environment.New(appOptions.Env),
 	)
 
+	ctx := context.Background()
+
 	registrySet, err := registry.NewRegistrySet(
-		context.Background(),
+		ctx,
 		logger,
 		registryEnv,
 		config.Registries,
+		runtime.NewRuntime(ctx, config.Options.Runtime, logger),
 	)
 
 	if err != nil {
@@ -3,20 +3,21 @@ package subcommand
 import (
 	"context"
 	"fmt"
-	"time"
 
-	"github.com/ij-build/ij/command"
 	"github.com/ij-build/ij/config"
 	"github.com/ij-build/ij/options"
 	"github.com/ij-build/ij/runner"
+	"github.com/ij-build/ij/runtime"
 )
 
 var ErrBuildFailed = fmt.Errorf("subcommand failed")
 
 func NewRunCommand(appOptions *options.AppOptions, runOptions *options.RunOptions) CommandRunner {
 	return func(config *config.Config) error {
-		if !ensureDocker() {
-			return fmt.Errorf("docker is not running")
+		if !runOptions.DryRun {
+			if err := ensureRuntime(config.Options.Runtime); err != nil {
+				return err
+			}
 		}
 
 		for _, name := range runOptions.Plans {
@@ -28,6 +29,14 @@ func NewRunCommand(appOptions *options.AppOptions, runOptions *options.RunOption
 			}
 		}
 
+		if err := config.ApplyParameters(runOptions.Plans, runOptions.Params); err != nil {
+			return err
+		}
+
+		if runOptions.DryRun {
+			return dryRun(config, appOptions, runOptions)
+		}
+
 		runner, err := runner.SetupRunner(
 			config,
 			appOptions,
@@ -46,21 +55,33 @@ func NewRunCommand(appOptions *options.AppOptions, runOptions *options.RunOption
 	}
 }
 
-func ensureDocker() bool {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+func dryRun(config *config.Config, appOptions *options.AppOptions, runOptions *options.RunOptions) error {
+	runner, err := runner.SetupDryRunner(
+		config,
+		appOptions,
+		runOptions,
+	)
 
-	args := []string{
-		"docker",
-		"ps",
-		"-q",
+	if err != nil {
+		return err
 	}
 
-	_, _, err := command.NewRunner(nil).RunForOutput(
-		ctx,
-		args,
-		nil,
-	)
+	if !runner.Run(runOptions.Plans) {
+		return ErrBuildFailed
+	}
+
+	return nil
+}
+
+func ensureRuntime(name string) error {
+	if name == "" {
+		name = runtime.DockerExecutable
+	}
+
+	ctx := context.Background()
+	if err := runtime.NewRuntime(ctx, name, nil).Ping(ctx); err != nil {
+		return fmt.Errorf("%s is not running", name)
+	}
 
-	return err == nil
+	return nil
 }
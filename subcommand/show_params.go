@@ -0,0 +1,75 @@
+package subcommand
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ij-build/ij/config"
+	"github.com/ij-build/ij/options"
+)
+
+func NewShowParamsCommand(appOptions *options.AppOptions) CommandRunner {
+	return func(config *config.Config) error {
+		names := []string{}
+		for name, plan := range config.Plans {
+			if len(plan.Parameters) > 0 {
+				names = append(names, name)
+			}
+		}
+
+		if len(names) == 0 {
+			fmt.Println("No plans declare parameters.")
+			return nil
+		}
+
+		sort.Strings(names)
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for i, name := range names {
+			if i > 0 {
+				fmt.Fprintln(writer)
+			}
+
+			fmt.Fprintf(writer, "%s:\n", name)
+
+			for _, parameter := range config.Plans[name].Parameters {
+				fmt.Fprintf(
+					writer,
+					"  %s\t%s\t%s\t%s\n",
+					parameter.Name,
+					describeParameterType(parameter),
+					describeParameterDefault(parameter),
+					parameter.Description,
+				)
+			}
+		}
+
+		return writer.Flush()
+	}
+}
+
+func describeParameterType(parameter *config.Parameter) string {
+	switch parameter.Type {
+	case "":
+		return config.ParameterTypeString
+	case config.ParameterTypeEnum:
+		return fmt.Sprintf("enum(%s)", strings.Join(parameter.Values, "|"))
+	}
+
+	return parameter.Type
+}
+
+func describeParameterDefault(parameter *config.Parameter) string {
+	if parameter.Required {
+		return "required"
+	}
+
+	if parameter.Default != "" {
+		return fmt.Sprintf("default %s", parameter.Default)
+	}
+
+	return "optional"
+}
@@ -16,6 +16,7 @@ func TestMain(m *testing.M) {
 
 		s.AddSuite(&IDSuite{})
 		s.AddSuite(&ParallelSuite{})
+		s.AddSuite(&SemaphoreSuite{})
 		s.AddSuite(&SubsetSuite{})
 	})
 }
@@ -0,0 +1,41 @@
+package util
+
+import "context"
+
+// Semaphore bounds the number of concurrent holders. A nil semaphore
+// places no bound and never blocks.
+type Semaphore chan struct{}
+
+// NewSemaphore creates a semaphore with the given number of slots. A
+// non-positive size returns a nil (unbounded) semaphore.
+func NewSemaphore(size int) Semaphore {
+	if size <= 0 {
+		return nil
+	}
+
+	return make(Semaphore, size)
+}
+
+// Acquire blocks until a slot is available or the context is canceled.
+// Returns false if the slot was not acquired.
+func (s Semaphore) Acquire(ctx context.Context) bool {
+	if s == nil {
+		return true
+	}
+
+	select {
+	case s <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Release returns a slot previously taken by Acquire.
+func (s Semaphore) Release() {
+	if s == nil {
+		return
+	}
+
+	<-s
+}
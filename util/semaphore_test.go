@@ -0,0 +1,50 @@
+package util
+
+import (
+	"context"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type SemaphoreSuite struct{}
+
+func (s *SemaphoreSuite) TestAcquire(t sweet.T) {
+	var (
+		semaphore = NewSemaphore(2)
+		acquired  = make(chan struct{})
+	)
+
+	Expect(semaphore.Acquire(context.Background())).To(BeTrue())
+	Expect(semaphore.Acquire(context.Background())).To(BeTrue())
+
+	go func() {
+		defer close(acquired)
+		semaphore.Acquire(context.Background())
+	}()
+
+	Consistently(acquired).ShouldNot(BeClosed())
+	semaphore.Release()
+	Eventually(acquired).Should(BeClosed())
+}
+
+func (s *SemaphoreSuite) TestAcquireCanceled(t sweet.T) {
+	semaphore := NewSemaphore(1)
+	Expect(semaphore.Acquire(context.Background())).To(BeTrue())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	Expect(semaphore.Acquire(ctx)).To(BeFalse())
+}
+
+func (s *SemaphoreSuite) TestUnbounded(t sweet.T) {
+	semaphore := NewSemaphore(0)
+	Expect(semaphore).To(BeNil())
+
+	for i := 0; i < 10; i++ {
+		Expect(semaphore.Acquire(context.Background())).To(BeTrue())
+	}
+
+	semaphore.Release()
+}
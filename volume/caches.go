@@ -0,0 +1,129 @@
+package volume
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
+)
+
+type (
+	// Caches manages the volumes backing the dependency caches of a
+	// project. Each distinct key of a cache is held in its own volume,
+	// which is labeled with the project directory, the cache name, and
+	// the key so that the volumes of a project can be listed and pruned.
+	Caches struct {
+		projectDir string
+		runtime    runtime.ContainerRuntime
+		logger     logging.Logger
+		ensured    map[string]struct{}
+		mutex      sync.Mutex
+	}
+
+	CacheVolume struct {
+		Name  string
+		Cache string
+		Key   string
+	}
+)
+
+const (
+	ProjectLabel  = "ij.project"
+	CacheLabel    = "ij.cache"
+	CacheKeyLabel = "ij.cache-key"
+)
+
+func NewCaches(
+	projectDir string,
+	runtime runtime.ContainerRuntime,
+	logger logging.Logger,
+) *Caches {
+	return &Caches{
+		projectDir: projectDir,
+		runtime:    runtime,
+		logger:     logger,
+		ensured:    map[string]struct{}{},
+	}
+}
+
+// CacheVolumeName returns the name of the volume backing the given key of
+// a cache of the given project. The cache name and key are hashed together
+// so that distinct pairs never share a volume; their readable values are
+// held in the labels of the volume.
+func CacheVolumeName(projectDir, cache, key string) string {
+	projectSum := sha256.Sum256([]byte(projectDir))
+	cacheSum := sha256.Sum256([]byte(cache + "\x00" + key))
+	return fmt.Sprintf("ij-%x-%x", projectSum[:6], cacheSum[:12])
+}
+
+// Ensure creates the volume backing the given key of a cache unless it
+// already exists and returns its name.
+func (c *Caches) Ensure(ctx context.Context, cache, key string) (string, error) {
+	name := CacheVolumeName(c.projectDir, cache, key)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.ensured[name]; ok {
+		return name, nil
+	}
+
+	if _, err := c.runtime.VolumeLabels(ctx, name); err != nil {
+		c.logger.Info(
+			nil,
+			"Creating volume for cache %s",
+			cache,
+		)
+
+		labels := map[string]string{
+			ProjectLabel:  c.projectDir,
+			CacheLabel:    cache,
+			CacheKeyLabel: key,
+		}
+
+		if err := c.runtime.CreateVolume(ctx, name, labels); err != nil {
+			return "", err
+		}
+	}
+
+	c.ensured[name] = struct{}{}
+	return name, nil
+}
+
+// List returns the cache volumes of the project ordered by name.
+func (c *Caches) List(ctx context.Context) ([]*CacheVolume, error) {
+	names, err := c.runtime.ListVolumes(ctx, fmt.Sprintf("%s=%s", ProjectLabel, c.projectDir))
+	if err != nil {
+		return nil, err
+	}
+
+	volumes := []*CacheVolume{}
+	for _, name := range names {
+		labels, err := c.runtime.VolumeLabels(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		volumes = append(volumes, &CacheVolume{
+			Name:  name,
+			Cache: labels[CacheLabel],
+			Key:   labels[CacheKeyLabel],
+		})
+	}
+
+	sort.Slice(volumes, func(i, j int) bool {
+		return volumes[i].Name < volumes[j].Name
+	})
+
+	return volumes, nil
+}
+
+// Remove removes the given cache volume. A volume in use by a container
+// cannot be removed.
+func (c *Caches) Remove(ctx context.Context, name string) error {
+	return c.runtime.RemoveVolume(ctx, name)
+}
@@ -0,0 +1,98 @@
+package volume
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
+	. "github.com/onsi/gomega"
+)
+
+type CachesSuite struct{}
+
+func (s *CachesSuite) TestCacheVolumeName(t sweet.T) {
+	name := CacheVolumeName("/src/project", "gomod", "")
+	Expect(name).To(MatchRegexp(`^ij-[0-9a-f]{12}-[0-9a-f]{24}$`))
+	Expect(CacheVolumeName("/src/project", "gomod", "")).To(Equal(name))
+	Expect(CacheVolumeName("/src/other", "gomod", "")).NotTo(Equal(name))
+
+	Expect(CacheVolumeName("/src/project", "go", "mod-main")).NotTo(Equal(CacheVolumeName("/src/project", "go-mod", "main")))
+	Expect(CacheVolumeName("/src/project", "gomod", "feature/x")).NotTo(Equal(CacheVolumeName("/src/project", "gomod", "feature-x")))
+}
+
+func (s *CachesSuite) TestEnsureCreatesMissingVolume(t sweet.T) {
+	containerRuntime := runtime.NewFakeRuntime()
+
+	caches := NewCaches("/src/project", containerRuntime, logging.NilLogger)
+	name, err := caches.Ensure(context.Background(), "gomod", "master")
+	Expect(err).To(BeNil())
+	Expect(name).To(Equal(CacheVolumeName("/src/project", "gomod", "master")))
+	Expect(containerRuntime.Volumes).To(Equal(map[string]map[string]string{
+		name: map[string]string{
+			"ij.project":   "/src/project",
+			"ij.cache":     "gomod",
+			"ij.cache-key": "master",
+		},
+	}))
+
+	// Volumes are only checked once per run
+	containerRuntime.Errors["VolumeLabels"] = fmt.Errorf("utoh")
+	_, err = caches.Ensure(context.Background(), "gomod", "master")
+	Expect(err).To(BeNil())
+}
+
+func (s *CachesSuite) TestEnsureExistingVolume(t sweet.T) {
+	containerRuntime := runtime.NewFakeRuntime()
+	name := CacheVolumeName("/src/project", "gomod", "")
+	containerRuntime.Volumes[name] = map[string]string{}
+	containerRuntime.Errors["CreateVolume"] = fmt.Errorf("utoh")
+
+	caches := NewCaches("/src/project", containerRuntime, logging.NilLogger)
+	_, err := caches.Ensure(context.Background(), "gomod", "")
+	Expect(err).To(BeNil())
+}
+
+func (s *CachesSuite) TestEnsureCreateError(t sweet.T) {
+	containerRuntime := runtime.NewFakeRuntime()
+	containerRuntime.Errors["CreateVolume"] = fmt.Errorf("utoh")
+
+	caches := NewCaches("/src/project", containerRuntime, logging.NilLogger)
+	_, err := caches.Ensure(context.Background(), "gomod", "")
+	Expect(err).To(MatchError("utoh"))
+}
+
+func (s *CachesSuite) TestList(t sweet.T) {
+	containerRuntime := runtime.NewFakeRuntime()
+	containerRuntime.Volumes["vol-b"] = map[string]string{"ij.cache": "gomod", "ij.cache-key": "master", "ij.project": "/src/project"}
+	containerRuntime.Volumes["vol-a"] = map[string]string{"ij.cache": "npm", "ij.cache-key": "", "ij.project": "/src/project"}
+	containerRuntime.Volumes["vol-c"] = map[string]string{"ij.cache": "npm", "ij.cache-key": "", "ij.project": "/src/other"}
+
+	caches := NewCaches("/src/project", containerRuntime, logging.NilLogger)
+	volumes, err := caches.List(context.Background())
+	Expect(err).To(BeNil())
+	Expect(volumes).To(Equal([]*CacheVolume{
+		&CacheVolume{Name: "vol-a", Cache: "npm", Key: ""},
+		&CacheVolume{Name: "vol-b", Cache: "gomod", Key: "master"},
+	}))
+}
+
+func (s *CachesSuite) TestListError(t sweet.T) {
+	containerRuntime := runtime.NewFakeRuntime()
+	containerRuntime.Volumes["vol-a"] = map[string]string{"ij.project": "/src/project"}
+	containerRuntime.Errors["VolumeLabels"] = fmt.Errorf("utoh")
+
+	caches := NewCaches("/src/project", containerRuntime, logging.NilLogger)
+	_, err := caches.List(context.Background())
+	Expect(err).To(MatchError("utoh"))
+}
+
+func (s *CachesSuite) TestRemove(t sweet.T) {
+	containerRuntime := runtime.NewFakeRuntime()
+	containerRuntime.Volumes["vol-a"] = map[string]string{}
+
+	caches := NewCaches("/src/project", containerRuntime, logging.NilLogger)
+	Expect(caches.Remove(context.Background(), "vol-a")).To(BeNil())
+	Expect(containerRuntime.Volumes).To(BeEmpty())
+}
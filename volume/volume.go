@@ -0,0 +1,363 @@
+package volume
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/paths"
+	"github.com/ij-build/ij/runtime"
+)
+
+// Volume holds the workspace and scripts of a run in Docker volumes so
+// that no host path is mounted into a container. Files are transferred
+// as archives through a helper container that is never started, which
+// works against any daemon reachable by the container runtime.
+//
+// Containers using the workspace attach to the volume while they run, and
+// transfers into or out of the workspace wait until no container is
+// attached so that a stale copy never overwrites a file being written.
+type Volume struct {
+	runID   string
+	runtime runtime.ContainerRuntime
+	logger  logging.Logger
+	mutex   sync.RWMutex
+}
+
+const (
+	HelperImage   = "busybox:1"
+	WorkspacePath = "/workspace"
+	ScriptsPath   = "/scripts"
+)
+
+func NewVolume(
+	ctx context.Context,
+	runID string,
+	containerRuntime runtime.ContainerRuntime,
+	logger logging.Logger,
+) (*Volume, error) {
+	logger.Info(
+		nil,
+		"Creating workspace volume",
+	)
+
+	v := &Volume{
+		runID:   runID,
+		runtime: containerRuntime,
+		logger:  logger,
+	}
+
+	steps := []func() error{
+		func() error { return containerRuntime.CreateVolume(ctx, v.WorkspaceName(), nil) },
+		func() error { return containerRuntime.CreateVolume(ctx, v.ScriptsName(), nil) },
+		func() error {
+			return containerRuntime.CreateContainer(ctx, &runtime.RunOptions{
+				Name:  v.helperName(),
+				Image: HelperImage,
+				Binds: []string{
+					fmt.Sprintf("%s:%s", v.WorkspaceName(), WorkspacePath),
+					fmt.Sprintf("%s:%s", v.ScriptsName(), ScriptsPath),
+				},
+			})
+		},
+	}
+
+	for i, step := range steps {
+		if err := step(); err != nil {
+			v.teardown(i)
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+// WorkspaceName returns the name of the volume holding the workspace.
+func (v *Volume) WorkspaceName() string {
+	return fmt.Sprintf("%s-workspace", v.runID)
+}
+
+// ScriptsName returns the name of the volume holding task scripts.
+func (v *Volume) ScriptsName() string {
+	return fmt.Sprintf("%s-scripts", v.runID)
+}
+
+// Attach marks the workspace volume as in use by a running container.
+// Transfers wait until each attached container calls Detach.
+func (v *Volume) Attach() {
+	v.mutex.RLock()
+}
+
+// Detach releases a container previously attached to the volume.
+func (v *Volume) Detach() {
+	v.mutex.RUnlock()
+}
+
+// Push copies the contents of the given host directory into the workspace
+// volume. Ownership is preserved so that tasks running as the current user
+// can modify the files. Files absent from the host are not removed.
+func (v *Volume) Push(ctx context.Context, src string) error {
+	v.logger.Debug(
+		nil,
+		"Copying workspace into volume",
+	)
+
+	files, err := walkFiles(src)
+	if err != nil {
+		return err
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	return v.copyFiles(ctx, src, files, WorkspacePath)
+}
+
+// Pull copies the contents of the workspace volume into the given host
+// directory. Files absent from the volume are not removed.
+func (v *Volume) Pull(ctx context.Context, dest string) error {
+	v.logger.Debug(
+		nil,
+		"Copying workspace out of volume",
+	)
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	return v.runtime.CopyFrom(ctx, v.helperName(), WorkspacePath+"/.", dest)
+}
+
+// PushPaths copies the given files, relative to the given host directory,
+// into the same paths of the workspace volume. The files are streamed as
+// an archive along with their parent directories so that ownership of
+// new directories is preserved as well.
+func (v *Volume) PushPaths(ctx context.Context, src string, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	v.logger.Debug(
+		nil,
+		"Copying %d files into volume",
+		len(files),
+	)
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	return v.copyFiles(ctx, src, files, WorkspacePath)
+}
+
+// PullPaths copies the given paths of the workspace volume, which may be
+// files or directories, into the same paths of the given host directory.
+// Paths absent from the volume are skipped.
+func (v *Volume) PullPaths(ctx context.Context, dest string, targets []string) error {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	v.logger.Debug(
+		nil,
+		"Copying %s out of volume",
+		strings.Join(targets, ", "),
+	)
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	for _, target := range targets {
+		parent := filepath.Join(dest, filepath.Dir(target))
+		if err := paths.EnsureDirExists(parent, 0755); err != nil {
+			return err
+		}
+
+		err := v.runtime.CopyFrom(
+			ctx,
+			v.helperName(),
+			WorkspacePath+"/"+filepath.ToSlash(target),
+			parent,
+		)
+
+		if err != nil && err != runtime.ErrNoSuchPath {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PushScript copies the given script into the scripts volume and returns
+// its path relative to the root of the volume.
+func (v *Volume) PushScript(ctx context.Context, path string) (string, error) {
+	name := filepath.Base(path)
+
+	if err := v.copyFiles(ctx, filepath.Dir(path), []string{name}, ScriptsPath); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+func (v *Volume) Teardown() {
+	v.logger.Info(
+		nil,
+		"Removing workspace volume",
+	)
+
+	v.teardown(3)
+}
+
+// copyFiles copies the given files, relative to the given host directory,
+// into the given directory of the helper container.
+func (v *Volume) copyFiles(ctx context.Context, src string, files []string, dest string) error {
+	archive := archiveFiles(src, files)
+	defer archive.Close()
+
+	return v.runtime.CopyTo(ctx, v.helperName(), dest, archive)
+}
+
+// teardown removes the first n resources created during setup in the
+// reverse order of their creation.
+func (v *Volume) teardown(n int) {
+	steps := []func() error{
+		func() error { return v.runtime.RemoveVolume(context.Background(), v.WorkspaceName()) },
+		func() error { return v.runtime.RemoveVolume(context.Background(), v.ScriptsName()) },
+		func() error { return v.runtime.Remove(context.Background(), v.helperName()) },
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		if err := steps[i](); err != nil {
+			v.logger.Error(
+				nil,
+				"Failed to remove workspace volume: %s",
+				err.Error(),
+			)
+		}
+	}
+}
+
+func (v *Volume) helperName() string {
+	return fmt.Sprintf("%s-volume", v.runID)
+}
+
+// walkFiles returns the paths of every file and directory beneath the given
+// directory, relative to the directory.
+func walkFiles(root string) ([]string, error) {
+	files := []string{}
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, rel)
+		return nil
+	})
+
+	return files, err
+}
+
+// archiveFiles streams a tar archive of the given files relative to the
+// given directory. Each parent directory is added before its contents. An
+// error reading a file is returned by the reader of the archive, which must
+// be closed by the caller.
+func archiveFiles(root string, files []string) io.ReadCloser {
+	reader, writer := io.Pipe()
+
+	go func() {
+		writer.CloseWithError(writeArchive(writer, root, files))
+	}()
+
+	return reader
+}
+
+func writeArchive(w io.Writer, root string, files []string) error {
+	var (
+		writer = tar.NewWriter(w)
+		added  = map[string]struct{}{}
+	)
+
+	sorted := append([]string{}, files...)
+	sort.Strings(sorted)
+
+	for _, file := range sorted {
+		entries := []string{}
+		for dir := filepath.Dir(file); dir != "." && dir != "/"; dir = filepath.Dir(dir) {
+			entries = append([]string{dir}, entries...)
+		}
+
+		for _, entry := range append(entries, file) {
+			if _, ok := added[entry]; ok {
+				continue
+			}
+
+			added[entry] = struct{}{}
+
+			if err := addArchiveEntry(writer, root, entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	return writer.Close()
+}
+
+func addArchiveEntry(writer *tar.Writer, root, name string) error {
+	path := filepath.Join(root, name)
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	link := ""
+	if info.Mode()&os.ModeSymlink != 0 {
+		if link, err = os.Readlink(path); err != nil {
+			return err
+		}
+	}
+
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+
+	header.Name = filepath.ToSlash(name)
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	if err := writer.WriteHeader(header); err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	_, err = io.Copy(writer, file)
+	return err
+}
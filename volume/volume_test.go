@@ -0,0 +1,187 @@
+package volume
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aphistic/sweet"
+	"github.com/ij-build/ij/logging"
+	"github.com/ij-build/ij/runtime"
+	. "github.com/onsi/gomega"
+)
+
+type VolumeSuite struct{}
+
+func (s *VolumeSuite) TestSetupTeardown(t sweet.T) {
+	containerRuntime := runtime.NewFakeRuntime()
+
+	volume, err := NewVolume(
+		context.Background(),
+		"abcdef0",
+		containerRuntime,
+		logging.NilLogger,
+	)
+
+	Expect(err).To(BeNil())
+	Expect(containerRuntime.Volumes).To(HaveKey("abcdef0-workspace"))
+	Expect(containerRuntime.Volumes).To(HaveKey("abcdef0-scripts"))
+	Expect(containerRuntime.Created).To(Equal([]*runtime.RunOptions{
+		&runtime.RunOptions{
+			Name:  "abcdef0-volume",
+			Image: HelperImage,
+			Binds: []string{
+				"abcdef0-workspace:/workspace",
+				"abcdef0-scripts:/scripts",
+			},
+		},
+	}))
+
+	volume.Teardown()
+	Expect(containerRuntime.Volumes).To(BeEmpty())
+	Expect(containerRuntime.Removed).To(Equal([]string{"abcdef0-volume"}))
+}
+
+func (s *VolumeSuite) TestSetupError(t sweet.T) {
+	containerRuntime := runtime.NewFakeRuntime()
+	containerRuntime.Errors["CreateContainer"] = fmt.Errorf("utoh")
+
+	volume, err := NewVolume(
+		context.Background(),
+		"abcdef0",
+		containerRuntime,
+		logging.NilLogger,
+	)
+
+	Expect(err).To(MatchError("utoh"))
+	Expect(volume).To(BeNil())
+
+	// Volumes created before the failure are removed
+	Expect(containerRuntime.Volumes).To(BeEmpty())
+	Expect(containerRuntime.Removed).To(BeEmpty())
+}
+
+func (s *VolumeSuite) TestPushPull(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	Expect(os.MkdirAll(filepath.Join(name, "ws", "empty"), 0755)).To(BeNil())
+	Expect(ioutil.WriteFile(filepath.Join(name, "ws", "main.go"), []byte("main"), 0644)).To(BeNil())
+	Expect(ioutil.WriteFile(filepath.Join(name, "123"), []byte("script"), 0755)).To(BeNil())
+
+	containerRuntime := runtime.NewFakeRuntime()
+
+	volume, err := NewVolume(
+		context.Background(),
+		"abcdef0",
+		containerRuntime,
+		logging.NilLogger,
+	)
+
+	Expect(err).To(BeNil())
+	Expect(volume.Push(context.Background(), filepath.Join(name, "ws"))).To(BeNil())
+	Expect(volume.Pull(context.Background(), filepath.Join(name, "ws"))).To(BeNil())
+
+	script, err := volume.PushScript(context.Background(), filepath.Join(name, "123"))
+	Expect(err).To(BeNil())
+	Expect(script).To(Equal("123"))
+
+	Expect(containerRuntime.Copied).To(Equal(map[string][]string{
+		"abcdef0-volume:/workspace": []string{"empty/", "main.go"},
+		"abcdef0-volume:/scripts":   []string{"123"},
+	}))
+
+	Expect(containerRuntime.CopiedFrom).To(Equal([]string{"abcdef0-volume:/workspace/."}))
+}
+
+func (s *VolumeSuite) TestPushMissingDirectory(t sweet.T) {
+	volume, err := NewVolume(
+		context.Background(),
+		"abcdef0",
+		runtime.NewFakeRuntime(),
+		logging.NilLogger,
+	)
+
+	Expect(err).To(BeNil())
+	Expect(volume.Push(context.Background(), "/does/not/exist")).NotTo(BeNil())
+}
+
+func (s *VolumeSuite) TestPushPaths(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	Expect(os.MkdirAll(filepath.Join(name, "bin", "linux"), 0755)).To(BeNil())
+	Expect(ioutil.WriteFile(filepath.Join(name, "bin", "linux", "app"), []byte("app"), 0755)).To(BeNil())
+	Expect(ioutil.WriteFile(filepath.Join(name, "bin", "checksums"), []byte("sums"), 0644)).To(BeNil())
+
+	containerRuntime := runtime.NewFakeRuntime()
+
+	volume, err := NewVolume(
+		context.Background(),
+		"abcdef0",
+		containerRuntime,
+		logging.NilLogger,
+	)
+
+	Expect(err).To(BeNil())
+	Expect(volume.PushPaths(context.Background(), name, []string{"bin/linux/app", "bin/checksums"})).To(BeNil())
+	Expect(volume.PushPaths(context.Background(), name, nil)).To(BeNil())
+
+	Expect(containerRuntime.Copied).To(Equal(map[string][]string{
+		"abcdef0-volume:/workspace": []string{"bin/", "bin/checksums", "bin/linux/", "bin/linux/app"},
+	}))
+}
+
+func (s *VolumeSuite) TestPullPaths(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	containerRuntime := runtime.NewFakeRuntime()
+	containerRuntime.MissingPaths["abcdef0-volume:/workspace/missing"] = struct{}{}
+
+	volume, err := NewVolume(
+		context.Background(),
+		"abcdef0",
+		containerRuntime,
+		logging.NilLogger,
+	)
+
+	Expect(err).To(BeNil())
+	Expect(volume.PullPaths(context.Background(), name, []string{"dist/linux", "missing"})).To(BeNil())
+	Expect(containerRuntime.CopiedFrom).To(Equal([]string{"abcdef0-volume:/workspace/dist/linux"}))
+
+	// Parent directories are created on the host
+	Expect(filepath.Join(name, "dist")).To(BeADirectory())
+
+	containerRuntime.Errors["CopyFrom"] = fmt.Errorf("utoh")
+	Expect(volume.PullPaths(context.Background(), name, []string{"dist/linux"})).To(MatchError("utoh"))
+}
+
+func (s *VolumeSuite) TestTransfersWaitForAttached(t sweet.T) {
+	name, _ := ioutil.TempDir("", "ij-test")
+	defer os.RemoveAll(name)
+
+	volume, err := NewVolume(
+		context.Background(),
+		"abcdef0",
+		runtime.NewFakeRuntime(),
+		logging.NilLogger,
+	)
+
+	Expect(err).To(BeNil())
+
+	volume.Attach()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		volume.Push(context.Background(), name)
+	}()
+
+	Consistently(done, 50*time.Millisecond).ShouldNot(BeClosed())
+	volume.Detach()
+	Eventually(done).Should(BeClosed())
+}